@@ -0,0 +1,4 @@
+// Package proto holds the gRPC API definitions and the code generated from them
+package proto
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative marketdata.proto
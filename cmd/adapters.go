@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"marketdata/config"
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/infrastructure/messaging/kafka"
+	memorymessaging "marketdata/internal/infrastructure/messaging/memory"
+	"marketdata/internal/infrastructure/persistence/memory"
+	"marketdata/internal/infrastructure/persistence/redis"
+	"marketdata/internal/infrastructure/persistence/timescale"
+	"marketdata/pkg/logger"
+	"marketdata/pkg/metrics"
+)
+
+// adapters are the storage and messaging implementations selected by
+// storage.backend
+type adapters struct {
+	orderbookRepo output.OrderBookRepositoryPort
+	tradeRepo     output.TradeRepositoryPort
+	snapshotRepo  output.OrderBookSnapshotRepositoryPort
+	analyticsRepo output.AnalyticsRepositoryPort
+	publisher     output.EventPublisherPort
+	// arbitragePublisher is nil when events only reach in-process watchers
+	arbitragePublisher output.ArbitragePublisherPort
+	// signalPublisher is nil when statistical arbitrage signals are only logged
+	signalPublisher output.StatArbSignalPublisherPort
+	checkers        []output.HealthCheckPort
+	closers         []func(ctx context.Context) error
+}
+
+func newAdapters(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics) (*adapters, error) {
+	switch cfg.Storage.Backend {
+	case "", config.StorageExternal:
+		return newExternalAdapters(ctx, cfg, log, m)
+	case config.StorageMemory:
+		return newMemoryAdapters(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Storage.Backend)
+	}
+}
+
+// newExternalAdapters connects to Redis, TimescaleDB and Kafka
+func newExternalAdapters(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics) (*adapters, error) {
+	dbClient, err := timescale.Open(ctx, cfg.Database.DSN(), cfg.Database.MaxOpenConns)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Database.MigrateOnStartup {
+		if err := runMigrations(ctx, dbClient, log); err != nil {
+			dbClient.Close()
+			return nil, fmt.Errorf("failed to run migrations: %w", err)
+		}
+	}
+
+	var deadLetter *timescale.DeadLetterQueue
+	if dir := cfg.Database.TradeBatch.DeadLetterDir; dir != "" {
+		if deadLetter, err = timescale.NewDeadLetterQueue(dir); err != nil {
+			dbClient.Close()
+			return nil, err
+		}
+	}
+
+	redisClient := goredis.NewClient(&goredis.Options{
+		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	orderbookRepo := redis.NewOrderBookRepository(redisClient, cfg.Redis.TTL)
+	tradeRepo := timescale.NewBatchTradeWriter(
+		timescale.NewTradeRepository(dbClient),
+		timescale.BatchConfig{
+			BatchSize:     cfg.Database.TradeBatch.Size,
+			FlushInterval: cfg.Database.TradeBatch.FlushInterval,
+			MaxPending:    cfg.Database.TradeBatch.MaxPending,
+			DeadLetter:    deadLetter,
+		},
+		log,
+		m,
+	)
+	publisher := kafka.NewPublisher(cfg.Kafka.Brokers, cfg.Kafka.Topic)
+	arbitragePublisher := kafka.NewArbitragePublisher(cfg.Kafka.Brokers, cfg.Kafka.ArbitrageTopic)
+	signalPublisher := kafka.NewSignalPublisher(cfg.Kafka.Brokers, cfg.Kafka.SignalTopic)
+
+	return &adapters{
+		orderbookRepo:      orderbookRepo,
+		tradeRepo:          tradeRepo,
+		snapshotRepo:       timescale.NewOrderBookSnapshotRepository(dbClient),
+		analyticsRepo:      timescale.NewAnalyticsRepository(dbClient),
+		publisher:          publisher,
+		arbitragePublisher: arbitragePublisher,
+		signalPublisher:    signalPublisher,
+		checkers:           []output.HealthCheckPort{orderbookRepo, tradeRepo, publisher},
+		// Flush buffered trades before the connections they need are closed
+		closers: []func(ctx context.Context) error{
+			tradeRepo.Close,
+			func(context.Context) error { return publisher.Close() },
+			func(context.Context) error { return arbitragePublisher.Close() },
+			func(context.Context) error { return signalPublisher.Close() },
+			func(context.Context) error { return redisClient.Close() },
+			func(context.Context) error { return dbClient.Close() },
+		},
+	}, nil
+}
+
+// newMemoryAdapters keeps all state in process; nothing survives a restart
+func newMemoryAdapters(cfg *config.Config) *adapters {
+	tradeRepo := memory.NewTradeRepository(cfg.Storage.Memory.MaxTrades)
+
+	return &adapters{
+		orderbookRepo: memory.NewOrderBookRepository(cfg.Redis.TTL),
+		tradeRepo:     tradeRepo,
+		snapshotRepo:  memory.NewOrderBookSnapshotRepository(cfg.Storage.Memory.MaxSnapshotsPerBook),
+		analyticsRepo: tradeRepo,
+		publisher:     memorymessaging.NewPublisher(cfg.Storage.Memory.MaxEvents),
+		checkers:      []output.HealthCheckPort{},
+		closers:       []func(ctx context.Context) error{},
+	}
+}
+
+// close releases the adapters in order, logging failures
+func (a *adapters) close(ctx context.Context, log *logger.Logger) {
+	for _, closeFn := range a.closers {
+		if err := closeFn(ctx); err != nil {
+			log.Error("error closing adapter", "error", err)
+		}
+	}
+}
+
+// newToolAdapters wires the adapters for commands that run beside the
+// service. They never migrate, and need shared storage because the memory
+// backend only exists inside the serving process.
+func newToolAdapters(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics) (*adapters, error) {
+	if cfg.Storage.Backend == config.StorageMemory {
+		return nil, fmt.Errorf("storage backend %q keeps its state inside the serving process", cfg.Storage.Backend)
+	}
+
+	toolCfg := *cfg
+	toolCfg.Database.MigrateOnStartup = false
+	return newAdapters(ctx, &toolCfg, log, m)
+}
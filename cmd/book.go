@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"marketdata/internal/domain/entity"
+	"marketdata/pkg/metrics"
+)
+
+// runBook prints the stored orderbook of one instrument, redrawing it in
+// place on a terminal until interrupted
+func runBook(ctx context.Context, env *cli, args []string) error {
+	flags := newFlags("book", "book [flags] <exchange> <symbol>")
+	depth := flags.Int("depth", 10, "levels to show per side")
+	interval := flags.Duration("interval", time.Second, "refresh interval")
+	once := flags.Bool("once", false, "print the book once and exit")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return usageError("book needs an exchange and a symbol")
+	}
+	exchangeID, symbol := flags.Arg(0), flags.Arg(1)
+
+	cfg, err := env.loadConfig()
+	if err != nil {
+		return err
+	}
+	infra, err := newToolAdapters(ctx, cfg, env.log, metrics.NewMetrics("marketdata"))
+	if err != nil {
+		return err
+	}
+	defer infra.close(context.Background(), env.log)
+
+	live := !*once && isTerminal(os.Stdout)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		orderbook, err := infra.orderbookRepo.Get(ctx, exchangeID, symbol)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get orderbook: %w", err)
+		}
+
+		if live {
+			// Move home and clear the screen
+			fmt.Print("\033[H\033[2J")
+		}
+		printBook(os.Stdout, exchangeID, symbol, orderbook, *depth)
+
+		if !live {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// printBook shows asks above bids so the spread sits in the middle
+func printBook(out io.Writer, exchangeID, symbol string, orderbook *entity.OrderBook, depth int) {
+	if orderbook == nil {
+		fmt.Fprintf(out, "%s %s: no orderbook\n", exchangeID, symbol)
+		return
+	}
+
+	fmt.Fprintf(out, "%s %s  %s\n\n", exchangeID, symbol, orderbook.Timestamp().Format(time.RFC3339Nano))
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "side\tprice\tquantity\t")
+
+	asks := orderbook.Asks()[:min(depth, len(orderbook.Asks()))]
+	for i := len(asks) - 1; i >= 0; i-- {
+		fmt.Fprintf(w, "ask\t%g\t%g\t\n", asks[i].Price.Value(), asks[i].Quantity.Value())
+	}
+
+	bid, hasBid := orderbook.BestBid()
+	ask, hasAsk := orderbook.BestAsk()
+	if hasBid && hasAsk {
+		spread := ask.Price.Value() - bid.Price.Value()
+		mid := (ask.Price.Value() + bid.Price.Value()) / 2
+		fmt.Fprintf(w, "spread\t%g\t%.2f bps\t\n", spread, spread/mid*1e4)
+	}
+
+	bids := orderbook.Bids()[:min(depth, len(orderbook.Bids()))]
+	for _, level := range bids {
+		fmt.Fprintf(w, "bid\t%g\t%g\t\n", level.Price.Value(), level.Quantity.Value())
+	}
+	w.Flush()
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"marketdata/config"
+)
+
+// runConfig checks the config file and environment, listing every problem,
+// including keys that would otherwise be ignored silently
+func runConfig(ctx context.Context, env *cli, args []string) error {
+	if len(args) == 0 || args[0] != "validate" {
+		return usageError("unknown config command")
+	}
+	if err := parseFlags(newFlags("config validate", "config validate"), args[1:]); err != nil {
+		return err
+	}
+
+	if _, err := config.LoadStrict(env.configPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return errReported
+	}
+
+	fmt.Println("config is valid")
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"marketdata/internal/infrastructure/persistence/timescale"
+)
+
+// runDLQ writes trade batches the service dead-lettered back to TimescaleDB
+func runDLQ(ctx context.Context, env *cli, args []string) error {
+	if len(args) == 0 || args[0] != "replay" {
+		return usageError("unknown dlq command")
+	}
+	if err := parseFlags(newFlags("dlq replay", "dlq replay"), args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := env.loadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.Database.TradeBatch.DeadLetterDir == "" {
+		return fmt.Errorf("database.trade_batch.dead_letter_dir is not configured")
+	}
+
+	queue, err := timescale.NewDeadLetterQueue(cfg.Database.TradeBatch.DeadLetterDir)
+	if err != nil {
+		return err
+	}
+	files, err := queue.Files()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		env.log.Info("dead letter queue is empty")
+		return nil
+	}
+
+	dbClient, err := timescale.Open(ctx, cfg.Database.DSN(), cfg.Database.MaxOpenConns)
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	replayed, err := queue.Replay(ctx, timescale.NewTradeRepository(dbClient).StoreTrades)
+	env.log.Info("replayed dead letter queue", "batches", len(files), "trades", replayed)
+	return err
+}
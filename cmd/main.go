@@ -2,57 +2,155 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"marketdata/config"
-	"marketdata/internal/application/service"
-	"marketdata/internal/domain/repository"
-	"marketdata/internal/infrastructure/exchange"
 	"marketdata/pkg/logger"
 )
 
+// command is one subcommand; args are what follows its name
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(ctx context.Context, env *cli, args []string) error
+}
+
+// cli is what every subcommand shares: the config location and the logger
+type cli struct {
+	configPath string
+	log        *logger.Logger
+}
+
+// loadConfig reads the config every subcommand uses
+func (c *cli) loadConfig() (*config.Config, error) {
+	return config.LoadFile(c.configPath)
+}
+
+var commands = []command{
+	{"serve", "serve", "run the market data service (default)", runServe},
+	{"migrate", "migrate", "apply pending database migrations", runMigrate},
+	{"book", "book [flags] <exchange> <symbol>", "show a live orderbook", runBook},
+	{"trades", "trades [flags]", "tail stored trades", runTrades},
+	{"record", "record [flags]", "record raw exchange frames without serving", runRecord},
+	{"replay", "replay [flags] <files or directories>", "replay recordings through the live pipeline", runReplay},
+	{"backfill", "backfill [flags] <files or directories>", "load recorded trades and book snapshots into TimescaleDB", runBackfill},
+	{"dlq", "dlq replay", "write dead-lettered trade batches to TimescaleDB", runDLQ},
+	{"config", "config validate", "check the config file", runConfig},
+}
+
 func main() {
-	// Initialize logger
 	log := logger.NewLogger()
 	defer log.Sync()
 
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatal("failed to load config", err)
+	flags := flag.NewFlagSet("marketdata", flag.ContinueOnError)
+	configPath := flags.String("config", "", "config file (default: config.yaml in . or ./config)")
+	flags.Usage = func() { usage(flags) }
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		os.Exit(2)
 	}
 
-	// Create context with cancellation
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	args := flags.Args()
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
 
-	// Initialize market data service
-	svc, err := service.NewMarketDataService(
-		ctx,
-		cfg,
-		log,
-		repository.NewOrderBookRepository(redisClient),
-		repository.NewTradeRepository(dbClient),
-		exchange.NewExchangeManager(cfg.Exchange),
-	)
-	if err != nil {
-		log.Fatal("failed to create market data service", err)
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage(flags)
+		os.Exit(2)
 	}
 
-	// Start the service
-	if err := svc.Start(ctx); err != nil {
-		log.Fatal("failed to start market data service", err)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	err := cmd.run(ctx, &cli{configPath: *configPath, log: log}, args)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errFlags):
+		os.Exit(2)
+	case errors.Is(err, errReported):
+		os.Exit(1)
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(os.Stderr, "%v\nusage: marketdata %s\n", err, cmd.usage)
+		os.Exit(2)
+	default:
+		log.Fatal(cmd.name+" failed", err)
 	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
 
-	// Handle graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
+func usage(flags *flag.FlagSet) {
+	out := flags.Output()
+	fmt.Fprintln(out, "usage: marketdata [-config file] <command> [args]")
+	fmt.Fprintln(out, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-40s %s\n", cmd.usage, cmd.summary)
+	}
+	fmt.Fprintln(out, "\nflags:")
+	flags.PrintDefaults()
+}
+
+// errUsage reports wrong arguments; main prints the command's usage with it
+var errUsage = errors.New("invalid arguments")
+
+func usageError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+var (
+	// errFlags reports a flag parsing error the flag package already printed
+	errFlags = errors.New("invalid flags")
+	// errReported ends a command whose failure it already explained
+	errReported = errors.New("command failed")
+)
+
+// newFlags creates a subcommand flag set whose help lists the command usage
+func newFlags(name, synopsis string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: marketdata %s\n", synopsis)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errFlags
+	}
+	return nil
+}
 
-	log.Info("shutting down market data service...")
-	if err := svc.Stop(); err != nil {
-		log.Error("error during shutdown", err)
+// splitList parses a comma separated flag value
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"marketdata/internal/infrastructure/persistence/timescale"
+	"marketdata/pkg/logger"
+)
+
+// runMigrate applies pending schema migrations and exits
+func runMigrate(ctx context.Context, env *cli, args []string) error {
+	if err := parseFlags(newFlags("migrate", "migrate"), args); err != nil {
+		return err
+	}
+
+	cfg, err := env.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	dbClient, err := timescale.Open(ctx, cfg.Database.DSN(), cfg.Database.MaxOpenConns)
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	if err := runMigrations(ctx, dbClient, env.log); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	return nil
+}
+
+func runMigrations(ctx context.Context, db *sql.DB, log *logger.Logger) error {
+	migrator, err := timescale.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(ctx)
+	for _, migration := range applied {
+		log.Info("applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		log.Info("database schema is up to date")
+	}
+	return nil
+}
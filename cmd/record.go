@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"marketdata/config"
+	"marketdata/internal/application/port/output"
+	"marketdata/pkg/metrics"
+)
+
+// runRecord connects to the exchanges and records their raw frames without
+// storing or serving anything, for capturing test fixtures and incidents
+func runRecord(ctx context.Context, env *cli, args []string) error {
+	flags := newFlags("record", "record [flags]")
+	dir := flags.String("dir", "", "recording directory (default: recorder.dir)")
+	symbols := flags.String("symbols", "", "comma separated symbols (default: binance symbols set to record)")
+	duration := flags.Duration("duration", 0, "stop after this long; 0 records until interrupted")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	cfg, err := env.loadConfig()
+	if err != nil {
+		return err
+	}
+	if *dir != "" {
+		cfg.Recorder.Dir = *dir
+	}
+
+	var subscribe []config.SymbolSubscription
+	for _, symbol := range splitList(*symbols) {
+		subscribe = append(subscribe, config.SymbolSubscription{Exchange: "binance", Symbol: symbol, SymbolSettings: cfg.Exchange.Defaults})
+	}
+	if len(subscribe) == 0 {
+		subscribe = recordedSymbols(cfg.SubscriptionPlan(), "binance")
+	}
+	if len(subscribe) == 0 {
+		return usageError("no symbols to record")
+	}
+	names := make([]string, len(subscribe))
+	for i, sub := range subscribe {
+		names[i] = sub.Symbol
+	}
+
+	// Record exactly what is subscribed
+	rec, err := newRecorder(cfg.Recorder, map[string][]string{"binance": names}, env.log, metrics.NewMetrics("marketdata"))
+	if err != nil {
+		return err
+	}
+	defer rec.Close()
+
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	client := newBinanceClient(cfg, rec, nil)
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect to binance: %w", err)
+	}
+	defer client.Close()
+
+	// The client records every frame it reads; the decoded books are discarded
+	var wg sync.WaitGroup
+	for _, sub := range subscribe {
+		updates, err := client.SubscribeOrderBookWithOptions(ctx, sub.Symbol, output.OrderBookStreamOptions{
+			Depth:       sub.Depth,
+			UpdateSpeed: sub.UpdateSpeed,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", sub.Symbol, err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case _, ok := <-updates:
+					if !ok {
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	env.log.Info("recording", "exchange", "binance", "symbols", names, "dir", cfg.Recorder.Dir)
+	started := time.Now()
+	<-ctx.Done()
+	wg.Wait()
+	env.log.Info("recording stopped", "duration", time.Since(started).Round(time.Second))
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"slices"
+
+	"marketdata/config"
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+	"marketdata/pkg/logger"
+)
+
+// configActor names config changes in the subscription audit log
+const configActor = "config"
+
+// subscriptionSpecs converts the plan entries of the given exchanges for the
+// subscription service
+func subscriptionSpecs(plan []config.SymbolSubscription, exchanges []string) []dto.SubscriptionSpecDTO {
+	var specs []dto.SubscriptionSpecDTO
+	for _, sub := range plan {
+		if slices.Contains(exchanges, sub.Exchange) {
+			spec := specOf(sub.SymbolSettings)
+			spec.ExchangeID, spec.Symbol = sub.Exchange, sub.Symbol
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// specOf converts symbol settings; the exchange and symbol are left empty
+func specOf(settings config.SymbolSettings) dto.SubscriptionSpecDTO {
+	return dto.SubscriptionSpecDTO{
+		Depth:             settings.Depth,
+		UpdateSpeedMs:     settings.UpdateSpeed.Milliseconds(),
+		Record:            settings.Record,
+		PublishThrottleMs: settings.PublishThrottle.Milliseconds(),
+	}
+}
+
+// recordedSymbols returns the plan entries of one exchange set to record
+func recordedSymbols(plan []config.SymbolSubscription, exchange string) []config.SymbolSubscription {
+	var subs []config.SymbolSubscription
+	for _, sub := range plan {
+		if sub.Exchange == exchange && sub.Record {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// unservedExchanges lists the exchanges with planned symbols but no adapter
+func unservedExchanges(plan []config.SymbolSubscription, exchanges []string) []string {
+	var unserved []string
+	for _, sub := range plan {
+		if !slices.Contains(exchanges, sub.Exchange) && !slices.Contains(unserved, sub.Exchange) {
+			unserved = append(unserved, sub.Exchange)
+		}
+	}
+	return unserved
+}
+
+// watchConfig applies symbol changes in the config file to the running
+// subscriptions of the live exchanges; simulated markets are fixed at start.
+// Subscriptions added or removed through the admin API are kept unless the
+// edit names the same symbols. Other settings only take effect on restart,
+// which is logged.
+func watchConfig(ctx context.Context, path string, cfg *config.Config, live []string, subscriptions input.SubscriptionUseCase, log *logger.Logger) {
+	current := cfg
+	file, err := config.Watch(path, func(next *config.Config, err error) {
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Error("ignoring config change", "error", err)
+			return
+		}
+
+		log.Info("config changed, applying symbol changes")
+		previous := subscriptionSpecs(current.SubscriptionPlan(), live)
+		if err := subscriptions.Reconcile(ctx, previous, subscriptionSpecs(next.SubscriptionPlan(), live), configActor); err != nil {
+			log.Error("failed to apply some symbol changes", "error", err)
+		}
+		if sections := restartSections(current, next); len(sections) > 0 {
+			log.Info("config changes need a restart to take effect", "sections", sections)
+		}
+		current = next
+	})
+	if err != nil {
+		log.Error("config hot reload is disabled", "error", err)
+		return
+	}
+	log.Info("watching config for symbol changes", "file", file)
+}
+
+// restartSections names the config sections that changed apart from the
+// symbol lists hot reload applies. exchange.defaults is applied to planned
+// symbols, but still reported because admin API subscriptions keep the
+// defaults the service started with.
+func restartSections(previous, next *config.Config) []string {
+	a, b := *previous, *next
+	for _, c := range []*config.Config{&a, &b} {
+		c.Exchange.Symbols = nil
+		c.Exchange.Binance.Symbols = nil
+		c.Exchange.OKX.Symbols = nil
+	}
+
+	sections := []struct {
+		name           string
+		previous, next interface{}
+	}{
+		{"server", a.Server, b.Server},
+		{"storage", a.Storage, b.Storage},
+		{"database", a.Database, b.Database},
+		{"redis", a.Redis, b.Redis},
+		{"kafka", a.Kafka, b.Kafka},
+		{"exchange", a.Exchange, b.Exchange},
+		{"recorder", a.Recorder, b.Recorder},
+		{"admin", a.Admin, b.Admin},
+		{"arbitrage", a.Arbitrage, b.Arbitrage},
+		{"fees", a.Fees, b.Fees},
+		{"indicators", a.Indicators, b.Indicators},
+		{"risk", a.Risk, b.Risk},
+		{"sizing", a.Sizing, b.Sizing},
+	}
+
+	var changed []string
+	for _, section := range sections {
+		if !reflect.DeepEqual(section.previous, section.next) {
+			changed = append(changed, section.name)
+		}
+	}
+	return changed
+}
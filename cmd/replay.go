@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"marketdata/config"
+	"marketdata/internal/domain/entity"
+	"marketdata/internal/infrastructure/exchange"
+	"marketdata/internal/infrastructure/exchange/binance"
+	"marketdata/internal/infrastructure/exchange/replay"
+	"marketdata/internal/infrastructure/persistence/timescale"
+)
+
+const (
+	defaultBackfillInterval = time.Minute
+	defaultBackfillDepth    = 50
+	defaultBackfillBatch    = 1000
+)
+
+// decoders lists the exchanges whose recordings can be replayed
+var decoders = map[string]func() exchange.FrameDecoder{
+	"binance": func() exchange.FrameDecoder { return binance.NewDecoder() },
+}
+
+// recordingOptions select what to play from a set of recordings
+type recordingOptions struct {
+	exchange string
+	symbols  []string
+	files    []string
+	speed    float64
+	from     time.Time
+	to       time.Time
+}
+
+// recordingFlags registers the flags shared by replay and backfill
+func recordingFlags(name, synopsis string) (*flag.FlagSet, *recordingFlagValues) {
+	flags := newFlags(name, synopsis)
+	values := &recordingFlagValues{
+		exchange: flags.String("exchange", "binance", "exchange whose frames are played"),
+		symbols:  flags.String("symbols", "", "comma separated symbols (default: the exchange's symbols set to record)"),
+		from:     flags.String("from", "", "skip frames received before this RFC 3339 time"),
+		to:       flags.String("to", "", "stop at frames received at or after this RFC 3339 time"),
+	}
+	return flags, values
+}
+
+type recordingFlagValues struct {
+	exchange *string
+	symbols  *string
+	from     *string
+	to       *string
+}
+
+// options validates the flag values against the config
+func (v *recordingFlagValues) options(cfg *config.Config, files []string) (recordingOptions, error) {
+	opts := recordingOptions{exchange: *v.exchange, files: files}
+
+	if _, ok := decoders[opts.exchange]; !ok {
+		return opts, usageError("recordings of %q cannot be decoded", opts.exchange)
+	}
+	if len(files) == 0 {
+		return opts, usageError("no recordings given")
+	}
+
+	opts.symbols = splitList(*v.symbols)
+	if len(opts.symbols) == 0 {
+		for _, sub := range recordedSymbols(cfg.SubscriptionPlan(), opts.exchange) {
+			opts.symbols = append(opts.symbols, sub.Symbol)
+		}
+	}
+	if len(opts.symbols) == 0 {
+		return opts, usageError("no symbols to play")
+	}
+
+	var err error
+	if opts.from, err = parseTimeFlag("from", *v.from); err != nil {
+		return opts, err
+	}
+	if opts.to, err = parseTimeFlag("to", *v.to); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+func parseTimeFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, usageError("-%s must be an RFC 3339 time", name)
+	}
+	return t, nil
+}
+
+// playRecordings plays the recordings and calls handle for every book and
+// trade in recorded order, from a single goroutine, so handlers need no
+// locking. It returns once playback ends or handle fails.
+func playRecordings(ctx context.Context, opts recordingOptions, handle func(book *entity.OrderBook, trade *entity.Trade) error) (*replay.Exchange, error) {
+	player := replay.NewExchange(replayConfig(opts))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates, err := player.SubscribeAll(ctx, opts.symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	playErr := make(chan error, 1)
+	go func() {
+		playErr <- player.Play(ctx)
+	}()
+
+	for update := range updates {
+		if err := handle(update.Book, update.Trade); err != nil {
+			cancel()
+			for range updates {
+			}
+			return player, err
+		}
+	}
+
+	if err := <-playErr; err != nil && ctx.Err() == nil {
+		return player, fmt.Errorf("failed to play recordings: %w", err)
+	}
+	return player, nil
+}
+
+// replayConfig plays the selected recordings with the exchange's decoder
+func replayConfig(opts recordingOptions) replay.Config {
+	return replay.Config{
+		Name:    opts.exchange,
+		Files:   opts.files,
+		Decoder: decoders[opts.exchange](),
+		Speed:   opts.speed,
+		Start:   opts.from,
+		End:     opts.to,
+	}
+}
+
+// runReplay plays recordings at their original pace, or faster. By default it
+// prints the books and trades; with -serve it runs the service with the
+// recordings in place of the live exchange, see config.ReplayExchangeConfig.
+func runReplay(ctx context.Context, env *cli, args []string) error {
+	flags, values := recordingFlags("replay", "replay [flags] <files or directories>")
+	speed := flags.Float64("speed", 1, "playback speed; 1 is real time, 0 as fast as possible")
+	serve := flags.Bool("serve", false, "run the recordings through the service instead of printing")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *speed < 0 {
+		return usageError("-speed cannot be negative")
+	}
+	if *serve && *values.symbols != "" {
+		return usageError("-symbols does not apply to -serve; the config's subscriptions pick the symbols")
+	}
+
+	cfg, err := env.loadConfig()
+	if err != nil {
+		return err
+	}
+	opts, err := values.options(cfg, flags.Args())
+	if err != nil {
+		return err
+	}
+	opts.speed = *speed
+
+	if *serve {
+		cfg.Exchange.Replay = config.ReplayExchangeConfig{
+			Enabled:  true,
+			Exchange: opts.exchange,
+			Files:    opts.files,
+			Speed:    opts.speed,
+			From:     opts.from,
+			To:       opts.to,
+		}
+		return serveConfig(ctx, env, cfg)
+	}
+
+	var books, trades int
+	player, err := playRecordings(ctx, opts, func(book *entity.OrderBook, trade *entity.Trade) error {
+		switch {
+		case book != nil:
+			books++
+			printTopOfBook(book)
+		case trade != nil:
+			trades++
+			printTrades(os.Stdout, []*entity.Trade{trade})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	env.log.Info("replay finished",
+		"books", books,
+		"trades", trades,
+		"decode_errors", player.DecodeErrors(),
+	)
+	return nil
+}
+
+func printTopOfBook(book *entity.OrderBook) {
+	bid, _ := book.BestBid()
+	ask, _ := book.BestAsk()
+	fmt.Printf("%s  %-10s %-12s bid %g x %g  ask %g x %g\n",
+		book.Timestamp().Format(time.RFC3339Nano),
+		book.ExchangeID(),
+		book.Symbol(),
+		bid.Price.Value(), bid.Quantity.Value(),
+		ask.Price.Value(), ask.Quantity.Value(),
+	)
+}
+
+// runBackfill loads recorded trades and sampled book snapshots into
+// TimescaleDB as fast as the database accepts them. Rows that already exist
+// are skipped, so overlapping runs are safe.
+func runBackfill(ctx context.Context, env *cli, args []string) error {
+	flags, values := recordingFlags("backfill", "backfill [flags] <files or directories>")
+	interval := flags.Duration("snapshot-interval", 0, "book snapshot spacing (default: database.book_snapshots.interval)")
+	depth := flags.Int("depth", 0, "book snapshot levels per side (default: database.book_snapshots.depth)")
+	batchSize := flags.Int("batch", defaultBackfillBatch, "trades per insert")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	cfg, err := env.loadConfig()
+	if err != nil {
+		return err
+	}
+	opts, err := values.options(cfg, flags.Args())
+	if err != nil {
+		return err
+	}
+
+	*interval = firstPositive(*interval, cfg.Database.BookSnapshots.Interval, defaultBackfillInterval)
+	*depth = firstPositive(*depth, cfg.Database.BookSnapshots.Depth, defaultBackfillDepth)
+	*batchSize = max(*batchSize, 1)
+
+	dbClient, err := timescale.Open(ctx, cfg.Database.DSN(), cfg.Database.MaxOpenConns)
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	tradeRepo := timescale.NewTradeRepository(dbClient)
+	snapshotRepo := timescale.NewOrderBookSnapshotRepository(dbClient)
+
+	var (
+		batch     []*entity.Trade
+		trades    int
+		snapshots int
+		sampled   = make(map[string]time.Time)
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := tradeRepo.StoreTrades(ctx, batch); err != nil {
+			return err
+		}
+		trades += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	player, err := playRecordings(ctx, opts, func(book *entity.OrderBook, trade *entity.Trade) error {
+		if trade != nil {
+			batch = append(batch, trade)
+			if len(batch) >= *batchSize {
+				return flush()
+			}
+			return nil
+		}
+
+		if last, ok := sampled[book.Symbol()]; ok && book.Timestamp().Sub(last) < *interval {
+			return nil
+		}
+		if err := snapshotRepo.StoreSnapshot(ctx, book, *depth); err != nil {
+			return err
+		}
+		sampled[book.Symbol()] = book.Timestamp()
+		snapshots++
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return err
+	}
+
+	env.log.Info("backfill finished",
+		"trades", trades,
+		"snapshots", snapshots,
+		"decode_errors", player.DecodeErrors(),
+	)
+	return nil
+}
+
+func firstPositive[T int | time.Duration](values ...T) T {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
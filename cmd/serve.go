@@ -0,0 +1,547 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"marketdata/config"
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/application/service"
+	"marketdata/internal/domain/entity"
+	domainservice "marketdata/internal/domain/service"
+	"marketdata/internal/infrastructure/audit"
+	"marketdata/internal/infrastructure/exchange"
+	"marketdata/internal/infrastructure/exchange/binance"
+	"marketdata/internal/infrastructure/exchange/recorder"
+	"marketdata/internal/infrastructure/exchange/replay"
+	"marketdata/internal/infrastructure/persistence/statefile"
+	grpcapi "marketdata/internal/interfaces/api/grpc"
+	httpapi "marketdata/internal/interfaces/api/http"
+	"marketdata/pkg/logger"
+	"marketdata/pkg/metrics"
+)
+
+// runServe runs the service until ctx is cancelled by SIGINT or SIGTERM
+func runServe(ctx context.Context, env *cli, args []string) error {
+	if err := parseFlags(newFlags("serve", "serve"), args); err != nil {
+		return err
+	}
+
+	cfg, err := env.loadConfig()
+	if err != nil {
+		return err
+	}
+	return serveConfig(ctx, env, cfg)
+}
+
+// serveConfig runs the service with a loaded config until ctx is cancelled
+// or, with a replay exchange, the recordings are played out
+func serveConfig(ctx context.Context, env *cli, cfg *config.Config) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	log := env.log
+
+	// Initialize infrastructure adapters
+	appMetrics := metrics.NewMetrics("marketdata")
+	infra, err := newAdapters(ctx, cfg, log, appMetrics)
+	if err != nil {
+		return fmt.Errorf("failed to initialize adapters: %w", err)
+	}
+
+	// Record raw exchange frames for offline reproduction
+	var frameRecorder exchange.FrameRecorder
+	var recording output.FrameRecordingPort
+	if cfg.Recorder.Enabled {
+		// Subscriptions enable the symbols set to record
+		rec, err := newRecorder(cfg.Recorder, nil, log, appMetrics)
+		if err != nil {
+			return err
+		}
+		defer rec.Close()
+		frameRecorder, recording = rec, rec
+	}
+
+	// Adapters report sequence gaps to the feed monitor
+	feedMonitor := service.NewFeedMonitor()
+	binanceClient := newBinanceClient(cfg, frameRecorder, feedMonitor)
+	var exchanges []output.ExchangeStatusPort
+	var streamExchanges []output.ExchangePort
+
+	// Recordings play in place of the live exchange they were recorded from
+	var player *replay.Exchange
+	if r := cfg.Exchange.Replay; r.Enabled {
+		if _, ok := decoders[r.Exchange]; !ok {
+			return fmt.Errorf("recordings of %q cannot be decoded", r.Exchange)
+		}
+		replayCfg := replayConfig(recordingOptions{
+			exchange: r.Exchange,
+			files:    r.Files,
+			speed:    r.Speed,
+			from:     r.From,
+			to:       r.To,
+		})
+		// Subscriptions resubscribe to closed streams, so they stay open
+		replayCfg.Hold = true
+		replayCfg.Gaps = feedMonitor
+		player = replay.NewExchange(replayCfg)
+		exchanges = append(exchanges, player)
+		streamExchanges = append(streamExchanges, player)
+	}
+	binanceConnected := false
+	if player == nil || player.GetName() != binanceClient.GetName() {
+		if err := binanceClient.Connect(ctx); err != nil {
+			return fmt.Errorf("failed to connect to binance: %w", err)
+		}
+		binanceConnected = true
+		exchanges = append(exchanges, binanceClient)
+		streamExchanges = append(streamExchanges, binanceClient)
+	}
+
+	// Generate synthetic markets when live venues are unavailable
+	stopSimulated := func(context.Context) {}
+	if cfg.Exchange.Simulated.Enabled {
+		simExchange, stop, err := startSimulated(ctx, cfg.Exchange.Simulated, feedMonitor, log)
+		if err != nil {
+			return fmt.Errorf("failed to start simulated exchange: %w", err)
+		}
+		exchanges = append(exchanges, simExchange)
+		streamExchanges = append(streamExchanges, simExchange)
+		stopSimulated = stop
+	}
+
+	// Fees come from config, refreshed from the accounts we have keys for
+	var feeSources []output.FeeRatesPort
+	if cfg.Exchange.Binance.APIKey != "" && cfg.Exchange.Binance.APISecret != "" {
+		feeSources = append(feeSources, binanceClient)
+	}
+	fees, err := service.NewFeeService(feeRates(cfg.Fees.Default), feeScheduleConfigs(cfg.Fees), feeSources, log)
+	if err != nil {
+		return fmt.Errorf("failed to load fee schedules: %w", err)
+	}
+	if cfg.Fees.RefreshInterval > 0 {
+		go fees.Run(ctx, cfg.Fees.RefreshInterval)
+	}
+
+	// Initialize market data service
+	svc := service.NewMarketDataService(
+		infra.orderbookRepo,
+		infra.tradeRepo,
+		infra.snapshotRepo,
+		streamExchanges,
+		infra.publisher,
+		domainservice.NewOrderBookService(),
+		fees,
+		feedMonitor,
+		log,
+	)
+	statusSvc := service.NewStatusService(
+		infra.checkers,
+		exchanges,
+		feedMonitor,
+	)
+
+	// Stream the planned symbols; the admin API and config reloads change
+	// them while the service runs
+	auditLog, err := audit.NewSubscriptionLog(cfg.Admin.AuditLog, 0)
+	if err != nil {
+		return err
+	}
+	defer auditLog.Close()
+	// Hold fills to the loss limits; a breach halts opportunity execution
+	processors := service.OrderBookProcessors{svc}
+	var risk *service.RiskManager
+	var gate service.ExecutionGate
+	if cfg.Risk.Enabled {
+		var store output.RiskStatePort
+		if path := cfg.Risk.StateFile; path != "" {
+			store = statefile.NewRiskStore(path)
+		}
+		risk = service.NewRiskManager(riskConfig(cfg.Risk), fees, store, log)
+		risk.Start(ctx)
+		processors = append(processors, risk)
+		gate = risk
+	}
+
+	var indicators *service.IndicatorService
+	if cfg.Indicators.Enabled {
+		indicators = service.NewIndicatorService(indicatorConfig(cfg.Indicators), appMetrics)
+		go indicators.Run(ctx)
+		processors = append(processors, indicators)
+	}
+
+	// Size opportunities from the equity, balances, liquidity and volatility
+	var sizer service.OpportunitySizer
+	if cfg.Sizing.Enabled {
+		var accountSources []output.AccountPort
+		if cfg.Exchange.Binance.APIKey != "" && cfg.Exchange.Binance.APISecret != "" {
+			accountSources = append(accountSources, binanceClient)
+		}
+		accounts := service.NewAccountService(cfg.Sizing.DefaultLotSize, accountConfigs(cfg.Sizing), accountSources, log)
+		if cfg.Sizing.RefreshInterval > 0 {
+			go accounts.Run(ctx, cfg.Sizing.RefreshInterval)
+		}
+
+		var equity service.EquityProvider
+		if risk != nil {
+			equity = risk
+		}
+		var volatility service.VolatilityProvider
+		if indicators != nil {
+			volatility = indicators
+		}
+		sizer = service.NewPositionSizer(positionSizingConfig(cfg.Sizing), equity, accounts, volatility)
+	}
+
+	// Scan every streamed book for arbitrage across and within exchanges
+	var scanner *service.ArbitrageScanner
+	if cfg.Arbitrage.Enabled {
+		scanner = service.NewArbitrageScanner(
+			arbitrageScannerConfig(cfg.Arbitrage),
+			domainservice.NewOrderBookService(),
+			fees,
+			gate,
+			sizer,
+			infra.arbitragePublisher,
+			log,
+		)
+		scanner.Start(ctx)
+		processors = append(processors, scanner)
+	}
+	var triangular *service.TriangularScanner
+	if cfg.Arbitrage.Triangular.Enabled {
+		triangular = service.NewTriangularScanner(
+			triangularScannerConfig(cfg.Arbitrage),
+			domainservice.NewOrderBookService(),
+			fees,
+		)
+		processors = append(processors, triangular)
+	}
+	var statArb *service.StatArbEngine
+	if cfg.Arbitrage.Statistical.Enabled {
+		var store output.StatArbStatePort
+		if path := cfg.Arbitrage.Statistical.StateFile; path != "" {
+			store = statefile.NewStatArbStore(path)
+		}
+		statArb = service.NewStatArbEngine(
+			statArbConfig(cfg.Arbitrage),
+			infra.signalPublisher,
+			store,
+			log,
+		)
+		statArb.Start(ctx)
+		processors = append(processors, statArb)
+	}
+
+	subscriptions := service.NewSubscriptionService(streamExchanges, processors, svc, recording, auditLog, log)
+	subscriptions.Start(ctx)
+
+	plan := cfg.SubscriptionPlan()
+	streamed := make([]string, len(streamExchanges))
+	for i, exchange := range streamExchanges {
+		streamed[i] = exchange.GetName()
+	}
+	for _, name := range unservedExchanges(plan, streamed) {
+		log.Info("no adapter for exchange, its symbols are not streamed", "exchange", name)
+	}
+	if err := subscriptions.Reconcile(ctx, nil, subscriptionSpecs(plan, streamed), configActor); err != nil {
+		log.Error("failed to subscribe to planned symbols", "error", err)
+	}
+	if cfg.Exchange.HotReload && player == nil {
+		live := []string{binanceClient.GetName()}
+		watchConfig(ctx, env.configPath, cfg, live, subscriptions, log)
+	}
+
+	// Play once the planned symbols are subscribed, then shut down
+	if player != nil {
+		go func() {
+			defer cancel()
+			if err := player.Play(ctx); err != nil && ctx.Err() == nil {
+				log.Error("failed to play recordings", "error", err)
+				return
+			}
+			log.Info("replay finished", "decode_errors", player.DecodeErrors())
+		}()
+	}
+
+	// Archive sampled orderbooks for historical queries
+	if cfg.Database.BookSnapshots.Enabled {
+		archiver := service.NewSnapshotArchiver(
+			infra.orderbookRepo,
+			infra.snapshotRepo,
+			cfg.Database.BookSnapshots.Interval,
+			cfg.Database.BookSnapshots.Depth,
+			log,
+		)
+		go archiver.Run(ctx)
+	}
+
+	// Start gRPC server
+	grpcServer, healthServer := grpcapi.NewServer(
+		grpcapi.NewMarketDataServer(svc),
+		grpcapi.NewInterceptors(cfg.Server.APIKeys, log, appMetrics),
+	)
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.GRPCPort))
+	if err != nil {
+		return fmt.Errorf("failed to listen for grpc: %w", err)
+	}
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Error("grpc server stopped", "error", err)
+		}
+	}()
+
+	// Start HTTP server
+	analyticsSvc := service.NewAnalyticsService(infra.analyticsRepo)
+	router := httpapi.NewRouter(
+		httpapi.NewMarketDataHandler(svc),
+		httpapi.NewAnalyticsHandler(analyticsSvc),
+		cfg.Server.RequestTimeout,
+		log,
+	)
+	router.HandleStream("GET /api/v1/ws", httpapi.NewStreamHandler(svc, httpapi.StreamOptions{
+		MaxSubscriptions:  cfg.Server.WebSocket.MaxSubscriptions,
+		HeartbeatInterval: cfg.Server.WebSocket.HeartbeatInterval,
+		PollInterval:      cfg.Server.WebSocket.PollInterval,
+	}, log))
+	sseHandler := httpapi.NewSSEHandler(svc, httpapi.SSEOptions{
+		BufferSize:        cfg.Server.SSE.BufferSize,
+		HeartbeatInterval: cfg.Server.SSE.HeartbeatInterval,
+		Linger:            cfg.Server.SSE.Linger,
+	}, log)
+	defer sseHandler.Close()
+	router.HandleStreamFunc("GET /api/v1/sse/orderbook", sseHandler.StreamOrderBook)
+	router.HandleStreamFunc("GET /api/v1/sse/trades", sseHandler.StreamTrades)
+	if scanner != nil {
+		arbitrageHandler := httpapi.NewArbitrageHandler(scanner, cfg.Server.SSE.HeartbeatInterval)
+		router.HandleFunc("GET /api/v1/arbitrage/opportunities", arbitrageHandler.ListOpportunities)
+		router.HandleStreamFunc("GET /api/v1/sse/arbitrage", arbitrageHandler.StreamOpportunities)
+	}
+	if triangular != nil {
+		router.HandleFunc("GET /api/v1/arbitrage/triangular", httpapi.NewTriangularHandler(triangular).ListCycles)
+	}
+	router.HandleFunc("GET /api/v1/fees", httpapi.NewFeeHandler(fees).ListFeeSchedules)
+	if statArb != nil {
+		router.HandleFunc("GET /api/v1/arbitrage/statistical", httpapi.NewStatArbHandler(statArb).ListPairs)
+	}
+	if indicators != nil {
+		router.HandleFunc("GET /api/v1/indicators", httpapi.NewIndicatorHandler(indicators).ListIndicators)
+	}
+	var riskHandler *httpapi.RiskHandler
+	if risk != nil {
+		riskHandler = httpapi.NewRiskHandler(risk)
+		router.HandleFunc("GET /api/v1/risk", riskHandler.GetStatus)
+		router.HandleFunc("POST /api/v1/risk/check", riskHandler.CheckTrade)
+	}
+	healthHandler := httpapi.NewHealthHandler(statusSvc)
+	router.HandleFunc("GET /healthz", healthHandler.Healthz)
+	router.HandleFunc("GET /readyz", healthHandler.Readyz)
+	router.HandleFunc("GET /status", healthHandler.Status)
+	if cfg.Admin.Enabled {
+		adminHandler := httpapi.NewAdminHandler(subscriptions, specOf(cfg.Exchange.Defaults))
+		router.Handle("/admin/", httpapi.NewAdminRouter(adminHandler, riskHandler, cfg.Admin.APIKeys, log))
+	}
+	httpServer := httpapi.NewServer(cfg.Server.HTTPPort, router, log)
+	httpServer.Start()
+
+	// Handle graceful shutdown
+	<-ctx.Done()
+
+	log.Info("shutting down market data service...")
+	shutdownTimeout := cfg.Server.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = 15 * time.Second
+	}
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Error("error shutting down http server", "error", err)
+	}
+	healthServer.Shutdown()
+	grpcServer.GracefulStop()
+	subscriptions.Stop()
+	if player != nil {
+		player.Close()
+	}
+	if scanner != nil {
+		scanner.Stop()
+	}
+	if statArb != nil {
+		statArb.Stop()
+	}
+	if risk != nil {
+		risk.Stop()
+	}
+	if binanceConnected {
+		if err := binanceClient.Close(); err != nil {
+			log.Error("error closing binance client", "error", err)
+		}
+	}
+	stopSimulated(shutdownCtx)
+	infra.close(shutdownCtx, log)
+
+	return nil
+}
+
+// newRecorder starts a recorder for the given symbols per exchange, see
+// recorder.Config.Symbols
+func newRecorder(cfg config.RecorderConfig, symbols map[string][]string, log *logger.Logger, m *metrics.Metrics) (*recorder.Recorder, error) {
+	rec, err := recorder.New(recorder.Config{
+		Dir:         cfg.Dir,
+		QueueSize:   cfg.QueueSize,
+		MaxFileSize: cfg.MaxFileSize,
+		Symbols:     symbols,
+	}, log, m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start recorder: %w", err)
+	}
+	return rec, nil
+}
+
+func arbitrageScannerConfig(cfg config.ArbitrageConfig) service.ArbitrageScannerConfig {
+	return service.ArbitrageScannerConfig{
+		MinProfitPct:   cfg.MinProfitPct,
+		OpenAfter:      cfg.OpenAfter,
+		CloseAfter:     cfg.CloseAfter,
+		UpdateInterval: cfg.UpdateInterval,
+		MinChangePct:   cfg.MinChangePct,
+		MaxBookAge:     cfg.MaxBookAge,
+	}
+}
+
+func triangularScannerConfig(cfg config.ArbitrageConfig) service.TriangularScannerConfig {
+	return service.TriangularScannerConfig{
+		MinProfitPct:    cfg.Triangular.MinProfitPct,
+		StartCurrencies: cfg.Triangular.StartCurrencies,
+		MaxBookAge:      cfg.MaxBookAge,
+	}
+}
+
+func statArbConfig(cfg config.ArbitrageConfig) service.StatArbConfig {
+	s := cfg.Statistical
+	pairs := make([]service.StatArbPairConfig, len(s.Pairs))
+	for i, pair := range s.Pairs {
+		hedgeRatio := pair.HedgeRatio
+		if hedgeRatio == 0 {
+			hedgeRatio = 1
+		}
+		pairs[i] = service.StatArbPairConfig{
+			Name:       pair.Name,
+			ExchangeA:  pair.A.Exchange,
+			SymbolA:    pair.A.Symbol,
+			ExchangeB:  pair.B.Exchange,
+			SymbolB:    pair.B.Symbol,
+			HedgeRatio: hedgeRatio,
+			OLS:        pair.OLS,
+			LogPrices:  pair.LogPrices,
+		}
+	}
+	return service.StatArbConfig{
+		Pairs:        pairs,
+		Interval:     s.Interval,
+		Window:       s.Window,
+		MinSamples:   s.MinSamples,
+		EntryZ:       s.EntryZ,
+		ExitZ:        s.ExitZ,
+		SaveInterval: s.SaveInterval,
+		MaxBookAge:   cfg.MaxBookAge,
+	}
+}
+
+func indicatorConfig(cfg config.IndicatorsConfig) service.IndicatorConfig {
+	return service.IndicatorConfig{
+		CandleInterval:        cfg.CandleInterval,
+		VolatilityWindow:      cfg.VolatilityWindow,
+		ATRPeriod:             cfg.ATRPeriod,
+		DepthBps:              cfg.DepthBps,
+		HighVolatilityPct:     cfg.HighVolatilityPct,
+		HighVolatilityHorizon: cfg.HighVolatilityHorizon,
+		HighCandlePct:         cfg.HighCandlePct,
+		StaleAfter:            cfg.StaleAfter,
+	}
+}
+
+// riskConfig falls back to UTC for a time zone that does not load, which
+// validation has already reported
+func riskConfig(cfg config.RiskConfig) service.RiskConfig {
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	return service.RiskConfig{
+		Limits: domainservice.RiskLimits{
+			PerTradePct:    cfg.PerTradeLossPct,
+			DailyLossPct:   cfg.DailyLossPct,
+			MaxDrawdownPct: cfg.MaxDrawdownPct,
+		},
+		AccountSize:  cfg.AccountSize,
+		DailyReset:   cfg.DailyReset,
+		Location:     location,
+		SaveInterval: cfg.SaveInterval,
+	}
+}
+
+func positionSizingConfig(cfg config.SizingConfig) service.PositionSizingConfig {
+	return service.PositionSizingConfig{
+		AccountSize:       cfg.AccountSize,
+		AccountPct:        cfg.AccountPct,
+		LiquidityPct:      cfg.LiquidityPct,
+		RiskLimitPct:      cfg.RiskLimitPct,
+		VolatilityHorizon: cfg.VolatilityHorizon,
+	}
+}
+
+// accountConfigs leaves the balances of an exchange unknown when none are
+// configured, so they do not read as zero
+func accountConfigs(cfg config.SizingConfig) []service.AccountConfig {
+	accounts := make([]service.AccountConfig, 0, len(cfg.Exchanges))
+	for name, e := range cfg.Exchanges {
+		account := service.AccountConfig{ExchangeID: name, LotSizes: e.LotSizes}
+		if len(e.Balances) > 0 {
+			account.Balances = e.Balances
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts
+}
+
+// feeScheduleConfigs resolves the tier of each exchange. Symbols are upper
+// cased since config keys are read in lower case.
+func feeScheduleConfigs(cfg config.FeesConfig) []service.FeeScheduleConfig {
+	schedules := make([]service.FeeScheduleConfig, 0, len(cfg.Exchanges))
+	for name, e := range cfg.Exchanges {
+		rates := cfg.Default
+		if e.Tier != "" {
+			rates = e.Tiers[strings.ToLower(e.Tier)]
+		}
+		symbols := make(map[string]entity.FeeRates, len(e.Symbols))
+		for symbol, symbolRates := range e.Symbols {
+			symbols[strings.ToUpper(symbol)] = feeRates(symbolRates)
+		}
+		schedules = append(schedules, service.FeeScheduleConfig{
+			ExchangeID: name,
+			Tier:       e.Tier,
+			Rates:      feeRates(rates),
+			Symbols:    symbols,
+			Discount:   entity.FeeDiscount{Asset: e.Discount.Asset, Pct: e.Discount.Pct},
+		})
+	}
+	return schedules
+}
+
+func feeRates(cfg config.FeeRatesConfig) entity.FeeRates {
+	return entity.FeeRates{Maker: cfg.Maker, Taker: cfg.Taker}
+}
+
+func newBinanceClient(cfg *config.Config, frameRecorder exchange.FrameRecorder, gaps output.SequenceGapPort) *binance.Client {
+	return binance.NewClient(exchange.Config{
+		Name:      "binance",
+		APIKey:    cfg.Exchange.Binance.APIKey,
+		APISecret: cfg.Exchange.Binance.APISecret,
+		Recorder:  frameRecorder,
+		Gaps:      gaps,
+	})
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+
+	"marketdata/config"
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/infrastructure/exchange/simulated"
+	httpapi "marketdata/internal/interfaces/api/http"
+	"marketdata/pkg/logger"
+)
+
+// startSimulated runs the simulated markets, connects the simulated exchange
+// and, if a port is set, serves the same markets over the Binance-compatible
+// server. The exchange reports sequence gaps to gaps. The returned function
+// stops everything.
+func startSimulated(ctx context.Context, cfg config.SimulatedExchangeConfig, gaps output.SequenceGapPort, log *logger.Logger) (*simulated.Exchange, func(context.Context), error) {
+	feed, err := simulated.NewFeed(simulatedConfig(cfg))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	simExchange := simulated.NewExchange(feed, gaps)
+	if err := simExchange.Connect(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	var simServer *simulated.Server
+	var httpServer *httpapi.Server
+	if cfg.ServerPort != 0 {
+		simServer = simulated.NewServer(feed, log)
+		httpServer = httpapi.NewServer(cfg.ServerPort, simServer.Handler(), log)
+		httpServer.Start()
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		feed.Run(runCtx)
+	}()
+
+	return simExchange, func(shutdownCtx context.Context) {
+		if httpServer != nil {
+			if err := httpServer.Shutdown(shutdownCtx); err != nil {
+				log.Error("error shutting down simulated exchange server", "error", err)
+			}
+		}
+		cancel()
+		<-done
+		if simServer != nil {
+			simServer.Close()
+		}
+		simExchange.Close()
+	}, nil
+}
+
+func simulatedConfig(cfg config.SimulatedExchangeConfig) simulated.Config {
+	symbols := make([]simulated.SymbolConfig, len(cfg.Symbols))
+	for i, symbol := range cfg.Symbols {
+		symbols[i] = simulated.SymbolConfig{
+			Symbol:          symbol.Symbol,
+			StartPrice:      symbol.StartPrice,
+			SpreadBps:       symbol.SpreadBps,
+			Volatility:      symbol.Volatility,
+			TickSize:        symbol.TickSize,
+			LevelQuantity:   symbol.LevelQuantity,
+			TradesPerUpdate: symbol.TradesPerUpdate,
+		}
+	}
+
+	return simulated.Config{
+		Name:           cfg.Name,
+		Symbols:        symbols,
+		UpdateInterval: cfg.UpdateInterval,
+		Depth:          cfg.Depth,
+		Seed:           cfg.Seed,
+		Faults: simulated.Faults{
+			SequenceGap:  cfg.Faults.SequenceGap,
+			Disconnect:   cfg.Faults.Disconnect,
+			CrossedBook:  cfg.Faults.CrossedBook,
+			LatencySpike: cfg.Faults.LatencySpike,
+			SpikeDelay:   cfg.Faults.SpikeDelay,
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+	"marketdata/pkg/metrics"
+)
+
+const tailPageSize = 1000
+
+// runTrades prints the latest stored trades, then follows new ones like
+// tail -f. Trades stored late with an older timestamp than the last one
+// printed are not shown.
+func runTrades(ctx context.Context, env *cli, args []string) error {
+	flags := newFlags("trades", "trades [flags]")
+	exchangeID := flags.String("exchange", "", "only trades from this exchange")
+	symbol := flags.String("symbol", "", "only trades of this symbol")
+	side := flags.String("side", "", "only BUY or SELL trades")
+	last := flags.Int("n", 20, "trades to print before following")
+	interval := flags.Duration("interval", time.Second, "poll interval")
+	follow := flags.Bool("follow", true, "keep printing new trades")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usageError("trades takes no arguments")
+	}
+
+	filter := output.TradeFilter{
+		ExchangeID: *exchangeID,
+		Symbol:     *symbol,
+		Side:       entity.TradeType(strings.ToUpper(*side)),
+	}
+	if filter.Side != "" && filter.Side != entity.TradeBuy && filter.Side != entity.TradeSell {
+		return usageError("side must be BUY or SELL")
+	}
+
+	cfg, err := env.loadConfig()
+	if err != nil {
+		return err
+	}
+	infra, err := newToolAdapters(ctx, cfg, env.log, metrics.NewMetrics("marketdata"))
+	if err != nil {
+		return err
+	}
+	defer infra.close(context.Background(), env.log)
+
+	// The newest trades come back newest first; print them oldest first
+	filter.Limit = max(*last, 1)
+	trades, err := infra.tradeRepo.QueryTrades(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to query trades: %w", err)
+	}
+	slices.Reverse(trades)
+	if *last > 0 {
+		printTrades(os.Stdout, trades)
+	}
+
+	filter.Limit, filter.Ascending = tailPageSize, true
+	if len(trades) > 0 {
+		newest := trades[len(trades)-1]
+		filter.After = &output.TradeKey{Timestamp: newest.Timestamp(), ExchangeID: newest.ExchangeID(), ID: newest.ID()}
+	} else {
+		filter.From = time.Now()
+	}
+
+	if !*follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// Drain full pages before waiting again
+		for {
+			trades, err := infra.tradeRepo.QueryTrades(ctx, filter)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to query trades: %w", err)
+			}
+			printTrades(os.Stdout, trades)
+
+			if len(trades) > 0 {
+				newest := trades[len(trades)-1]
+				filter.After = &output.TradeKey{Timestamp: newest.Timestamp(), ExchangeID: newest.ExchangeID(), ID: newest.ID()}
+			}
+			if len(trades) < filter.Limit {
+				break
+			}
+		}
+	}
+}
+
+func printTrades(out io.Writer, trades []*entity.Trade) {
+	for _, trade := range trades {
+		fmt.Fprintf(out, "%s  %-10s %-12s %-4s %14g x %-14g %s\n",
+			trade.Timestamp().Format(time.RFC3339Nano),
+			trade.ExchangeID(),
+			trade.Symbol(),
+			trade.Type(),
+			trade.Price().Value(),
+			trade.Volume().Value(),
+			trade.ID(),
+		)
+	}
+}
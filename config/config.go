@@ -1,73 +1,515 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Kafka    KafkaConfig    `mapstructure:"kafka"`
-	Exchange ExchangeConfig `mapstructure:"exchange"`
+	Server     ServerConfig     `mapstructure:"server"`
+	Storage    StorageConfig    `mapstructure:"storage"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	Redis      RedisConfig      `mapstructure:"redis"`
+	Kafka      KafkaConfig      `mapstructure:"kafka"`
+	Exchange   ExchangeConfig   `mapstructure:"exchange"`
+	Recorder   RecorderConfig   `mapstructure:"recorder"`
+	Admin      AdminConfig      `mapstructure:"admin"`
+	Arbitrage  ArbitrageConfig  `mapstructure:"arbitrage"`
+	Fees       FeesConfig       `mapstructure:"fees"`
+	Indicators IndicatorsConfig `mapstructure:"indicators"`
+	Risk       RiskConfig       `mapstructure:"risk"`
+	Sizing     SizingConfig     `mapstructure:"sizing"`
 }
 
+// ServerConfig configures the APIs. APIKeysFile, when set, adds one API key
+// per non-empty line of the file to APIKeys.
 type ServerConfig struct {
-	HTTPPort int `mapstructure:"http_port"`
-	GRPCPort int `mapstructure:"grpc_port"`
+	HTTPPort        int           `mapstructure:"http_port"`
+	GRPCPort        int           `mapstructure:"grpc_port"`
+	APIKeys         []string      `mapstructure:"api_keys"`
+	APIKeysFile     string        `mapstructure:"api_keys_file"`
+	RequestTimeout  time.Duration `mapstructure:"request_timeout"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	WebSocket       StreamConfig  `mapstructure:"websocket"`
+	SSE             SSEConfig     `mapstructure:"sse"`
 }
 
+type StreamConfig struct {
+	MaxSubscriptions  int           `mapstructure:"max_subscriptions"`
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"`
+	PollInterval      time.Duration `mapstructure:"poll_interval"`
+}
+
+type SSEConfig struct {
+	BufferSize        int           `mapstructure:"buffer_size"`
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"`
+	Linger            time.Duration `mapstructure:"linger"`
+}
+
+const (
+	// StorageExternal keeps books in Redis, trades in TimescaleDB and publishes to Kafka
+	StorageExternal = "external"
+	// StorageMemory keeps everything in process, for local development and single-node deployments
+	StorageMemory = "memory"
+)
+
+type StorageConfig struct {
+	Backend string              `mapstructure:"backend"`
+	Memory  MemoryStorageConfig `mapstructure:"memory"`
+}
+
+// MemoryStorageConfig bounds the in-memory stores; zero means unbounded
+type MemoryStorageConfig struct {
+	MaxTrades           int `mapstructure:"max_trades"`
+	MaxSnapshotsPerBook int `mapstructure:"max_snapshots_per_book"`
+	MaxEvents           int `mapstructure:"max_events"`
+}
+
+// DatabaseConfig configures TimescaleDB. PasswordFile, when set, replaces
+// Password with the file's contents.
 type DatabaseConfig struct {
-	Host     string `mapstructure:"host"`
-	Port     int    `mapstructure:"port"`
-	User     string `mapstructure:"user"`
-	Password string `mapstructure:"password"`
-	DBName   string `mapstructure:"dbname"`
+	Host             string             `mapstructure:"host"`
+	Port             int                `mapstructure:"port"`
+	User             string             `mapstructure:"user"`
+	Password         string             `mapstructure:"password"`
+	PasswordFile     string             `mapstructure:"password_file"`
+	DBName           string             `mapstructure:"dbname"`
+	SSLMode          string             `mapstructure:"sslmode"`
+	MaxOpenConns     int                `mapstructure:"max_open_conns"`
+	MigrateOnStartup bool               `mapstructure:"migrate_on_startup"`
+	TradeBatch       TradeBatchConfig   `mapstructure:"trade_batch"`
+	BookSnapshots    BookSnapshotConfig `mapstructure:"book_snapshots"`
 }
 
+type TradeBatchConfig struct {
+	Size          int           `mapstructure:"size"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	MaxPending    int           `mapstructure:"max_pending"`
+	// DeadLetterDir keeps batches that could not be written; empty drops them
+	DeadLetterDir string `mapstructure:"dead_letter_dir"`
+}
+
+// BookSnapshotConfig controls archival of orderbook samples to TimescaleDB
+type BookSnapshotConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+	Depth    int           `mapstructure:"depth"`
+}
+
+// DSN returns the PostgreSQL connection string
+func (c DatabaseConfig) DSN() string {
+	sslMode := c.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+		Path:     c.DBName,
+		RawQuery: url.Values{"sslmode": []string{sslMode}}.Encode(),
+	}
+	return u.String()
+}
+
+// RedisConfig configures Redis. PasswordFile, when set, replaces Password
+// with the file's contents.
 type RedisConfig struct {
-	Host     string        `mapstructure:"host"`
-	Port     int           `mapstructure:"port"`
-	Password string        `mapstructure:"password"`
-	DB       int           `mapstructure:"db"`
-	TTL      time.Duration `mapstructure:"ttl"`
+	Host         string        `mapstructure:"host"`
+	Port         int           `mapstructure:"port"`
+	Password     string        `mapstructure:"password"`
+	PasswordFile string        `mapstructure:"password_file"`
+	DB           int           `mapstructure:"db"`
+	TTL          time.Duration `mapstructure:"ttl"`
 }
 
 type KafkaConfig struct {
 	Brokers []string `mapstructure:"brokers"`
 	Topic   string   `mapstructure:"topic"`
 	GroupID string   `mapstructure:"group_id"`
+	// ArbitrageTopic receives the arbitrage scanner's opportunity events
+	ArbitrageTopic string `mapstructure:"arbitrage_topic"`
+	// SignalTopic receives the statistical arbitrage signals
+	SignalTopic string `mapstructure:"signal_topic"`
 }
 
+// ExchangeConfig lists the symbols each exchange subscribes to. Symbols is
+// the list for every exchange without its own, and Defaults fills in the
+// settings a symbol leaves unset.
 type ExchangeConfig struct {
-	Symbols []string `mapstructure:"symbols"`
-	Binance struct {
-		APIKey    string `mapstructure:"api_key"`
-		APISecret string `mapstructure:"api_secret"`
-	} `mapstructure:"binance"`
-	OKX struct {
-		APIKey    string `mapstructure:"api_key"`
-		APISecret string `mapstructure:"api_secret"`
-	} `mapstructure:"okx"`
+	Symbols  []string       `mapstructure:"symbols"`
+	Defaults SymbolSettings `mapstructure:"defaults"`
+	// HotReload watches the config file and applies symbol changes without
+	// a restart
+	HotReload bool                    `mapstructure:"hot_reload"`
+	Binance   VenueConfig             `mapstructure:"binance"`
+	OKX       VenueConfig             `mapstructure:"okx"`
+	Simulated SimulatedExchangeConfig `mapstructure:"simulated"`
+	Replay    ReplayExchangeConfig    `mapstructure:"replay"`
+}
+
+// VenueConfig configures one live exchange
+type VenueConfig struct {
+	CredentialsConfig `mapstructure:",squash"`
+	// Symbols overrides exchange.symbols for this exchange; entries are
+	// either a symbol or a table with per-symbol settings
+	Symbols []SymbolConfig `mapstructure:"symbols"`
+}
+
+// SymbolSettings controls how one symbol is streamed
+type SymbolSettings struct {
+	// Depth keeps this many levels per side; zero keeps the full book
+	Depth int `mapstructure:"depth"`
+	// UpdateSpeed is the exchange push interval, 100ms or 1s on Binance
+	UpdateSpeed time.Duration `mapstructure:"update_speed"`
+	// Record writes the symbol's raw frames when the recorder is enabled
+	Record bool `mapstructure:"record"`
+	// PublishThrottle publishes at most one book per interval, keeping the
+	// latest; zero publishes every update
+	PublishThrottle time.Duration `mapstructure:"publish_throttle"`
+}
+
+// SymbolConfig is a symbol with the settings that differ from
+// exchange.defaults; unset settings are nil
+type SymbolConfig struct {
+	Symbol          string         `mapstructure:"symbol"`
+	Depth           *int           `mapstructure:"depth"`
+	UpdateSpeed     *time.Duration `mapstructure:"update_speed"`
+	Record          *bool          `mapstructure:"record"`
+	PublishThrottle *time.Duration `mapstructure:"publish_throttle"`
+}
+
+// CredentialsConfig holds exchange API credentials. The _file variants,
+// when set, replace the inline values with the contents of the file.
+type CredentialsConfig struct {
+	APIKey        string `mapstructure:"api_key"`
+	APIKeyFile    string `mapstructure:"api_key_file"`
+	APISecret     string `mapstructure:"api_secret"`
+	APISecretFile string `mapstructure:"api_secret_file"`
+}
+
+// SimulatedExchangeConfig runs a synthetic venue for load and chaos testing
+type SimulatedExchangeConfig struct {
+	Enabled        bool                    `mapstructure:"enabled"`
+	Name           string                  `mapstructure:"name"`
+	UpdateInterval time.Duration           `mapstructure:"update_interval"`
+	Depth          int                     `mapstructure:"depth"`
+	Seed           int64                   `mapstructure:"seed"`
+	Symbols        []SimulatedSymbolConfig `mapstructure:"symbols"`
+	Faults         SimulatedFaultConfig    `mapstructure:"faults"`
+	// ServerPort, when set, also serves simulated markets over a
+	// Binance-compatible websocket and REST API
+	ServerPort int `mapstructure:"server_port"`
+}
+
+// ReplayExchangeConfig plays recordings in place of the live exchange they
+// were recorded from, through the same subscriptions and processors. The
+// service shuts down once the recordings are played out.
+type ReplayExchangeConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Exchange is the recorded exchange; its live adapter is not streamed
+	Exchange string `mapstructure:"exchange"`
+	// Files are recordings or directories of recordings
+	Files []string `mapstructure:"files"`
+	// Speed scales playback: 1 keeps the recorded pace and 0 plays as fast
+	// as the service consumes
+	Speed float64 `mapstructure:"speed"`
+	// From and To bound the replayed frames to [From, To); zero is unbounded
+	From time.Time `mapstructure:"from"`
+	To   time.Time `mapstructure:"to"`
+}
+
+type SimulatedSymbolConfig struct {
+	Symbol          string  `mapstructure:"symbol"`
+	StartPrice      float64 `mapstructure:"start_price"`
+	SpreadBps       float64 `mapstructure:"spread_bps"`
+	Volatility      float64 `mapstructure:"volatility"`
+	TickSize        float64 `mapstructure:"tick_size"`
+	LevelQuantity   float64 `mapstructure:"level_quantity"`
+	TradesPerUpdate float64 `mapstructure:"trades_per_update"`
+}
+
+// SimulatedFaultConfig holds per-update fault probabilities
+type SimulatedFaultConfig struct {
+	SequenceGap  float64       `mapstructure:"sequence_gap"`
+	Disconnect   float64       `mapstructure:"disconnect"`
+	CrossedBook  float64       `mapstructure:"crossed_book"`
+	LatencySpike float64       `mapstructure:"latency_spike"`
+	SpikeDelay   time.Duration `mapstructure:"spike_delay"`
+}
+
+// RecorderConfig controls recording of raw exchange frames to disk. Which
+// symbols are recorded is a per-symbol setting, see SymbolSettings.Record.
+type RecorderConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	Dir         string `mapstructure:"dir"`
+	QueueSize   int    `mapstructure:"queue_size"`
+	MaxFileSize int64  `mapstructure:"max_file_size"`
+}
+
+// AdminConfig enables the admin API, which changes subscriptions at runtime.
+// APIKeysFile, when set, adds one API key per non-empty line of the file to
+// APIKeys. AuditLog is the JSON lines file subscription changes are appended
+// to; empty keeps them in memory only.
+type AdminConfig struct {
+	Enabled     bool     `mapstructure:"enabled"`
+	APIKeys     []string `mapstructure:"api_keys"`
+	APIKeysFile string   `mapstructure:"api_keys_file"`
+	AuditLog    string   `mapstructure:"audit_log"`
+}
+
+// ArbitrageConfig tunes the arbitrage scanner, which pays the taker rates of
+// FeesConfig. Percentages are in percent.
+type ArbitrageConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MinProfitPct is the spread that must remain after fees
+	MinProfitPct float64 `mapstructure:"min_profit_pct"`
+	// OpenAfter and CloseAfter debounce opportunities that flicker
+	OpenAfter  time.Duration `mapstructure:"open_after"`
+	CloseAfter time.Duration `mapstructure:"close_after"`
+	// UpdateInterval and MinChangePct limit how often an open opportunity
+	// is updated
+	UpdateInterval time.Duration `mapstructure:"update_interval"`
+	MinChangePct   float64       `mapstructure:"min_change_pct"`
+	// MaxBookAge ignores books without an update for longer
+	MaxBookAge  time.Duration              `mapstructure:"max_book_age"`
+	Triangular  TriangularArbitrageConfig  `mapstructure:"triangular"`
+	Statistical StatisticalArbitrageConfig `mapstructure:"statistical"`
+}
+
+// TriangularArbitrageConfig tunes the search for cycles within one exchange,
+// which shares the book age of ArbitrageConfig
+type TriangularArbitrageConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MinProfitPct is what a cycle must return after fees
+	MinProfitPct float64 `mapstructure:"min_profit_pct"`
+	// StartCurrencies ranks the currencies cycles are reported from
+	StartCurrencies []string `mapstructure:"start_currencies"`
+}
+
+// StatisticalArbitrageConfig tunes the z-score signals on the spread of
+// configured pairs, which shares the book age of ArbitrageConfig
+type StatisticalArbitrageConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is how often the pairs are sampled and Window how many
+	// samples the statistics cover
+	Interval time.Duration `mapstructure:"interval"`
+	Window   int           `mapstructure:"window"`
+	// MinSamples is how many samples a pair needs before it signals
+	MinSamples int `mapstructure:"min_samples"`
+	// EntryZ and ExitZ are the absolute z-scores a position opens and
+	// closes at
+	EntryZ float64 `mapstructure:"entry_z"`
+	ExitZ  float64 `mapstructure:"exit_z"`
+	// StateFile keeps the windows and positions across restarts; empty
+	// starts afresh every time
+	StateFile    string              `mapstructure:"state_file"`
+	SaveInterval time.Duration       `mapstructure:"save_interval"`
+	Pairs        []StatArbPairConfig `mapstructure:"pairs"`
+}
+
+// StatArbPairConfig is a pair whose spread is A - hedge_ratio * B. With OLS
+// the hedge ratio is fitted over the window instead; otherwise zero means 1.
+type StatArbPairConfig struct {
+	Name       string           `mapstructure:"name"`
+	A          StatArbLegConfig `mapstructure:"a"`
+	B          StatArbLegConfig `mapstructure:"b"`
+	HedgeRatio float64          `mapstructure:"hedge_ratio"`
+	OLS        bool             `mapstructure:"ols"`
+	// LogPrices takes the spread of the logarithm of the prices
+	LogPrices bool `mapstructure:"log_prices"`
+}
+
+type StatArbLegConfig struct {
+	Exchange string `mapstructure:"exchange"`
+	Symbol   string `mapstructure:"symbol"`
+}
+
+// FeesConfig holds the fee schedule of each exchange. Rates are fractions,
+// 0.001 for 0.1%; a negative maker rate is a rebate.
+type FeesConfig struct {
+	// Default is paid on exchanges missing from Exchanges
+	Default FeeRatesConfig `mapstructure:"default"`
+	// RefreshInterval is how often the account rates are fetched from
+	// exchanges with API credentials; zero never fetches them
+	RefreshInterval time.Duration                 `mapstructure:"refresh_interval"`
+	Exchanges       map[string]ExchangeFeesConfig `mapstructure:"exchanges"`
+}
+
+type FeeRatesConfig struct {
+	Maker float64 `mapstructure:"maker"`
+	Taker float64 `mapstructure:"taker"`
+}
+
+// ExchangeFeesConfig is the schedule of one exchange: the rates of Tier, one
+// of Tiers, or the default rates without tiers. Symbols overrides them per
+// symbol, and Discount lowers every fee paid.
+type ExchangeFeesConfig struct {
+	Tier     string                    `mapstructure:"tier"`
+	Tiers    map[string]FeeRatesConfig `mapstructure:"tiers"`
+	Symbols  map[string]FeeRatesConfig `mapstructure:"symbols"`
+	Discount FeeDiscountConfig         `mapstructure:"discount"`
+}
+
+// FeeDiscountConfig takes Pct percent off fees paid in Asset, such as 25
+// for BNB on Binance
+type FeeDiscountConfig struct {
+	Asset string  `mapstructure:"asset"`
+	Pct   float64 `mapstructure:"pct"`
+}
+
+// IndicatorsConfig configures the volatility and market quality indicators.
+// Candles are built from mid prices and kept for 24h.
+type IndicatorsConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	CandleInterval time.Duration `mapstructure:"candle_interval"`
+	// VolatilityWindow is how many candle returns the realized volatility
+	// covers
+	VolatilityWindow int `mapstructure:"volatility_window"`
+	ATRPeriod        int `mapstructure:"atr_period"`
+	// DepthBps are the distances from the mid price, in basis points, that
+	// book depth and imbalance are measured within
+	DepthBps []float64 `mapstructure:"depth_bps"`
+	// HighVolatilityPct and HighCandlePct flag a symbol as highly volatile
+	// when its volatility, scaled from the candle interval to
+	// HighVolatilityHorizon, or its last candle's range, in percent, exceeds
+	// them
+	HighVolatilityPct     float64       `mapstructure:"high_volatility_pct"`
+	HighVolatilityHorizon time.Duration `mapstructure:"high_volatility_horizon"`
+	HighCandlePct         float64       `mapstructure:"high_candle_pct"`
+	// StaleAfter is how long a symbol without updates keeps its indicators
+	// and gauges
+	StaleAfter time.Duration `mapstructure:"stale_after"`
+}
+
+// RiskConfig holds the loss limits of the risk manager. Limits are in
+// percent of the equity, 0.2 for a 0.2% loss; zero disables a limit.
+type RiskConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// AccountSize is the equity, in the quote currency, before any PnL
+	AccountSize     float64 `mapstructure:"account_size"`
+	PerTradeLossPct float64 `mapstructure:"per_trade_loss_pct"`
+	DailyLossPct    float64 `mapstructure:"daily_loss_pct"`
+	MaxDrawdownPct  float64 `mapstructure:"max_drawdown_pct"`
+	// DailyReset is the time after midnight in Timezone the day restarts at
+	DailyReset time.Duration `mapstructure:"daily_reset"`
+	Timezone   string        `mapstructure:"timezone"`
+	// StateFile keeps the PnL, positions and halt across restarts; empty
+	// starts afresh every time
+	StateFile    string        `mapstructure:"state_file"`
+	SaveInterval time.Duration `mapstructure:"save_interval"`
+}
+
+// SizingConfig configures position sizing of arbitrage opportunities. A
+// position is the smallest of AccountPct of the equity, LiquidityPct of the
+// executable volume, the notional whose move of one volatility loses
+// RiskLimitPct of the equity, and the balances held for both legs. Sizes are
+// rounded down to the lot size.
+type SizingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// AccountSize is the equity sized against while the risk manager, whose
+	// equity is used otherwise, is disabled
+	AccountSize  float64 `mapstructure:"account_size"`
+	AccountPct   float64 `mapstructure:"account_pct"`
+	LiquidityPct float64 `mapstructure:"liquidity_pct"`
+	// RiskLimitPct needs the indicators; zero leaves volatility out
+	RiskLimitPct float64 `mapstructure:"risk_limit_pct"`
+	// VolatilityHorizon is the holding time the volatility is scaled to
+	VolatilityHorizon time.Duration `mapstructure:"volatility_horizon"`
+	// RefreshInterval is how often balances and lot sizes are fetched from
+	// exchanges with API credentials; zero never fetches them
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+	// DefaultLotSize rounds symbols without a known lot size; zero does not
+	// round them
+	DefaultLotSize float64                          `mapstructure:"default_lot_size"`
+	Exchanges      map[string]ExchangeAccountConfig `mapstructure:"exchanges"`
+}
+
+// ExchangeAccountConfig is the free balance of each asset and the lot size of
+// each symbol on one exchange. Exchanges without balances are not limited
+// by them.
+type ExchangeAccountConfig struct {
+	Balances map[string]float64 `mapstructure:"balances"`
+	LotSizes map[string]float64 `mapstructure:"lot_sizes"`
 }
 
+// Load reads config.yaml from the working directory or ./config
 func Load() (*Config, error) {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("./config")
+	return LoadFile("")
+}
+
+// LoadFile reads the config from path, or searches the default locations
+// when path is empty
+func LoadFile(path string) (*Config, error) {
+	return load(path, false)
+}
+
+// LoadStrict is LoadFile, but also rejects keys that match no setting, which
+// catches typos that would otherwise silently fall back to defaults
+func LoadStrict(path string) (*Config, error) {
+	return load(path, true)
+}
 
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+// load layers, from lowest to highest precedence: defaults, the config file,
+// MARKETDATA_* environment variables and _file secrets. The config file is
+// optional when path is empty, so a deployment can configure only through
+// the environment. The result is validated before it is returned.
+func load(path string, strict bool) (*Config, error) {
+	v := newViper(path)
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if path != "" || !errors.As(err, &notFound) {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
+
+	unmarshal := v.Unmarshal
+	if strict {
+		unmarshal = v.UnmarshalExact
 	}
 
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := unmarshal(&config, viper.DecodeHook(decodeHook)); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	problems := config.resolveSecrets()
+	if err := config.Validate(); err != nil {
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) {
+			return nil, err
+		}
+		problems = append(problems, invalid.Problems...)
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
 	return &config, nil
 }
+
+// newViper configures the setting sources for path without reading anything
+func newViper(path string) *viper.Viper {
+	v := viper.New()
+	setDefaults(v)
+
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	if path != "" {
+		v.SetConfigFile(path)
+	} else {
+		v.SetConfigName("config")
+		v.SetConfigType("yaml")
+		v.AddConfigPath(".")
+		v.AddConfigPath("./config")
+	}
+	return v
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// envPrefix namespaces environment overrides: redis.host is MARKETDATA_REDIS_HOST
+const envPrefix = "MARKETDATA"
+
+// setDefaults registers a default for every setting. Viper only applies
+// environment overrides to keys it knows, so a key without a default can
+// only be set in the config file.
+func setDefaults(v *viper.Viper) {
+	defaults := map[string]interface{}{
+		"server.http_port":                    8080,
+		"server.grpc_port":                    9090,
+		"server.api_keys":                     []string{},
+		"server.api_keys_file":                "",
+		"server.request_timeout":              10 * time.Second,
+		"server.shutdown_timeout":             15 * time.Second,
+		"server.websocket.max_subscriptions":  50,
+		"server.websocket.heartbeat_interval": 15 * time.Second,
+		"server.websocket.poll_interval":      time.Second,
+		"server.sse.buffer_size":              1024,
+		"server.sse.heartbeat_interval":       15 * time.Second,
+		"server.sse.linger":                   time.Minute,
+
+		"storage.backend":                       StorageExternal,
+		"storage.memory.max_trades":             1000000,
+		"storage.memory.max_snapshots_per_book": 1440,
+		"storage.memory.max_events":             10000,
+
+		"database.host":                        "localhost",
+		"database.port":                        5432,
+		"database.user":                        "postgres",
+		"database.password":                    "",
+		"database.password_file":               "",
+		"database.dbname":                      "marketdata",
+		"database.sslmode":                     "disable",
+		"database.max_open_conns":              20,
+		"database.migrate_on_startup":          false,
+		"database.trade_batch.size":            1000,
+		"database.trade_batch.flush_interval":  250 * time.Millisecond,
+		"database.trade_batch.max_pending":     50000,
+		"database.trade_batch.dead_letter_dir": "",
+		"database.book_snapshots.enabled":      false,
+		"database.book_snapshots.interval":     time.Minute,
+		"database.book_snapshots.depth":        50,
+
+		"redis.host":          "localhost",
+		"redis.port":          6379,
+		"redis.password":      "",
+		"redis.password_file": "",
+		"redis.db":            0,
+		"redis.ttl":           time.Hour,
+
+		"kafka.brokers":         []string{"localhost:9092"},
+		"kafka.topic":           "orderbook_updates",
+		"kafka.group_id":        "marketdata_service",
+		"kafka.arbitrage_topic": "arbitrage_opportunities",
+		"kafka.signal_topic":    "statarb_signals",
+
+		"exchange.symbols":                        []string{},
+		"exchange.defaults.depth":                 0,
+		"exchange.defaults.update_speed":          100 * time.Millisecond,
+		"exchange.defaults.record":                true,
+		"exchange.defaults.publish_throttle":      time.Duration(0),
+		"exchange.hot_reload":                     true,
+		"exchange.binance.symbols":                []string{},
+		"exchange.binance.api_key":                "",
+		"exchange.binance.api_key_file":           "",
+		"exchange.binance.api_secret":             "",
+		"exchange.binance.api_secret_file":        "",
+		"exchange.okx.symbols":                    []string{},
+		"exchange.okx.api_key":                    "",
+		"exchange.okx.api_key_file":               "",
+		"exchange.okx.api_secret":                 "",
+		"exchange.okx.api_secret_file":            "",
+		"exchange.simulated.enabled":              false,
+		"exchange.simulated.name":                 "simulated",
+		"exchange.simulated.update_interval":      100 * time.Millisecond,
+		"exchange.simulated.depth":                20,
+		"exchange.simulated.seed":                 0,
+		"exchange.simulated.server_port":          0,
+		"exchange.simulated.faults.sequence_gap":  0.0,
+		"exchange.simulated.faults.disconnect":    0.0,
+		"exchange.simulated.faults.crossed_book":  0.0,
+		"exchange.simulated.faults.latency_spike": 0.0,
+		"exchange.simulated.faults.spike_delay":   2 * time.Second,
+		"exchange.replay.enabled":                 false,
+		"exchange.replay.exchange":                "binance",
+		"exchange.replay.files":                   []string{},
+		"exchange.replay.speed":                   1.0,
+
+		"recorder.enabled":       false,
+		"recorder.dir":           "recordings",
+		"recorder.queue_size":    10000,
+		"recorder.max_file_size": 256 << 20,
+
+		"admin.enabled":       false,
+		"admin.api_keys":      []string{},
+		"admin.api_keys_file": "",
+		"admin.audit_log":     "",
+
+		"arbitrage.enabled":         true,
+		"arbitrage.min_profit_pct":  0.1,
+		"arbitrage.open_after":      200 * time.Millisecond,
+		"arbitrage.close_after":     time.Second,
+		"arbitrage.update_interval": time.Second,
+		"arbitrage.min_change_pct":  0.02,
+		"arbitrage.max_book_age":    5 * time.Second,
+
+		"arbitrage.triangular.enabled":          true,
+		"arbitrage.triangular.min_profit_pct":   0.1,
+		"arbitrage.triangular.start_currencies": []string{"USDT", "USDC", "BTC", "ETH"},
+
+		"fees.default.maker":    0.001,
+		"fees.default.taker":    0.001,
+		"fees.refresh_interval": time.Hour,
+		"fees.exchanges":        map[string]interface{}{},
+
+		"arbitrage.statistical.enabled":       false,
+		"arbitrage.statistical.interval":      time.Second,
+		"arbitrage.statistical.window":        300,
+		"arbitrage.statistical.min_samples":   60,
+		"arbitrage.statistical.entry_z":       2.0,
+		"arbitrage.statistical.exit_z":        0.5,
+		"arbitrage.statistical.state_file":    "",
+		"arbitrage.statistical.save_interval": 30 * time.Second,
+		"arbitrage.statistical.pairs":         []map[string]interface{}{},
+
+		"indicators.enabled":                 true,
+		"indicators.candle_interval":         time.Minute,
+		"indicators.volatility_window":       60,
+		"indicators.atr_period":              14,
+		"indicators.depth_bps":               []float64{10, 50, 100},
+		"indicators.high_volatility_pct":     2.0,
+		"indicators.high_volatility_horizon": time.Hour,
+		"indicators.high_candle_pct":         1.0,
+		"indicators.stale_after":             5 * time.Minute,
+
+		"risk.enabled":            false,
+		"risk.account_size":       0.0,
+		"risk.per_trade_loss_pct": 0.2,
+		"risk.daily_loss_pct":     1.0,
+		"risk.max_drawdown_pct":   5.0,
+		"risk.daily_reset":        time.Duration(0),
+		"risk.timezone":           "UTC",
+		"risk.state_file":         "",
+		"risk.save_interval":      30 * time.Second,
+
+		"sizing.enabled":            false,
+		"sizing.account_size":       0.0,
+		"sizing.account_pct":        10.0,
+		"sizing.liquidity_pct":      50.0,
+		"sizing.risk_limit_pct":     0.1,
+		"sizing.volatility_horizon": 24 * time.Hour,
+		"sizing.refresh_interval":   time.Minute,
+		"sizing.default_lot_size":   0.0,
+	}
+
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+}
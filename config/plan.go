@@ -0,0 +1,103 @@
+package config
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// SymbolSubscription is one entry of the subscription plan: a symbol on an
+// exchange with its settings resolved against exchange.defaults
+type SymbolSubscription struct {
+	Exchange string
+	Symbol   string
+	SymbolSettings
+}
+
+// SubscriptionPlan resolves which symbols every exchange subscribes to and
+// how. A live exchange without its own symbol list uses exchange.symbols; the
+// simulated exchange, when enabled, subscribes all of its markets with the
+// default settings.
+func (c *Config) SubscriptionPlan() []SymbolSubscription {
+	var plan []SymbolSubscription
+
+	for _, name := range KnownExchanges {
+		symbols := c.Exchange.venue(name).Symbols
+		if len(symbols) == 0 {
+			symbols = make([]SymbolConfig, len(c.Exchange.Symbols))
+			for i, symbol := range c.Exchange.Symbols {
+				symbols[i] = SymbolConfig{Symbol: symbol}
+			}
+		}
+		for _, symbol := range symbols {
+			plan = append(plan, SymbolSubscription{
+				Exchange:       name,
+				Symbol:         symbol.Symbol,
+				SymbolSettings: symbol.resolve(c.Exchange.Defaults),
+			})
+		}
+	}
+
+	if sim := c.Exchange.Simulated; sim.Enabled {
+		for _, symbol := range sim.Symbols {
+			plan = append(plan, SymbolSubscription{
+				Exchange:       sim.Name,
+				Symbol:         symbol.Symbol,
+				SymbolSettings: c.Exchange.Defaults,
+			})
+		}
+	}
+
+	return plan
+}
+
+// venue returns the config of a live exchange by name
+func (c ExchangeConfig) venue(name string) VenueConfig {
+	switch name {
+	case "binance":
+		return c.Binance
+	case "okx":
+		return c.OKX
+	default:
+		return VenueConfig{}
+	}
+}
+
+// resolve fills the settings the symbol leaves unset from defaults
+func (s SymbolConfig) resolve(defaults SymbolSettings) SymbolSettings {
+	settings := defaults
+	if s.Depth != nil {
+		settings.Depth = *s.Depth
+	}
+	if s.UpdateSpeed != nil {
+		settings.UpdateSpeed = *s.UpdateSpeed
+	}
+	if s.Record != nil {
+		settings.Record = *s.Record
+	}
+	if s.PublishThrottle != nil {
+		settings.PublishThrottle = *s.PublishThrottle
+	}
+	return settings
+}
+
+// decodeHook extends Viper's default hooks so a symbol list may mix plain
+// symbols with tables of per-symbol settings, and times may be RFC 3339
+// strings
+var decodeHook = mapstructure.ComposeDecodeHookFunc(
+	mapstructure.StringToTimeDurationHookFunc(),
+	mapstructure.StringToTimeHookFunc(time.RFC3339Nano),
+	mapstructure.StringToSliceHookFunc(","),
+	stringToSymbolConfig,
+)
+
+func stringToSymbolConfig(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(SymbolConfig{}) {
+		return data, nil
+	}
+	return SymbolConfig{Symbol: data.(string)}, nil
+}
+
+// binanceUpdateSpeeds are the push intervals Binance depth streams offer
+var binanceUpdateSpeeds = []time.Duration{100 * time.Millisecond, time.Second}
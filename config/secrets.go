@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// resolveSecrets replaces secrets with the contents of their _file settings,
+// as mounted by Docker and Kubernetes secrets, and reports unreadable files
+func (c *Config) resolveSecrets() []string {
+	var problems []string
+
+	secrets := []struct {
+		key   string
+		file  string
+		value *string
+	}{
+		{"database.password_file", c.Database.PasswordFile, &c.Database.Password},
+		{"redis.password_file", c.Redis.PasswordFile, &c.Redis.Password},
+		{"exchange.binance.api_key_file", c.Exchange.Binance.APIKeyFile, &c.Exchange.Binance.APIKey},
+		{"exchange.binance.api_secret_file", c.Exchange.Binance.APISecretFile, &c.Exchange.Binance.APISecret},
+		{"exchange.okx.api_key_file", c.Exchange.OKX.APIKeyFile, &c.Exchange.OKX.APIKey},
+		{"exchange.okx.api_secret_file", c.Exchange.OKX.APISecretFile, &c.Exchange.OKX.APISecret},
+	}
+	for _, secret := range secrets {
+		if secret.file == "" {
+			continue
+		}
+		value, err := readSecret(secret.file)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", secret.key, err))
+			continue
+		}
+		*secret.value = value
+	}
+
+	keyFiles := []struct {
+		key  string
+		file string
+		keys *[]string
+	}{
+		{"server.api_keys_file", c.Server.APIKeysFile, &c.Server.APIKeys},
+		{"admin.api_keys_file", c.Admin.APIKeysFile, &c.Admin.APIKeys},
+	}
+	for _, keyFile := range keyFiles {
+		if keyFile.file == "" {
+			continue
+		}
+		keys, err := readSecret(keyFile.file)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", keyFile.key, err))
+		}
+		for _, key := range strings.Split(keys, "\n") {
+			if key = strings.TrimSpace(key); key != "" {
+				*keyFile.keys = append(*keyFile.keys, key)
+			}
+		}
+	}
+
+	return problems
+}
+
+// readSecret reads a secret file, dropping the trailing newline editors add
+func readSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
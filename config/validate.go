@@ -0,0 +1,588 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KnownExchanges lists the live exchanges that take symbol settings
+var KnownExchanges = []string{"binance", "okx"}
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// ValidationError lists every problem found in a config
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// validator collects problems instead of stopping at the first
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) port(key string, port int) {
+	if port < 1 || port > 65535 {
+		v.addf("%s: %d is not a valid port", key, port)
+	}
+}
+
+func (v *validator) nonNegative(key string, value int) {
+	if value < 0 {
+		v.addf("%s: must not be negative, got %d", key, value)
+	}
+}
+
+func (v *validator) positiveDuration(key string, value time.Duration) {
+	if value <= 0 {
+		v.addf("%s: must be positive, got %s", key, value)
+	}
+}
+
+func (v *validator) probability(key string, value float64) {
+	if value < 0 || value > 1 {
+		v.addf("%s: must be between 0 and 1, got %g", key, value)
+	}
+}
+
+// fee accepts rates from 0 up to but excluding 1
+func (v *validator) fee(key string, value float64) {
+	if value < 0 || value >= 1 {
+		v.addf("%s: must be a fraction between 0 and 1, such as 0.001 for 0.1%%, got %g", key, value)
+	}
+}
+
+// feeRates accepts taker rates from 0 and maker rates above -1, both below 1
+func (v *validator) feeRates(key string, rates FeeRatesConfig) {
+	v.fee(key+".taker", rates.Taker)
+	if rates.Maker <= -1 || rates.Maker >= 1 {
+		v.addf("%s.maker: must be a fraction between -1 and 1, such as 0.001 for 0.1%%, got %g", key, rates.Maker)
+	}
+}
+
+func (v *validator) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.addf("%s: is required", key)
+	}
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	c.validateServer(v)
+	c.validateStorage(v)
+	c.validateExchanges(v)
+	c.validateRecorder(v)
+	c.validateAdmin(v)
+	c.validateArbitrage(v)
+	c.validateStatisticalArbitrage(v)
+	c.validateFees(v)
+	c.validateIndicators(v)
+	c.validateRisk(v)
+	c.validateSizing(v)
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+func (c *Config) validateServer(v *validator) {
+	s := c.Server
+	v.port("server.http_port", s.HTTPPort)
+	v.port("server.grpc_port", s.GRPCPort)
+	if s.HTTPPort == s.GRPCPort {
+		v.addf("server.http_port and server.grpc_port: both use port %d", s.HTTPPort)
+	}
+	v.positiveDuration("server.request_timeout", s.RequestTimeout)
+	v.positiveDuration("server.shutdown_timeout", s.ShutdownTimeout)
+	v.nonNegative("server.websocket.max_subscriptions", s.WebSocket.MaxSubscriptions)
+	v.positiveDuration("server.websocket.heartbeat_interval", s.WebSocket.HeartbeatInterval)
+	v.positiveDuration("server.websocket.poll_interval", s.WebSocket.PollInterval)
+	v.nonNegative("server.sse.buffer_size", s.SSE.BufferSize)
+	v.positiveDuration("server.sse.heartbeat_interval", s.SSE.HeartbeatInterval)
+	v.positiveDuration("server.sse.linger", s.SSE.Linger)
+}
+
+func (c *Config) validateStorage(v *validator) {
+	m := c.Storage.Memory
+	v.nonNegative("storage.memory.max_trades", m.MaxTrades)
+	v.nonNegative("storage.memory.max_snapshots_per_book", m.MaxSnapshotsPerBook)
+	v.nonNegative("storage.memory.max_events", m.MaxEvents)
+
+	if c.Redis.TTL < 0 {
+		v.addf("redis.ttl: must not be negative, got %s", c.Redis.TTL)
+	}
+
+	switch c.Storage.Backend {
+	case StorageMemory:
+		return
+	case StorageExternal:
+	default:
+		v.addf("storage.backend: must be %q or %q, got %q", StorageExternal, StorageMemory, c.Storage.Backend)
+		return
+	}
+
+	db := c.Database
+	v.required("database.host", db.Host)
+	v.port("database.port", db.Port)
+	v.required("database.user", db.User)
+	v.required("database.dbname", db.DBName)
+	if db.SSLMode != "" && !slices.Contains(sslModes, db.SSLMode) {
+		v.addf("database.sslmode: must be one of %s, got %q", strings.Join(sslModes, ", "), db.SSLMode)
+	}
+	v.nonNegative("database.max_open_conns", db.MaxOpenConns)
+	v.nonNegative("database.trade_batch.size", db.TradeBatch.Size)
+	v.nonNegative("database.trade_batch.max_pending", db.TradeBatch.MaxPending)
+	if db.TradeBatch.FlushInterval < 0 {
+		v.addf("database.trade_batch.flush_interval: must not be negative, got %s", db.TradeBatch.FlushInterval)
+	}
+	if db.BookSnapshots.Enabled {
+		v.positiveDuration("database.book_snapshots.interval", db.BookSnapshots.Interval)
+		if db.BookSnapshots.Depth <= 0 {
+			v.addf("database.book_snapshots.depth: must be positive, got %d", db.BookSnapshots.Depth)
+		}
+	}
+
+	v.required("redis.host", c.Redis.Host)
+	v.port("redis.port", c.Redis.Port)
+	v.nonNegative("redis.db", c.Redis.DB)
+
+	if len(c.Kafka.Brokers) == 0 {
+		v.addf("kafka.brokers: at least one broker is required")
+	}
+	for _, broker := range c.Kafka.Brokers {
+		host, port, err := net.SplitHostPort(broker)
+		if err != nil || host == "" {
+			v.addf("kafka.brokers: %q is not a host:port address", broker)
+			continue
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			v.addf("kafka.brokers: %q has an invalid port", broker)
+		}
+	}
+	v.required("kafka.topic", c.Kafka.Topic)
+	if c.Arbitrage.Enabled {
+		v.required("kafka.arbitrage_topic", c.Kafka.ArbitrageTopic)
+	}
+	if c.Arbitrage.Statistical.Enabled {
+		v.required("kafka.signal_topic", c.Kafka.SignalTopic)
+	}
+}
+
+func (c *Config) validateExchanges(v *validator) {
+	seen := make(map[string]bool, len(c.Exchange.Symbols))
+	for _, symbol := range c.Exchange.Symbols {
+		if !validSymbol(symbol) {
+			v.addf("exchange.symbols: %q is not a BASE-QUOTE symbol", symbol)
+		}
+		if seen[symbol] {
+			v.addf("exchange.symbols: %q is listed twice", symbol)
+		}
+		seen[symbol] = true
+	}
+
+	d := c.Exchange.Defaults
+	v.nonNegative("exchange.defaults.depth", d.Depth)
+	v.positiveDuration("exchange.defaults.update_speed", d.UpdateSpeed)
+	if d.PublishThrottle < 0 {
+		v.addf("exchange.defaults.publish_throttle: must not be negative, got %s", d.PublishThrottle)
+	}
+	for _, name := range KnownExchanges {
+		c.validateVenue(v, name)
+	}
+
+	c.validateReplay(v)
+
+	sim := c.Exchange.Simulated
+	if !sim.Enabled {
+		return
+	}
+	if slices.Contains(KnownExchanges, sim.Name) {
+		v.addf("exchange.simulated.name: %q is a real exchange", sim.Name)
+	}
+	v.positiveDuration("exchange.simulated.update_interval", sim.UpdateInterval)
+	v.nonNegative("exchange.simulated.depth", sim.Depth)
+	if sim.ServerPort != 0 {
+		v.port("exchange.simulated.server_port", sim.ServerPort)
+		if sim.ServerPort == c.Server.HTTPPort || sim.ServerPort == c.Server.GRPCPort {
+			v.addf("exchange.simulated.server_port: port %d is already used by the service", sim.ServerPort)
+		}
+	}
+	if len(sim.Symbols) == 0 {
+		v.addf("exchange.simulated.symbols: at least one symbol is required")
+	}
+	for i, symbol := range sim.Symbols {
+		if !validSymbol(symbol.Symbol) {
+			v.addf("exchange.simulated.symbols[%d].symbol: %q is not a BASE-QUOTE symbol", i, symbol.Symbol)
+		}
+		if symbol.StartPrice <= 0 {
+			v.addf("exchange.simulated.symbols[%d].start_price: must be positive", i)
+		}
+		for _, field := range []struct {
+			key   string
+			value float64
+		}{
+			{"spread_bps", symbol.SpreadBps},
+			{"volatility", symbol.Volatility},
+			{"tick_size", symbol.TickSize},
+			{"level_quantity", symbol.LevelQuantity},
+			{"trades_per_update", symbol.TradesPerUpdate},
+		} {
+			if field.value < 0 {
+				v.addf("exchange.simulated.symbols[%d].%s: must not be negative", i, field.key)
+			}
+		}
+	}
+	v.probability("exchange.simulated.faults.sequence_gap", sim.Faults.SequenceGap)
+	v.probability("exchange.simulated.faults.disconnect", sim.Faults.Disconnect)
+	v.probability("exchange.simulated.faults.crossed_book", sim.Faults.CrossedBook)
+	v.probability("exchange.simulated.faults.latency_spike", sim.Faults.LatencySpike)
+	if sim.Faults.SpikeDelay < 0 {
+		v.addf("exchange.simulated.faults.spike_delay: must not be negative, got %s", sim.Faults.SpikeDelay)
+	}
+}
+
+// validateVenue checks the per-symbol settings of one live exchange
+func (c *Config) validateVenue(v *validator, name string) {
+	key := "exchange." + name + ".symbols"
+	seen := make(map[string]bool)
+	for i, symbol := range c.Exchange.venue(name).Symbols {
+		if !validSymbol(symbol.Symbol) {
+			v.addf("%s[%d]: %q is not a BASE-QUOTE symbol", key, i, symbol.Symbol)
+		}
+		if seen[symbol.Symbol] {
+			v.addf("%s: %q is listed twice", key, symbol.Symbol)
+		}
+		seen[symbol.Symbol] = true
+
+		if symbol.Depth != nil {
+			v.nonNegative(fmt.Sprintf("%s[%d].depth", key, i), *symbol.Depth)
+		}
+		if symbol.UpdateSpeed != nil {
+			v.positiveDuration(fmt.Sprintf("%s[%d].update_speed", key, i), *symbol.UpdateSpeed)
+		}
+		if symbol.PublishThrottle != nil && *symbol.PublishThrottle < 0 {
+			v.addf("%s[%d].publish_throttle: must not be negative, got %s", key, i, *symbol.PublishThrottle)
+		}
+	}
+
+	if name != "binance" {
+		return
+	}
+	for _, sub := range c.SubscriptionPlan() {
+		if sub.Exchange == name && sub.UpdateSpeed > 0 && !slices.Contains(binanceUpdateSpeeds, sub.UpdateSpeed) {
+			v.addf("%s: %s update_speed must be 100ms or 1s on binance, got %s", key, sub.Symbol, sub.UpdateSpeed)
+		}
+	}
+}
+
+func (c *Config) validateReplay(v *validator) {
+	r := c.Exchange.Replay
+	if !r.Enabled {
+		return
+	}
+
+	if !slices.Contains(KnownExchanges, r.Exchange) {
+		v.addf("exchange.replay.exchange: unknown exchange %q", r.Exchange)
+	}
+	if len(r.Files) == 0 {
+		v.addf("exchange.replay.files: at least one recording is required")
+	}
+	if r.Speed < 0 {
+		v.addf("exchange.replay.speed: must not be negative, got %g", r.Speed)
+	}
+	if !r.From.IsZero() && !r.To.IsZero() && !r.From.Before(r.To) {
+		v.addf("exchange.replay.to: must be after from")
+	}
+}
+
+func (c *Config) validateRecorder(v *validator) {
+	r := c.Recorder
+	if !r.Enabled {
+		return
+	}
+
+	v.required("recorder.dir", r.Dir)
+	v.nonNegative("recorder.queue_size", r.QueueSize)
+	if r.MaxFileSize < 0 {
+		v.addf("recorder.max_file_size: must not be negative, got %d", r.MaxFileSize)
+	}
+}
+
+func (c *Config) validateAdmin(v *validator) {
+	if c.Admin.Enabled && len(c.Admin.APIKeys) == 0 {
+		v.addf("admin.api_keys: at least one key is required when the admin API is enabled")
+	}
+}
+
+func (c *Config) validateArbitrage(v *validator) {
+	a := c.Arbitrage
+	if !a.Enabled && !a.Triangular.Enabled && !a.Statistical.Enabled {
+		return
+	}
+
+	if a.MinProfitPct < 0 {
+		v.addf("arbitrage.min_profit_pct: must not be negative, got %g", a.MinProfitPct)
+	}
+	for _, field := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"open_after", a.OpenAfter},
+		{"close_after", a.CloseAfter},
+		{"update_interval", a.UpdateInterval},
+		{"max_book_age", a.MaxBookAge},
+	} {
+		if field.value < 0 {
+			v.addf("arbitrage.%s: must not be negative, got %s", field.key, field.value)
+		}
+	}
+	if a.MinChangePct < 0 {
+		v.addf("arbitrage.min_change_pct: must not be negative, got %g", a.MinChangePct)
+	}
+
+	t := a.Triangular
+	if !t.Enabled {
+		return
+	}
+	if t.MinProfitPct < 0 {
+		v.addf("arbitrage.triangular.min_profit_pct: must not be negative, got %g", t.MinProfitPct)
+	}
+	for i, currency := range t.StartCurrencies {
+		if currency == "" || strings.ContainsAny(currency, "- \t/_") {
+			v.addf("arbitrage.triangular.start_currencies[%d]: %q is not a currency", i, currency)
+		}
+	}
+}
+
+func (c *Config) validateStatisticalArbitrage(v *validator) {
+	s := c.Arbitrage.Statistical
+	if !s.Enabled {
+		return
+	}
+
+	v.positiveDuration("arbitrage.statistical.interval", s.Interval)
+	if s.MinSamples < 2 {
+		v.addf("arbitrage.statistical.min_samples: must be at least 2, got %d", s.MinSamples)
+	}
+	if s.Window < s.MinSamples {
+		v.addf("arbitrage.statistical.window: must be at least min_samples (%d), got %d", s.MinSamples, s.Window)
+	}
+	if s.ExitZ < 0 {
+		v.addf("arbitrage.statistical.exit_z: must not be negative, got %g", s.ExitZ)
+	}
+	if s.EntryZ <= s.ExitZ {
+		v.addf("arbitrage.statistical.entry_z: must be greater than exit_z (%g), got %g", s.ExitZ, s.EntryZ)
+	}
+	if s.StateFile != "" {
+		v.positiveDuration("arbitrage.statistical.save_interval", s.SaveInterval)
+	}
+
+	if len(s.Pairs) == 0 {
+		v.addf("arbitrage.statistical.pairs: at least one pair is required")
+	}
+	exchanges := KnownExchanges
+	if c.Exchange.Simulated.Enabled {
+		exchanges = append(slices.Clone(exchanges), c.Exchange.Simulated.Name)
+	}
+	seen := make(map[string]bool, len(s.Pairs))
+	for i, pair := range s.Pairs {
+		key := fmt.Sprintf("arbitrage.statistical.pairs[%d]", i)
+		v.required(key+".name", pair.Name)
+		if pair.Name != "" && seen[pair.Name] {
+			v.addf("%s.name: %q is used twice", key, pair.Name)
+		}
+		seen[pair.Name] = true
+
+		for _, leg := range []struct {
+			key string
+			StatArbLegConfig
+		}{
+			{key + ".a", pair.A},
+			{key + ".b", pair.B},
+		} {
+			if !slices.Contains(exchanges, leg.Exchange) {
+				v.addf("%s.exchange: %q is not a known exchange", leg.key, leg.Exchange)
+			}
+			if !validSymbol(leg.Symbol) {
+				v.addf("%s.symbol: %q is not a BASE-QUOTE symbol", leg.key, leg.Symbol)
+			}
+		}
+		if pair.A == pair.B {
+			v.addf("%s: a and b are the same instrument", key)
+		}
+	}
+}
+
+func (c *Config) validateFees(v *validator) {
+	f := c.Fees
+	v.feeRates("fees.default", f.Default)
+	if f.RefreshInterval < 0 {
+		v.addf("fees.refresh_interval: must not be negative, got %s", f.RefreshInterval)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(f.Exchanges)) {
+		e := f.Exchanges[name]
+		key := "fees.exchanges." + name
+		for _, tier := range slices.Sorted(maps.Keys(e.Tiers)) {
+			v.feeRates(key+".tiers."+tier, e.Tiers[tier])
+		}
+		switch {
+		case e.Tier == "" && len(e.Tiers) > 0:
+			v.addf("%s.tier: is required when tiers are listed", key)
+		case e.Tier != "" && len(e.Tiers) == 0:
+			v.addf("%s.tiers: must list tier %q", key, e.Tier)
+		case e.Tier != "":
+			if _, ok := e.Tiers[strings.ToLower(e.Tier)]; !ok {
+				v.addf("%s.tier: %q is not one of the tiers", key, e.Tier)
+			}
+		}
+		for _, symbol := range slices.Sorted(maps.Keys(e.Symbols)) {
+			if !validSymbol(strings.ToUpper(symbol)) {
+				v.addf("%s.symbols: %q is not a BASE-QUOTE symbol", key, symbol)
+			}
+			v.feeRates(key+".symbols."+symbol, e.Symbols[symbol])
+		}
+		if d := e.Discount; d.Pct < 0 || d.Pct > 100 {
+			v.addf("%s.discount.pct: must be between 0 and 100, got %g", key, d.Pct)
+		} else if d.Pct > 0 && d.Asset == "" {
+			v.addf("%s.discount.asset: is required with a discount", key)
+		}
+	}
+}
+
+func (c *Config) validateIndicators(v *validator) {
+	ind := c.Indicators
+	if !ind.Enabled {
+		return
+	}
+
+	v.positiveDuration("indicators.candle_interval", ind.CandleInterval)
+	v.positiveDuration("indicators.stale_after", ind.StaleAfter)
+	v.positiveDuration("indicators.high_volatility_horizon", ind.HighVolatilityHorizon)
+	if ind.VolatilityWindow < 2 {
+		v.addf("indicators.volatility_window: must be at least 2, got %d", ind.VolatilityWindow)
+	}
+	if ind.ATRPeriod < 1 {
+		v.addf("indicators.atr_period: must be at least 1, got %d", ind.ATRPeriod)
+	}
+	// Candles are kept for 24h, so longer windows would never fill
+	if ind.CandleInterval > 0 {
+		candles := int(24 * time.Hour / ind.CandleInterval)
+		if ind.VolatilityWindow+1 > candles {
+			v.addf("indicators.volatility_window: needs %d candles, more than the %d of 24h", ind.VolatilityWindow+1, candles)
+		}
+		if ind.ATRPeriod+1 > candles {
+			v.addf("indicators.atr_period: needs %d candles, more than the %d of 24h", ind.ATRPeriod+1, candles)
+		}
+	}
+	for _, bps := range ind.DepthBps {
+		if bps <= 0 || bps >= 10000 {
+			v.addf("indicators.depth_bps: must be between 0 and 10000, got %g", bps)
+		}
+	}
+	if ind.HighVolatilityPct < 0 {
+		v.addf("indicators.high_volatility_pct: must not be negative, got %g", ind.HighVolatilityPct)
+	}
+	if ind.HighCandlePct < 0 {
+		v.addf("indicators.high_candle_pct: must not be negative, got %g", ind.HighCandlePct)
+	}
+}
+
+func (c *Config) validateRisk(v *validator) {
+	r := c.Risk
+	if !r.Enabled {
+		return
+	}
+
+	if r.AccountSize <= 0 {
+		v.addf("risk.account_size: must be positive, got %g", r.AccountSize)
+	}
+	limit := func(key string, pct float64) {
+		if pct < 0 || pct >= 100 {
+			v.addf("%s: must be between 0 and 100, got %g", key, pct)
+		}
+	}
+	limit("risk.per_trade_loss_pct", r.PerTradeLossPct)
+	limit("risk.daily_loss_pct", r.DailyLossPct)
+	limit("risk.max_drawdown_pct", r.MaxDrawdownPct)
+	if r.DailyReset < 0 || r.DailyReset >= 24*time.Hour {
+		v.addf("risk.daily_reset: must be between 0 and 24h, got %s", r.DailyReset)
+	}
+	if _, err := time.LoadLocation(r.Timezone); err != nil {
+		v.addf("risk.timezone: %q is not a known time zone", r.Timezone)
+	}
+	if r.StateFile != "" {
+		v.positiveDuration("risk.save_interval", r.SaveInterval)
+	}
+}
+
+func (c *Config) validateSizing(v *validator) {
+	s := c.Sizing
+	if !s.Enabled {
+		return
+	}
+
+	if !c.Risk.Enabled && s.AccountSize <= 0 {
+		v.addf("sizing.account_size: must be positive when risk is disabled, got %g", s.AccountSize)
+	}
+	share := func(key string, pct float64) {
+		if pct <= 0 || pct > 100 {
+			v.addf("%s: must be above 0 and at most 100, got %g", key, pct)
+		}
+	}
+	share("sizing.account_pct", s.AccountPct)
+	share("sizing.liquidity_pct", s.LiquidityPct)
+	switch {
+	case s.RiskLimitPct < 0 || s.RiskLimitPct >= 100:
+		v.addf("sizing.risk_limit_pct: must be between 0 and 100, got %g", s.RiskLimitPct)
+	case s.RiskLimitPct > 0 && !c.Indicators.Enabled:
+		v.addf("sizing.risk_limit_pct: needs indicators.enabled for the volatility")
+	case s.RiskLimitPct > 0:
+		v.positiveDuration("sizing.volatility_horizon", s.VolatilityHorizon)
+	}
+	if s.RefreshInterval < 0 {
+		v.addf("sizing.refresh_interval: must not be negative, got %s", s.RefreshInterval)
+	}
+	if s.DefaultLotSize < 0 {
+		v.addf("sizing.default_lot_size: must not be negative, got %g", s.DefaultLotSize)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(s.Exchanges)) {
+		e := s.Exchanges[name]
+		key := "sizing.exchanges." + name
+		for _, asset := range slices.Sorted(maps.Keys(e.Balances)) {
+			if balance := e.Balances[asset]; balance < 0 {
+				v.addf("%s.balances.%s: must not be negative, got %g", key, asset, balance)
+			}
+		}
+		for _, symbol := range slices.Sorted(maps.Keys(e.LotSizes)) {
+			if !validSymbol(strings.ToUpper(symbol)) {
+				v.addf("%s.lot_sizes: %q is not a BASE-QUOTE symbol", key, symbol)
+			}
+			if lotSize := e.LotSizes[symbol]; lotSize <= 0 {
+				v.addf("%s.lot_sizes.%s: must be positive, got %g", key, symbol, lotSize)
+			}
+		}
+	}
+}
+
+// validSymbol accepts BASE-QUOTE symbols such as BTC-USDT
+func validSymbol(symbol string) bool {
+	base, quote, ok := strings.Cut(symbol, "-")
+	return ok && base != "" && quote != "" && !strings.ContainsAny(symbol, " \t/_") && !strings.Contains(quote, "-")
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay lets a burst of file events settle before reloading, so a save
+// that truncates the file first is not read half written
+const reloadDelay = 250 * time.Millisecond
+
+// Watch reloads the config whenever its file changes and passes the result to
+// onChange: either the new config or the error that made it unusable, in
+// which case the caller should keep running on the old one. Changes arrive
+// one at a time. Watch returns the file it watches; path is resolved as in
+// LoadFile, but a config file must exist.
+func Watch(path string, onChange func(*Config, error)) (string, error) {
+	v := newViper(path)
+	if err := v.ReadInConfig(); err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	file := v.ConfigFileUsed()
+	var mu sync.Mutex
+	reload := time.AfterFunc(reloadDelay, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		onChange(load(file, false))
+	})
+	reload.Stop()
+
+	v.OnConfigChange(func(fsnotify.Event) {
+		reload.Reset(reloadDelay)
+	})
+	v.WatchConfig()
+
+	return file, nil
+}
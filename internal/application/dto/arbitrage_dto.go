@@ -0,0 +1,89 @@
+package dto
+
+import "time"
+
+// ArbitrageOpportunityDTO is a cross-exchange spread. FeesPct is the taker
+// fees of both exchanges and NetSpreadPct the spread left after them.
+type ArbitrageOpportunityDTO struct {
+	Symbol       string  `json:"symbol"`
+	BuyExchange  string  `json:"buy_exchange"`
+	SellExchange string  `json:"sell_exchange"`
+	SpreadPct    float64 `json:"spread_pct"`
+	FeesPct      float64 `json:"fees_pct"`
+	NetSpreadPct float64 `json:"net_spread_pct"`
+	MaxVolume    float64 `json:"max_volume"`
+}
+
+// Lifecycle events of a tracked opportunity
+const (
+	ArbitrageOpened  = "opened"
+	ArbitrageUpdated = "updated"
+	ArbitrageClosed  = "closed"
+)
+
+// TrackedOpportunityDTO is an opportunity followed by the arbitrage scanner.
+// Prices are the average fills of MaxVolume across the depth of both books,
+// so SpreadPct already includes the slippage.
+type TrackedOpportunityDTO struct {
+	ID string `json:"id"`
+	ArbitrageOpportunityDTO
+	BuyPrice         float64    `json:"buy_price"`
+	SellPrice        float64    `json:"sell_price"`
+	SlippagePct      float64    `json:"slippage_pct"`
+	PeakNetSpreadPct float64    `json:"peak_net_spread_pct"`
+	ExpectedProfit   float64    `json:"expected_profit"`
+	OpenedAt         time.Time  `json:"opened_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	ClosedAt         *time.Time `json:"closed_at,omitempty"`
+	DurationMs       int64      `json:"duration_ms"`
+	// Size is how much of the opportunity to trade, when sizing is on
+	Size *PositionSizeDTO `json:"size,omitempty"`
+}
+
+// PositionSizeDTO is the quantity to trade, rounded down to the lot size, and
+// the limit that bound it. Notionals are in the quote currency.
+type PositionSizeDTO struct {
+	Quantity float64        `json:"quantity"`
+	Notional float64        `json:"notional"`
+	Binding  string         `json:"binding"`
+	Limits   []SizeLimitDTO `json:"limits"`
+}
+
+// SizeLimitDTO is the most one constraint allows a position to be worth
+type SizeLimitDTO struct {
+	Name     string  `json:"name"`
+	Notional float64 `json:"notional"`
+}
+
+// ArbitrageEventDTO reports that an opportunity opened, changed or closed
+type ArbitrageEventDTO struct {
+	Type        string                 `json:"type"`
+	Opportunity *TrackedOpportunityDTO `json:"opportunity"`
+	Time        time.Time              `json:"time"`
+}
+
+// ArbitrageCycleDTO is a profitable cycle of conversions on one exchange.
+// StartAmount, in StartCurrency, is the most that can go around with every
+// unit still earning the minimum profit.
+type ArbitrageCycleDTO struct {
+	ID            string        `json:"id"`
+	ExchangeID    string        `json:"exchange_id"`
+	StartCurrency string        `json:"start_currency"`
+	Legs          []CycleLegDTO `json:"legs"`
+	StartAmount   float64       `json:"start_amount"`
+	EndAmount     float64       `json:"end_amount"`
+	ProfitPct     float64       `json:"profit_pct"`
+	DetectedAt    time.Time     `json:"detected_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+// CycleLegDTO is how one leg fills; AmountOut is net of the taker fee
+type CycleLegDTO struct {
+	Symbol       string  `json:"symbol"`
+	Side         string  `json:"side"`
+	FromCurrency string  `json:"from_currency"`
+	ToCurrency   string  `json:"to_currency"`
+	Price        float64 `json:"price"`
+	AmountIn     float64 `json:"amount_in"`
+	AmountOut    float64 `json:"amount_out"`
+}
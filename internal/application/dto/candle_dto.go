@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"time"
+)
+
+type CandleDTO struct {
+	ExchangeID string    `json:"exchange_id"`
+	Symbol     string    `json:"symbol"`
+	Interval   string    `json:"interval"`
+	OpenTime   time.Time `json:"open_time"`
+	Open       float64   `json:"open"`
+	High       float64   `json:"high"`
+	Low        float64   `json:"low"`
+	Close      float64   `json:"close"`
+	Volume     float64   `json:"volume"`
+	TradeCount int64     `json:"trade_count"`
+}
+
+type CandleQueryDTO struct {
+	ExchangeID string
+	Symbol     string
+	Interval   time.Duration
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
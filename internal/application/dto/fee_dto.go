@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+// FeeRatesDTO are fee rates as fractions, 0.001 for 0.1%
+type FeeRatesDTO struct {
+	Maker float64 `json:"maker"`
+	Taker float64 `json:"taker"`
+}
+
+// FeeScheduleDTO is the fee schedule of one exchange. Rates are after the
+// discount. Symbols lists the configured overrides; ExchangeSymbols counts
+// the symbol rates last fetched from the exchange account.
+type FeeScheduleDTO struct {
+	ExchangeID      string                 `json:"exchange_id"`
+	Tier            string                 `json:"tier,omitempty"`
+	Rates           FeeRatesDTO            `json:"rates"`
+	Symbols         map[string]FeeRatesDTO `json:"symbols,omitempty"`
+	DiscountAsset   string                 `json:"discount_asset,omitempty"`
+	DiscountPct     float64                `json:"discount_pct,omitempty"`
+	ExchangeSymbols int                    `json:"exchange_symbols"`
+	RefreshedAt     *time.Time             `json:"refreshed_at,omitempty"`
+}
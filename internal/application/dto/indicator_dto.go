@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// IndicatorsDTO describes the volatility and market quality of one symbol on
+// one exchange. Candles are built from the mid price of the streamed books;
+// indicators that need more candles than are available are omitted.
+// Percentages are in percent.
+type IndicatorsDTO struct {
+	ExchangeID string  `json:"exchange_id"`
+	Symbol     string  `json:"symbol"`
+	MidPrice   float64 `json:"mid_price"`
+	// RealizedVolatilityPct is the standard deviation of the close-to-close
+	// returns per candle interval
+	RealizedVolatilityPct *float64 `json:"realized_volatility_pct,omitempty"`
+	ATR                   *float64 `json:"atr,omitempty"`
+	ATRPct                *float64 `json:"atr_pct,omitempty"`
+	// CandleRangePct is the high/low range of the last closed candle
+	CandleRangePct *float64 `json:"candle_range_pct,omitempty"`
+	// High24h, Low24h and Range24hPct cover the candles of the last 24 hours
+	// that were seen, including the current one
+	High24h     float64 `json:"high_24h"`
+	Low24h      float64 `json:"low_24h"`
+	Range24hPct float64 `json:"range_24h_pct"`
+	// HighVolatility is set when the volatility, scaled to the configured
+	// horizon, or the last candle exceeds its configured threshold
+	HighVolatility bool           `json:"high_volatility"`
+	Depth          []BookDepthDTO `json:"depth"`
+	Candles        int            `json:"candles"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// BookDepthDTO is the liquidity within Bps basis points of the mid price.
+// Imbalance runs from -1, all asks, to 1, all bids.
+type BookDepthDTO struct {
+	Bps         float64 `json:"bps"`
+	BidQuantity float64 `json:"bid_quantity"`
+	AskQuantity float64 `json:"ask_quantity"`
+	BidNotional float64 `json:"bid_notional"`
+	AskNotional float64 `json:"ask_notional"`
+	Imbalance   float64 `json:"imbalance"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+type InstrumentDTO struct {
+	ExchangeID string `json:"exchange_id"`
+	Symbol     string `json:"symbol"`
+	BaseAsset  string `json:"base_asset"`
+	QuoteAsset string `json:"quote_asset"`
+}
@@ -0,0 +1,106 @@
+package dto
+
+import "time"
+
+// RiskLimitManual is the limit of a halt engaged by an operator
+const RiskLimitManual = "manual"
+
+// FillDTO is an executed order reported to the risk manager. Side is BUY or
+// SELL and Liquidity maker or taker, taker when empty.
+type FillDTO struct {
+	ExchangeID string    `json:"exchange_id"`
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`
+	Quantity   float64   `json:"quantity"`
+	Price      float64   `json:"price"`
+	Liquidity  string    `json:"liquidity,omitempty"`
+	Time       time.Time `json:"time,omitempty"`
+}
+
+// FillResultDTO is what a fill did to its position. RealizedPnL is net of
+// Fee.
+type FillResultDTO struct {
+	Position    RiskPositionDTO `json:"position"`
+	RealizedPnL float64         `json:"realized_pnl"`
+	Fee         float64         `json:"fee"`
+	Halt        *RiskHaltDTO    `json:"halt,omitempty"`
+}
+
+// TradeCheckDTO asks whether a trade may be placed. MaxLoss is what the
+// trade may lose besides its taker fee, in the quote currency.
+type TradeCheckDTO struct {
+	ExchangeID string  `json:"exchange_id"`
+	Symbol     string  `json:"symbol"`
+	Side       string  `json:"side"`
+	Quantity   float64 `json:"quantity"`
+	Price      float64 `json:"price"`
+	MaxLoss    float64 `json:"max_loss"`
+}
+
+// TradeCheckResultDTO approves a trade or says which limit rejects it.
+// MaxLoss includes the fee.
+type TradeCheckResultDTO struct {
+	Approved bool    `json:"approved"`
+	Limit    string  `json:"limit,omitempty"`
+	Reason   string  `json:"reason,omitempty"`
+	Fee      float64 `json:"fee"`
+	MaxLoss  float64 `json:"max_loss"`
+}
+
+// RiskHaltDTO is an engaged kill switch
+type RiskHaltDTO struct {
+	Limit  string    `json:"limit"`
+	Reason string    `json:"reason"`
+	At     time.Time `json:"at"`
+}
+
+// RiskLimitsDTO are loss limits in percent; zero is not enforced
+type RiskLimitsDTO struct {
+	PerTradePct    float64 `json:"per_trade_pct"`
+	DailyLossPct   float64 `json:"daily_loss_pct"`
+	MaxDrawdownPct float64 `json:"max_drawdown_pct"`
+}
+
+// RiskPositionDTO is a held position marked at the latest mid price
+type RiskPositionDTO struct {
+	ExchangeID    string  `json:"exchange_id"`
+	Symbol        string  `json:"symbol"`
+	Quantity      float64 `json:"quantity"`
+	AvgPrice      float64 `json:"avg_price"`
+	MarkPrice     float64 `json:"mark_price"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+}
+
+// RiskStatusDTO is the PnL of the account against its limits. Equity is the
+// account size plus the realized and unrealized PnL; percentages are in
+// percent, losses positive.
+type RiskStatusDTO struct {
+	AccountSize    float64           `json:"account_size"`
+	Equity         float64           `json:"equity"`
+	RealizedPnL    float64           `json:"realized_pnl"`
+	UnrealizedPnL  float64           `json:"unrealized_pnl"`
+	FeesPaid       float64           `json:"fees_paid"`
+	DayStart       time.Time         `json:"day_start"`
+	DayStartEquity float64           `json:"day_start_equity"`
+	DailyPnL       float64           `json:"daily_pnl"`
+	DailyLossPct   float64           `json:"daily_loss_pct"`
+	PeakEquity     float64           `json:"peak_equity"`
+	DrawdownPct    float64           `json:"drawdown_pct"`
+	Limits         RiskLimitsDTO     `json:"limits"`
+	Halted         bool              `json:"halted"`
+	Halt           *RiskHaltDTO      `json:"halt,omitempty"`
+	Positions      []RiskPositionDTO `json:"positions"`
+}
+
+// RiskStateDTO is what the risk manager saves to carry on after a restart
+type RiskStateDTO struct {
+	AccountSize    float64           `json:"account_size"`
+	RealizedPnL    float64           `json:"realized_pnl"`
+	FeesPaid       float64           `json:"fees_paid"`
+	DayStart       time.Time         `json:"day_start"`
+	DayStartEquity float64           `json:"day_start_equity"`
+	PeakEquity     float64           `json:"peak_equity"`
+	Halt           *RiskHaltDTO      `json:"halt,omitempty"`
+	Positions      []RiskPositionDTO `json:"positions"`
+	SavedAt        time.Time         `json:"saved_at"`
+}
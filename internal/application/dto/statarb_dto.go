@@ -0,0 +1,69 @@
+package dto
+
+import "time"
+
+// Positions of a statistical arbitrage pair. Long spread buys leg A and sells
+// the hedge ratio of leg B; short spread does the opposite.
+const (
+	StatArbFlat        = "flat"
+	StatArbLongSpread  = "long_spread"
+	StatArbShortSpread = "short_spread"
+)
+
+// Statistical arbitrage signal types
+const (
+	StatArbEntry = "entry"
+	StatArbExit  = "exit"
+)
+
+// StatArbSignalDTO tells to enter or exit a position on a pair
+type StatArbSignalDTO struct {
+	Pair       string    `json:"pair"`
+	Type       string    `json:"type"`
+	Position   string    `json:"position"`
+	ZScore     float64   `json:"z_score"`
+	Spread     float64   `json:"spread"`
+	Mean       float64   `json:"mean"`
+	StdDev     float64   `json:"std_dev"`
+	HedgeRatio float64   `json:"hedge_ratio"`
+	PriceA     float64   `json:"price_a"`
+	PriceB     float64   `json:"price_b"`
+	Time       time.Time `json:"time"`
+}
+
+// StatArbPairDTO is the current state of a pair. The statistics are set once
+// the window holds enough samples.
+type StatArbPairDTO struct {
+	Pair       string            `json:"pair"`
+	ExchangeA  string            `json:"exchange_a"`
+	SymbolA    string            `json:"symbol_a"`
+	ExchangeB  string            `json:"exchange_b"`
+	SymbolB    string            `json:"symbol_b"`
+	Samples    int               `json:"samples"`
+	Ready      bool              `json:"ready"`
+	Mean       float64           `json:"mean"`
+	StdDev     float64           `json:"std_dev"`
+	HedgeRatio float64           `json:"hedge_ratio"`
+	Spread     float64           `json:"spread"`
+	ZScore     float64           `json:"z_score"`
+	Position   string            `json:"position"`
+	LastSignal *StatArbSignalDTO `json:"last_signal,omitempty"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// StatArbSampleDTO is one pair of mid prices
+type StatArbSampleDTO struct {
+	Time time.Time `json:"t"`
+	A    float64   `json:"a"`
+	B    float64   `json:"b"`
+}
+
+// StatArbStateDTO is what survives a restart of one pair. Legs identifies
+// the instruments the samples were taken from.
+type StatArbStateDTO struct {
+	Pair       string             `json:"pair"`
+	Legs       string             `json:"legs"`
+	Samples    []StatArbSampleDTO `json:"samples"`
+	Position   string             `json:"position"`
+	LastSignal *StatArbSignalDTO  `json:"last_signal,omitempty"`
+}
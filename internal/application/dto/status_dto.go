@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"time"
+)
+
+type DependencyStatusDTO struct {
+	Name      string  `json:"name"`
+	Healthy   bool    `json:"healthy"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type ReadinessDTO struct {
+	Ready              bool                  `json:"ready"`
+	ConnectedExchanges int                   `json:"connected_exchanges"`
+	Dependencies       []DependencyStatusDTO `json:"dependencies"`
+}
+
+type ExchangeStatusDTO struct {
+	Name      string `json:"name"`
+	Connected bool   `json:"connected"`
+}
+
+type SymbolStatusDTO struct {
+	ExchangeID          string    `json:"exchange_id"`
+	Symbol              string    `json:"symbol"`
+	Updates             uint64    `json:"updates"`
+	LastUpdate          time.Time `json:"last_update"`
+	LastUpdateAgeSecs   float64   `json:"last_update_age_seconds"`
+	SequenceGaps        uint64    `json:"sequence_gaps"`
+	PublisherLagSeconds float64   `json:"publisher_lag_seconds"`
+	PublishErrors       uint64    `json:"publish_errors"`
+}
+
+type StatusDTO struct {
+	Status        string                `json:"status"`
+	StartedAt     time.Time             `json:"started_at"`
+	UptimeSeconds float64               `json:"uptime_seconds"`
+	Exchanges     []ExchangeStatusDTO   `json:"exchanges"`
+	Symbols       []SymbolStatusDTO     `json:"symbols"`
+	Dependencies  []DependencyStatusDTO `json:"dependencies"`
+}
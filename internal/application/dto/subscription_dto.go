@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+)
+
+// SubscriptionSpecDTO describes how one symbol is streamed. Zero depth keeps
+// the full book, zero update speed uses the exchange default and zero
+// throttle publishes every update.
+type SubscriptionSpecDTO struct {
+	ExchangeID        string `json:"exchange_id"`
+	Symbol            string `json:"symbol"`
+	Depth             int    `json:"depth"`
+	UpdateSpeedMs     int64  `json:"update_speed_ms"`
+	Record            bool   `json:"record"`
+	PublishThrottleMs int64  `json:"publish_throttle_ms"`
+}
+
+type SubscriptionDTO struct {
+	SubscriptionSpecDTO
+	Since time.Time `json:"since"`
+}
+
+// SubscriptionSettingsDTO are the settings of a SubscriptionSpecDTO
+type SubscriptionSettingsDTO struct {
+	Depth             int   `json:"depth"`
+	UpdateSpeedMs     int64 `json:"update_speed_ms"`
+	Record            bool  `json:"record"`
+	PublishThrottleMs int64 `json:"publish_throttle_ms"`
+}
+
+// SubscriptionChangeDTO is one audited change. Old is absent for a new
+// subscription and New for a removed one.
+type SubscriptionChangeDTO struct {
+	Time       time.Time                `json:"time"`
+	Actor      string                   `json:"actor"`
+	Action     string                   `json:"action"`
+	ExchangeID string                   `json:"exchange_id"`
+	Symbol     string                   `json:"symbol"`
+	Old        *SubscriptionSettingsDTO `json:"old,omitempty"`
+	New        *SubscriptionSettingsDTO `json:"new,omitempty"`
+}
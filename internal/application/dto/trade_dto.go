@@ -13,3 +13,23 @@ type TradeDTO struct {
 	TradeType  string    `json:"trade_type"`
 	Timestamp  time.Time `json:"timestamp"`
 }
+
+// TradeQueryDTO selects trades. Side is "BUY", "SELL" or empty for both.
+// Cursor is the NextCursor of a previous page; pages are ordered by
+// (timestamp, exchange_id, id), newest first unless Ascending is set.
+type TradeQueryDTO struct {
+	ExchangeID string
+	Symbol     string
+	From       time.Time
+	To         time.Time
+	Side       string
+	Cursor     string
+	Limit      int
+	Ascending  bool
+}
+
+// TradePageDTO is one page of trades. NextCursor is empty on the last page.
+type TradePageDTO struct {
+	Trades     []*TradeDTO `json:"trades"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"time"
+)
+
+type TradeStatsDTO struct {
+	ExchangeID         string    `json:"exchange_id"`
+	Symbol             string    `json:"symbol"`
+	Interval           string    `json:"interval"`
+	Bucket             time.Time `json:"bucket"`
+	Open               float64   `json:"open"`
+	High               float64   `json:"high"`
+	Low                float64   `json:"low"`
+	Close              float64   `json:"close"`
+	Volume             float64   `json:"volume"`
+	QuoteVolume        float64   `json:"quote_volume"`
+	BuyVolume          float64   `json:"buy_volume"`
+	SellVolume         float64   `json:"sell_volume"`
+	TradeCount         int64     `json:"trade_count"`
+	BuyCount           int64     `json:"buy_count"`
+	SellCount          int64     `json:"sell_count"`
+	VWAP               float64   `json:"vwap"`
+	BuySellRatio       *float64  `json:"buy_sell_ratio"`
+	RealizedVolatility float64   `json:"realized_volatility"`
+}
+
+// TradeStatsQueryDTO selects analytics buckets. Interval must be one minute,
+// one hour or one day.
+type TradeStatsQueryDTO struct {
+	ExchangeID string
+	Symbol     string
+	Interval   time.Duration
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
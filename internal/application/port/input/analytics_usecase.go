@@ -0,0 +1,13 @@
+package input
+
+import (
+	"context"
+
+	"marketdata/internal/application/dto"
+)
+
+type AnalyticsUseCase interface {
+	// GetTradeStats retrieves per-bucket volume, trade counts, VWAP, buy/sell
+	// ratio and realized volatility
+	GetTradeStats(ctx context.Context, query dto.TradeStatsQueryDTO) ([]*dto.TradeStatsDTO, error)
+}
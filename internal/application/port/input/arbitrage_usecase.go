@@ -0,0 +1,34 @@
+package input
+
+import (
+	"context"
+
+	"marketdata/internal/application/dto"
+)
+
+// ArbitrageScannerUseCase exposes the opportunities the scanner tracks
+type ArbitrageScannerUseCase interface {
+	// ListOpportunities returns the open opportunities, optionally for one
+	// symbol, best net spread first
+	ListOpportunities(ctx context.Context, symbol string) []*dto.TrackedOpportunityDTO
+
+	// WatchOpportunities streams lifecycle events, optionally for one symbol,
+	// until ctx is done. The channel is closed when the watcher falls too far
+	// behind or the scanner stops.
+	WatchOpportunities(ctx context.Context, symbol string) <-chan *dto.ArbitrageEventDTO
+}
+
+// TriangularArbitrageUseCase exposes the profitable cycles found within
+// single exchanges
+type TriangularArbitrageUseCase interface {
+	// ListCycles returns the profitable cycles, optionally on one exchange,
+	// most profitable first
+	ListCycles(ctx context.Context, exchangeID string) []*dto.ArbitrageCycleDTO
+}
+
+// StatisticalArbitrageUseCase exposes the pairs of the statistical arbitrage
+// engine
+type StatisticalArbitrageUseCase interface {
+	// ListPairs returns the state of every configured pair
+	ListPairs(ctx context.Context) []*dto.StatArbPairDTO
+}
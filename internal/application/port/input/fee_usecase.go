@@ -0,0 +1,14 @@
+package input
+
+import (
+	"context"
+
+	"marketdata/internal/application/dto"
+)
+
+// FeeScheduleUseCase exposes the fee schedules of the exchanges
+type FeeScheduleUseCase interface {
+	// ListFeeSchedules returns the schedule of every configured exchange and
+	// the default one, which has no exchange ID
+	ListFeeSchedules(ctx context.Context) []*dto.FeeScheduleDTO
+}
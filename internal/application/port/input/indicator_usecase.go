@@ -0,0 +1,14 @@
+package input
+
+import (
+	"context"
+
+	"marketdata/internal/application/dto"
+)
+
+// IndicatorUseCase exposes the volatility and market quality indicators
+type IndicatorUseCase interface {
+	// ListIndicators returns the indicators of every streamed symbol,
+	// optionally filtered by exchange and symbol
+	ListIndicators(ctx context.Context, exchangeID, symbol string) []*dto.IndicatorsDTO
+}
@@ -2,20 +2,49 @@ package input
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"marketdata/internal/application/dto"
 )
 
+// ErrInvalidQuery wraps errors caused by bad query parameters, such as a
+// malformed cursor, so adapters can report them as client errors
+var ErrInvalidQuery = errors.New("invalid query")
+
 type MarketDataUseCase interface {
 	// GetOrderBook retrieves the current orderbook for a given exchange and symbol
 	GetOrderBook(ctx context.Context, exchangeID, symbol string) (*dto.OrderBookDTO, error)
 
+	// GetAllOrderBooks retrieves the current orderbook of every tracked exchange and symbol
+	GetAllOrderBooks(ctx context.Context) ([]*dto.OrderBookDTO, error)
+
+	// GetOrderBookAt retrieves the archived orderbook as it was at the given time
+	GetOrderBookAt(ctx context.Context, exchangeID, symbol string, at time.Time) (*dto.OrderBookDTO, error)
+
 	// SubscribeOrderBook subscribes to orderbook updates for a given exchange and symbol
 	SubscribeOrderBook(ctx context.Context, exchangeID, symbol string) (<-chan *dto.OrderBookDTO, error)
 
-	// GetTrades retrieves recent trades for a given exchange and symbol
-	GetTrades(ctx context.Context, exchangeID, symbol string, limit int) ([]*dto.TradeDTO, error)
+	// SubscribeTrades streams trades as they are processed, for one exchange or
+	// for every exchange when exchangeID is empty. The channel is closed when
+	// ctx is done or when the subscriber falls too far behind.
+	SubscribeTrades(ctx context.Context, exchangeID, symbol string) (<-chan *dto.TradeDTO, error)
+
+	// GetTrades retrieves one page of trades matching the query
+	GetTrades(ctx context.Context, query dto.TradeQueryDTO) (*dto.TradePageDTO, error)
+
+	// GetCandles retrieves OHLCV candles aggregated from trades
+	GetCandles(ctx context.Context, query dto.CandleQueryDTO) ([]*dto.CandleDTO, error)
+
+	// GetInstruments lists the exchange/symbol pairs with a tracked orderbook
+	GetInstruments(ctx context.Context) ([]*dto.InstrumentDTO, error)
+
+	// GetArbitrageOpportunities detects cross-exchange opportunities, optionally for one symbol
+	GetArbitrageOpportunities(ctx context.Context, symbol string) ([]*dto.ArbitrageOpportunityDTO, error)
 
 	// ProcessOrderBookUpdate processes an orderbook update from an exchange
 	ProcessOrderBookUpdate(ctx context.Context, update *dto.OrderBookDTO) error
+
+	// ProcessTrade stores and publishes a trade streamed from an exchange
+	ProcessTrade(ctx context.Context, trade *dto.TradeDTO) error
 }
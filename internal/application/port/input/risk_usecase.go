@@ -0,0 +1,29 @@
+package input
+
+import (
+	"context"
+
+	"marketdata/internal/application/dto"
+)
+
+// RiskUseCase checks trades against the loss limits and controls the kill
+// switch that halts execution
+type RiskUseCase interface {
+	// GetRiskStatus returns the PnL, limits and halt of the account
+	GetRiskStatus(ctx context.Context) *dto.RiskStatusDTO
+
+	// CheckTrade approves a trade or rejects it with the reason. Malformed
+	// checks fail with ErrInvalidQuery.
+	CheckTrade(ctx context.Context, check *dto.TradeCheckDTO) (*dto.TradeCheckResultDTO, error)
+
+	// RecordFill applies an executed order to its position and the PnL.
+	// Malformed fills fail with ErrInvalidQuery.
+	RecordFill(ctx context.Context, fill *dto.FillDTO) (*dto.FillResultDTO, error)
+
+	// Halt engages the kill switch until Resume
+	Halt(ctx context.Context, reason string) *dto.RiskStatusDTO
+
+	// Resume releases the kill switch. The drawdown restarts from the current
+	// equity; a daily loss still beyond its limit halts again at once.
+	Resume(ctx context.Context) *dto.RiskStatusDTO
+}
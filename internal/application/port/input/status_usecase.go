@@ -0,0 +1,15 @@
+package input
+
+import (
+	"context"
+
+	"marketdata/internal/application/dto"
+)
+
+type StatusUseCase interface {
+	// Readiness checks every dependency and whether any exchange is connected
+	Readiness(ctx context.Context) *dto.ReadinessDTO
+
+	// Status reports exchange connections, per-symbol feed health and dependencies
+	Status(ctx context.Context) *dto.StatusDTO
+}
@@ -0,0 +1,36 @@
+package input
+
+import (
+	"context"
+	"errors"
+
+	"marketdata/internal/application/dto"
+)
+
+var (
+	// ErrUnknownExchange reports an exchange the service has no adapter for
+	ErrUnknownExchange = errors.New("unknown exchange")
+	// ErrNotSubscribed reports removing a subscription that is not active
+	ErrNotSubscribed = errors.New("not subscribed")
+)
+
+// SubscriptionUseCase changes which orderbooks are streamed while the service
+// runs. Actor names who made a change in the audit log.
+type SubscriptionUseCase interface {
+	// ListSubscriptions lists the active subscriptions by exchange and symbol
+	ListSubscriptions(ctx context.Context) []*dto.SubscriptionDTO
+
+	// Subscribe starts streaming a symbol, or applies new settings to an
+	// active subscription; it is a no-op when nothing changes
+	Subscribe(ctx context.Context, spec dto.SubscriptionSpecDTO, actor string) (*dto.SubscriptionDTO, error)
+
+	// Unsubscribe stops streaming a symbol
+	Unsubscribe(ctx context.Context, exchangeID, symbol, actor string) error
+
+	// Reconcile moves from the previous to the next subscription plan,
+	// leaving subscriptions neither mentions untouched
+	Reconcile(ctx context.Context, previous, next []dto.SubscriptionSpecDTO, actor string) error
+
+	// GetAuditLog returns up to limit subscription changes, newest first
+	GetAuditLog(ctx context.Context, limit int) ([]*dto.SubscriptionChangeDTO, error)
+}
@@ -0,0 +1,17 @@
+package output
+
+import "context"
+
+// AccountPort reads the balances of the account and the trading rules of an
+// exchange
+type AccountPort interface {
+	// GetBalances returns the free balance of every asset the account holds
+	GetBalances(ctx context.Context) (map[string]float64, error)
+
+	// GetLotSizes returns the quantity step of every symbol, keyed by the
+	// exchange's symbol
+	GetLotSizes(ctx context.Context) (map[string]float64, error)
+
+	// GetName returns the exchange name
+	GetName() string
+}
@@ -0,0 +1,26 @@
+package output
+
+import (
+	"context"
+
+	"marketdata/internal/application/dto"
+)
+
+// ArbitragePublisherPort publishes opportunity lifecycle events to
+// downstream consumers such as the trading engine
+type ArbitragePublisherPort interface {
+	PublishArbitrageEvent(ctx context.Context, event *dto.ArbitrageEventDTO) error
+}
+
+// StatArbSignalPublisherPort publishes statistical arbitrage signals
+type StatArbSignalPublisherPort interface {
+	PublishStatArbSignal(ctx context.Context, signal *dto.StatArbSignalDTO) error
+}
+
+// StatArbStatePort keeps the rolling windows and positions of statistical
+// arbitrage pairs across restarts
+type StatArbStatePort interface {
+	// LoadStatArbState returns the saved pairs, or none if nothing was saved
+	LoadStatArbState(ctx context.Context) ([]*dto.StatArbStateDTO, error)
+	SaveStatArbState(ctx context.Context, pairs []*dto.StatArbStateDTO) error
+}
@@ -0,0 +1,16 @@
+package output
+
+import (
+	"context"
+
+	"marketdata/internal/domain/entity"
+)
+
+// SubscriptionAuditPort keeps the history of runtime subscription changes
+type SubscriptionAuditPort interface {
+	// Record appends a change to the log
+	Record(ctx context.Context, change *entity.SubscriptionChange) error
+
+	// Recent returns up to limit changes, newest first
+	Recent(ctx context.Context, limit int) ([]*entity.SubscriptionChange, error)
+}
@@ -2,6 +2,7 @@ package output
 
 import (
 	"context"
+	"time"
 
 	"marketdata/internal/domain/entity"
 )
@@ -23,3 +24,39 @@ type ExchangePort interface {
 	// GetName returns the exchange name
 	GetName() string
 }
+
+// OrderBookStreamOptions tunes an orderbook subscription; zero values use the
+// exchange defaults
+type OrderBookStreamOptions struct {
+	// Depth is the number of levels per side
+	Depth int
+
+	// UpdateSpeed is the interval the exchange pushes updates at
+	UpdateSpeed time.Duration
+}
+
+// OrderBookStreamPort is implemented by exchanges whose orderbook streams take
+// options
+type OrderBookStreamPort interface {
+	// SubscribeOrderBookWithOptions subscribes to orderbook updates for a symbol
+	SubscribeOrderBookWithOptions(ctx context.Context, symbol string, opts OrderBookStreamOptions) (<-chan *entity.OrderBook, error)
+}
+
+// TradeStreamPort is implemented by exchanges that stream executed trades
+type TradeStreamPort interface {
+	// SubscribeTrades subscribes to the trades of a symbol
+	SubscribeTrades(ctx context.Context, symbol string) (<-chan *entity.Trade, error)
+}
+
+// FrameRecordingPort selects the symbols whose raw exchange frames are recorded
+type FrameRecordingPort interface {
+	// SetRecording starts or stops recording a symbol of an exchange
+	SetRecording(exchangeID, symbol string, enabled bool)
+}
+
+// SequenceGapPort is told when an exchange adapter sees that a symbol's book
+// updates skipped update IDs, so the local book missed changes
+type SequenceGapPort interface {
+	// RecordSequenceGap notes one gap in the symbol's update stream
+	RecordSequenceGap(exchangeID, symbol string)
+}
@@ -0,0 +1,17 @@
+package output
+
+import (
+	"context"
+
+	"marketdata/internal/domain/entity"
+)
+
+// FeeRatesPort reads the fee rates of the account from an exchange
+type FeeRatesPort interface {
+	// GetTradeFees returns the rates of every symbol the account trades,
+	// keyed by the exchange's symbol, before any fee asset discount
+	GetTradeFees(ctx context.Context) (map[string]entity.FeeRates, error)
+
+	// GetName returns the exchange name
+	GetName() string
+}
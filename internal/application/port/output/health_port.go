@@ -0,0 +1,21 @@
+package output
+
+import (
+	"context"
+)
+
+// HealthCheckPort is implemented by adapters whose backing service must be
+// reachable for the market data service to be ready
+type HealthCheckPort interface {
+	// Name identifies the dependency in readiness and status reports
+	Name() string
+
+	// Check returns an error when the dependency is unreachable
+	Check(ctx context.Context) error
+}
+
+// ExchangeStatusPort reports the connection state of an exchange adapter
+type ExchangeStatusPort interface {
+	GetName() string
+	IsConnected() bool
+}
@@ -2,6 +2,7 @@ package output
 
 import (
 	"context"
+	"time"
 
 	"marketdata/internal/domain/entity"
 )
@@ -15,9 +16,69 @@ type OrderBookRepositoryPort interface {
 type TradeRepositoryPort interface {
 	StoreTrade(ctx context.Context, trade *entity.Trade) error
 	GetTradesBySymbol(ctx context.Context, symbol string, limit int) ([]*entity.Trade, error)
+	QueryTrades(ctx context.Context, filter TradeFilter) ([]*entity.Trade, error)
+	GetCandles(ctx context.Context, filter CandleFilter) ([]*entity.Candle, error)
+}
+
+// OrderBookSnapshotRepositoryPort archives sampled orderbooks for historical queries
+type OrderBookSnapshotRepositoryPort interface {
+	// StoreSnapshot stores the top depth levels of each side of the book
+	StoreSnapshot(ctx context.Context, orderbook *entity.OrderBook, depth int) error
+	// GetSnapshotAt returns the latest snapshot taken at or before at, or nil if none
+	GetSnapshotAt(ctx context.Context, exchangeID, symbol string, at time.Time) (*entity.OrderBook, error)
+}
+
+// AnalyticsRepositoryPort reads pre-aggregated trade statistics
+type AnalyticsRepositoryPort interface {
+	GetTradeStats(ctx context.Context, filter TradeStatsFilter) ([]*entity.TradeStats, error)
 }
 
 type EventPublisherPort interface {
 	PublishOrderBookUpdate(ctx context.Context, orderbook *entity.OrderBook) error
 	PublishTrade(ctx context.Context, trade *entity.Trade) error
 }
+
+// TradeFilter narrows a trade query. Zero values mean "no constraint",
+// except Limit which must be positive. Results are ordered by
+// (timestamp, exchange_id, id), descending unless Ascending is set, and start
+// strictly after After.
+type TradeFilter struct {
+	ExchangeID string
+	Symbol     string
+	From       time.Time
+	To         time.Time
+	Side       entity.TradeType
+	After      *TradeKey
+	Limit      int
+	Ascending  bool
+}
+
+// TradeKey is the position of a trade in the (timestamp, exchange_id, id)
+// ordering. Trade ids are only unique per exchange, so the exchange breaks ties
+// between trades sharing a timestamp.
+type TradeKey struct {
+	Timestamp  time.Time
+	ExchangeID string
+	ID         string
+}
+
+// CandleFilter selects OHLCV buckets aggregated from trades
+type CandleFilter struct {
+	ExchangeID string
+	Symbol     string
+	Interval   time.Duration
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
+
+// TradeStatsFilter selects trade statistics buckets, oldest first. Interval
+// must be one of the aggregated widths: one minute, one hour or one day.
+type TradeStatsFilter struct {
+	ExchangeID string
+	Symbol     string
+	Interval   time.Duration
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
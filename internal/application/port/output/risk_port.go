@@ -0,0 +1,15 @@
+package output
+
+import (
+	"context"
+
+	"marketdata/internal/application/dto"
+)
+
+// RiskStatePort keeps the PnL, positions and halt of the risk manager across
+// restarts
+type RiskStatePort interface {
+	// LoadRiskState returns the saved state, or nil if nothing was saved
+	LoadRiskState(ctx context.Context) (*dto.RiskStateDTO, error)
+	SaveRiskState(ctx context.Context, state *dto.RiskStateDTO) error
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"marketdata/internal/application/port/output"
+)
+
+// AccountConfig is the configured balances and lot sizes of one exchange
+type AccountConfig struct {
+	ExchangeID string
+	Balances   map[string]float64
+	LotSizes   map[string]float64
+}
+
+type exchangeAccount struct {
+	balances map[string]float64
+	lotSizes map[string]float64
+}
+
+// AccountService keeps the free balances of the account and the lot sizes of
+// the symbols on every exchange. They start from the configuration and, when
+// the exchange has a source, are replaced by what it reports on every
+// refresh; configured lot sizes win over fetched ones.
+type AccountService struct {
+	defaultLotSize float64
+	configs        map[string]AccountConfig
+	sources        []output.AccountPort
+	logger         Logger
+
+	mu       sync.RWMutex
+	accounts map[string]exchangeAccount
+}
+
+func NewAccountService(
+	defaultLotSize float64,
+	configs []AccountConfig,
+	sources []output.AccountPort,
+	logger Logger,
+) *AccountService {
+	s := &AccountService{
+		defaultLotSize: defaultLotSize,
+		configs:        make(map[string]AccountConfig, len(configs)),
+		sources:        sources,
+		logger:         logger,
+		accounts:       make(map[string]exchangeAccount, len(configs)),
+	}
+	for _, cfg := range configs {
+		s.configs[cfg.ExchangeID] = cfg
+		s.accounts[cfg.ExchangeID] = s.build(cfg.ExchangeID, nil, nil)
+	}
+	return s
+}
+
+// Balance returns the free balance of an asset on an exchange; it is false
+// when the balances of the exchange are unknown
+func (s *AccountService) Balance(exchangeID, asset string) (float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	account, ok := s.accounts[exchangeID]
+	if !ok || account.balances == nil {
+		return 0, false
+	}
+	return account.balances[strings.ToUpper(asset)], true
+}
+
+// LotSize returns the quantity step of a symbol on an exchange, or the
+// default lot size
+func (s *AccountService) LotSize(exchangeID, symbol string) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if lotSize, ok := s.accounts[exchangeID].lotSizes[accountSymbolKey(symbol)]; ok {
+		return lotSize
+	}
+	return s.defaultLotSize
+}
+
+// Run refreshes the accounts from their sources now and every interval until
+// ctx is done. Failures are logged and the previous values kept.
+func (s *AccountService) Run(ctx context.Context, interval time.Duration) {
+	if len(s.sources) == 0 {
+		return
+	}
+	if err := s.Refresh(ctx); err != nil {
+		s.logger.Error("failed to refresh accounts", "error", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				s.logger.Error("failed to refresh accounts", "error", err)
+			}
+		}
+	}
+}
+
+// Refresh fetches the balances and lot sizes from every source. An exchange
+// whose source fails keeps its previous values.
+func (s *AccountService) Refresh(ctx context.Context) error {
+	var errs []error
+	for _, source := range s.sources {
+		exchangeID := source.GetName()
+		balances, err := source.GetBalances(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to fetch %s balances: %w", exchangeID, err))
+			continue
+		}
+		lotSizes, err := source.GetLotSizes(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to fetch %s lot sizes: %w", exchangeID, err))
+			continue
+		}
+
+		s.mu.Lock()
+		s.accounts[exchangeID] = s.build(exchangeID, balances, lotSizes)
+		s.mu.Unlock()
+
+		s.logger.Info("refreshed account", "exchange", exchangeID, "assets", len(balances), "symbols", len(lotSizes))
+	}
+	return errors.Join(errs...)
+}
+
+// build makes the account of an exchange from fetched values, when given,
+// over its configuration
+func (s *AccountService) build(exchangeID string, balances, lotSizes map[string]float64) exchangeAccount {
+	cfg := s.configs[exchangeID]
+	account := exchangeAccount{lotSizes: make(map[string]float64, len(lotSizes)+len(cfg.LotSizes))}
+
+	if balances == nil {
+		balances = cfg.Balances
+	}
+	if balances != nil {
+		account.balances = make(map[string]float64, len(balances))
+		for asset, balance := range balances {
+			account.balances[strings.ToUpper(asset)] = balance
+		}
+	}
+	for symbol, lotSize := range lotSizes {
+		account.lotSizes[accountSymbolKey(symbol)] = lotSize
+	}
+	for symbol, lotSize := range cfg.LotSizes {
+		account.lotSizes[accountSymbolKey(symbol)] = lotSize
+	}
+	return account
+}
+
+// accountSymbolKey matches symbols without their separator and case, so
+// BTC-USDT finds Binance's BTCUSDT
+func accountSymbolKey(symbol string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", "/", "", "_", "").Replace(symbol))
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+)
+
+// analyticsIntervals are the bucket widths kept as continuous aggregates
+var analyticsIntervals = map[time.Duration]bool{
+	time.Minute:    true,
+	time.Hour:      true,
+	24 * time.Hour: true,
+}
+
+type AnalyticsService struct {
+	analyticsRepo output.AnalyticsRepositoryPort
+}
+
+func NewAnalyticsService(analyticsRepo output.AnalyticsRepositoryPort) *AnalyticsService {
+	return &AnalyticsService{
+		analyticsRepo: analyticsRepo,
+	}
+}
+
+// GetTradeStats retrieves per-bucket trade statistics, oldest first
+func (s *AnalyticsService) GetTradeStats(ctx context.Context, query dto.TradeStatsQueryDTO) ([]*dto.TradeStatsDTO, error) {
+	if !analyticsIntervals[query.Interval] {
+		return nil, fmt.Errorf("%w: interval must be 1m, 1h or 1d", input.ErrInvalidQuery)
+	}
+
+	stats, err := s.analyticsRepo.GetTradeStats(ctx, output.TradeStatsFilter{
+		ExchangeID: query.ExchangeID,
+		Symbol:     query.Symbol,
+		Interval:   query.Interval,
+		From:       query.From,
+		To:         query.To,
+		Limit:      clampLimit(query.Limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade stats: %w", err)
+	}
+
+	statsDTOs := make([]*dto.TradeStatsDTO, len(stats))
+	for i, bucket := range stats {
+		statsDTOs[i] = convertToTradeStatsDTO(bucket)
+	}
+
+	return statsDTOs, nil
+}
+
+func convertToTradeStatsDTO(stats *entity.TradeStats) *dto.TradeStatsDTO {
+	statsDTO := &dto.TradeStatsDTO{
+		ExchangeID:         stats.ExchangeID(),
+		Symbol:             stats.Symbol(),
+		Interval:           formatInterval(stats.Interval()),
+		Bucket:             stats.Bucket(),
+		Open:               stats.Open(),
+		High:               stats.High(),
+		Low:                stats.Low(),
+		Close:              stats.Close(),
+		Volume:             stats.Volume(),
+		QuoteVolume:        stats.QuoteVolume(),
+		BuyVolume:          stats.BuyVolume(),
+		SellVolume:         stats.SellVolume(),
+		TradeCount:         stats.TradeCount(),
+		BuyCount:           stats.BuyCount(),
+		SellCount:          stats.SellCount(),
+		VWAP:               stats.VWAP(),
+		RealizedVolatility: stats.RealizedVolatility(),
+	}
+	if ratio, ok := stats.BuySellRatio(); ok {
+		statsDTO.BuySellRatio = &ratio
+	}
+	return statsDTO
+}
+
+// Ensure AnalyticsService implements AnalyticsUseCase interface
+var _ input.AnalyticsUseCase = (*AnalyticsService)(nil)
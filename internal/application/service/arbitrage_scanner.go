@@ -0,0 +1,498 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+	domainservice "marketdata/internal/domain/service"
+)
+
+const (
+	arbitrageSweepInterval  = 100 * time.Millisecond
+	arbitragePublishTimeout = 5 * time.Second
+	arbitragePublishQueue   = 1024
+	arbitrageWatchBuffer    = 256
+
+	// volumeChangeRatio is the relative change in executable volume that
+	// makes an open opportunity worth an update
+	volumeChangeRatio = 0.1
+)
+
+var _ input.ArbitrageScannerUseCase = (*ArbitrageScanner)(nil)
+
+// OpportunitySizer sizes an arbitrage opportunity
+type OpportunitySizer interface {
+	SizeArbitrage(o *domainservice.ArbitrageOpportunity) (domainservice.PositionSize, error)
+}
+
+// ArbitrageScannerConfig tunes the arbitrage scanner
+type ArbitrageScannerConfig struct {
+	// MinProfitPct is the spread, in percent, that must remain after fees
+	MinProfitPct float64
+	// OpenAfter is how long an opportunity must persist before it opens
+	OpenAfter time.Duration
+	// CloseAfter is how long an open opportunity may be gone before it
+	// closes, so a flickering book does not close and reopen it
+	CloseAfter time.Duration
+	// UpdateInterval is the least time between updates of one opportunity
+	UpdateInterval time.Duration
+	// MinChangePct is the change of net spread, in percent, worth an update
+	MinChangePct float64
+	// MaxBookAge drops books without an update for longer; zero keeps them
+	MaxBookAge time.Duration
+}
+
+type arbitrageQuote struct {
+	book     *entity.OrderBook
+	received time.Time
+}
+
+type opportunityKey struct {
+	symbol       string
+	buyExchange  string
+	sellExchange string
+}
+
+type trackedOpportunity struct {
+	id        string
+	latest    *domainservice.ArbitrageOpportunity
+	emitted   *domainservice.ArbitrageOpportunity
+	peak      float64
+	firstSeen time.Time
+	openedAt  time.Time
+	updatedAt time.Time
+	// lostAt is when an open opportunity stopped clearing its costs; zero
+	// while it still does
+	lostAt time.Time
+	open   bool
+}
+
+type opportunityWatcher struct {
+	symbol string
+	events chan *dto.ArbitrageEventDTO
+}
+
+// ArbitrageScanner evaluates every pair of exchanges quoting a symbol on each
+// of its book updates, walking the depth of both books, and follows the
+// opportunities that clear the taker fees and the minimum profit. One opens
+// once it has persisted for OpenAfter, is updated when its net spread or
+// volume moves, and closes once it has been gone for CloseAfter. Events go to
+// the publisher, when given, and to watchers. While the gate, when given, is
+// halted no opportunity opens and open ones close after CloseAfter. The
+// sizer, when given, sizes every opportunity reported.
+type ArbitrageScanner struct {
+	cfg          ArbitrageScannerConfig
+	orderbookSvc domainservice.OrderBookDomainService
+	fees         FeeProvider
+	gate         ExecutionGate
+	sizer        OpportunitySizer
+	publisher    output.ArbitragePublisherPort
+	logger       Logger
+	now          func() time.Time
+
+	mu         sync.Mutex
+	books      map[string]map[string]arbitrageQuote
+	tracked    map[opportunityKey]*trackedOpportunity
+	watchers   map[*opportunityWatcher]struct{}
+	queue      chan *dto.ArbitrageEventDTO
+	stop       context.CancelFunc
+	sweeping   chan struct{}
+	publishing chan struct{}
+	stopped    bool
+}
+
+func NewArbitrageScanner(
+	cfg ArbitrageScannerConfig,
+	orderbookSvc domainservice.OrderBookDomainService,
+	fees FeeProvider,
+	gate ExecutionGate,
+	sizer OpportunitySizer,
+	publisher output.ArbitragePublisherPort,
+	logger Logger,
+) *ArbitrageScanner {
+	return &ArbitrageScanner{
+		cfg:          cfg,
+		orderbookSvc: orderbookSvc,
+		fees:         fees,
+		gate:         gate,
+		sizer:        sizer,
+		publisher:    publisher,
+		logger:       logger,
+		now:          time.Now,
+		books:        make(map[string]map[string]arbitrageQuote),
+		tracked:      make(map[opportunityKey]*trackedOpportunity),
+		watchers:     make(map[*opportunityWatcher]struct{}),
+	}
+}
+
+// Start re-evaluates tracked opportunities in the background, so they open
+// and close on time when books go quiet, and publishes events until Stop
+func (s *ArbitrageScanner) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stop != nil || s.stopped {
+		return
+	}
+	sweepCtx, stop := context.WithCancel(ctx)
+	s.stop = stop
+	s.queue = make(chan *dto.ArbitrageEventDTO, arbitragePublishQueue)
+	s.sweeping = make(chan struct{})
+	s.publishing = make(chan struct{})
+
+	go s.sweep(sweepCtx)
+	// Publishing outlives ctx so the final closed events are delivered
+	go s.publish(context.WithoutCancel(ctx), s.queue)
+}
+
+// Stop closes every open opportunity, ends the watchers and waits for the
+// pending events to be published
+func (s *ArbitrageScanner) Stop() {
+	s.mu.Lock()
+	stop, sweeping, publishing := s.stop, s.sweeping, s.publishing
+	s.stop = nil
+	s.mu.Unlock()
+
+	if stop != nil {
+		stop()
+		<-sweeping
+	}
+
+	s.mu.Lock()
+	now := s.now()
+	for key, t := range s.tracked {
+		if t.open {
+			s.close(key, t, now)
+		}
+		delete(s.tracked, key)
+	}
+	for w := range s.watchers {
+		delete(s.watchers, w)
+		close(w.events)
+	}
+	if s.queue != nil {
+		close(s.queue)
+		s.queue = nil
+	}
+	s.stopped = true
+	s.mu.Unlock()
+
+	if publishing != nil {
+		<-publishing
+	}
+}
+
+// ProcessOrderBookUpdate takes the latest book of an exchange and
+// re-evaluates its symbol against every other exchange quoting it
+func (s *ArbitrageScanner) ProcessOrderBookUpdate(ctx context.Context, update *dto.OrderBookDTO) error {
+	book := convertToOrderBookEntity(update)
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	quotes, ok := s.books[update.Symbol]
+	if !ok {
+		quotes = make(map[string]arbitrageQuote)
+		s.books[update.Symbol] = quotes
+	}
+	quotes[update.ExchangeID] = arbitrageQuote{book: book, received: now}
+	s.evaluate(update.Symbol, now)
+	return nil
+}
+
+// ListOpportunities returns the open opportunities, optionally for one
+// symbol, best net spread first
+func (s *ArbitrageScanner) ListOpportunities(ctx context.Context, symbol string) []*dto.TrackedOpportunityDTO {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	opportunities := make([]*dto.TrackedOpportunityDTO, 0, len(s.tracked))
+	for key, t := range s.tracked {
+		if t.open && (symbol == "" || key.symbol == symbol) {
+			opportunities = append(opportunities, s.toDTO(t, now, nil))
+		}
+	}
+	sort.Slice(opportunities, func(i, j int) bool {
+		return opportunities[i].NetSpreadPct > opportunities[j].NetSpreadPct
+	})
+	return opportunities
+}
+
+// WatchOpportunities streams lifecycle events, optionally for one symbol,
+// until ctx is done. A watcher that falls behind is dropped by closing its
+// channel, so it never misses an event silently.
+func (s *ArbitrageScanner) WatchOpportunities(ctx context.Context, symbol string) <-chan *dto.ArbitrageEventDTO {
+	w := &opportunityWatcher{
+		symbol: symbol,
+		events: make(chan *dto.ArbitrageEventDTO, arbitrageWatchBuffer),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		close(w.events)
+		return w.events
+	}
+	s.watchers[w] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.unwatch(w)
+	}()
+	return w.events
+}
+
+func (s *ArbitrageScanner) sweep(ctx context.Context) {
+	defer close(s.sweeping)
+
+	ticker := time.NewTicker(arbitrageSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			now := s.now()
+			symbols := make(map[string]struct{}, len(s.books))
+			for symbol := range s.books {
+				symbols[symbol] = struct{}{}
+			}
+			for key := range s.tracked {
+				symbols[key.symbol] = struct{}{}
+			}
+			for symbol := range symbols {
+				s.evaluate(symbol, now)
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *ArbitrageScanner) publish(ctx context.Context, queue <-chan *dto.ArbitrageEventDTO) {
+	defer close(s.publishing)
+
+	for event := range queue {
+		if s.publisher == nil {
+			continue
+		}
+		publishCtx, cancel := context.WithTimeout(ctx, arbitragePublishTimeout)
+		err := s.publisher.PublishArbitrageEvent(publishCtx, event)
+		cancel()
+		if err != nil {
+			s.logger.Error("failed to publish arbitrage event",
+				"error", err,
+				"type", event.Type,
+				"opportunity", event.Opportunity.ID,
+			)
+		}
+	}
+}
+
+// evaluate checks every pair of fresh books of a symbol and advances the
+// lifecycle of its opportunities. It is called with mu held.
+func (s *ArbitrageScanner) evaluate(symbol string, now time.Time) {
+	quotes := s.books[symbol]
+	fresh := make([]*entity.OrderBook, 0, len(quotes))
+	for exchangeID, quote := range quotes {
+		if s.cfg.MaxBookAge > 0 && now.Sub(quote.received) > s.cfg.MaxBookAge {
+			delete(quotes, exchangeID)
+			continue
+		}
+		fresh = append(fresh, quote.book)
+	}
+	if len(quotes) == 0 {
+		delete(s.books, symbol)
+	}
+
+	found := make(map[opportunityKey]bool)
+	// A halted gate finds nothing, so open opportunities close
+	if s.gate != nil && s.gate.Halted() {
+		fresh = nil
+	}
+	for _, buy := range fresh {
+		for _, sell := range fresh {
+			if buy.ExchangeID() == sell.ExchangeID() {
+				continue
+			}
+			opportunity, ok := s.orderbookSvc.EvaluateArbitrage(buy, sell, domainservice.ArbitrageCosts{
+				BuyFee:       s.fees.TakerFee(buy.ExchangeID(), symbol),
+				SellFee:      s.fees.TakerFee(sell.ExchangeID(), symbol),
+				MinProfitPct: s.cfg.MinProfitPct,
+			})
+			if !ok {
+				continue
+			}
+			key := opportunityKey{symbol: symbol, buyExchange: buy.ExchangeID(), sellExchange: sell.ExchangeID()}
+			found[key] = true
+			s.observe(key, opportunity, now)
+		}
+	}
+
+	for key, t := range s.tracked {
+		if key.symbol == symbol && !found[key] {
+			s.lose(key, t, now)
+		}
+	}
+}
+
+// observe records that an opportunity clears its costs
+func (s *ArbitrageScanner) observe(key opportunityKey, opportunity *domainservice.ArbitrageOpportunity, now time.Time) {
+	t, ok := s.tracked[key]
+	if !ok {
+		t = &trackedOpportunity{
+			id:        fmt.Sprintf("%s:%s:%s:%d", key.symbol, key.buyExchange, key.sellExchange, now.UnixMilli()),
+			peak:      opportunity.NetSpreadPct,
+			firstSeen: now,
+		}
+		s.tracked[key] = t
+	}
+	t.latest = opportunity
+	t.lostAt = time.Time{}
+	t.peak = math.Max(t.peak, opportunity.NetSpreadPct)
+
+	switch {
+	case !t.open:
+		if now.Sub(t.firstSeen) >= s.cfg.OpenAfter {
+			t.open, t.openedAt = true, now
+			s.emit(dto.ArbitrageOpened, t, now, nil)
+		}
+	case s.changed(t) && now.Sub(t.updatedAt) >= s.cfg.UpdateInterval:
+		s.emit(dto.ArbitrageUpdated, t, now, nil)
+	}
+}
+
+// lose records that an opportunity no longer clears its costs. One that
+// never opened is forgotten at once; an open one closes after CloseAfter.
+func (s *ArbitrageScanner) lose(key opportunityKey, t *trackedOpportunity, now time.Time) {
+	if !t.open {
+		delete(s.tracked, key)
+		return
+	}
+	if t.lostAt.IsZero() {
+		t.lostAt = now
+	}
+	if now.Sub(t.lostAt) >= s.cfg.CloseAfter {
+		s.close(key, t, t.lostAt)
+	}
+}
+
+func (s *ArbitrageScanner) close(key opportunityKey, t *trackedOpportunity, at time.Time) {
+	delete(s.tracked, key)
+	s.emit(dto.ArbitrageClosed, t, s.now(), &at)
+}
+
+func (s *ArbitrageScanner) changed(t *trackedOpportunity) bool {
+	latest, emitted := t.latest, t.emitted
+	return math.Abs(latest.NetSpreadPct-emitted.NetSpreadPct) >= s.cfg.MinChangePct ||
+		math.Abs(latest.MaxVolume-emitted.MaxVolume) >= volumeChangeRatio*emitted.MaxVolume
+}
+
+// emit sends an event to the watchers of its symbol and queues it for
+// publishing. It is called with mu held.
+func (s *ArbitrageScanner) emit(kind string, t *trackedOpportunity, now time.Time, closedAt *time.Time) {
+	t.emitted, t.updatedAt = t.latest, now
+	event := &dto.ArbitrageEventDTO{
+		Type:        kind,
+		Opportunity: s.toDTO(t, now, closedAt),
+		Time:        now,
+	}
+
+	for w := range s.watchers {
+		if w.symbol != "" && w.symbol != event.Opportunity.Symbol {
+			continue
+		}
+		select {
+		case w.events <- event:
+		default:
+			s.unwatch(w)
+		}
+	}
+
+	if s.queue == nil || s.publisher == nil {
+		return
+	}
+	select {
+	case s.queue <- event:
+	default:
+		s.logger.Error("arbitrage publish queue is full, dropping event",
+			"type", event.Type,
+			"opportunity", event.Opportunity.ID,
+		)
+	}
+}
+
+// toDTO reports the latest state of a tracked opportunity, sized when the
+// scanner has a sizer. It is called with mu held.
+func (s *ArbitrageScanner) toDTO(t *trackedOpportunity, now time.Time, closedAt *time.Time) *dto.TrackedOpportunityDTO {
+	opportunity := t.toDTO(t.latest, now, closedAt)
+	if s.sizer == nil {
+		return opportunity
+	}
+	size, err := s.sizer.SizeArbitrage(t.latest)
+	if err != nil {
+		s.logger.Error("failed to size arbitrage opportunity", "error", err, "opportunity", t.id)
+		return opportunity
+	}
+
+	opportunity.Size = &dto.PositionSizeDTO{
+		Quantity: size.Quantity,
+		Notional: size.Notional,
+		Binding:  size.Binding,
+		Limits:   make([]dto.SizeLimitDTO, len(size.Limits)),
+	}
+	for i, limit := range size.Limits {
+		opportunity.Size.Limits[i] = dto.SizeLimitDTO{Name: limit.Name, Notional: limit.Notional}
+	}
+	return opportunity
+}
+
+func (s *ArbitrageScanner) unwatch(w *opportunityWatcher) {
+	if _, ok := s.watchers[w]; ok {
+		delete(s.watchers, w)
+		close(w.events)
+	}
+}
+
+func (t *trackedOpportunity) toDTO(o *domainservice.ArbitrageOpportunity, now time.Time, closedAt *time.Time) *dto.TrackedOpportunityDTO {
+	end := now
+	if closedAt != nil {
+		end = *closedAt
+	}
+
+	return &dto.TrackedOpportunityDTO{
+		ID: t.id,
+		ArbitrageOpportunityDTO: dto.ArbitrageOpportunityDTO{
+			Symbol:       o.Symbol,
+			BuyExchange:  o.BuyExchange,
+			SellExchange: o.SellExchange,
+			SpreadPct:    o.SpreadPct,
+			FeesPct:      o.FeesPct,
+			NetSpreadPct: o.NetSpreadPct,
+			MaxVolume:    o.MaxVolume,
+		},
+		BuyPrice:         o.BuyPrice,
+		SellPrice:        o.SellPrice,
+		SlippagePct:      o.SlippagePct,
+		PeakNetSpreadPct: t.peak,
+		ExpectedProfit:   o.ExpectedProfit,
+		OpenedAt:         t.openedAt,
+		UpdatedAt:        t.updatedAt,
+		ClosedAt:         closedAt,
+		DurationMs:       end.Sub(t.openedAt).Milliseconds(),
+	}
+}
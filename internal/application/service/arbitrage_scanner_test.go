@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"marketdata/internal/application/dto"
+	domainservice "marketdata/internal/domain/service"
+	"marketdata/pkg/logger"
+)
+
+type flatFees float64
+
+func (f flatFees) TakerFee(exchangeID, symbol string) float64 { return float64(f) }
+
+func arbitrageBook(exchangeID string, bids, asks []dto.PriceLevelDTO) *dto.OrderBookDTO {
+	return &dto.OrderBookDTO{ExchangeID: exchangeID, Symbol: "BTC-USDT", Bids: bids, Asks: asks}
+}
+
+func TestArbitrageScannerLifecycle(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	now := start
+
+	scanner := NewArbitrageScanner(ArbitrageScannerConfig{
+		MinProfitPct:   0.1,
+		OpenAfter:      2 * time.Second,
+		CloseAfter:     3 * time.Second,
+		UpdateInterval: time.Second,
+		MinChangePct:   0.05,
+	}, domainservice.NewOrderBookService(), flatFees(0), nil, nil, nil, logger.NewLogger())
+	scanner.now = func() time.Time { return now }
+	events := scanner.WatchOpportunities(ctx, "BTC-USDT")
+
+	buy := arbitrageBook("a", nil, []dto.PriceLevelDTO{{Price: 100, Quantity: 1}})
+	profitable := arbitrageBook("b", []dto.PriceLevelDTO{{Price: 101, Quantity: 1}}, nil)
+	flat := arbitrageBook("b", []dto.PriceLevelDTO{{Price: 100, Quantity: 1}}, nil)
+
+	// at advances the clock to start+offset and processes the sell book
+	at := func(offset time.Duration, sell *dto.OrderBookDTO) {
+		t.Helper()
+		now = start.Add(offset)
+		if err := scanner.ProcessOrderBookUpdate(ctx, sell); err != nil {
+			t.Fatalf("ProcessOrderBookUpdate: %v", err)
+		}
+	}
+	expect := func(types ...string) []*dto.ArbitrageEventDTO {
+		t.Helper()
+		var got []*dto.ArbitrageEventDTO
+		for len(events) > 0 {
+			got = append(got, <-events)
+		}
+		if len(got) != len(types) {
+			t.Fatalf("at %v: got %d events, want %v", now.Sub(start), len(got), types)
+		}
+		for i, event := range got {
+			if event.Type != types[i] {
+				t.Fatalf("at %v: event %d is %s, want %s", now.Sub(start), i, event.Type, types[i])
+			}
+		}
+		return got
+	}
+
+	if err := scanner.ProcessOrderBookUpdate(ctx, buy); err != nil {
+		t.Fatal(err)
+	}
+	at(0, profitable)
+	at(time.Second, profitable)
+	expect()
+	if open := scanner.ListOpportunities(ctx, ""); len(open) != 0 {
+		t.Fatalf("%d open before open_after", len(open))
+	}
+
+	at(2*time.Second, profitable)
+	opened := expect(dto.ArbitrageOpened)[0].Opportunity
+	if !opened.OpenedAt.Equal(start.Add(2*time.Second)) || opened.DurationMs != 0 {
+		t.Fatalf("opened at %v after %dms, want the open transition at +2s", opened.OpenedAt, opened.DurationMs)
+	}
+	if opened.NetSpreadPct != 1 || opened.MaxVolume != 1 {
+		t.Fatalf("opened %+v", opened.ArbitrageOpportunityDTO)
+	}
+
+	// A flicker shorter than close_after keeps it open
+	at(3*time.Second, flat)
+	at(4*time.Second, profitable)
+	expect()
+	if open := scanner.ListOpportunities(ctx, ""); len(open) != 1 || !open[0].OpenedAt.Equal(opened.OpenedAt) {
+		t.Fatalf("open after flicker = %+v", open)
+	}
+
+	at(5*time.Second, flat)
+	at(7*time.Second, flat)
+	expect()
+	at(8*time.Second, flat)
+	closed := expect(dto.ArbitrageClosed)[0].Opportunity
+	if closed.ClosedAt == nil || !closed.ClosedAt.Equal(start.Add(5*time.Second)) {
+		t.Fatalf("closed at %v, want when it was lost at +5s", closed.ClosedAt)
+	}
+	if closed.DurationMs != 3000 {
+		t.Fatalf("closed after %dms, want 3000", closed.DurationMs)
+	}
+	if open := scanner.ListOpportunities(ctx, ""); len(open) != 0 {
+		t.Fatalf("%d open after close", len(open))
+	}
+
+	// One lost before it opens starts over
+	at(9*time.Second, profitable)
+	at(10*time.Second, flat)
+	at(11*time.Second, profitable)
+	at(12*time.Second, profitable)
+	expect()
+	at(13*time.Second, profitable)
+	expect(dto.ArbitrageOpened)
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+)
+
+var _ input.FeeScheduleUseCase = (*FeeService)(nil)
+
+// FeeProvider gives the taker rate, as a fraction, an order on a symbol pays
+type FeeProvider interface {
+	TakerFee(exchangeID, symbol string) float64
+}
+
+// FeeScheduleConfig is the configured fee schedule of one exchange. Rates are
+// those of Tier; Symbols overrides them per symbol.
+type FeeScheduleConfig struct {
+	ExchangeID string
+	Tier       string
+	Rates      entity.FeeRates
+	Symbols    map[string]entity.FeeRates
+	Discount   entity.FeeDiscount
+}
+
+type fetchedFees struct {
+	rates map[string]entity.FeeRates
+	at    time.Time
+}
+
+// FeeService keeps the fee schedule of every exchange. A schedule starts from
+// its configuration and, when the exchange has a source, takes the symbol
+// rates of the account from it on every refresh. Configured symbol overrides
+// win over fetched rates, and exchanges without a schedule pay the default
+// rates.
+type FeeService struct {
+	defaults *entity.FeeSchedule
+	configs  map[string]FeeScheduleConfig
+	sources  []output.FeeRatesPort
+	logger   Logger
+	now      func() time.Time
+
+	mu        sync.RWMutex
+	schedules map[string]*entity.FeeSchedule
+	fetched   map[string]fetchedFees
+}
+
+func NewFeeService(
+	defaults entity.FeeRates,
+	configs []FeeScheduleConfig,
+	sources []output.FeeRatesPort,
+	logger Logger,
+) (*FeeService, error) {
+	if err := defaults.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid default fee rates: %w", err)
+	}
+	defaultSchedule, err := entity.NewFeeSchedule("", "", defaults, nil, entity.FeeDiscount{})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FeeService{
+		defaults:  defaultSchedule,
+		configs:   make(map[string]FeeScheduleConfig, len(configs)),
+		sources:   sources,
+		logger:    logger,
+		now:       time.Now,
+		schedules: make(map[string]*entity.FeeSchedule, len(configs)),
+		fetched:   make(map[string]fetchedFees),
+	}
+	for _, cfg := range configs {
+		s.configs[cfg.ExchangeID] = cfg
+		schedule, err := s.build(cfg.ExchangeID)
+		if err != nil {
+			return nil, err
+		}
+		s.schedules[cfg.ExchangeID] = schedule
+	}
+	return s, nil
+}
+
+// Schedule returns the fee schedule of an exchange, or the default one
+func (s *FeeService) Schedule(exchangeID string) *entity.FeeSchedule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if schedule, ok := s.schedules[exchangeID]; ok {
+		return schedule
+	}
+	return s.defaults
+}
+
+// TakerFee returns the taker rate of a symbol on an exchange
+func (s *FeeService) TakerFee(exchangeID, symbol string) float64 {
+	return s.Schedule(exchangeID).Rate(symbol, entity.LiquidityTaker)
+}
+
+// Run refreshes the schedules from their sources now and every interval
+// until ctx is done. Failures are logged and the previous rates kept.
+func (s *FeeService) Run(ctx context.Context, interval time.Duration) {
+	if len(s.sources) == 0 {
+		return
+	}
+	if err := s.Refresh(ctx); err != nil {
+		s.logger.Error("failed to refresh fee schedules", "error", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				s.logger.Error("failed to refresh fee schedules", "error", err)
+			}
+		}
+	}
+}
+
+// Refresh fetches the account rates from every source. An exchange whose
+// source fails keeps its previous schedule.
+func (s *FeeService) Refresh(ctx context.Context) error {
+	var errs []error
+	for _, source := range s.sources {
+		exchangeID := source.GetName()
+		rates, err := source.GetTradeFees(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to fetch %s fees: %w", exchangeID, err))
+			continue
+		}
+
+		s.mu.Lock()
+		previous, hadPrevious := s.fetched[exchangeID]
+		s.fetched[exchangeID] = fetchedFees{rates: rates, at: s.now()}
+		schedule, err := s.build(exchangeID)
+		if err != nil {
+			if hadPrevious {
+				s.fetched[exchangeID] = previous
+			} else {
+				delete(s.fetched, exchangeID)
+			}
+			s.mu.Unlock()
+			errs = append(errs, err)
+			continue
+		}
+		s.schedules[exchangeID] = schedule
+		s.mu.Unlock()
+
+		s.logger.Info("refreshed fee schedule", "exchange", exchangeID, "symbols", len(rates))
+	}
+	return errors.Join(errs...)
+}
+
+// ListFeeSchedules returns the default schedule followed by each exchange's
+func (s *FeeService) ListFeeSchedules(ctx context.Context) []*dto.FeeScheduleDTO {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	schedules := []*dto.FeeScheduleDTO{s.toDTO(s.defaults)}
+	for _, exchangeID := range slices.Sorted(maps.Keys(s.schedules)) {
+		schedules = append(schedules, s.toDTO(s.schedules[exchangeID]))
+	}
+	return schedules
+}
+
+// build makes the schedule of an exchange from its configuration and fetched
+// rates. It is called with mu held, or before the service is shared.
+func (s *FeeService) build(exchangeID string) (*entity.FeeSchedule, error) {
+	cfg, ok := s.configs[exchangeID]
+	if !ok {
+		cfg = FeeScheduleConfig{ExchangeID: exchangeID, Rates: s.defaults.Rates("")}
+	}
+
+	// Keys are normalized first, so a configured ETH-USDT replaces a fetched
+	// ETHUSDT rather than racing it
+	symbols := make(map[string]entity.FeeRates)
+	for symbol, rates := range s.fetched[exchangeID].rates {
+		symbols[entity.FeeSymbolKey(symbol)] = rates
+	}
+	for symbol, rates := range cfg.Symbols {
+		symbols[entity.FeeSymbolKey(symbol)] = rates
+	}
+	return entity.NewFeeSchedule(exchangeID, cfg.Tier, cfg.Rates, symbols, cfg.Discount)
+}
+
+// toDTO is called with mu held
+func (s *FeeService) toDTO(schedule *entity.FeeSchedule) *dto.FeeScheduleDTO {
+	exchangeID := schedule.ExchangeID()
+	rates := schedule.Rates("")
+	scheduleDTO := &dto.FeeScheduleDTO{
+		ExchangeID:    exchangeID,
+		Tier:          schedule.Tier(),
+		Rates:         dto.FeeRatesDTO{Maker: rates.Maker, Taker: rates.Taker},
+		DiscountAsset: schedule.Discount().Asset,
+		DiscountPct:   schedule.Discount().Pct,
+	}
+
+	if symbols := s.configs[exchangeID].Symbols; len(symbols) > 0 {
+		scheduleDTO.Symbols = make(map[string]dto.FeeRatesDTO, len(symbols))
+		for symbol := range symbols {
+			rates := schedule.Rates(symbol)
+			scheduleDTO.Symbols[symbol] = dto.FeeRatesDTO{Maker: rates.Maker, Taker: rates.Taker}
+		}
+	}
+	if fetched, ok := s.fetched[exchangeID]; ok {
+		at := fetched.at
+		scheduleDTO.ExchangeSymbols = len(fetched.rates)
+		scheduleDTO.RefreshedAt = &at
+	}
+	return scheduleDTO
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+	"marketdata/pkg/logger"
+)
+
+// feeSource serves rates, or err when set
+type feeSource struct {
+	name  string
+	rates map[string]entity.FeeRates
+	err   error
+}
+
+func (s *feeSource) GetTradeFees(ctx context.Context) (map[string]entity.FeeRates, error) {
+	return s.rates, s.err
+}
+
+func (s *feeSource) GetName() string { return s.name }
+
+type feeRateCase struct {
+	exchangeID, symbol string
+	liquidity          entity.Liquidity
+	want               float64
+}
+
+func TestFeeServiceSchedules(t *testing.T) {
+	ctx := context.Background()
+	binance := &feeSource{name: "binance", rates: map[string]entity.FeeRates{
+		"BTCUSDT": {Maker: 0.0002, Taker: 0.0004},
+		"ETHUSDT": {Maker: 0.0008, Taker: 0.0008},
+	}}
+	okx := &feeSource{name: "okx", rates: map[string]entity.FeeRates{"BTCUSDT": {Maker: 0.0005, Taker: 0.0007}}}
+
+	fees, err := NewFeeService(entity.FeeRates{Maker: 0.001, Taker: 0.002}, []FeeScheduleConfig{{
+		ExchangeID: "binance",
+		Tier:       "vip1",
+		Rates:      entity.FeeRates{Maker: 0.0009, Taker: 0.001},
+		Symbols:    map[string]entity.FeeRates{"ETH-USDT": {Maker: -0.0001, Taker: 0.0005}},
+	}}, []output.FeeRatesPort{binance, okx}, logger.NewLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := func(stage string, tests []feeRateCase) {
+		t.Helper()
+		for _, tt := range tests {
+			if got := fees.Schedule(tt.exchangeID).Rate(tt.symbol, tt.liquidity); !near(got, tt.want) {
+				t.Errorf("%s: %s %s %s = %v, want %v", stage, tt.exchangeID, tt.symbol, tt.liquidity, got, tt.want)
+			}
+		}
+	}
+
+	expect("configured", []feeRateCase{
+		// binance's tier and symbol override win over fees.default
+		{"binance", "BTC-USDT", entity.LiquidityMaker, 0.0009},
+		{"binance", "BTC-USDT", entity.LiquidityTaker, 0.001},
+		{"binance", "ETH-USDT", entity.LiquidityMaker, -0.0001},
+		{"binance", "ETH-USDT", entity.LiquidityTaker, 0.0005},
+		// Exchanges without a schedule pay the default
+		{"okx", "BTC-USDT", entity.LiquidityMaker, 0.001},
+		{"kraken", "BTC-USDT", entity.LiquidityTaker, 0.002},
+	})
+	if got := fees.TakerFee("kraken", "BTC-USDT"); !near(got, 0.002) {
+		t.Errorf("TakerFee of an unknown exchange = %v, want the default 0.002", got)
+	}
+
+	if err := fees.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	refreshed := []feeRateCase{
+		// Fetched account rates replace the tier rates of their symbols
+		{"binance", "BTC-USDT", entity.LiquidityTaker, 0.0004},
+		// but not a configured symbol override
+		{"binance", "ETH-USDT", entity.LiquidityTaker, 0.0005},
+		// Symbols the exchange did not report keep the tier rates
+		{"binance", "SOL-USDT", entity.LiquidityTaker, 0.001},
+		// An exchange with only a source starts from the default
+		{"okx", "BTC-USDT", entity.LiquidityTaker, 0.0007},
+		{"okx", "SOL-USDT", entity.LiquidityTaker, 0.002},
+	}
+	expect("refreshed", refreshed)
+
+	// A failing source and one returning invalid rates both keep the
+	// schedule of the last successful refresh
+	binance.rates = map[string]entity.FeeRates{"BTCUSDT": {Taker: 2}}
+	okx.err = errors.New("connection refused")
+	if err := fees.Refresh(ctx); err == nil {
+		t.Fatal("Refresh succeeded with a failing source and invalid rates")
+	}
+	expect("after failed refresh", refreshed)
+
+	schedules := fees.ListFeeSchedules(ctx)
+	if len(schedules) != 3 || schedules[0].ExchangeID != "" || schedules[1].ExchangeID != "binance" || schedules[2].ExchangeID != "okx" {
+		t.Fatalf("listed schedules %+v", schedules)
+	}
+	if schedules[1].ExchangeSymbols != 2 || schedules[1].RefreshedAt == nil {
+		t.Fatalf("binance schedule lost its last refresh: %+v", schedules[1])
+	}
+}
+
+func TestNewFeeServiceRejectsInvalidRates(t *testing.T) {
+	if _, err := NewFeeService(entity.FeeRates{Taker: 1.5}, nil, nil, logger.NewLogger()); err == nil {
+		t.Fatal("invalid default rates accepted")
+	}
+	_, err := NewFeeService(entity.FeeRates{Taker: 0.001}, []FeeScheduleConfig{{
+		ExchangeID: "binance",
+		Rates:      entity.FeeRates{Taker: 0.001},
+		Discount:   entity.FeeDiscount{Asset: "BNB", Pct: 150},
+	}}, nil, logger.NewLogger())
+	if err == nil {
+		t.Fatal("invalid discount accepted")
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FeedMonitor tracks per exchange/symbol feed health: when the last update
+// arrived, how many sequence gaps the adapter detected, and how far behind
+// the exchange timestamp the last publish happened
+type FeedMonitor struct {
+	mu    sync.RWMutex
+	feeds map[feedKey]*feedState
+	now   func() time.Time
+}
+
+type feedKey struct {
+	exchangeID string
+	symbol     string
+}
+
+type feedState struct {
+	updates       uint64
+	lastUpdate    time.Time
+	sequenceGaps  uint64
+	publisherLag  time.Duration
+	publishErrors uint64
+}
+
+// FeedStatus is a point-in-time copy of one feed's health
+type FeedStatus struct {
+	ExchangeID    string
+	Symbol        string
+	Updates       uint64
+	LastUpdate    time.Time
+	LastUpdateAge time.Duration
+	SequenceGaps  uint64
+	PublisherLag  time.Duration
+	PublishErrors uint64
+}
+
+func NewFeedMonitor() *FeedMonitor {
+	return &FeedMonitor{
+		feeds: make(map[feedKey]*feedState),
+		now:   time.Now,
+	}
+}
+
+// RecordUpdate notes that an update for the feed was received
+func (m *FeedMonitor) RecordUpdate(exchangeID, symbol string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := m.state(exchangeID, symbol)
+	state.updates++
+	state.lastUpdate = m.now()
+}
+
+// RecordSequenceGap is called by exchange adapters when an update's sequence
+// number does not follow the previous one
+func (m *FeedMonitor) RecordSequenceGap(exchangeID, symbol string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.state(exchangeID, symbol).sequenceGaps++
+}
+
+// RecordPublish records the delay between the exchange event time and its publication
+func (m *FeedMonitor) RecordPublish(exchangeID, symbol string, eventTime time.Time, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := m.state(exchangeID, symbol)
+	if err != nil {
+		state.publishErrors++
+		return
+	}
+	if !eventTime.IsZero() {
+		state.publisherLag = m.now().Sub(eventTime)
+	}
+}
+
+// Snapshot returns every feed sorted by exchange and symbol
+func (m *FeedMonitor) Snapshot() []FeedStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := m.now()
+	statuses := make([]FeedStatus, 0, len(m.feeds))
+	for key, state := range m.feeds {
+		statuses = append(statuses, FeedStatus{
+			ExchangeID:    key.exchangeID,
+			Symbol:        key.symbol,
+			Updates:       state.updates,
+			LastUpdate:    state.lastUpdate,
+			LastUpdateAge: now.Sub(state.lastUpdate),
+			SequenceGaps:  state.sequenceGaps,
+			PublisherLag:  state.publisherLag,
+			PublishErrors: state.publishErrors,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].ExchangeID != statuses[j].ExchangeID {
+			return statuses[i].ExchangeID < statuses[j].ExchangeID
+		}
+		return statuses[i].Symbol < statuses[j].Symbol
+	})
+
+	return statuses
+}
+
+func (m *FeedMonitor) state(exchangeID, symbol string) *feedState {
+	key := feedKey{exchangeID: exchangeID, symbol: symbol}
+	state, ok := m.feeds[key]
+	if !ok {
+		state = &feedState{}
+		m.feeds[key] = state
+	}
+	return state
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+	"marketdata/internal/domain/entity"
+	domainservice "marketdata/internal/domain/service"
+)
+
+// indicatorRangeWindow is how far back the high, low and range look
+const indicatorRangeWindow = 24 * time.Hour
+
+var _ input.IndicatorUseCase = (*IndicatorService)(nil)
+
+// IndicatorConfig tunes the indicator service
+type IndicatorConfig struct {
+	// CandleInterval is the width of the candles built from mid prices
+	CandleInterval time.Duration
+	// VolatilityWindow is how many candle returns the volatility covers
+	VolatilityWindow int
+	// ATRPeriod is the smoothing period of the average true range
+	ATRPeriod int
+	// DepthBps are the distances from the mid price, in basis points, that
+	// depth and imbalance are measured within
+	DepthBps []float64
+	// HighVolatilityPct and HighCandlePct flag a symbol as highly volatile
+	// when the volatility, scaled to HighVolatilityHorizon, or the last
+	// candle's range exceeds them
+	HighVolatilityPct     float64
+	HighVolatilityHorizon time.Duration
+	HighCandlePct         float64
+	// StaleAfter is how long a symbol without updates keeps its indicators;
+	// zero keeps them for good
+	StaleAfter time.Duration
+}
+
+// IndicatorMetrics exports indicators as gauges
+type IndicatorMetrics interface {
+	SetVolatility(exchange, symbol string, pct float64)
+	SetATR(exchange, symbol string, pct float64)
+	SetRange24h(exchange, symbol string, pct float64)
+	SetBookDepth(exchange, symbol, side string, bps, notional float64)
+	SetBookImbalance(exchange, symbol string, bps, imbalance float64)
+	// DeleteIndicators removes every gauge of a symbol
+	DeleteIndicators(exchange, symbol string)
+}
+
+// liveCandle is the candle still being built
+type liveCandle struct {
+	openTime               time.Time
+	open, high, low, close float64
+}
+
+type symbolIndicators struct {
+	// candles are the closed candles of the range window, oldest first
+	candles []*entity.Candle
+	live    *liveCandle
+	// closedHigh and closedLow are the extremes of candles
+	closedHigh, closedLow float64
+
+	volatility  *float64
+	atr         *float64
+	candleRange *float64
+
+	mid       float64
+	depth     []domainservice.BookDepth
+	updatedAt time.Time
+}
+
+// IndicatorService keeps volatility and market quality indicators for every
+// streamed symbol. Each book update moves the symbol's current candle, built
+// from the mid price, and re-measures the depth and imbalance of the book;
+// the candle indicators are recomputed as each candle closes. While Run is
+// running, candles also close on time when a symbol is quiet, and symbols
+// without updates for StaleAfter are dropped. Gauges go to the metrics, when
+// given.
+type IndicatorService struct {
+	cfg     IndicatorConfig
+	metrics IndicatorMetrics
+	now     func() time.Time
+
+	mu      sync.Mutex
+	symbols map[feedKey]*symbolIndicators
+}
+
+func NewIndicatorService(cfg IndicatorConfig, metrics IndicatorMetrics) *IndicatorService {
+	return &IndicatorService{
+		cfg:     cfg,
+		metrics: metrics,
+		now:     time.Now,
+		symbols: make(map[feedKey]*symbolIndicators),
+	}
+}
+
+// ProcessOrderBookUpdate updates the indicators of the book's symbol. Books
+// without a bid and an ask are skipped.
+func (s *IndicatorService) ProcessOrderBookUpdate(ctx context.Context, update *dto.OrderBookDTO) error {
+	if len(update.Bids) == 0 || len(update.Asks) == 0 {
+		return nil
+	}
+	mid := (update.Bids[0].Price + update.Asks[0].Price) / 2
+	book := convertToOrderBookEntity(update)
+	depth := make([]domainservice.BookDepth, 0, len(s.cfg.DepthBps))
+	for _, bps := range s.cfg.DepthBps {
+		if d, err := domainservice.CalculateDepth(book, bps); err == nil {
+			depth = append(depth, d)
+		}
+	}
+
+	key := feedKey{exchangeID: update.ExchangeID, symbol: update.Symbol}
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.symbols[key]
+	if !ok {
+		state = &symbolIndicators{}
+		s.symbols[key] = state
+	}
+	s.advance(key, state, mid, now)
+	state.mid, state.depth, state.updatedAt = mid, depth, now
+
+	if s.metrics != nil {
+		for _, d := range depth {
+			s.metrics.SetBookDepth(key.exchangeID, key.symbol, "bid", d.Bps, d.BidNotional)
+			s.metrics.SetBookDepth(key.exchangeID, key.symbol, "ask", d.Bps, d.AskNotional)
+			s.metrics.SetBookImbalance(key.exchangeID, key.symbol, d.Bps, d.Imbalance())
+		}
+		_, _, rangePct := state.rangeOf()
+		s.metrics.SetRange24h(key.exchangeID, key.symbol, rangePct)
+	}
+	return nil
+}
+
+// ListIndicators returns the indicators of every symbol, optionally filtered
+// by exchange and symbol, ordered by exchange then symbol
+func (s *IndicatorService) ListIndicators(ctx context.Context, exchangeID, symbol string) []*dto.IndicatorsDTO {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	indicators := make([]*dto.IndicatorsDTO, 0, len(s.symbols))
+	for key, state := range s.symbols {
+		if (exchangeID == "" || key.exchangeID == exchangeID) && (symbol == "" || key.symbol == symbol) {
+			indicators = append(indicators, s.toDTO(key, state))
+		}
+	}
+	sort.Slice(indicators, func(i, j int) bool {
+		if indicators[i].ExchangeID != indicators[j].ExchangeID {
+			return indicators[i].ExchangeID < indicators[j].ExchangeID
+		}
+		return indicators[i].Symbol < indicators[j].Symbol
+	})
+	return indicators
+}
+
+// Volatility returns the realized volatility of a symbol, in percent, scaled
+// from the candle interval to horizon by the square root of time. It is
+// false until enough candles have closed.
+func (s *IndicatorService) Volatility(exchangeID, symbol string, horizon time.Duration) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.symbols[feedKey{exchangeID: exchangeID, symbol: symbol}]
+	if !ok || state.volatility == nil {
+		return 0, false
+	}
+	return s.scaleVolatility(*state.volatility, horizon), true
+}
+
+// scaleVolatility scales a per-candle volatility to horizon by the square
+// root of time
+func (s *IndicatorService) scaleVolatility(perCandle float64, horizon time.Duration) float64 {
+	return perCandle * math.Sqrt(float64(horizon)/float64(s.cfg.CandleInterval))
+}
+
+// Run closes the candles of quiet symbols as their intervals end and drops
+// stale symbols, until ctx is done
+func (s *IndicatorService) Run(ctx context.Context) {
+	timer := time.NewTimer(s.untilNextCandle())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			s.sweep(s.now())
+			timer.Reset(s.untilNextCandle())
+		}
+	}
+}
+
+// untilNextCandle returns the time left in the current candle interval
+func (s *IndicatorService) untilNextCandle() time.Duration {
+	now := s.now()
+	return now.Truncate(s.cfg.CandleInterval).Add(s.cfg.CandleInterval).Sub(now)
+}
+
+// sweep closes the candles due by now and drops the symbols whose last
+// update is older than StaleAfter, with their gauges
+func (s *IndicatorService) sweep(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, state := range s.symbols {
+		if s.cfg.StaleAfter > 0 && now.Sub(state.updatedAt) > s.cfg.StaleAfter {
+			delete(s.symbols, key)
+			if s.metrics != nil {
+				s.metrics.DeleteIndicators(key.exchangeID, key.symbol)
+			}
+			continue
+		}
+		s.roll(key, state, now)
+		if s.metrics != nil {
+			_, _, rangePct := state.rangeOf()
+			s.metrics.SetRange24h(key.exchangeID, key.symbol, rangePct)
+		}
+	}
+}
+
+// advance moves the live candle to mid, first closing the candles due by
+// now. It is called with mu held.
+func (s *IndicatorService) advance(key feedKey, state *symbolIndicators, mid float64, now time.Time) {
+	s.roll(key, state, now)
+	if live := state.live; live != nil {
+		live.high = math.Max(live.high, mid)
+		live.low = math.Min(live.low, mid)
+		live.close = mid
+		return
+	}
+	state.live = &liveCandle{openTime: now.Truncate(s.cfg.CandleInterval), open: mid, high: mid, low: mid, close: mid}
+}
+
+// roll closes the live candle once now is past its interval. Intervals
+// without updates since the last candle get flat candles at its close, so a
+// quiet symbol's volatility falls instead of standing still. It is called
+// with mu held.
+func (s *IndicatorService) roll(key feedKey, state *symbolIndicators, now time.Time) {
+	openTime := now.Truncate(s.cfg.CandleInterval)
+	closed := false
+	if live := state.live; live != nil {
+		if !openTime.After(live.openTime) {
+			return
+		}
+		state.candles = append(state.candles, entity.NewCandle(
+			key.exchangeID, key.symbol, s.cfg.CandleInterval, live.openTime,
+			live.open, live.high, live.low, live.close, 0, 0,
+		))
+		state.live = nil
+		closed = true
+	}
+
+	if len(state.candles) > 0 {
+		last := state.candles[len(state.candles)-1]
+		next := last.OpenTime().Add(s.cfg.CandleInterval)
+		// Older candles would fall out of the range window straight away
+		if oldest := openTime.Add(-indicatorRangeWindow); next.Before(oldest) {
+			next = oldest.Truncate(s.cfg.CandleInterval)
+		}
+		for ; next.Before(openTime); next = next.Add(s.cfg.CandleInterval) {
+			state.candles = append(state.candles, entity.NewCandle(
+				key.exchangeID, key.symbol, s.cfg.CandleInterval, next,
+				last.Close(), last.Close(), last.Close(), last.Close(), 0, 0,
+			))
+			closed = true
+		}
+	}
+
+	if closed {
+		s.recompute(key, state, now)
+	}
+}
+
+// recompute drops candles older than the range window and updates the
+// candle indicators. It is called with mu held.
+func (s *IndicatorService) recompute(key feedKey, state *symbolIndicators, now time.Time) {
+	oldest := now.Add(-indicatorRangeWindow)
+	drop := 0
+	for drop < len(state.candles) && state.candles[drop].OpenTime().Before(oldest) {
+		drop++
+	}
+	state.candles = state.candles[drop:]
+
+	state.closedHigh, state.closedLow = 0, 0
+	for _, candle := range state.candles {
+		if state.closedHigh == 0 || candle.High() > state.closedHigh {
+			state.closedHigh = candle.High()
+		}
+		if state.closedLow == 0 || candle.Low() < state.closedLow {
+			state.closedLow = candle.Low()
+		}
+	}
+
+	state.volatility, state.atr, state.candleRange = nil, nil, nil
+	if len(state.candles) == 0 {
+		return
+	}
+	last := state.candles[len(state.candles)-1]
+	if last.Open() > 0 {
+		candleRange := (last.High() - last.Low()) / last.Open() * 100
+		state.candleRange = &candleRange
+	}
+
+	window := state.candles[max(0, len(state.candles)-s.cfg.VolatilityWindow-1):]
+	closes := make([]float64, len(window))
+	for i, candle := range window {
+		closes[i] = candle.Close()
+	}
+	if volatility, err := domainservice.CalculateRealizedVolatility(closes); err == nil {
+		volatility *= 100
+		state.volatility = &volatility
+		if s.metrics != nil {
+			s.metrics.SetVolatility(key.exchangeID, key.symbol, volatility)
+		}
+	}
+	if atr, err := domainservice.CalculateATR(state.candles, s.cfg.ATRPeriod); err == nil {
+		state.atr = &atr
+		if s.metrics != nil && last.Close() > 0 {
+			s.metrics.SetATR(key.exchangeID, key.symbol, atr/last.Close()*100)
+		}
+	}
+}
+
+// rangeOf returns the high, low and range of the closed and live candles
+func (state *symbolIndicators) rangeOf() (high, low, rangePct float64) {
+	high, low = state.closedHigh, state.closedLow
+	if live := state.live; live != nil {
+		if high == 0 || live.high > high {
+			high = live.high
+		}
+		if low == 0 || live.low < low {
+			low = live.low
+		}
+	}
+	if low > 0 {
+		rangePct = (high - low) / low * 100
+	}
+	return high, low, rangePct
+}
+
+// toDTO is called with mu held
+func (s *IndicatorService) toDTO(key feedKey, state *symbolIndicators) *dto.IndicatorsDTO {
+	high, low, rangePct := state.rangeOf()
+	indicators := &dto.IndicatorsDTO{
+		ExchangeID:            key.exchangeID,
+		Symbol:                key.symbol,
+		MidPrice:              state.mid,
+		RealizedVolatilityPct: state.volatility,
+		ATR:                   state.atr,
+		CandleRangePct:        state.candleRange,
+		High24h:               high,
+		Low24h:                low,
+		Range24hPct:           rangePct,
+		Depth:                 make([]dto.BookDepthDTO, len(state.depth)),
+		Candles:               len(state.candles),
+		UpdatedAt:             state.updatedAt,
+	}
+	if state.atr != nil && len(state.candles) > 0 {
+		if lastClose := state.candles[len(state.candles)-1].Close(); lastClose > 0 {
+			atrPct := *state.atr / lastClose * 100
+			indicators.ATRPct = &atrPct
+		}
+	}
+	indicators.HighVolatility = (state.volatility != nil &&
+		s.scaleVolatility(*state.volatility, s.cfg.HighVolatilityHorizon) > s.cfg.HighVolatilityPct) ||
+		(state.candleRange != nil && *state.candleRange > s.cfg.HighCandlePct)
+
+	for i, d := range state.depth {
+		indicators.Depth[i] = dto.BookDepthDTO{
+			Bps:         d.Bps,
+			BidQuantity: d.BidQuantity,
+			AskQuantity: d.AskQuantity,
+			BidNotional: d.BidNotional,
+			AskNotional: d.AskNotional,
+			Imbalance:   d.Imbalance(),
+		}
+	}
+	return indicators
+}
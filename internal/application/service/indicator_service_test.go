@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"marketdata/internal/application/dto"
+)
+
+func TestIndicatorServiceScalesVolatilityToHorizon(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	// One book a minute closes candles at 100 and 100e^0.01 in turn: a
+	// per-candle volatility of 1 * sqrt(4/3) percent
+	perCandle := math.Sqrt(4.0 / 3)
+	mids := []float64{100, 100 * math.Exp(0.01), 100, 100 * math.Exp(0.01), 100}
+
+	indicatorsOver := func(horizon time.Duration) *dto.IndicatorsDTO {
+		t.Helper()
+		indicators := NewIndicatorService(IndicatorConfig{
+			CandleInterval:        time.Minute,
+			VolatilityWindow:      3,
+			ATRPeriod:             2,
+			HighVolatilityPct:     2,
+			HighVolatilityHorizon: horizon,
+			HighCandlePct:         100,
+		}, nil)
+		now := start
+		indicators.now = func() time.Time { return now }
+		for _, mid := range mids {
+			if err := indicators.ProcessOrderBookUpdate(ctx, midBook("x", "BTC-USDT", mid)); err != nil {
+				t.Fatal(err)
+			}
+			now = now.Add(time.Minute)
+		}
+
+		if got, ok := indicators.Volatility("x", "BTC-USDT", time.Hour); !ok || !near(got, perCandle*math.Sqrt(60)) {
+			t.Fatalf("hourly volatility = %v, %v; want %v", got, ok, perCandle*math.Sqrt(60))
+		}
+		return indicators.ListIndicators(ctx, "x", "BTC-USDT")[0]
+	}
+
+	// The reported volatility stays per candle whatever the horizon
+	for _, tt := range []struct {
+		horizon time.Duration
+		high    bool
+	}{
+		// 1.15% over a minute is under the 2% threshold
+		{time.Minute, false},
+		// but 2.31% over 4 minutes is above it
+		{4 * time.Minute, true},
+	} {
+		got := indicatorsOver(tt.horizon)
+		if got.Candles != 4 || got.RealizedVolatilityPct == nil || !near(*got.RealizedVolatilityPct, perCandle) {
+			t.Fatalf("horizon %v: %d candles, volatility %v", tt.horizon, got.Candles, got.RealizedVolatilityPct)
+		}
+		if got.HighVolatility != tt.high {
+			t.Errorf("horizon %v: high volatility %v, want %v", tt.horizon, got.HighVolatility, tt.high)
+		}
+	}
+}
@@ -3,11 +3,21 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"marketdata/internal/application/dto"
 	"marketdata/internal/application/port/input"
 	"marketdata/internal/application/port/output"
 	"marketdata/internal/domain/entity"
+	domainservice "marketdata/internal/domain/service"
+	"marketdata/internal/domain/valueobject"
+)
+
+const (
+	defaultTradeLimit = 100
+	maxTradeLimit     = 1000
 )
 
 type Logger interface {
@@ -18,23 +28,42 @@ type Logger interface {
 type MarketDataService struct {
 	orderbookRepo output.OrderBookRepositoryPort
 	tradeRepo     output.TradeRepositoryPort
-	exchangeMgr   output.ExchangePort
+	snapshotRepo  output.OrderBookSnapshotRepositoryPort
+	exchanges     map[string]output.ExchangePort
 	publisher     output.EventPublisherPort
+	orderbookSvc  domainservice.OrderBookDomainService
+	fees          FeeProvider
+	monitor       *FeedMonitor
+	trades        *tradeFanout
 	logger        Logger
 }
 
 func NewMarketDataService(
 	orderbookRepo output.OrderBookRepositoryPort,
 	tradeRepo output.TradeRepositoryPort,
-	exchangeMgr output.ExchangePort,
+	snapshotRepo output.OrderBookSnapshotRepositoryPort,
+	exchanges []output.ExchangePort,
 	publisher output.EventPublisherPort,
+	orderbookSvc domainservice.OrderBookDomainService,
+	fees FeeProvider,
+	monitor *FeedMonitor,
 	logger Logger,
 ) *MarketDataService {
+	byName := make(map[string]output.ExchangePort, len(exchanges))
+	for _, exchange := range exchanges {
+		byName[exchange.GetName()] = exchange
+	}
+
 	return &MarketDataService{
 		orderbookRepo: orderbookRepo,
 		tradeRepo:     tradeRepo,
-		exchangeMgr:   exchangeMgr,
+		snapshotRepo:  snapshotRepo,
+		exchanges:     byName,
 		publisher:     publisher,
+		orderbookSvc:  orderbookSvc,
+		fees:          fees,
+		monitor:       monitor,
+		trades:        newTradeFanout(),
 		logger:        logger,
 	}
 }
@@ -55,10 +84,44 @@ func (s *MarketDataService) GetOrderBook(ctx context.Context, exchangeID, symbol
 	return convertToOrderBookDTO(orderbook), nil
 }
 
+// GetOrderBookAt retrieves the latest archived snapshot taken at or before at
+func (s *MarketDataService) GetOrderBookAt(ctx context.Context, exchangeID, symbol string, at time.Time) (*dto.OrderBookDTO, error) {
+	orderbook, err := s.snapshotRepo.GetSnapshotAt(ctx, exchangeID, symbol, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orderbook snapshot: %w", err)
+	}
+
+	if orderbook == nil {
+		return nil, nil
+	}
+
+	return convertToOrderBookDTO(orderbook), nil
+}
+
+// GetAllOrderBooks retrieves the current orderbook of every tracked exchange and symbol
+func (s *MarketDataService) GetAllOrderBooks(ctx context.Context) ([]*dto.OrderBookDTO, error) {
+	orderbooks, err := s.orderbookRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orderbooks: %w", err)
+	}
+
+	dtos := make([]*dto.OrderBookDTO, len(orderbooks))
+	for i, orderbook := range orderbooks {
+		dtos[i] = convertToOrderBookDTO(orderbook)
+	}
+
+	return dtos, nil
+}
+
 // SubscribeOrderBook subscribes to orderbook updates for a given exchange and symbol
 func (s *MarketDataService) SubscribeOrderBook(ctx context.Context, exchangeID, symbol string) (<-chan *dto.OrderBookDTO, error) {
+	exchange, ok := s.exchanges[exchangeID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", input.ErrUnknownExchange, exchangeID)
+	}
+
 	// Subscribe to exchange updates
-	updates, err := s.exchangeMgr.SubscribeOrderBook(ctx, exchangeID, symbol)
+	updates, err := exchange.SubscribeOrderBook(ctx, symbol)
 	if err != nil {
 		return nil, fmt.Errorf("failed to subscribe to orderbook: %w", err)
 	}
@@ -85,25 +148,169 @@ func (s *MarketDataService) SubscribeOrderBook(ctx context.Context, exchangeID,
 	return dtoChan, nil
 }
 
-// GetTrades retrieves recent trades for a given exchange and symbol
-func (s *MarketDataService) GetTrades(ctx context.Context, exchangeID, symbol string, limit int) ([]*dto.TradeDTO, error) {
+// SubscribeTrades streams the trades processed from now on. The channel is
+// closed when ctx is done or when the subscriber falls too far behind.
+func (s *MarketDataService) SubscribeTrades(ctx context.Context, exchangeID, symbol string) (<-chan *dto.TradeDTO, error) {
+	if exchangeID != "" {
+		if _, ok := s.exchanges[exchangeID]; !ok {
+			return nil, fmt.Errorf("%w: %q", input.ErrUnknownExchange, exchangeID)
+		}
+	}
+	return s.trades.subscribe(ctx, exchangeID, symbol), nil
+}
+
+// GetTrades retrieves one page of trades matching the query. The page is
+// ordered by (timestamp, exchange_id, id) and its NextCursor resumes right
+// after it.
+func (s *MarketDataService) GetTrades(ctx context.Context, query dto.TradeQueryDTO) (*dto.TradePageDTO, error) {
+	filter := output.TradeFilter{
+		ExchangeID: query.ExchangeID,
+		Symbol:     query.Symbol,
+		From:       query.From,
+		To:         query.To,
+		Limit:      clampLimit(query.Limit),
+		Ascending:  query.Ascending,
+	}
+
+	switch side := entity.TradeType(strings.ToUpper(query.Side)); side {
+	case "", entity.TradeBuy, entity.TradeSell:
+		filter.Side = side
+	default:
+		return nil, fmt.Errorf("%w: side must be BUY or SELL", input.ErrInvalidQuery)
+	}
+
+	if query.Cursor != "" {
+		after, err := decodeTradeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
 	// Get trades from repository
-	trades, err := s.tradeRepo.GetTradesBySymbol(ctx, symbol, limit)
+	trades, err := s.tradeRepo.QueryTrades(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get trades: %w", err)
 	}
 
 	// Convert domain entities to DTOs
-	tradeDTOs := make([]*dto.TradeDTO, len(trades))
+	page := &dto.TradePageDTO{Trades: make([]*dto.TradeDTO, len(trades))}
 	for i, trade := range trades {
-		tradeDTOs[i] = convertToTradeDTO(trade)
+		page.Trades[i] = convertToTradeDTO(trade)
+	}
+
+	// A full page may have more behind it; a short one is the last
+	if len(trades) == filter.Limit {
+		last := trades[len(trades)-1]
+		page.NextCursor = encodeTradeCursor(output.TradeKey{
+			Timestamp:  last.Timestamp(),
+			ExchangeID: last.ExchangeID(),
+			ID:         last.ID(),
+		})
+	}
+
+	return page, nil
+}
+
+// GetCandles retrieves OHLCV candles aggregated from trades
+func (s *MarketDataService) GetCandles(ctx context.Context, query dto.CandleQueryDTO) ([]*dto.CandleDTO, error) {
+	if query.Interval <= 0 {
+		return nil, fmt.Errorf("candle interval must be positive")
+	}
+
+	candles, err := s.tradeRepo.GetCandles(ctx, output.CandleFilter{
+		ExchangeID: query.ExchangeID,
+		Symbol:     query.Symbol,
+		Interval:   query.Interval,
+		From:       query.From,
+		To:         query.To,
+		Limit:      clampLimit(query.Limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get candles: %w", err)
+	}
+
+	candleDTOs := make([]*dto.CandleDTO, len(candles))
+	for i, candle := range candles {
+		candleDTOs[i] = convertToCandleDTO(candle)
+	}
+
+	return candleDTOs, nil
+}
+
+// GetInstruments lists the exchange/symbol pairs with a tracked orderbook
+func (s *MarketDataService) GetInstruments(ctx context.Context) ([]*dto.InstrumentDTO, error) {
+	orderbooks, err := s.orderbookRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orderbooks: %w", err)
+	}
+
+	instruments := make([]*dto.InstrumentDTO, 0, len(orderbooks))
+	for _, orderbook := range orderbooks {
+		base, quote := splitSymbol(orderbook.Symbol())
+		instruments = append(instruments, &dto.InstrumentDTO{
+			ExchangeID: orderbook.ExchangeID(),
+			Symbol:     orderbook.Symbol(),
+			BaseAsset:  base,
+			QuoteAsset: quote,
+		})
+	}
+
+	sort.Slice(instruments, func(i, j int) bool {
+		if instruments[i].Symbol != instruments[j].Symbol {
+			return instruments[i].Symbol < instruments[j].Symbol
+		}
+		return instruments[i].ExchangeID < instruments[j].ExchangeID
+	})
+
+	return instruments, nil
+}
+
+// GetArbitrageOpportunities detects cross-exchange opportunities at the top of
+// the books, optionally for one symbol, with the taker fees they would pay
+func (s *MarketDataService) GetArbitrageOpportunities(ctx context.Context, symbol string) ([]*dto.ArbitrageOpportunityDTO, error) {
+	orderbooks, err := s.orderbookRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orderbooks: %w", err)
+	}
+
+	if symbol != "" {
+		filtered := orderbooks[:0]
+		for _, orderbook := range orderbooks {
+			if orderbook.Symbol() == symbol {
+				filtered = append(filtered, orderbook)
+			}
+		}
+		orderbooks = filtered
 	}
 
-	return tradeDTOs, nil
+	opportunities, err := s.orderbookSvc.DetectArbitrageOpportunity(orderbooks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect arbitrage opportunities: %w", err)
+	}
+
+	opportunityDTOs := make([]*dto.ArbitrageOpportunityDTO, len(opportunities))
+	for i, opportunity := range opportunities {
+		feesPct := (s.fees.TakerFee(opportunity.BuyExchange, opportunity.Symbol) +
+			s.fees.TakerFee(opportunity.SellExchange, opportunity.Symbol)) * 100
+		opportunityDTOs[i] = &dto.ArbitrageOpportunityDTO{
+			Symbol:       opportunity.Symbol,
+			BuyExchange:  opportunity.BuyExchange,
+			SellExchange: opportunity.SellExchange,
+			SpreadPct:    opportunity.SpreadPct,
+			FeesPct:      feesPct,
+			NetSpreadPct: opportunity.SpreadPct - feesPct,
+			MaxVolume:    opportunity.MaxVolume,
+		}
+	}
+
+	return opportunityDTOs, nil
 }
 
 // ProcessOrderBookUpdate processes an orderbook update from an exchange
 func (s *MarketDataService) ProcessOrderBookUpdate(ctx context.Context, update *dto.OrderBookDTO) error {
+	s.monitor.RecordUpdate(update.ExchangeID, update.Symbol)
+
 	// Convert DTO to domain entity
 	orderbook := convertToOrderBookEntity(update)
 
@@ -113,7 +320,9 @@ func (s *MarketDataService) ProcessOrderBookUpdate(ctx context.Context, update *
 	}
 
 	// Publish update
-	if err := s.publisher.PublishOrderBookUpdate(ctx, update); err != nil {
+	err := s.publisher.PublishOrderBookUpdate(ctx, orderbook)
+	s.monitor.RecordPublish(update.ExchangeID, update.Symbol, update.Timestamp, err)
+	if err != nil {
 		s.logger.Error("failed to publish orderbook update",
 			"error", err,
 			"exchange", update.ExchangeID,
@@ -124,6 +333,31 @@ func (s *MarketDataService) ProcessOrderBookUpdate(ctx context.Context, update *
 	return nil
 }
 
+// ProcessTrade stores a trade from an exchange, publishes it and hands it to
+// the live trade subscribers. With the batch writer, storing only queues the
+// trade for the next batch.
+func (s *MarketDataService) ProcessTrade(ctx context.Context, update *dto.TradeDTO) error {
+	trade, err := convertToTradeEntity(update)
+	if err != nil {
+		return err
+	}
+
+	if err := s.tradeRepo.StoreTrade(ctx, trade); err != nil {
+		return fmt.Errorf("failed to store trade: %w", err)
+	}
+
+	if err := s.publisher.PublishTrade(ctx, trade); err != nil {
+		s.logger.Error("failed to publish trade",
+			"error", err,
+			"exchange", update.ExchangeID,
+			"symbol", update.Symbol,
+		)
+	}
+	s.trades.publish(convertToTradeDTO(trade))
+
+	return nil
+}
+
 // Helper functions to convert between domain entities and DTOs
 func convertToOrderBookDTO(ob *entity.OrderBook) *dto.OrderBookDTO {
 	return &dto.OrderBookDTO{
@@ -137,8 +371,8 @@ func convertToOrderBookDTO(ob *entity.OrderBook) *dto.OrderBookDTO {
 
 func convertToOrderBookEntity(dto *dto.OrderBookDTO) *entity.OrderBook {
 	ob := entity.NewOrderBook(dto.ExchangeID, dto.Symbol, dto.Timestamp)
-	ob.UpdateBids(convertToPriceLevels(dto.Bids))
-	ob.UpdateAsks(convertToPriceLevels(dto.Asks))
+	ob.UpdateBids(convertToPriceLevels(dto.Symbol, dto.Bids))
+	ob.UpdateAsks(convertToPriceLevels(dto.Symbol, dto.Asks))
 	return ob
 }
 
@@ -153,15 +387,29 @@ func convertToPriceLevelDTOs(levels []entity.PriceLevel) []dto.PriceLevelDTO {
 	return dtos
 }
 
-func convertToPriceLevels(dtos []dto.PriceLevelDTO) []entity.PriceLevel {
-	levels := make([]entity.PriceLevel, len(dtos))
-	for i, dto := range dtos {
-		price, _ := entity.NewPrice(dto.Price, "USDT")
-		quantity, _ := entity.NewVolume(dto.Quantity, "")
-		levels[i] = entity.PriceLevel{
+// convertToPriceLevels prices levels in the symbol's quote asset and sizes
+// them in its base asset. Levels that are not valid, such as a negative
+// price, are skipped.
+func convertToPriceLevels(symbol string, dtos []dto.PriceLevelDTO) []entity.PriceLevel {
+	base, quote := splitSymbol(symbol)
+	if quote == "" {
+		quote = "USDT"
+	}
+
+	levels := make([]entity.PriceLevel, 0, len(dtos))
+	for _, dto := range dtos {
+		price, err := valueobject.NewPrice(dto.Price, quote)
+		if err != nil {
+			continue
+		}
+		quantity, err := valueobject.NewVolume(dto.Quantity, base)
+		if err != nil {
+			continue
+		}
+		levels = append(levels, entity.PriceLevel{
 			Price:    *price,
 			Quantity: *quantity,
-		}
+		})
 	}
 	return levels
 }
@@ -178,5 +426,80 @@ func convertToTradeDTO(trade *entity.Trade) *dto.TradeDTO {
 	}
 }
 
+func convertToTradeEntity(trade *dto.TradeDTO) (*entity.Trade, error) {
+	base, quote := splitSymbol(trade.Symbol)
+	if quote == "" {
+		quote = "USDT"
+	}
+	price, err := valueobject.NewPrice(trade.Price, quote)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trade price: %w", err)
+	}
+	volume, err := valueobject.NewVolume(trade.Volume, base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trade volume: %w", err)
+	}
+	return entity.NewTrade(
+		trade.ID,
+		trade.ExchangeID,
+		trade.Symbol,
+		*price,
+		*volume,
+		entity.TradeType(trade.TradeType),
+		trade.Timestamp,
+	), nil
+}
+
+func convertToCandleDTO(candle *entity.Candle) *dto.CandleDTO {
+	return &dto.CandleDTO{
+		ExchangeID: candle.ExchangeID(),
+		Symbol:     candle.Symbol(),
+		Interval:   formatInterval(candle.Interval()),
+		OpenTime:   candle.OpenTime(),
+		Open:       candle.Open(),
+		High:       candle.High(),
+		Low:        candle.Low(),
+		Close:      candle.Close(),
+		Volume:     candle.Volume(),
+		TradeCount: candle.TradeCount(),
+	}
+}
+
+func formatInterval(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Minute && d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return d.String()
+	}
+}
+
+// splitSymbol splits "BTC-USDT" or "BTC/USDT" into base and quote assets
+func splitSymbol(symbol string) (string, string) {
+	for _, sep := range []string{"-", "/", "_"} {
+		if base, quote, ok := strings.Cut(symbol, sep); ok {
+			return base, quote
+		}
+	}
+	return symbol, ""
+}
+
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return defaultTradeLimit
+	}
+	if limit > maxTradeLimit {
+		return maxTradeLimit
+	}
+	return limit
+}
+
 // Ensure MarketDataService implements MarketDataUseCase interface
-var _ input.MarketDataUseCase = (*MarketDataService)(nil)
+var (
+	_ input.MarketDataUseCase = (*MarketDataService)(nil)
+	_ TradeProcessor          = (*MarketDataService)(nil)
+)
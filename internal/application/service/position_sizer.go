@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	domainservice "marketdata/internal/domain/service"
+)
+
+var _ OpportunitySizer = (*PositionSizer)(nil)
+
+// PositionSizingConfig tunes the position sizer. Percentages are in percent:
+// 10 is 10%.
+type PositionSizingConfig struct {
+	// AccountSize is the equity sized against when there is no equity
+	// provider
+	AccountSize float64
+	// AccountPct is the most of the equity one position may be worth
+	AccountPct float64
+	// LiquidityPct is the most of an opportunity's executable volume one
+	// position may take
+	LiquidityPct float64
+	// RiskLimitPct is the share of the equity a position may lose in a move
+	// of one volatility; zero leaves volatility out of the sizing
+	RiskLimitPct float64
+	// VolatilityHorizon is the holding time the volatility is scaled to
+	VolatilityHorizon time.Duration
+}
+
+// EquityProvider gives the current equity of the account
+type EquityProvider interface {
+	Equity() float64
+}
+
+// VolatilityProvider gives the volatility of a symbol, in percent, over a
+// horizon; it is false while unknown
+type VolatilityProvider interface {
+	Volatility(exchangeID, symbol string, horizon time.Duration) (float64, bool)
+}
+
+// AccountProvider gives the free balances and lot sizes of the account
+type AccountProvider interface {
+	Balance(exchangeID, asset string) (float64, bool)
+	LotSize(exchangeID, symbol string) float64
+}
+
+// PositionSizer sizes arbitrage opportunities as the smallest of a share of
+// the equity, a share of the executable volume, the notional whose move of
+// one volatility loses the risk limit, and the balances the account holds
+// for both legs. Providers that are nil, and balances or volatilities not
+// yet known, leave their limits out.
+type PositionSizer struct {
+	cfg        PositionSizingConfig
+	equity     EquityProvider
+	account    AccountProvider
+	volatility VolatilityProvider
+}
+
+func NewPositionSizer(
+	cfg PositionSizingConfig,
+	equity EquityProvider,
+	account AccountProvider,
+	volatility VolatilityProvider,
+) *PositionSizer {
+	return &PositionSizer{
+		cfg:        cfg,
+		equity:     equity,
+		account:    account,
+		volatility: volatility,
+	}
+}
+
+// SizeArbitrage sizes an opportunity at its buy price, rounded down to a
+// multiple of the lot sizes of both its exchanges
+func (p *PositionSizer) SizeArbitrage(o *domainservice.ArbitrageOpportunity) (domainservice.PositionSize, error) {
+	equity := p.cfg.AccountSize
+	if p.equity != nil {
+		equity = p.equity.Equity()
+	}
+
+	limits := []domainservice.SizeLimit{
+		{Name: domainservice.SizeLimitAccount, Notional: equity * p.cfg.AccountPct / 100},
+		{Name: domainservice.SizeLimitLiquidity, Notional: o.MaxVolume * o.BuyPrice * p.cfg.LiquidityPct / 100},
+	}
+
+	if p.volatility != nil && p.cfg.RiskLimitPct > 0 {
+		buyVol, buyOK := p.volatility.Volatility(o.BuyExchange, o.Symbol, p.cfg.VolatilityHorizon)
+		sellVol, sellOK := p.volatility.Volatility(o.SellExchange, o.Symbol, p.cfg.VolatilityHorizon)
+		if vol := math.Max(buyVol, sellVol); (buyOK || sellOK) && vol > 0 {
+			limits = append(limits, domainservice.SizeLimit{
+				Name:     domainservice.SizeLimitVolatility,
+				Notional: equity * p.cfg.RiskLimitPct / vol,
+			})
+		}
+	}
+
+	lotSize := 0.0
+	if p.account != nil {
+		// Buying spends the quote on one exchange and selling the base on the
+		// other
+		if base, quote := splitSymbol(o.Symbol); quote != "" {
+			if balance, ok := p.account.Balance(o.BuyExchange, quote); ok {
+				limits = append(limits, domainservice.SizeLimit{Name: domainservice.SizeLimitQuoteBalance, Notional: balance})
+			}
+			if balance, ok := p.account.Balance(o.SellExchange, base); ok {
+				limits = append(limits, domainservice.SizeLimit{Name: domainservice.SizeLimitBaseBalance, Notional: balance * o.BuyPrice})
+			}
+		}
+		lot, err := domainservice.CommonLotSize(p.account.LotSize(o.BuyExchange, o.Symbol), p.account.LotSize(o.SellExchange, o.Symbol))
+		if err != nil {
+			return domainservice.PositionSize{}, fmt.Errorf("failed to size %s opportunity: %w", o.Symbol, err)
+		}
+		lotSize = lot
+	}
+
+	size, err := domainservice.CalculatePositionSize(limits, o.BuyPrice, lotSize)
+	if err != nil {
+		return domainservice.PositionSize{}, fmt.Errorf("failed to size %s opportunity: %w", o.Symbol, err)
+	}
+	return size, nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	domainservice "marketdata/internal/domain/service"
+)
+
+type fixedEquity float64
+
+func (e fixedEquity) Equity() float64 { return float64(e) }
+
+// fixedVolatility is the volatility of every symbol of an exchange at any
+// horizon
+type fixedVolatility map[string]float64
+
+func (v fixedVolatility) Volatility(exchangeID, symbol string, horizon time.Duration) (float64, bool) {
+	vol, ok := v[exchangeID]
+	return vol, ok
+}
+
+// fixedAccount has the lot sizes of every symbol of an exchange and no
+// known balances
+type fixedAccount map[string]float64
+
+func (a fixedAccount) Balance(exchangeID, asset string) (float64, bool) { return 0, false }
+
+func (a fixedAccount) LotSize(exchangeID, symbol string) float64 { return a[exchangeID] }
+
+func TestPositionSizerSizesArbitrage(t *testing.T) {
+	// The worked example of the README: with 100000 of equity, a 0.1% risk
+	// limit and a 2% volatility, the position may be worth 5000
+	cfg := PositionSizingConfig{
+		AccountPct:        10,
+		LiquidityPct:      50,
+		RiskLimitPct:      0.1,
+		VolatilityHorizon: 24 * time.Hour,
+	}
+	volatility := fixedVolatility{"a": 1.5, "b": 2}
+
+	tests := []struct {
+		name     string
+		lots     fixedAccount
+		price    float64
+		maxVol   float64
+		quantity float64
+		binding  string
+	}{
+		// 5000 / 50450 is 0.0991, rounded down to the 0.001 lot of a
+		{name: "worked example", lots: fixedAccount{"a": 0.001, "b": 0.00001}, price: 50450, maxVol: 1, quantity: 0.099, binding: domainservice.SizeLimitVolatility},
+		// 5000 / 5000 is 1, which holds one 0.6 lot of both. 0.9, three of
+		// the coarser 0.3 lot, is not a whole number of 0.2 lots.
+		{name: "lots that are not multiples", lots: fixedAccount{"a": 0.3, "b": 0.2}, price: 5000, maxVol: 10, quantity: 0.6, binding: domainservice.SizeLimitVolatility},
+		// The liquidity limit binds on a thin book
+		{name: "thin book", lots: fixedAccount{}, price: 50450, maxVol: 0.1, quantity: 0.05, binding: domainservice.SizeLimitLiquidity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sizer := NewPositionSizer(cfg, fixedEquity(100000), tt.lots, volatility)
+			size, err := sizer.SizeArbitrage(&domainservice.ArbitrageOpportunity{
+				BuyExchange: "a", SellExchange: "b", Symbol: "BTC-USDT", BuyPrice: tt.price, MaxVolume: tt.maxVol,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !near(size.Quantity, tt.quantity) || size.Binding != tt.binding {
+				t.Fatalf("sized %v bound by %s, want %v bound by %s", size.Quantity, size.Binding, tt.quantity, tt.binding)
+			}
+		})
+	}
+}
@@ -0,0 +1,551 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+	domainservice "marketdata/internal/domain/service"
+)
+
+var _ input.RiskUseCase = (*RiskManager)(nil)
+
+// ExecutionGate tells whether opportunities may be executed
+type ExecutionGate interface {
+	Halted() bool
+}
+
+// FeeScheduleProvider gives the fee schedule of an exchange
+type FeeScheduleProvider interface {
+	Schedule(exchangeID string) *entity.FeeSchedule
+}
+
+// RiskConfig tunes the risk manager
+type RiskConfig struct {
+	Limits domainservice.RiskLimits
+	// AccountSize is the equity, in the quote currency, before any PnL
+	AccountSize float64
+	// DailyReset is the time after midnight in Location at which the day,
+	// and with it the daily loss, restarts
+	DailyReset time.Duration
+	Location   *time.Location
+	// SaveInterval is how often the state is saved when only the marks
+	// moved; fills, halts and new days save it at once
+	SaveInterval time.Duration
+}
+
+type riskPosition struct {
+	position domainservice.Position
+	mark     float64
+}
+
+// RiskManager tracks the realized and unrealized PnL of the account from the
+// fills reported to it and the mid prices of the streamed books, and holds
+// it to the per-trade, daily loss and drawdown limits. Breaching a limit
+// engages the kill switch: every trade check is rejected and the gate closes
+// until Resume, or until the next day for the per-trade and daily limits.
+// The state is saved to the store, when given.
+type RiskManager struct {
+	cfg    RiskConfig
+	fees   FeeScheduleProvider
+	store  output.RiskStatePort
+	logger Logger
+	now    func() time.Time
+
+	mu             sync.Mutex
+	positions      map[feedKey]*riskPosition
+	realized       float64
+	feesPaid       float64
+	dayStart       time.Time
+	dayStartEquity float64
+	peakEquity     float64
+	halt           *dto.RiskHaltDTO
+	dirty          bool
+	stop           context.CancelFunc
+	running        chan struct{}
+}
+
+func NewRiskManager(
+	cfg RiskConfig,
+	fees FeeScheduleProvider,
+	store output.RiskStatePort,
+	logger Logger,
+) *RiskManager {
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+	return &RiskManager{
+		cfg:            cfg,
+		fees:           fees,
+		store:          store,
+		logger:         logger,
+		now:            time.Now,
+		positions:      make(map[feedKey]*riskPosition),
+		dayStartEquity: cfg.AccountSize,
+		peakEquity:     cfg.AccountSize,
+	}
+}
+
+// Start restores the saved state and restarts the day on its boundary until
+// Stop
+func (m *RiskManager) Start(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stop != nil {
+		return
+	}
+	if err := m.restore(ctx); err != nil {
+		m.logger.Error("failed to restore risk state", "error", err)
+	}
+	m.rollDay(m.now())
+
+	runCtx, stop := context.WithCancel(ctx)
+	m.stop = stop
+	m.running = make(chan struct{})
+	go m.run(runCtx)
+}
+
+// Stop ends the background work and saves the state
+func (m *RiskManager) Stop() {
+	m.mu.Lock()
+	stop, running := m.stop, m.running
+	m.stop = nil
+	m.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	stop()
+	<-running
+	m.save(context.Background())
+}
+
+// Halted reports whether the kill switch is engaged
+func (m *RiskManager) Halted() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rollDay(m.now())
+	return m.halt != nil
+}
+
+// Equity is the account size plus the realized and unrealized PnL
+func (m *RiskManager) Equity() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.equity()
+}
+
+// ProcessOrderBookUpdate marks the positions of the book's symbol at its mid
+// price and checks the account limits
+func (m *RiskManager) ProcessOrderBookUpdate(ctx context.Context, update *dto.OrderBookDTO) error {
+	if len(update.Bids) == 0 || len(update.Asks) == 0 {
+		return nil
+	}
+	key := feedKey{exchangeID: update.ExchangeID, symbol: update.Symbol}
+	mid := (update.Bids[0].Price + update.Asks[0].Price) / 2
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.positions[key]
+	if !ok {
+		return nil
+	}
+	// The day starts from the equity before the move
+	m.rollDay(m.now())
+	p.mark = mid
+	m.evaluate()
+	return nil
+}
+
+// CheckTrade approves a trade unless the kill switch is engaged or losing
+// its MaxLoss and taker fee would breach a limit
+func (m *RiskManager) CheckTrade(ctx context.Context, check *dto.TradeCheckDTO) (*dto.TradeCheckResultDTO, error) {
+	if err := validateOrder(check.ExchangeID, check.Symbol, check.Side, check.Quantity, check.Price); err != nil {
+		return nil, err
+	}
+	if check.MaxLoss < 0 {
+		return nil, fmt.Errorf("%w: max_loss must not be negative", input.ErrInvalidQuery)
+	}
+	fee := m.fees.Schedule(check.ExchangeID).Fee(check.Symbol, entity.LiquidityTaker, check.Quantity*check.Price)
+	result := &dto.TradeCheckResultDTO{Fee: fee, MaxLoss: check.MaxLoss + fee}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rollDay(m.now())
+	if m.halt != nil {
+		result.Limit = m.halt.Limit
+		result.Reason = "kill switch engaged: " + m.halt.Reason
+		return result, nil
+	}
+	if breach := domainservice.CheckTradeRisk(m.cfg.Limits, m.account(), result.MaxLoss); breach != nil {
+		result.Limit = breach.Limit
+		result.Reason = breach.Error()
+		return result, nil
+	}
+	result.Approved = true
+	return result, nil
+}
+
+// RecordFill applies a fill to its position, pays its fee from the schedule
+// of the exchange and checks the limits. A fill that closes part of a
+// position for a loss beyond the per-trade limit engages the kill switch.
+func (m *RiskManager) RecordFill(ctx context.Context, fill *dto.FillDTO) (*dto.FillResultDTO, error) {
+	if err := validateOrder(fill.ExchangeID, fill.Symbol, fill.Side, fill.Quantity, fill.Price); err != nil {
+		return nil, err
+	}
+	liquidity := entity.LiquidityTaker
+	switch entity.Liquidity(strings.ToLower(fill.Liquidity)) {
+	case "", entity.LiquidityTaker:
+	case entity.LiquidityMaker:
+		liquidity = entity.LiquidityMaker
+	default:
+		return nil, fmt.Errorf("%w: liquidity must be maker or taker, got %q", input.ErrInvalidQuery, fill.Liquidity)
+	}
+	quantity := fill.Quantity
+	if strings.EqualFold(fill.Side, string(entity.TradeSell)) {
+		quantity = -quantity
+	}
+	fee := m.fees.Schedule(fill.ExchangeID).Fee(fill.Symbol, liquidity, fill.Quantity*fill.Price)
+
+	m.mu.Lock()
+	m.rollDay(m.now())
+	equity := m.equity()
+
+	key := feedKey{exchangeID: fill.ExchangeID, symbol: fill.Symbol}
+	p, ok := m.positions[key]
+	if !ok {
+		p = &riskPosition{mark: fill.Price}
+		m.positions[key] = p
+	}
+	var realized float64
+	p.position, realized = p.position.Fill(quantity, fill.Price)
+	realized -= fee
+	m.realized += realized
+	m.feesPaid += fee
+	if p.position.Quantity == 0 {
+		delete(m.positions, key)
+	}
+
+	if limit := m.cfg.Limits.PerTradePct; limit > 0 && realized < 0 && equity > 0 {
+		if lossPct := -realized / equity * 100; lossPct > limit {
+			breach := &domainservice.RiskBreach{Limit: domainservice.RiskLimitPerTrade, LossPct: lossPct, LimitPct: limit}
+			m.trip(breach.Limit, fmt.Sprintf("%s on %s %s", breach.Error(), fill.ExchangeID, fill.Symbol))
+		}
+	}
+	m.evaluate()
+	m.dirty = true
+
+	result := &dto.FillResultDTO{
+		Position:    m.positionDTO(key, p),
+		RealizedPnL: realized,
+		Fee:         fee,
+		Halt:        m.halt,
+	}
+	m.mu.Unlock()
+
+	m.save(ctx)
+	return result, nil
+}
+
+// GetRiskStatus returns the PnL of the account against its limits
+func (m *RiskManager) GetRiskStatus(ctx context.Context) *dto.RiskStatusDTO {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rollDay(m.now())
+	return m.status()
+}
+
+// Halt engages the kill switch until Resume
+func (m *RiskManager) Halt(ctx context.Context, reason string) *dto.RiskStatusDTO {
+	if reason == "" {
+		reason = "halted by operator"
+	}
+
+	m.mu.Lock()
+	m.halt = nil
+	m.trip(dto.RiskLimitManual, reason)
+	status := m.status()
+	m.mu.Unlock()
+
+	m.save(ctx)
+	return status
+}
+
+// Resume releases the kill switch and restarts the drawdown from the current
+// equity. A daily loss still beyond its limit engages it again at once.
+func (m *RiskManager) Resume(ctx context.Context) *dto.RiskStatusDTO {
+	m.mu.Lock()
+	if m.halt != nil {
+		m.logger.Info("risk kill switch released", "limit", m.halt.Limit)
+	}
+	m.halt = nil
+	m.peakEquity = m.equity()
+	m.dirty = true
+	m.rollDay(m.now())
+	m.evaluate()
+	status := m.status()
+	m.mu.Unlock()
+
+	m.save(ctx)
+	return status
+}
+
+func (m *RiskManager) run(ctx context.Context) {
+	defer close(m.running)
+
+	var saves <-chan time.Time
+	if m.store != nil && m.cfg.SaveInterval > 0 {
+		saveTicker := time.NewTicker(m.cfg.SaveInterval)
+		defer saveTicker.Stop()
+		saves = saveTicker.C
+	}
+
+	for {
+		m.mu.Lock()
+		now := m.now()
+		nextDay := m.dayStartOf(now).AddDate(0, 0, 1)
+		m.mu.Unlock()
+		dayTimer := time.NewTimer(nextDay.Sub(now))
+
+		select {
+		case <-ctx.Done():
+			dayTimer.Stop()
+			return
+		case <-dayTimer.C:
+			m.mu.Lock()
+			m.rollDay(m.now())
+			m.mu.Unlock()
+			m.save(ctx)
+		case <-saves:
+			dayTimer.Stop()
+			m.save(ctx)
+		}
+	}
+}
+
+// rollDay starts a new day once now is past the boundary: the daily loss
+// restarts from the current equity and a halt on the per-trade or daily
+// limit is released. It is called with mu held.
+func (m *RiskManager) rollDay(now time.Time) {
+	dayStart := m.dayStartOf(now)
+	if !dayStart.After(m.dayStart) {
+		return
+	}
+	m.dayStart = dayStart
+	m.dayStartEquity = m.equity()
+	m.dirty = true
+	if m.halt != nil && (m.halt.Limit == domainservice.RiskLimitPerTrade || m.halt.Limit == domainservice.RiskLimitDailyLoss) {
+		m.logger.Info("risk kill switch released for the new day", "limit", m.halt.Limit)
+		m.halt = nil
+	}
+}
+
+// dayStartOf returns the latest day boundary at or before t. The reset is
+// a wall clock time, so it stays put when daylight saving time starts or
+// ends.
+func (m *RiskManager) dayStartOf(t time.Time) time.Time {
+	local := t.In(m.cfg.Location)
+	reset := func(day time.Time) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, int(m.cfg.DailyReset), m.cfg.Location)
+	}
+	if start := reset(local); !start.After(t) {
+		return start
+	}
+	return reset(local.AddDate(0, 0, -1))
+}
+
+// evaluate raises the peak equity and engages the kill switch when the
+// account is beyond its daily loss or drawdown limit. It is called with mu
+// held.
+func (m *RiskManager) evaluate() {
+	if equity := m.equity(); equity > m.peakEquity {
+		m.peakEquity = equity
+		m.dirty = true
+	}
+	if breach := domainservice.CheckRiskLimits(m.cfg.Limits, m.account()); breach != nil {
+		m.trip(breach.Limit, breach.Error())
+	}
+}
+
+// trip engages the kill switch unless it already is. It is called with mu
+// held.
+func (m *RiskManager) trip(limit, reason string) {
+	if m.halt != nil {
+		return
+	}
+	m.halt = &dto.RiskHaltDTO{Limit: limit, Reason: reason, At: m.now()}
+	m.dirty = true
+	m.logger.Error("risk kill switch engaged, halting execution", "limit", limit, "reason", reason)
+}
+
+// equity is called with mu held
+func (m *RiskManager) equity() float64 {
+	return m.cfg.AccountSize + m.realized + m.unrealized()
+}
+
+// unrealized is called with mu held
+func (m *RiskManager) unrealized() float64 {
+	var pnl float64
+	for _, p := range m.positions {
+		pnl += p.position.UnrealizedPnL(p.mark)
+	}
+	return pnl
+}
+
+// account is called with mu held
+func (m *RiskManager) account() domainservice.AccountRisk {
+	return domainservice.AccountRisk{
+		Equity:         m.equity(),
+		DayStartEquity: m.dayStartEquity,
+		PeakEquity:     m.peakEquity,
+	}
+}
+
+// status is called with mu held
+func (m *RiskManager) status() *dto.RiskStatusDTO {
+	account := m.account()
+	status := &dto.RiskStatusDTO{
+		AccountSize:    m.cfg.AccountSize,
+		Equity:         account.Equity,
+		RealizedPnL:    m.realized,
+		UnrealizedPnL:  m.unrealized(),
+		FeesPaid:       m.feesPaid,
+		DayStart:       m.dayStart,
+		DayStartEquity: m.dayStartEquity,
+		DailyPnL:       account.Equity - m.dayStartEquity,
+		DailyLossPct:   account.DailyLossPct(),
+		PeakEquity:     m.peakEquity,
+		DrawdownPct:    account.DrawdownPct(),
+		Limits: dto.RiskLimitsDTO{
+			PerTradePct:    m.cfg.Limits.PerTradePct,
+			DailyLossPct:   m.cfg.Limits.DailyLossPct,
+			MaxDrawdownPct: m.cfg.Limits.MaxDrawdownPct,
+		},
+		Halted:    m.halt != nil,
+		Halt:      m.halt,
+		Positions: m.positionDTOs(),
+	}
+	return status
+}
+
+// positionDTOs is called with mu held
+func (m *RiskManager) positionDTOs() []dto.RiskPositionDTO {
+	positions := make([]dto.RiskPositionDTO, 0, len(m.positions))
+	for key, p := range m.positions {
+		positions = append(positions, m.positionDTO(key, p))
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].ExchangeID != positions[j].ExchangeID {
+			return positions[i].ExchangeID < positions[j].ExchangeID
+		}
+		return positions[i].Symbol < positions[j].Symbol
+	})
+	return positions
+}
+
+func (m *RiskManager) positionDTO(key feedKey, p *riskPosition) dto.RiskPositionDTO {
+	return dto.RiskPositionDTO{
+		ExchangeID:    key.exchangeID,
+		Symbol:        key.symbol,
+		Quantity:      p.position.Quantity,
+		AvgPrice:      p.position.AvgPrice,
+		MarkPrice:     p.mark,
+		UnrealizedPnL: p.position.UnrealizedPnL(p.mark),
+	}
+}
+
+// save writes the state when it changed since the last save
+func (m *RiskManager) save(ctx context.Context) {
+	if m.store == nil {
+		return
+	}
+
+	m.mu.Lock()
+	if !m.dirty {
+		m.mu.Unlock()
+		return
+	}
+	state := &dto.RiskStateDTO{
+		AccountSize:    m.cfg.AccountSize,
+		RealizedPnL:    m.realized,
+		FeesPaid:       m.feesPaid,
+		DayStart:       m.dayStart,
+		DayStartEquity: m.dayStartEquity,
+		PeakEquity:     m.peakEquity,
+		Halt:           m.halt,
+		Positions:      m.positionDTOs(),
+		SavedAt:        m.now(),
+	}
+	m.dirty = false
+	m.mu.Unlock()
+
+	if err := m.store.SaveRiskState(ctx, state); err != nil {
+		m.logger.Error("failed to save risk state", "error", err)
+		m.mu.Lock()
+		m.dirty = true
+		m.mu.Unlock()
+	}
+}
+
+// restore loads the saved state. A changed account size moves the day start
+// and peak equity with it. It is called with mu held.
+func (m *RiskManager) restore(ctx context.Context) error {
+	if m.store == nil {
+		return nil
+	}
+	state, err := m.store.LoadRiskState(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if state == nil {
+		return nil
+	}
+
+	resized := m.cfg.AccountSize - state.AccountSize
+	m.realized = state.RealizedPnL
+	m.feesPaid = state.FeesPaid
+	m.dayStart = state.DayStart
+	m.dayStartEquity = state.DayStartEquity + resized
+	m.peakEquity = state.PeakEquity + resized
+	m.halt = state.Halt
+	for _, p := range state.Positions {
+		m.positions[feedKey{exchangeID: p.ExchangeID, symbol: p.Symbol}] = &riskPosition{
+			position: domainservice.Position{Quantity: p.Quantity, AvgPrice: p.AvgPrice},
+			mark:     p.MarkPrice,
+		}
+	}
+	m.logger.Info("restored risk state",
+		"positions", len(state.Positions),
+		"realized_pnl", state.RealizedPnL,
+		"halted", state.Halt != nil,
+	)
+	return nil
+}
+
+func validateOrder(exchangeID, symbol, side string, quantity, price float64) error {
+	switch {
+	case exchangeID == "" || symbol == "":
+		return fmt.Errorf("%w: exchange_id and symbol are required", input.ErrInvalidQuery)
+	case !strings.EqualFold(side, string(entity.TradeBuy)) && !strings.EqualFold(side, string(entity.TradeSell)):
+		return fmt.Errorf("%w: side must be BUY or SELL, got %q", input.ErrInvalidQuery, side)
+	case !(quantity > 0) || math.IsInf(quantity, 0):
+		return fmt.Errorf("%w: quantity must be positive", input.ErrInvalidQuery)
+	case !(price > 0) || math.IsInf(price, 0):
+		return fmt.Errorf("%w: price must be positive", input.ErrInvalidQuery)
+	}
+	return nil
+}
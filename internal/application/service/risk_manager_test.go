@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/domain/entity"
+	domainservice "marketdata/internal/domain/service"
+	"marketdata/internal/infrastructure/persistence/statefile"
+	"marketdata/pkg/logger"
+)
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s: %v", name, err)
+	}
+	return location
+}
+
+// riskFees charges takers 0.1% and makers nothing
+func riskFees(t *testing.T) *FeeService {
+	t.Helper()
+	fees, err := NewFeeService(entity.FeeRates{Taker: 0.001}, nil, nil, logger.NewLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fees
+}
+
+func makerFill(side string, quantity, price float64) *dto.FillDTO {
+	return &dto.FillDTO{ExchangeID: "a", Symbol: "BTC-USDT", Side: side, Quantity: quantity, Price: price, Liquidity: "maker"}
+}
+
+func TestRiskManagerDayStart(t *testing.T) {
+	newYork := loadLocation(t, "America/New_York")
+	tokyo := loadLocation(t, "Asia/Tokyo")
+
+	tests := []struct {
+		name     string
+		location *time.Location
+		reset    time.Duration
+		now      time.Time
+		want     time.Time
+	}{
+		{
+			name:     "midnight utc",
+			location: time.UTC,
+			now:      time.Date(2026, 3, 8, 23, 59, 0, 0, time.UTC),
+			want:     time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// Tokyo is already on the 9th
+			name:     "midnight in tokyo",
+			location: tokyo,
+			now:      time.Date(2026, 3, 8, 16, 0, 0, 0, time.UTC),
+			want:     time.Date(2026, 3, 8, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			// 12:00 in New York is before the reset, so the day began the
+			// evening before, still on standard time
+			name:     "before the reset",
+			location: newYork,
+			reset:    17 * time.Hour,
+			now:      time.Date(2026, 3, 8, 16, 0, 0, 0, time.UTC),
+			want:     time.Date(2026, 3, 7, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			// Daylight saving time began that morning: 17:00 is 21:00 UTC
+			name:     "after the reset on daylight saving time",
+			location: newYork,
+			reset:    17 * time.Hour,
+			now:      time.Date(2026, 3, 8, 21, 0, 0, 0, time.UTC),
+			want:     time.Date(2026, 3, 8, 21, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			risk := NewRiskManager(RiskConfig{DailyReset: tt.reset, Location: tt.location}, riskFees(t), nil, logger.NewLogger())
+			if got := risk.dayStartOf(tt.now); !got.Equal(tt.want) {
+				t.Errorf("day of %v started at %v, want %v", tt.now, got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRiskManagerPerTradeLossHaltsUntilTheNextDay(t *testing.T) {
+	ctx := context.Background()
+	// The day resets at 17:00 in New York, on the day clocks go forward
+	now := time.Date(2026, 3, 8, 16, 0, 0, 0, time.UTC)
+	risk := NewRiskManager(RiskConfig{
+		Limits:      domainservice.RiskLimits{PerTradePct: 0.2},
+		AccountSize: 100000,
+		DailyReset:  17 * time.Hour,
+		Location:    loadLocation(t, "America/New_York"),
+	}, riskFees(t), nil, logger.NewLogger())
+	risk.now = func() time.Time { return now }
+	risk.Start(ctx)
+	defer risk.Stop()
+
+	check := func(maxLoss float64) *dto.TradeCheckResultDTO {
+		t.Helper()
+		result, err := risk.CheckTrade(ctx, &dto.TradeCheckDTO{
+			ExchangeID: "a", Symbol: "BTC-USDT", Side: "BUY", Quantity: 0.1, Price: 50000, MaxLoss: maxLoss,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	// The 5 taker fee counts towards the 200 a trade may lose
+	if result := check(150); !result.Approved || !near(result.Fee, 5) || !near(result.MaxLoss, 155) {
+		t.Fatalf("check within the limit: %+v", result)
+	}
+	if result := check(196); result.Approved || result.Limit != domainservice.RiskLimitPerTrade {
+		t.Fatalf("check beyond the limit: %+v", result)
+	}
+	if risk.Halted() {
+		t.Fatal("a rejected check engaged the kill switch")
+	}
+
+	// Closing 1 BTC for a 300 loss is 0.3% of the equity
+	for _, fill := range []*dto.FillDTO{makerFill("BUY", 1, 50000), makerFill("SELL", 1, 49700)} {
+		if _, err := risk.RecordFill(ctx, fill); err != nil {
+			t.Fatal(err)
+		}
+	}
+	status := risk.GetRiskStatus(ctx)
+	if !status.Halted || status.Halt.Limit != domainservice.RiskLimitPerTrade || !near(status.RealizedPnL, -300) {
+		t.Fatalf("after the losing trade: halted %v by %+v, realized %v", status.Halted, status.Halt, status.RealizedPnL)
+	}
+	if result := check(0); result.Approved || result.Limit != domainservice.RiskLimitPerTrade {
+		t.Fatalf("check while halted: %+v", result)
+	}
+
+	// Still halted a minute before 17:00 EDT, released at it
+	now = time.Date(2026, 3, 8, 20, 59, 0, 0, time.UTC)
+	if !risk.Halted() {
+		t.Fatal("released before the day ended")
+	}
+	now = time.Date(2026, 3, 8, 21, 0, 0, 0, time.UTC)
+	if risk.Halted() {
+		t.Fatal("still halted on the new day")
+	}
+	if status := risk.GetRiskStatus(ctx); !status.DayStart.Equal(now) || !near(status.DayStartEquity, 99700) {
+		t.Fatalf("new day started at %v with %v", status.DayStart, status.DayStartEquity)
+	}
+}
+
+func TestRiskManagerAccountLimitsAndRestore(t *testing.T) {
+	ctx := context.Background()
+	store := statefile.NewRiskStore(filepath.Join(t.TempDir(), "risk.json"))
+	cfg := RiskConfig{
+		Limits:      domainservice.RiskLimits{DailyLossPct: 1, MaxDrawdownPct: 2},
+		AccountSize: 100000,
+		Location:    time.UTC,
+	}
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	newRisk := func() *RiskManager {
+		risk := NewRiskManager(cfg, riskFees(t), store, logger.NewLogger())
+		risk.now = func() time.Time { return now }
+		risk.Start(ctx)
+		return risk
+	}
+	mark := func(risk *RiskManager, mid float64) *dto.RiskStatusDTO {
+		t.Helper()
+		if err := risk.ProcessOrderBookUpdate(ctx, midBook("a", "BTC-USDT", mid)); err != nil {
+			t.Fatal(err)
+		}
+		return risk.GetRiskStatus(ctx)
+	}
+
+	risk := newRisk()
+	if _, err := risk.RecordFill(ctx, makerFill("BUY", 2, 50000)); err != nil {
+		t.Fatal(err)
+	}
+	// Up to a peak of 101200, then back to 99300: 0.7% down on the day and
+	// 1.88% from the peak
+	mark(risk, 50600)
+	if status := mark(risk, 49650); status.Halted || !near(status.PeakEquity, 101200) || !near(status.Equity, 99300) {
+		t.Fatalf("within the limits: halted %v, peak %v, equity %v", status.Halted, status.PeakEquity, status.Equity)
+	}
+
+	// The next day starts from 99300, so 98200 is a 1.1% daily loss
+	now = time.Date(2026, 1, 3, 0, 30, 0, 0, time.UTC)
+	status := mark(risk, 49100)
+	if !status.Halted || status.Halt.Limit != domainservice.RiskLimitDailyLoss || !near(status.DayStartEquity, 99300) {
+		t.Fatalf("daily loss: halted %v by %+v from %v", status.Halted, status.Halt, status.DayStartEquity)
+	}
+	risk.Stop()
+
+	// A restart picks up the halt, the position and the day
+	risk = newRisk()
+	status = risk.GetRiskStatus(ctx)
+	if !status.Halted || status.Halt.Limit != domainservice.RiskLimitDailyLoss {
+		t.Fatalf("restored halt %+v", status.Halt)
+	}
+	if len(status.Positions) != 1 || status.Positions[0].Quantity != 2 || !near(status.Positions[0].MarkPrice, 49100) {
+		t.Fatalf("restored positions %+v", status.Positions)
+	}
+	if !near(status.Equity, 98200) || !near(status.DayStartEquity, 99300) || !near(status.PeakEquity, 101200) {
+		t.Fatalf("restored equity %v, day start %v, peak %v", status.Equity, status.DayStartEquity, status.PeakEquity)
+	}
+
+	// The new day releases the daily loss halt, but 2.96% from the peak is
+	// a drawdown that the day does not reset
+	now = time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)
+	if risk.Halted() {
+		t.Fatal("daily loss halt outlived the day")
+	}
+	if status := mark(risk, 49100); !status.Halted || status.Halt.Limit != domainservice.RiskLimitMaxDrawdown {
+		t.Fatalf("drawdown: halted %v by %+v", status.Halted, status.Halt)
+	}
+	now = time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	if !risk.Halted() {
+		t.Fatal("drawdown halt released by a new day")
+	}
+
+	// Resume restarts the drawdown from the current equity
+	if status := risk.Resume(ctx); status.Halted || !near(status.PeakEquity, 98200) {
+		t.Fatalf("resumed: halted %v by %+v, peak %v", status.Halted, status.Halt, status.PeakEquity)
+	}
+	risk.Stop()
+
+	// A larger account moves the restored day start and peak with it
+	cfg.AccountSize = 200000
+	risk = newRisk()
+	defer risk.Stop()
+	if status := risk.GetRiskStatus(ctx); status.Halted || !near(status.Equity, 198200) || !near(status.PeakEquity, 198200) {
+		t.Fatalf("resized: halted %v, equity %v, peak %v", status.Halted, status.Equity, status.PeakEquity)
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"marketdata/internal/application/port/output"
+)
+
+const (
+	defaultSnapshotInterval = time.Minute
+	defaultSnapshotDepth    = 50
+)
+
+// SnapshotArchiver periodically samples every live orderbook and stores it in
+// the snapshot repository, so depth can be analyzed after the live copy expires
+type SnapshotArchiver struct {
+	orderbookRepo output.OrderBookRepositoryPort
+	snapshotRepo  output.OrderBookSnapshotRepositoryPort
+	interval      time.Duration
+	depth         int
+	logger        Logger
+}
+
+func NewSnapshotArchiver(
+	orderbookRepo output.OrderBookRepositoryPort,
+	snapshotRepo output.OrderBookSnapshotRepositoryPort,
+	interval time.Duration,
+	depth int,
+	logger Logger,
+) *SnapshotArchiver {
+	if interval <= 0 {
+		interval = defaultSnapshotInterval
+	}
+	if depth <= 0 {
+		depth = defaultSnapshotDepth
+	}
+
+	return &SnapshotArchiver{
+		orderbookRepo: orderbookRepo,
+		snapshotRepo:  snapshotRepo,
+		interval:      interval,
+		depth:         depth,
+		logger:        logger,
+	}
+}
+
+// Run samples books every interval until ctx is done
+func (a *SnapshotArchiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.archive(ctx)
+		}
+	}
+}
+
+// archive stores one snapshot of each book. Failures are logged and skipped so
+// one bad book does not stop the others from being archived.
+func (a *SnapshotArchiver) archive(ctx context.Context) {
+	orderbooks, err := a.orderbookRepo.GetAll(ctx)
+	if err != nil {
+		a.logger.Error("failed to load orderbooks for archival", "error", err)
+		return
+	}
+
+	for _, orderbook := range orderbooks {
+		if err := a.snapshotRepo.StoreSnapshot(ctx, orderbook, a.depth); err != nil {
+			a.logger.Error("failed to archive orderbook snapshot",
+				"error", err,
+				"exchange", orderbook.ExchangeID(),
+				"symbol", orderbook.Symbol(),
+			)
+		}
+	}
+}
@@ -0,0 +1,460 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+	"marketdata/internal/application/port/output"
+	domainservice "marketdata/internal/domain/service"
+)
+
+const (
+	statArbPublishTimeout = 5 * time.Second
+	statArbPublishQueue   = 256
+)
+
+var _ input.StatisticalArbitrageUseCase = (*StatArbEngine)(nil)
+
+// StatArbPairConfig is one pair traded on the spread between two instruments,
+// which may be the same symbol on two exchanges or two related symbols
+type StatArbPairConfig struct {
+	Name      string
+	ExchangeA string
+	SymbolA   string
+	ExchangeB string
+	SymbolB   string
+	// HedgeRatio is the units of B per unit of A when OLS is off
+	HedgeRatio float64
+	// OLS fits the hedge ratio over the window on every sample
+	OLS bool
+	// LogPrices computes the spread on the logarithm of the prices
+	LogPrices bool
+}
+
+// StatArbConfig tunes the statistical arbitrage engine
+type StatArbConfig struct {
+	Pairs []StatArbPairConfig
+	// Interval is how often the mid prices of each pair are sampled
+	Interval time.Duration
+	// Window is how many samples the statistics are computed over
+	Window int
+	// MinSamples is how many samples a pair needs before it signals
+	MinSamples int
+	// EntryZ is the absolute z-score that opens a position and ExitZ the one
+	// it closes at
+	EntryZ float64
+	ExitZ  float64
+	// SaveInterval is how often the state is saved; it is also saved on Stop
+	SaveInterval time.Duration
+	// MaxBookAge skips a sample when a leg has no update for longer; zero
+	// samples any book
+	MaxBookAge time.Duration
+}
+
+type statArbMid struct {
+	price    float64
+	received time.Time
+}
+
+type statArbPair struct {
+	cfg        StatArbPairConfig
+	samples    []dto.StatArbSampleDTO
+	stats      domainservice.SpreadStatistics
+	ready      bool
+	position   string
+	lastSignal *dto.StatArbSignalDTO
+	updatedAt  time.Time
+}
+
+// StatArbEngine samples the mid prices of configured pairs, keeps a rolling
+// window of them and computes the mean, standard deviation and z-score of
+// their spread. A pair enters a position when its z-score reaches EntryZ,
+// shorting the spread when it is high and buying it when it is low, and
+// exits when the z-score returns within ExitZ. Signals go to the publisher,
+// when given, from a queue of their own so a slow publisher does not hold up
+// sampling. The windows and positions are saved to the store, when given, so
+// a restart carries on from them.
+type StatArbEngine struct {
+	cfg       StatArbConfig
+	publisher output.StatArbSignalPublisherPort
+	store     output.StatArbStatePort
+	logger    Logger
+	now       func() time.Time
+
+	mu         sync.Mutex
+	mids       map[feedKey]statArbMid
+	pairs      []*statArbPair
+	stop       context.CancelFunc
+	running    chan struct{}
+	queue      chan *dto.StatArbSignalDTO
+	publishing chan struct{}
+}
+
+func NewStatArbEngine(
+	cfg StatArbConfig,
+	publisher output.StatArbSignalPublisherPort,
+	store output.StatArbStatePort,
+	logger Logger,
+) *StatArbEngine {
+	pairs := make([]*statArbPair, len(cfg.Pairs))
+	for i, pairCfg := range cfg.Pairs {
+		pairs[i] = &statArbPair{cfg: pairCfg, position: dto.StatArbFlat}
+	}
+	return &StatArbEngine{
+		cfg:       cfg,
+		publisher: publisher,
+		store:     store,
+		logger:    logger,
+		now:       time.Now,
+		mids:      make(map[feedKey]statArbMid),
+		pairs:     pairs,
+	}
+}
+
+// Start restores the saved state and samples the pairs until Stop
+func (e *StatArbEngine) Start(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stop != nil {
+		return
+	}
+	if err := e.restore(ctx); err != nil {
+		e.logger.Error("failed to restore statistical arbitrage state", "error", err)
+	}
+
+	runCtx, stop := context.WithCancel(ctx)
+	e.stop = stop
+	e.running = make(chan struct{})
+	e.queue = make(chan *dto.StatArbSignalDTO, statArbPublishQueue)
+	e.publishing = make(chan struct{})
+	go e.run(runCtx, e.queue)
+	// Publishing outlives ctx so the signals queued before Stop are delivered
+	go e.publish(context.WithoutCancel(ctx), e.queue, e.publishing)
+}
+
+// Stop ends sampling, saves the state and waits for the queued signals to be
+// published
+func (e *StatArbEngine) Stop() {
+	e.mu.Lock()
+	stop, running, queue, publishing := e.stop, e.running, e.queue, e.publishing
+	e.stop = nil
+	e.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	stop()
+	<-running
+	e.save(context.Background())
+	// Only run sends on the queue, so it can be closed once run is done
+	close(queue)
+	<-publishing
+}
+
+// ProcessOrderBookUpdate keeps the mid price of the symbols the pairs are
+// built from
+func (e *StatArbEngine) ProcessOrderBookUpdate(ctx context.Context, update *dto.OrderBookDTO) error {
+	if len(update.Bids) == 0 || len(update.Asks) == 0 {
+		return nil
+	}
+	key := feedKey{exchangeID: update.ExchangeID, symbol: update.Symbol}
+	mid := (update.Bids[0].Price + update.Asks[0].Price) / 2
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.isLeg(key) {
+		e.mids[key] = statArbMid{price: mid, received: e.now()}
+	}
+	return nil
+}
+
+// ListPairs returns the state of every configured pair
+func (e *StatArbEngine) ListPairs(ctx context.Context) []*dto.StatArbPairDTO {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	pairs := make([]*dto.StatArbPairDTO, len(e.pairs))
+	for i, p := range e.pairs {
+		pairs[i] = &dto.StatArbPairDTO{
+			Pair:       p.cfg.Name,
+			ExchangeA:  p.cfg.ExchangeA,
+			SymbolA:    p.cfg.SymbolA,
+			ExchangeB:  p.cfg.ExchangeB,
+			SymbolB:    p.cfg.SymbolB,
+			Samples:    len(p.samples),
+			Ready:      p.ready,
+			HedgeRatio: p.cfg.HedgeRatio,
+			Position:   p.position,
+			LastSignal: p.lastSignal,
+			UpdatedAt:  p.updatedAt,
+		}
+		if p.ready {
+			pairs[i].Mean = p.stats.Mean
+			pairs[i].StdDev = p.stats.StdDev
+			pairs[i].HedgeRatio = p.stats.HedgeRatio
+			pairs[i].Spread = p.stats.Spread
+			pairs[i].ZScore = p.stats.ZScore
+		}
+	}
+	return pairs
+}
+
+func (e *StatArbEngine) run(ctx context.Context, queue chan<- *dto.StatArbSignalDTO) {
+	defer close(e.running)
+
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+
+	var saves <-chan time.Time
+	if e.store != nil && e.cfg.SaveInterval > 0 {
+		saveTicker := time.NewTicker(e.cfg.SaveInterval)
+		defer saveTicker.Stop()
+		saves = saveTicker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, signal := range e.sample() {
+				e.emit(queue, signal)
+			}
+		case <-saves:
+			e.save(ctx)
+		}
+	}
+}
+
+// sample adds the current mid prices to every pair with fresh legs and
+// returns the signals they trigger
+func (e *StatArbEngine) sample() []*dto.StatArbSignalDTO {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	var signals []*dto.StatArbSignalDTO
+	for _, p := range e.pairs {
+		a, okA := e.freshMid(feedKey{exchangeID: p.cfg.ExchangeA, symbol: p.cfg.SymbolA}, now)
+		b, okB := e.freshMid(feedKey{exchangeID: p.cfg.ExchangeB, symbol: p.cfg.SymbolB}, now)
+		if !okA || !okB {
+			continue
+		}
+		p.samples = append(p.samples, dto.StatArbSampleDTO{Time: now, A: a, B: b})
+		if len(p.samples) > e.cfg.Window {
+			p.samples = p.samples[len(p.samples)-e.cfg.Window:]
+		}
+		p.updatedAt = now
+		signals = append(signals, e.evaluate(p, now)...)
+	}
+	return signals
+}
+
+// evaluate recomputes the statistics of a pair and moves its position. It is
+// called with mu held.
+func (e *StatArbEngine) evaluate(p *statArbPair, now time.Time) []*dto.StatArbSignalDTO {
+	p.ready = false
+	if len(p.samples) < e.cfg.MinSamples {
+		return nil
+	}
+	a := make([]float64, len(p.samples))
+	b := make([]float64, len(p.samples))
+	for i, sample := range p.samples {
+		a[i], b[i] = sample.A, sample.B
+		if p.cfg.LogPrices {
+			a[i], b[i] = math.Log(a[i]), math.Log(b[i])
+		}
+	}
+	stats, err := domainservice.CalculateSpreadStatistics(a, b, p.cfg.HedgeRatio, p.cfg.OLS)
+	if err != nil {
+		return nil
+	}
+	p.stats, p.ready = stats, true
+
+	var signals []*dto.StatArbSignalDTO
+	z := stats.ZScore
+	switch {
+	case p.position == dto.StatArbLongSpread && z >= -e.cfg.ExitZ,
+		p.position == dto.StatArbShortSpread && z <= e.cfg.ExitZ:
+		signals = append(signals, e.signal(p, dto.StatArbExit, p.position, now))
+		p.position = dto.StatArbFlat
+	}
+	if p.position == dto.StatArbFlat {
+		switch {
+		case z >= e.cfg.EntryZ:
+			p.position = dto.StatArbShortSpread
+		case z <= -e.cfg.EntryZ:
+			p.position = dto.StatArbLongSpread
+		default:
+			return signals
+		}
+		signals = append(signals, e.signal(p, dto.StatArbEntry, p.position, now))
+	}
+	return signals
+}
+
+func (e *StatArbEngine) signal(p *statArbPair, kind, position string, now time.Time) *dto.StatArbSignalDTO {
+	latest := p.samples[len(p.samples)-1]
+	p.lastSignal = &dto.StatArbSignalDTO{
+		Pair:       p.cfg.Name,
+		Type:       kind,
+		Position:   position,
+		ZScore:     p.stats.ZScore,
+		Spread:     p.stats.Spread,
+		Mean:       p.stats.Mean,
+		StdDev:     p.stats.StdDev,
+		HedgeRatio: p.stats.HedgeRatio,
+		PriceA:     latest.A,
+		PriceB:     latest.B,
+		Time:       now,
+	}
+	return p.lastSignal
+}
+
+// emit logs a signal and queues it for publishing, dropping it when the
+// queue is full
+func (e *StatArbEngine) emit(queue chan<- *dto.StatArbSignalDTO, signal *dto.StatArbSignalDTO) {
+	e.logger.Info("statistical arbitrage signal",
+		"pair", signal.Pair,
+		"type", signal.Type,
+		"position", signal.Position,
+		"z_score", signal.ZScore,
+	)
+	if e.publisher == nil {
+		return
+	}
+	select {
+	case queue <- signal:
+	default:
+		e.logger.Error("statistical arbitrage publish queue is full, dropping signal",
+			"pair", signal.Pair,
+			"type", signal.Type,
+		)
+	}
+}
+
+func (e *StatArbEngine) publish(ctx context.Context, queue <-chan *dto.StatArbSignalDTO, done chan<- struct{}) {
+	defer close(done)
+
+	for signal := range queue {
+		publishCtx, cancel := context.WithTimeout(ctx, statArbPublishTimeout)
+		err := e.publisher.PublishStatArbSignal(publishCtx, signal)
+		cancel()
+		if err != nil {
+			e.logger.Error("failed to publish statistical arbitrage signal",
+				"error", err,
+				"pair", signal.Pair,
+				"type", signal.Type,
+			)
+		}
+	}
+}
+
+func (e *StatArbEngine) save(ctx context.Context) {
+	if e.store == nil {
+		return
+	}
+
+	e.mu.Lock()
+	states := make([]*dto.StatArbStateDTO, len(e.pairs))
+	for i, p := range e.pairs {
+		states[i] = &dto.StatArbStateDTO{
+			Pair:       p.cfg.Name,
+			Legs:       statArbLegs(p.cfg),
+			Samples:    append([]dto.StatArbSampleDTO(nil), p.samples...),
+			Position:   p.position,
+			LastSignal: p.lastSignal,
+		}
+	}
+	e.mu.Unlock()
+
+	if err := e.store.SaveStatArbState(ctx, states); err != nil {
+		e.logger.Error("failed to save statistical arbitrage state", "error", err)
+	}
+}
+
+// restore loads the saved windows of pairs whose legs are unchanged, dropping
+// samples older than the window. It is called with mu held.
+func (e *StatArbEngine) restore(ctx context.Context) error {
+	if e.store == nil {
+		return nil
+	}
+	states, err := e.store.LoadStatArbState(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	saved := make(map[string]*dto.StatArbStateDTO, len(states))
+	for _, state := range states {
+		saved[state.Pair] = state
+	}
+	now := e.now()
+	oldest := now.Add(-time.Duration(e.cfg.Window) * e.cfg.Interval)
+	for _, p := range e.pairs {
+		state, ok := saved[p.cfg.Name]
+		if !ok || state.Legs != statArbLegs(p.cfg) {
+			continue
+		}
+		for _, sample := range state.Samples {
+			if sample.Time.After(oldest) {
+				p.samples = append(p.samples, sample)
+			}
+		}
+		if len(p.samples) > e.cfg.Window {
+			p.samples = p.samples[len(p.samples)-e.cfg.Window:]
+		}
+		if state.Position != "" {
+			p.position = state.Position
+		}
+		p.lastSignal = state.LastSignal
+		if len(p.samples) > 0 {
+			p.updatedAt = p.samples[len(p.samples)-1].Time
+			e.evaluateRestored(p)
+		}
+	}
+	e.logger.Info("restored statistical arbitrage state", "pairs", len(states))
+	return nil
+}
+
+// evaluateRestored recomputes the statistics of a restored pair without
+// moving its position, which the next sample does. It is called with mu
+// held.
+func (e *StatArbEngine) evaluateRestored(p *statArbPair) {
+	position, lastSignal := p.position, p.lastSignal
+	e.evaluate(p, p.updatedAt)
+	p.position, p.lastSignal = position, lastSignal
+}
+
+// freshMid returns the mid price of a leg unless it is older than MaxBookAge.
+// It is called with mu held.
+func (e *StatArbEngine) freshMid(key feedKey, now time.Time) (float64, bool) {
+	mid, ok := e.mids[key]
+	if !ok || (e.cfg.MaxBookAge > 0 && now.Sub(mid.received) > e.cfg.MaxBookAge) {
+		return 0, false
+	}
+	return mid.price, true
+}
+
+// isLeg is called with mu held
+func (e *StatArbEngine) isLeg(key feedKey) bool {
+	for _, p := range e.pairs {
+		if (p.cfg.ExchangeA == key.exchangeID && p.cfg.SymbolA == key.symbol) ||
+			(p.cfg.ExchangeB == key.exchangeID && p.cfg.SymbolB == key.symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+// statArbLegs identifies the instruments of a pair, so saved samples are not
+// restored into a pair whose legs changed
+func statArbLegs(cfg StatArbPairConfig) string {
+	return fmt.Sprintf("%s:%s/%s:%s", cfg.ExchangeA, cfg.SymbolA, cfg.ExchangeB, cfg.SymbolB)
+}
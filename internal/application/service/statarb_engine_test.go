@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/infrastructure/persistence/statefile"
+	"marketdata/pkg/logger"
+)
+
+func midBook(exchangeID, symbol string, mid float64) *dto.OrderBookDTO {
+	return &dto.OrderBookDTO{
+		ExchangeID: exchangeID,
+		Symbol:     symbol,
+		Bids:       []dto.PriceLevelDTO{{Price: mid - 0.5, Quantity: 1}},
+		Asks:       []dto.PriceLevelDTO{{Price: mid + 0.5, Quantity: 1}},
+	}
+}
+
+func TestStatArbEngineHysteresisAndRestore(t *testing.T) {
+	ctx := context.Background()
+	store := statefile.NewStatArbStore(filepath.Join(t.TempDir(), "statarb.json"))
+	cfg := StatArbConfig{
+		Pairs: []StatArbPairConfig{{
+			Name: "btc", ExchangeA: "a", SymbolA: "BTC-USDT", ExchangeB: "b", SymbolB: "BTC-USDT", HedgeRatio: 1,
+		}},
+		// Sampling is driven by the test; the ticker never fires
+		Interval:   time.Hour,
+		Window:     5,
+		MinSamples: 5,
+		EntryZ:     1.5,
+		ExitZ:      0.5,
+	}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	newEngine := func(cfg StatArbConfig) *StatArbEngine {
+		engine := NewStatArbEngine(cfg, nil, store, logger.NewLogger())
+		engine.now = func() time.Time { return now }
+		return engine
+	}
+
+	// step samples B at 100 and A at 100 plus spread
+	step := func(engine *StatArbEngine, spread float64) []*dto.StatArbSignalDTO {
+		t.Helper()
+		now = now.Add(time.Second)
+		for _, book := range []*dto.OrderBookDTO{midBook("a", "BTC-USDT", 100+spread), midBook("b", "BTC-USDT", 100)} {
+			if err := engine.ProcessOrderBookUpdate(ctx, book); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return engine.sample()
+	}
+	expect := func(signals []*dto.StatArbSignalDTO, want ...string) {
+		t.Helper()
+		if len(signals) != len(want)/2 {
+			t.Fatalf("got %d signals, want %v", len(signals), want)
+		}
+		for i, signal := range signals {
+			if signal.Type != want[2*i] || signal.Position != want[2*i+1] {
+				t.Fatalf("signal %d is %s %s, want %s %s", i, signal.Type, signal.Position, want[2*i], want[2*i+1])
+			}
+		}
+	}
+
+	engine := newEngine(cfg)
+	engine.Start(ctx)
+
+	for i, spread := range []float64{0, 1, 0, 1} {
+		expect(step(engine, spread))
+		if pair := engine.ListPairs(ctx)[0]; pair.Ready || pair.Samples != i+1 {
+			t.Fatalf("after %d samples: ready %v with %d samples", i+1, pair.Ready, pair.Samples)
+		}
+	}
+	// Window 0 1 0 1 0: z = (0 - 0.4) / sqrt(0.3)
+	expect(step(engine, 0))
+	if pair := engine.ListPairs(ctx)[0]; !pair.Ready || !near(pair.ZScore, -0.4/math.Sqrt(0.3)) {
+		t.Fatalf("z = %v, ready %v", pair.ZScore, pair.Ready)
+	}
+
+	// Window 1 0 1 0 3: z = 2 / sqrt(1.5), above the entry
+	signals := step(engine, 3)
+	expect(signals, dto.StatArbEntry, dto.StatArbShortSpread)
+	if !near(signals[0].ZScore, 2/math.Sqrt(1.5)) || !near(signals[0].Mean, 1) || !near(signals[0].StdDev, math.Sqrt(1.5)) {
+		t.Fatalf("entry at z %v mean %v sd %v", signals[0].ZScore, signals[0].Mean, signals[0].StdDev)
+	}
+
+	// Window 0 1 0 3 2: z = 0.8 / sqrt(1.7), below the entry but above the
+	// exit, so the position holds
+	expect(step(engine, 2))
+	if pair := engine.ListPairs(ctx)[0]; pair.Position != dto.StatArbShortSpread || !near(pair.ZScore, 0.8/math.Sqrt(1.7)) {
+		t.Fatalf("position %s at z %v", pair.Position, pair.ZScore)
+	}
+
+	engine.Stop()
+
+	// A restart carries on with the saved window and position
+	restored := newEngine(cfg)
+	restored.Start(ctx)
+	pair := restored.ListPairs(ctx)[0]
+	if pair.Samples != 5 || !pair.Ready || pair.Position != dto.StatArbShortSpread || !near(pair.ZScore, 0.8/math.Sqrt(1.7)) {
+		t.Fatalf("restored %d samples, ready %v, %s at z %v", pair.Samples, pair.Ready, pair.Position, pair.ZScore)
+	}
+	if pair.LastSignal == nil || pair.LastSignal.Type != dto.StatArbEntry {
+		t.Fatalf("restored last signal %+v", pair.LastSignal)
+	}
+
+	// Window 1 0 3 2 1: z = -0.4 / sqrt(1.3), within the exit
+	expect(step(restored, 1), dto.StatArbExit, dto.StatArbShortSpread)
+	if pair := restored.ListPairs(ctx)[0]; pair.Position != dto.StatArbFlat {
+		t.Fatalf("position %s after exit", pair.Position)
+	}
+
+	// Window 0 3 2 1 -3: z = -3.6 / sqrt(5.3), below the negative entry
+	signals = step(restored, -3)
+	expect(signals, dto.StatArbEntry, dto.StatArbLongSpread)
+	if !near(signals[0].ZScore, -3.6/math.Sqrt(5.3)) {
+		t.Fatalf("long entry at z %v", signals[0].ZScore)
+	}
+	restored.Stop()
+
+	// A pair whose legs changed starts over
+	cfg.Pairs[0].SymbolB = "ETH-USDT"
+	changed := newEngine(cfg)
+	changed.Start(ctx)
+	defer changed.Stop()
+	if pair := changed.ListPairs(ctx)[0]; pair.Samples != 0 || pair.Position != dto.StatArbFlat {
+		t.Fatalf("changed pair restored %d samples, %s", pair.Samples, pair.Position)
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
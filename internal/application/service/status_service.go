@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+	"marketdata/internal/application/port/output"
+)
+
+const dependencyCheckTimeout = 2 * time.Second
+
+type StatusService struct {
+	checkers  []output.HealthCheckPort
+	exchanges []output.ExchangeStatusPort
+	monitor   *FeedMonitor
+	startedAt time.Time
+}
+
+func NewStatusService(
+	checkers []output.HealthCheckPort,
+	exchanges []output.ExchangeStatusPort,
+	monitor *FeedMonitor,
+) *StatusService {
+	return &StatusService{
+		checkers:  checkers,
+		exchanges: exchanges,
+		monitor:   monitor,
+		startedAt: time.Now(),
+	}
+}
+
+// Readiness checks every dependency and whether any exchange is connected
+func (s *StatusService) Readiness(ctx context.Context) *dto.ReadinessDTO {
+	dependencies := s.checkDependencies(ctx)
+	connected := s.connectedExchanges()
+
+	ready := connected > 0
+	for _, dependency := range dependencies {
+		ready = ready && dependency.Healthy
+	}
+
+	return &dto.ReadinessDTO{
+		Ready:              ready,
+		ConnectedExchanges: connected,
+		Dependencies:       dependencies,
+	}
+}
+
+// Status reports exchange connections, per-symbol feed health and dependencies
+func (s *StatusService) Status(ctx context.Context) *dto.StatusDTO {
+	readiness := s.Readiness(ctx)
+
+	exchanges := make([]dto.ExchangeStatusDTO, len(s.exchanges))
+	for i, exchange := range s.exchanges {
+		exchanges[i] = dto.ExchangeStatusDTO{
+			Name:      exchange.GetName(),
+			Connected: exchange.IsConnected(),
+		}
+	}
+
+	feeds := s.monitor.Snapshot()
+	symbols := make([]dto.SymbolStatusDTO, len(feeds))
+	for i, feed := range feeds {
+		symbols[i] = dto.SymbolStatusDTO{
+			ExchangeID:          feed.ExchangeID,
+			Symbol:              feed.Symbol,
+			Updates:             feed.Updates,
+			LastUpdate:          feed.LastUpdate,
+			LastUpdateAgeSecs:   feed.LastUpdateAge.Seconds(),
+			SequenceGaps:        feed.SequenceGaps,
+			PublisherLagSeconds: feed.PublisherLag.Seconds(),
+			PublishErrors:       feed.PublishErrors,
+		}
+	}
+
+	status := "ok"
+	if !readiness.Ready {
+		status = "degraded"
+	}
+
+	return &dto.StatusDTO{
+		Status:        status,
+		StartedAt:     s.startedAt,
+		UptimeSeconds: time.Since(s.startedAt).Seconds(),
+		Exchanges:     exchanges,
+		Symbols:       symbols,
+		Dependencies:  readiness.Dependencies,
+	}
+}
+
+func (s *StatusService) checkDependencies(ctx context.Context) []dto.DependencyStatusDTO {
+	results := make([]dto.DependencyStatusDTO, len(s.checkers))
+
+	var wg sync.WaitGroup
+	for i, checker := range s.checkers {
+		wg.Add(1)
+		go func(i int, checker output.HealthCheckPort) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := checker.Check(checkCtx)
+			results[i] = dto.DependencyStatusDTO{
+				Name:      checker.Name(),
+				Healthy:   err == nil,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}(i, checker)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (s *StatusService) connectedExchanges() int {
+	connected := 0
+	for _, exchange := range s.exchanges {
+		if exchange.IsConnected() {
+			connected++
+		}
+	}
+	return connected
+}
+
+// Ensure StatusService implements StatusUseCase interface
+var _ input.StatusUseCase = (*StatusService)(nil)
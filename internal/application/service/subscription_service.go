@@ -0,0 +1,543 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+)
+
+const (
+	defaultResubscribeDelay = 5 * time.Second
+	defaultAuditLimit       = 100
+	maxAuditLimit           = 1000
+)
+
+// OrderBookProcessor ingests the orderbooks streamed by subscriptions
+type OrderBookProcessor interface {
+	ProcessOrderBookUpdate(ctx context.Context, update *dto.OrderBookDTO) error
+}
+
+// OrderBookProcessors hands every update to each processor in turn
+type OrderBookProcessors []OrderBookProcessor
+
+// ProcessOrderBookUpdate runs all processors, even after one fails, and
+// returns their errors joined
+func (p OrderBookProcessors) ProcessOrderBookUpdate(ctx context.Context, update *dto.OrderBookDTO) error {
+	var errs []error
+	for _, processor := range p {
+		if err := processor.ProcessOrderBookUpdate(ctx, update); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// TradeProcessor ingests the trades streamed by subscriptions
+type TradeProcessor interface {
+	ProcessTrade(ctx context.Context, trade *dto.TradeDTO) error
+}
+
+type subscriptionKey struct {
+	exchangeID string
+	symbol     string
+}
+
+type subscription struct {
+	spec   dto.SubscriptionSpecDTO
+	since  time.Time
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// SubscriptionService owns the orderbook subscriptions of every exchange. Each
+// one streams into the processor, cut to its depth and throttled, until it is
+// removed; when an exchange ends a stream, for example after a disconnect, it
+// reconnects and resubscribes. The symbol's trades stream into the trade
+// processor alongside, from exchanges that stream trades. Recording follows
+// each subscription's setting when a recorder is given.
+type SubscriptionService struct {
+	exchanges        map[string]output.ExchangePort
+	processor        OrderBookProcessor
+	trades           TradeProcessor
+	recording        output.FrameRecordingPort
+	audit            output.SubscriptionAuditPort
+	logger           Logger
+	resubscribeDelay time.Duration
+
+	mu      sync.Mutex
+	ctx     context.Context
+	stop    context.CancelFunc
+	active  map[subscriptionKey]*subscription
+	connect sync.Mutex
+}
+
+func NewSubscriptionService(
+	exchanges []output.ExchangePort,
+	processor OrderBookProcessor,
+	trades TradeProcessor,
+	recording output.FrameRecordingPort,
+	audit output.SubscriptionAuditPort,
+	logger Logger,
+) *SubscriptionService {
+	byName := make(map[string]output.ExchangePort, len(exchanges))
+	for _, exchange := range exchanges {
+		byName[exchange.GetName()] = exchange
+	}
+
+	return &SubscriptionService{
+		exchanges:        byName,
+		processor:        processor,
+		trades:           trades,
+		recording:        recording,
+		audit:            audit,
+		logger:           logger,
+		resubscribeDelay: defaultResubscribeDelay,
+		active:           make(map[subscriptionKey]*subscription),
+	}
+}
+
+// Start lets subscriptions run until ctx is done or Stop is called
+func (s *SubscriptionService) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ctx, s.stop = context.WithCancel(ctx)
+}
+
+// Stop ends every subscription and waits for their streams to finish
+func (s *SubscriptionService) Stop() {
+	s.mu.Lock()
+	if s.stop != nil {
+		s.stop()
+	}
+	s.ctx, s.stop = nil, nil
+	active := s.active
+	s.active = make(map[subscriptionKey]*subscription)
+	s.mu.Unlock()
+
+	for _, sub := range active {
+		<-sub.done
+	}
+}
+
+// ListSubscriptions lists the active subscriptions by exchange and symbol
+func (s *SubscriptionService) ListSubscriptions(ctx context.Context) []*dto.SubscriptionDTO {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscriptions := make([]*dto.SubscriptionDTO, 0, len(s.active))
+	for _, sub := range s.active {
+		subscriptions = append(subscriptions, &dto.SubscriptionDTO{
+			SubscriptionSpecDTO: sub.spec,
+			Since:               sub.since,
+		})
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if subscriptions[i].ExchangeID != subscriptions[j].ExchangeID {
+			return subscriptions[i].ExchangeID < subscriptions[j].ExchangeID
+		}
+		return subscriptions[i].Symbol < subscriptions[j].Symbol
+	})
+	return subscriptions
+}
+
+// Subscribe starts streaming a symbol. An active subscription with other
+// settings is restarted with the new ones. The first subscription attempt
+// runs before it returns, so a symbol the exchange rejects is reported to the
+// caller instead of being retried in the background.
+func (s *SubscriptionService) Subscribe(ctx context.Context, spec dto.SubscriptionSpecDTO, actor string) (*dto.SubscriptionDTO, error) {
+	exchange, spec, err := s.resolve(spec)
+	if err != nil {
+		return nil, err
+	}
+	key := subscriptionKey{exchangeID: spec.ExchangeID, symbol: spec.Symbol}
+
+	s.mu.Lock()
+	if s.ctx == nil {
+		s.mu.Unlock()
+		return nil, errors.New("subscription service is not started")
+	}
+	previous, active := s.active[key]
+	if active && previous.spec == spec {
+		s.mu.Unlock()
+		return &dto.SubscriptionDTO{SubscriptionSpecDTO: spec, Since: previous.since}, nil
+	}
+
+	subCtx, cancel := context.WithCancel(s.ctx)
+	updates, err := subscribeOrderBook(subCtx, exchange, spec)
+	if err != nil {
+		s.mu.Unlock()
+		cancel()
+		return nil, fmt.Errorf("failed to subscribe to %s %s: %w", spec.ExchangeID, spec.Symbol, err)
+	}
+
+	sub := &subscription{
+		spec:   spec,
+		since:  time.Now(),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	s.active[key] = sub
+	s.mu.Unlock()
+
+	// The new stream is subscribed before the old one stops, so no update is
+	// missed between them. The old stream is waited for unlocked, so other
+	// subscriptions are not held up while it finishes.
+	if active {
+		previous.cancel()
+		<-previous.done
+	}
+
+	go func() {
+		defer close(sub.done)
+
+		var wg sync.WaitGroup
+		if tradeStream, ok := exchange.(output.TradeStreamPort); ok && s.trades != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.streamTrades(subCtx, exchange, tradeStream, spec)
+			}()
+		}
+		s.stream(subCtx, exchange, spec, updates)
+		wg.Wait()
+	}()
+	s.setRecording(spec.ExchangeID, spec.Symbol, spec.Record)
+
+	action := entity.SubscriptionAdded
+	var previousSettings *entity.SubscriptionSettings
+	if active {
+		action = entity.SubscriptionUpdated
+		previousSettings = subscriptionSettings(previous.spec)
+	}
+	s.record(ctx, entity.NewSubscriptionChange(sub.since, actor, action, spec.ExchangeID, spec.Symbol,
+		previousSettings, subscriptionSettings(spec)))
+	return &dto.SubscriptionDTO{SubscriptionSpecDTO: spec, Since: sub.since}, nil
+}
+
+// Unsubscribe stops streaming a symbol and waits for its stream to finish
+func (s *SubscriptionService) Unsubscribe(ctx context.Context, exchangeID, symbol, actor string) error {
+	_, spec, err := s.resolve(dto.SubscriptionSpecDTO{ExchangeID: exchangeID, Symbol: symbol})
+	if err != nil {
+		return err
+	}
+	key := subscriptionKey{exchangeID: spec.ExchangeID, symbol: spec.Symbol}
+
+	s.mu.Lock()
+	sub, ok := s.active[key]
+	delete(s.active, key)
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s %s", input.ErrNotSubscribed, spec.ExchangeID, spec.Symbol)
+	}
+
+	sub.cancel()
+	<-sub.done
+	s.setRecording(spec.ExchangeID, spec.Symbol, false)
+
+	s.record(ctx, entity.NewSubscriptionChange(time.Now(), actor, entity.SubscriptionRemoved, spec.ExchangeID, spec.Symbol,
+		subscriptionSettings(sub.spec), nil))
+	return nil
+}
+
+// Reconcile moves from the previous to the next plan: symbols only in
+// previous are unsubscribed, symbols only in next are subscribed and symbols
+// whose settings changed are restarted. Subscriptions made through other
+// actors are left alone unless one of the plans names them. Every change is
+// attempted and the failures are returned together.
+func (s *SubscriptionService) Reconcile(ctx context.Context, previous, next []dto.SubscriptionSpecDTO, actor string) error {
+	var errs []error
+
+	wanted := make(map[subscriptionKey]dto.SubscriptionSpecDTO, len(next))
+	for _, spec := range next {
+		wanted[subscriptionKey{exchangeID: spec.ExchangeID, symbol: spec.Symbol}] = spec
+	}
+	had := make(map[subscriptionKey]dto.SubscriptionSpecDTO, len(previous))
+	for _, spec := range previous {
+		key := subscriptionKey{exchangeID: spec.ExchangeID, symbol: spec.Symbol}
+		had[key] = spec
+		if _, ok := wanted[key]; ok {
+			continue
+		}
+		err := s.Unsubscribe(ctx, spec.ExchangeID, spec.Symbol, actor)
+		if err != nil && !errors.Is(err, input.ErrNotSubscribed) {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, spec := range next {
+		if old, ok := had[subscriptionKey{exchangeID: spec.ExchangeID, symbol: spec.Symbol}]; ok && old == spec {
+			continue
+		}
+		if _, err := s.Subscribe(ctx, spec, actor); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// GetAuditLog returns up to limit subscription changes, newest first
+func (s *SubscriptionService) GetAuditLog(ctx context.Context, limit int) ([]*dto.SubscriptionChangeDTO, error) {
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	limit = min(limit, maxAuditLimit)
+
+	changes, err := s.audit.Recent(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	dtos := make([]*dto.SubscriptionChangeDTO, len(changes))
+	for i, change := range changes {
+		dtos[i] = &dto.SubscriptionChangeDTO{
+			Time:       change.At(),
+			Actor:      change.Actor(),
+			Action:     string(change.Action()),
+			ExchangeID: change.ExchangeID(),
+			Symbol:     change.Symbol(),
+			Old:        settingsDTO(change.Previous()),
+			New:        settingsDTO(change.Current()),
+		}
+	}
+	return dtos, nil
+}
+
+// subscriptionSettings returns the settings of a spec
+func subscriptionSettings(spec dto.SubscriptionSpecDTO) *entity.SubscriptionSettings {
+	return &entity.SubscriptionSettings{
+		Depth:           spec.Depth,
+		UpdateSpeed:     time.Duration(spec.UpdateSpeedMs) * time.Millisecond,
+		PublishThrottle: time.Duration(spec.PublishThrottleMs) * time.Millisecond,
+		Record:          spec.Record,
+	}
+}
+
+func settingsDTO(settings *entity.SubscriptionSettings) *dto.SubscriptionSettingsDTO {
+	if settings == nil {
+		return nil
+	}
+	return &dto.SubscriptionSettingsDTO{
+		Depth:             settings.Depth,
+		UpdateSpeedMs:     settings.UpdateSpeed.Milliseconds(),
+		Record:            settings.Record,
+		PublishThrottleMs: settings.PublishThrottle.Milliseconds(),
+	}
+}
+
+// resolve looks up the exchange and normalizes the spec
+func (s *SubscriptionService) resolve(spec dto.SubscriptionSpecDTO) (output.ExchangePort, dto.SubscriptionSpecDTO, error) {
+	exchange, ok := s.exchanges[spec.ExchangeID]
+	if !ok {
+		return nil, spec, fmt.Errorf("%w: %q", input.ErrUnknownExchange, spec.ExchangeID)
+	}
+
+	spec.Symbol = strings.ToUpper(strings.TrimSpace(spec.Symbol))
+	base, quote, ok := strings.Cut(spec.Symbol, "-")
+	if !ok || base == "" || quote == "" || strings.Contains(quote, "-") {
+		return nil, spec, fmt.Errorf("%w: %q is not a BASE-QUOTE symbol", input.ErrInvalidQuery, spec.Symbol)
+	}
+	if spec.Depth < 0 || spec.UpdateSpeedMs < 0 || spec.PublishThrottleMs < 0 {
+		return nil, spec, fmt.Errorf("%w: depth, update speed and publish throttle must not be negative", input.ErrInvalidQuery)
+	}
+	return exchange, spec, nil
+}
+
+// subscribeOrderBook passes the stream options to exchanges that take them
+func subscribeOrderBook(ctx context.Context, exchange output.ExchangePort, spec dto.SubscriptionSpecDTO) (<-chan *entity.OrderBook, error) {
+	if tunable, ok := exchange.(output.OrderBookStreamPort); ok {
+		return tunable.SubscribeOrderBookWithOptions(ctx, spec.Symbol, output.OrderBookStreamOptions{
+			Depth:       spec.Depth,
+			UpdateSpeed: time.Duration(spec.UpdateSpeedMs) * time.Millisecond,
+		})
+	}
+	return exchange.SubscribeOrderBook(ctx, spec.Symbol)
+}
+
+// stream forwards updates until ctx is done, resubscribing whenever the
+// exchange closes the stream
+func (s *SubscriptionService) stream(ctx context.Context, exchange output.ExchangePort, spec dto.SubscriptionSpecDTO, updates <-chan *entity.OrderBook) {
+	for {
+		s.forward(ctx, spec, updates)
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.logger.Error("orderbook stream ended, resubscribing",
+			"exchange", spec.ExchangeID,
+			"symbol", spec.Symbol,
+		)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.resubscribeDelay):
+			}
+
+			var err error
+			if err = s.reconnect(ctx, exchange); err == nil {
+				updates, err = subscribeOrderBook(ctx, exchange, spec)
+			}
+			if err == nil {
+				break
+			}
+			s.logger.Error("failed to resubscribe to orderbook",
+				"error", err,
+				"exchange", spec.ExchangeID,
+				"symbol", spec.Symbol,
+			)
+		}
+	}
+}
+
+// streamTrades processes the symbol's trades until ctx is done, subscribing
+// again whenever the exchange closes the stream or refuses it
+func (s *SubscriptionService) streamTrades(ctx context.Context, exchange output.ExchangePort, tradeStream output.TradeStreamPort, spec dto.SubscriptionSpecDTO) {
+	for {
+		trades, err := tradeStream.SubscribeTrades(ctx, spec.Symbol)
+		if err != nil {
+			s.logger.Error("failed to subscribe to trades",
+				"error", err,
+				"exchange", spec.ExchangeID,
+				"symbol", spec.Symbol,
+			)
+		} else {
+			s.forwardTrades(ctx, trades)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.resubscribeDelay):
+		}
+		if err := s.reconnect(ctx, exchange); err != nil {
+			s.logger.Error("failed to resubscribe to trades",
+				"error", err,
+				"exchange", spec.ExchangeID,
+				"symbol", spec.Symbol,
+			)
+		}
+	}
+}
+
+func (s *SubscriptionService) forwardTrades(ctx context.Context, trades <-chan *entity.Trade) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case trade, ok := <-trades:
+			if !ok {
+				return
+			}
+			if err := s.trades.ProcessTrade(ctx, convertToTradeDTO(trade)); err != nil {
+				s.logger.Error("failed to process trade",
+					"error", err,
+					"exchange", trade.ExchangeID(),
+					"symbol", trade.Symbol(),
+				)
+			}
+		}
+	}
+}
+
+// forward processes updates cut to the spec's depth. With a publish throttle
+// it processes only the latest update of each interval.
+func (s *SubscriptionService) forward(ctx context.Context, spec dto.SubscriptionSpecDTO, updates <-chan *entity.OrderBook) {
+	var tick <-chan time.Time
+	if spec.PublishThrottleMs > 0 {
+		ticker := time.NewTicker(time.Duration(spec.PublishThrottleMs) * time.Millisecond)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var pending *entity.OrderBook
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			if tick != nil {
+				pending = update
+				continue
+			}
+			s.process(ctx, update, spec.Depth)
+		case <-tick:
+			if pending != nil {
+				s.process(ctx, pending, spec.Depth)
+				pending = nil
+			}
+		}
+	}
+}
+
+func (s *SubscriptionService) process(ctx context.Context, update *entity.OrderBook, depth int) {
+	orderbook := convertToOrderBookDTO(update)
+	if depth > 0 {
+		orderbook.Bids = orderbook.Bids[:min(depth, len(orderbook.Bids))]
+		orderbook.Asks = orderbook.Asks[:min(depth, len(orderbook.Asks))]
+	}
+
+	if err := s.processor.ProcessOrderBookUpdate(ctx, orderbook); err != nil {
+		s.logger.Error("failed to process orderbook update",
+			"error", err,
+			"exchange", orderbook.ExchangeID,
+			"symbol", orderbook.Symbol,
+		)
+	}
+}
+
+// reconnect connects exchanges that report a dropped connection. It is
+// serialized so the streams of one exchange do not race to connect it.
+func (s *SubscriptionService) reconnect(ctx context.Context, exchange output.ExchangePort) error {
+	status, ok := exchange.(output.ExchangeStatusPort)
+	if !ok {
+		return nil
+	}
+
+	s.connect.Lock()
+	defer s.connect.Unlock()
+
+	if status.IsConnected() {
+		return nil
+	}
+	if err := exchange.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to reconnect: %w", err)
+	}
+	s.logger.Info("reconnected to exchange", "exchange", exchange.GetName())
+	return nil
+}
+
+func (s *SubscriptionService) setRecording(exchangeID, symbol string, enabled bool) {
+	if s.recording != nil {
+		s.recording.SetRecording(exchangeID, symbol, enabled)
+	}
+}
+
+func (s *SubscriptionService) record(ctx context.Context, change *entity.SubscriptionChange) {
+	s.logger.Info("subscription changed",
+		"action", string(change.Action()),
+		"exchange", change.ExchangeID(),
+		"symbol", change.Symbol(),
+		"actor", change.Actor(),
+	)
+	if err := s.audit.Record(ctx, change); err != nil {
+		s.logger.Error("failed to record subscription change", "error", err)
+	}
+}
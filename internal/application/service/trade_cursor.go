@@ -0,0 +1,48 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"marketdata/internal/application/port/input"
+	"marketdata/internal/application/port/output"
+)
+
+// encodeTradeCursor turns a trade position into an opaque page token of the
+// form base64url("<unix nanos>:<exchange id>:<trade id>")
+func encodeTradeCursor(key output.TradeKey) string {
+	raw := strconv.FormatInt(key.Timestamp.UnixNano(), 10) + ":" + key.ExchangeID + ":" + key.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeTradeCursor parses a token from encodeTradeCursor. Cursors without an
+// exchange id predate exchange-aware ordering and are rejected, since they
+// cannot tell apart trades of different exchanges sharing a timestamp and id.
+
+func decodeTradeCursor(cursor string) (*output.TradeKey, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", input.ErrInvalidQuery)
+	}
+
+	// The trade id comes last so ids containing a colon survive the split
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("%w: malformed cursor", input.ErrInvalidQuery)
+	}
+	nanos, exchangeID, id := parts[0], parts[1], parts[2]
+
+	ts, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", input.ErrInvalidQuery)
+	}
+
+	return &output.TradeKey{
+		Timestamp:  time.Unix(0, ts).UTC(),
+		ExchangeID: exchangeID,
+		ID:         id,
+	}, nil
+}
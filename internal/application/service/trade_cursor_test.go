@@ -0,0 +1,45 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"marketdata/internal/application/port/input"
+	"marketdata/internal/application/port/output"
+)
+
+func TestTradeCursorRoundTrip(t *testing.T) {
+	keys := []output.TradeKey{
+		{Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC), ExchangeID: "binance", ID: "42"},
+		{Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC), ExchangeID: "okx", ID: "42"},
+		{Timestamp: time.Unix(0, 0).UTC(), ExchangeID: "kraken", ID: "T:1:2"},
+	}
+	for _, key := range keys {
+		got, err := decodeTradeCursor(encodeTradeCursor(key))
+		if err != nil {
+			t.Fatalf("decode(%+v): %v", key, err)
+		}
+		if !got.Timestamp.Equal(key.Timestamp) || got.ExchangeID != key.ExchangeID || got.ID != key.ID {
+			t.Fatalf("round trip of %+v = %+v", key, *got)
+		}
+	}
+}
+
+func TestTradeCursorRejectsMalformed(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+
+	cursors := map[string]string{
+		"not base64":          "%%%",
+		"two-part cursor":     encode("1767323045000000006:42"),
+		"empty exchange":      encode("1767323045000000006::42"),
+		"empty id":            encode("1767323045000000006:binance:"),
+		"non-numeric instant": encode("yesterday:binance:42"),
+	}
+	for name, cursor := range cursors {
+		if _, err := decodeTradeCursor(cursor); !errors.Is(err, input.ErrInvalidQuery) {
+			t.Errorf("%s: err = %v, want ErrInvalidQuery", name, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"marketdata/internal/application/dto"
+)
+
+const tradeSubscriberBuffer = 1024
+
+// tradeFanout hands every processed trade to the live subscribers of its
+// symbol. A subscriber that falls a full buffer behind has its channel closed
+// rather than silently missing trades, so it can resubscribe from a snapshot.
+type tradeFanout struct {
+	mu   sync.Mutex
+	subs map[*tradeSubscriber]struct{}
+}
+
+type tradeSubscriber struct {
+	exchangeID string
+	symbol     string
+	ch         chan *dto.TradeDTO
+}
+
+func newTradeFanout() *tradeFanout {
+	return &tradeFanout{subs: make(map[*tradeSubscriber]struct{})}
+}
+
+// subscribe streams the trades of symbol on exchangeID, or on every exchange
+// when exchangeID is empty, until ctx is done
+func (f *tradeFanout) subscribe(ctx context.Context, exchangeID, symbol string) <-chan *dto.TradeDTO {
+	sub := &tradeSubscriber{
+		exchangeID: exchangeID,
+		symbol:     symbol,
+		ch:         make(chan *dto.TradeDTO, tradeSubscriberBuffer),
+	}
+
+	f.mu.Lock()
+	f.subs[sub] = struct{}{}
+	f.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		f.remove(sub)
+	}()
+
+	return sub.ch
+}
+
+func (f *tradeFanout) publish(trade *dto.TradeDTO) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for sub := range f.subs {
+		if sub.symbol != trade.Symbol || (sub.exchangeID != "" && sub.exchangeID != trade.ExchangeID) {
+			continue
+		}
+		select {
+		case sub.ch <- trade:
+		default:
+			delete(f.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+func (f *tradeFanout) remove(sub *tradeSubscriber) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.subs[sub]; ok {
+		delete(f.subs, sub)
+		close(sub.ch)
+	}
+}
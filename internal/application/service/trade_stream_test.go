@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+	domainservice "marketdata/internal/domain/service"
+	"marketdata/internal/domain/valueobject"
+	"marketdata/internal/infrastructure/audit"
+	memorymessaging "marketdata/internal/infrastructure/messaging/memory"
+	"marketdata/internal/infrastructure/persistence/memory"
+	"marketdata/pkg/logger"
+)
+
+// tradeExchange streams the trades sent on trades and no orderbook updates
+type tradeExchange struct {
+	trades chan *entity.Trade
+}
+
+func (e *tradeExchange) Connect(ctx context.Context) error { return nil }
+func (e *tradeExchange) Close() error                      { return nil }
+func (e *tradeExchange) GetName() string                   { return "sim" }
+
+func (e *tradeExchange) GetOrderBook(ctx context.Context, symbol string) (*entity.OrderBook, error) {
+	return nil, nil
+}
+
+func (e *tradeExchange) SubscribeOrderBook(ctx context.Context, symbol string) (<-chan *entity.OrderBook, error) {
+	return make(chan *entity.OrderBook), nil
+}
+
+func (e *tradeExchange) SubscribeTrades(ctx context.Context, symbol string) (<-chan *entity.Trade, error) {
+	return e.trades, nil
+}
+
+func TestStreamedTradeReachesQueryTrades(t *testing.T) {
+	ctx := context.Background()
+	exchange := &tradeExchange{trades: make(chan *entity.Trade)}
+	tradeRepo := memory.NewTradeRepository(0)
+	publisher := memorymessaging.NewPublisher(0)
+	log := logger.NewLogger()
+
+	svc := NewMarketDataService(
+		memory.NewOrderBookRepository(time.Minute),
+		tradeRepo,
+		memory.NewOrderBookSnapshotRepository(0),
+		[]output.ExchangePort{exchange},
+		publisher,
+		domainservice.NewOrderBookService(),
+		nil,
+		NewFeedMonitor(),
+		log,
+	)
+	auditLog, err := audit.NewSubscriptionLog("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscriptions := NewSubscriptionService([]output.ExchangePort{exchange}, svc, svc, nil, auditLog, log)
+	subscriptions.Start(ctx)
+	defer subscriptions.Stop()
+
+	if _, err := subscriptions.Subscribe(ctx, dto.SubscriptionSpecDTO{ExchangeID: "sim", Symbol: "BTC-USDT"}, "test"); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	price, _ := valueobject.NewPrice(65000, "USDT")
+	volume, _ := valueobject.NewVolume(0.25, "BTC")
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	exchange.trades <- entity.NewTrade("42", "sim", "BTC-USDT", *price, *volume, entity.TradeBuy, at)
+
+	var trades []*entity.Trade
+	deadline := time.Now().Add(2 * time.Second)
+	for len(trades) == 0 && time.Now().Before(deadline) {
+		trades, err = tradeRepo.QueryTrades(ctx, output.TradeFilter{ExchangeID: "sim", Symbol: "BTC-USDT", Limit: 10})
+		if err != nil {
+			t.Fatalf("QueryTrades: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(trades) != 1 {
+		t.Fatalf("QueryTrades returned %d trades, want 1", len(trades))
+	}
+	trade := trades[0]
+	if trade.ID() != "42" || trade.Price().Value() != 65000 || trade.Volume().Value() != 0.25 ||
+		trade.Type() != entity.TradeBuy || !trade.Timestamp().Equal(at) {
+		t.Fatalf("stored trade = %s %v %v %s %v", trade.ID(), trade.Price().Value(), trade.Volume().Value(), trade.Type(), trade.Timestamp())
+	}
+
+	events := publisher.Events()
+	if len(events) != 1 || events[0].Type != memorymessaging.EventTrade || events[0].Trade.ID() != "42" {
+		t.Fatalf("published events = %+v, want the trade", events)
+	}
+}
+
+func TestSubscribeTradesFollowsProcessingOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	svc := NewMarketDataService(
+		memory.NewOrderBookRepository(time.Minute),
+		memory.NewTradeRepository(0),
+		memory.NewOrderBookSnapshotRepository(0),
+		[]output.ExchangePort{&tradeExchange{}},
+		memorymessaging.NewPublisher(0),
+		domainservice.NewOrderBookService(),
+		nil,
+		NewFeedMonitor(),
+		logger.NewLogger(),
+	)
+
+	if _, err := svc.SubscribeTrades(ctx, "kraken", "BTC-USDT"); !errors.Is(err, input.ErrUnknownExchange) {
+		t.Fatalf("unknown exchange: err = %v, want ErrUnknownExchange", err)
+	}
+	live, err := svc.SubscribeTrades(ctx, "sim", "BTC-USDT")
+	if err != nil {
+		t.Fatalf("SubscribeTrades: %v", err)
+	}
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	process := func(id, symbol string, ts time.Time) {
+		t.Helper()
+		err := svc.ProcessTrade(ctx, &dto.TradeDTO{
+			ID: id, ExchangeID: "sim", Symbol: symbol, Price: 100, Volume: 1, TradeType: "BUY", Timestamp: ts,
+		})
+		if err != nil {
+			t.Fatalf("ProcessTrade(%s): %v", id, err)
+		}
+	}
+	// A late trade carries an older timestamp than the one before it
+	process("2", "BTC-USDT", at)
+	process("e", "ETH-USDT", at)
+	process("1", "BTC-USDT", at.Add(-time.Second))
+
+	for _, want := range []string{"2", "1"} {
+		select {
+		case trade := <-live:
+			if trade.ID != want {
+				t.Fatalf("got trade %s, want %s", trade.ID, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("trade %s not delivered", want)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-live:
+		if ok {
+			t.Fatal("unexpected trade after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscription not closed after cancel")
+	}
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+	"marketdata/internal/domain/entity"
+	domainservice "marketdata/internal/domain/service"
+)
+
+var _ input.TriangularArbitrageUseCase = (*TriangularScanner)(nil)
+
+// TriangularScannerConfig tunes the triangular arbitrage scanner
+type TriangularScannerConfig struct {
+	// MinProfitPct is what a cycle must return, in percent, after fees
+	MinProfitPct float64
+	// StartCurrencies ranks the currencies a cycle is reported from; a
+	// cycle through none of them starts from its first currency by name
+	StartCurrencies []string
+	// MaxBookAge ignores books without an update for longer; zero keeps them
+	MaxBookAge time.Duration
+}
+
+// cycleStep is one leg of a cycle before its books are known
+type cycleStep struct {
+	symbol string
+	side   entity.TradeType
+}
+
+type triangle struct {
+	id    string
+	steps [3]cycleStep
+}
+
+type venueGraph struct {
+	books map[string]arbitrageQuote
+	// bySymbol indexes the triangles each symbol is a leg of
+	bySymbol map[string][]*triangle
+}
+
+type foundCycle struct {
+	cycle      *domainservice.ArbitrageCycle
+	detectedAt time.Time
+	updatedAt  time.Time
+	steps      [3]cycleStep
+}
+
+// TriangularScanner finds cycles of three conversions within one exchange,
+// such as USDT to BTC to ETH to USDT, that return more than they spend after
+// taker fees. It builds a currency graph from the books of each exchange and
+// re-evaluates the cycles through a symbol whenever its book updates,
+// walking the depth of every leg.
+type TriangularScanner struct {
+	cfg          TriangularScannerConfig
+	orderbookSvc domainservice.OrderBookDomainService
+	fees         FeeProvider
+	now          func() time.Time
+
+	mu     sync.Mutex
+	venues map[string]*venueGraph
+	found  map[string]*foundCycle
+}
+
+func NewTriangularScanner(
+	cfg TriangularScannerConfig,
+	orderbookSvc domainservice.OrderBookDomainService,
+	fees FeeProvider,
+) *TriangularScanner {
+	return &TriangularScanner{
+		cfg:          cfg,
+		orderbookSvc: orderbookSvc,
+		fees:         fees,
+		now:          time.Now,
+		venues:       make(map[string]*venueGraph),
+		found:        make(map[string]*foundCycle),
+	}
+}
+
+// ProcessOrderBookUpdate takes the latest book of a symbol and re-evaluates
+// the cycles it is a leg of
+func (s *TriangularScanner) ProcessOrderBookUpdate(ctx context.Context, update *dto.OrderBookDTO) error {
+	book := convertToOrderBookEntity(update)
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	venue, ok := s.venues[update.ExchangeID]
+	if !ok {
+		venue = &venueGraph{books: make(map[string]arbitrageQuote)}
+		s.venues[update.ExchangeID] = venue
+	}
+	_, known := venue.books[update.Symbol]
+	venue.books[update.Symbol] = arbitrageQuote{book: book, received: now}
+	if !known {
+		s.buildGraph(update.ExchangeID, venue)
+	}
+
+	for _, t := range venue.bySymbol[update.Symbol] {
+		s.evaluate(update.ExchangeID, venue, t, now)
+	}
+	return nil
+}
+
+// ListCycles returns the profitable cycles, optionally on one exchange, most
+// profitable first. Cycles with a stale leg are dropped.
+func (s *TriangularScanner) ListCycles(ctx context.Context, exchangeID string) []*dto.ArbitrageCycleDTO {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	cycles := make([]*dto.ArbitrageCycleDTO, 0, len(s.found))
+	for id, found := range s.found {
+		venue := s.venues[found.cycle.ExchangeID]
+		if _, fresh := s.legBooks(venue, found.steps, now); !fresh {
+			delete(s.found, id)
+			continue
+		}
+		if exchangeID == "" || found.cycle.ExchangeID == exchangeID {
+			cycles = append(cycles, toArbitrageCycleDTO(id, found))
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i].ProfitPct > cycles[j].ProfitPct
+	})
+	return cycles
+}
+
+// buildGraph finds every triangle among the symbols of an exchange. Each
+// symbol BASE-QUOTE is an edge from QUOTE to BASE by buying and from BASE to
+// QUOTE by selling. It is called with mu held.
+func (s *TriangularScanner) buildGraph(exchangeID string, venue *venueGraph) {
+	edges := make(map[string]map[string]cycleStep)
+	addEdge := func(from, to string, step cycleStep) {
+		if edges[from] == nil {
+			edges[from] = make(map[string]cycleStep)
+		}
+		edges[from][to] = step
+	}
+	for symbol := range venue.books {
+		base, quote, ok := strings.Cut(symbol, "-")
+		if !ok || base == quote {
+			continue
+		}
+		addEdge(quote, base, cycleStep{symbol: symbol, side: entity.TradeBuy})
+		addEdge(base, quote, cycleStep{symbol: symbol, side: entity.TradeSell})
+	}
+
+	venue.bySymbol = make(map[string][]*triangle)
+	for a, out := range edges {
+		for b, first := range out {
+			for c, second := range edges[b] {
+				third, ok := edges[c][a]
+				if c == a || !ok {
+					continue
+				}
+				// Each cycle is found once from each of its currencies; keep
+				// the one that starts from the preferred currency
+				if !s.startsBefore(a, b) || !s.startsBefore(a, c) {
+					continue
+				}
+				t := &triangle{steps: [3]cycleStep{first, second, third}}
+				t.id = cycleID(exchangeID, t.steps)
+				for _, step := range t.steps {
+					venue.bySymbol[step.symbol] = append(venue.bySymbol[step.symbol], t)
+				}
+			}
+		}
+	}
+}
+
+// evaluate re-checks one triangle. It is called with mu held.
+func (s *TriangularScanner) evaluate(exchangeID string, venue *venueGraph, t *triangle, now time.Time) {
+	books, fresh := s.legBooks(venue, t.steps, now)
+	if !fresh {
+		delete(s.found, t.id)
+		return
+	}
+
+	legs := make([]domainservice.CycleLeg, len(t.steps))
+	for i, step := range t.steps {
+		legs[i] = domainservice.CycleLeg{
+			Book: books[i],
+			Side: step.side,
+			Fee:  s.fees.TakerFee(exchangeID, step.symbol),
+		}
+	}
+	cycle, ok := s.orderbookSvc.EvaluateCycle(legs, s.cfg.MinProfitPct)
+	if !ok {
+		delete(s.found, t.id)
+		return
+	}
+
+	found, ok := s.found[t.id]
+	if !ok {
+		found = &foundCycle{detectedAt: now, steps: t.steps}
+		s.found[t.id] = found
+	}
+	found.cycle, found.updatedAt = cycle, now
+}
+
+// legBooks returns the books of a cycle's legs and whether all are fresh
+func (s *TriangularScanner) legBooks(venue *venueGraph, steps [3]cycleStep, now time.Time) ([]*entity.OrderBook, bool) {
+	if venue == nil {
+		return nil, false
+	}
+	books := make([]*entity.OrderBook, len(steps))
+	for i, step := range steps {
+		quote, ok := venue.books[step.symbol]
+		if !ok || (s.cfg.MaxBookAge > 0 && now.Sub(quote.received) > s.cfg.MaxBookAge) {
+			return nil, false
+		}
+		books[i] = quote.book
+	}
+	return books, true
+}
+
+// startsBefore reports whether a cycle through both currencies should start
+// from a rather than b
+func (s *TriangularScanner) startsBefore(a, b string) bool {
+	rank := func(currency string) int {
+		if i := slices.Index(s.cfg.StartCurrencies, currency); i >= 0 {
+			return i
+		}
+		return len(s.cfg.StartCurrencies)
+	}
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra < rb
+	}
+	return a < b
+}
+
+// cycleID names a cycle by its exchange and legs, e.g.
+// binance:BUY BTC-USDT|BUY ETH-BTC|SELL ETH-USDT
+func cycleID(exchangeID string, steps [3]cycleStep) string {
+	legs := make([]string, len(steps))
+	for i, step := range steps {
+		legs[i] = fmt.Sprintf("%s %s", step.side, step.symbol)
+	}
+	return exchangeID + ":" + strings.Join(legs, "|")
+}
+
+func toArbitrageCycleDTO(id string, found *foundCycle) *dto.ArbitrageCycleDTO {
+	cycle := found.cycle
+	legs := make([]dto.CycleLegDTO, len(cycle.Legs))
+	for i, leg := range cycle.Legs {
+		legs[i] = dto.CycleLegDTO{
+			Symbol:       leg.Symbol,
+			Side:         string(leg.Side),
+			FromCurrency: leg.FromCurrency,
+			ToCurrency:   leg.ToCurrency,
+			Price:        leg.Price,
+			AmountIn:     leg.AmountIn,
+			AmountOut:    leg.AmountOut,
+		}
+	}
+
+	return &dto.ArbitrageCycleDTO{
+		ID:            id,
+		ExchangeID:    cycle.ExchangeID,
+		StartCurrency: cycle.StartCurrency,
+		Legs:          legs,
+		StartAmount:   cycle.StartAmount,
+		EndAmount:     cycle.EndAmount,
+		ProfitPct:     cycle.ProfitPct,
+		DetectedAt:    found.detectedAt,
+		UpdatedAt:     found.updatedAt,
+	}
+}
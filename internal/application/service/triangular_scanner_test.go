@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"marketdata/internal/application/dto"
+	domainservice "marketdata/internal/domain/service"
+)
+
+func venueBook(symbol string, bid, ask [2]float64) *dto.OrderBookDTO {
+	return &dto.OrderBookDTO{
+		ExchangeID: "x",
+		Symbol:     symbol,
+		Bids:       []dto.PriceLevelDTO{{Price: bid[0], Quantity: bid[1]}},
+		Asks:       []dto.PriceLevelDTO{{Price: ask[0], Quantity: ask[1]}},
+	}
+}
+
+func TestTriangularScannerFindsCycles(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	scanner := NewTriangularScanner(TriangularScannerConfig{
+		MinProfitPct:    0.05,
+		StartCurrencies: []string{"USDT"},
+		MaxBookAge:      time.Second,
+	}, domainservice.NewOrderBookService(), flatFees(0.001))
+	scanner.now = func() time.Time { return now }
+
+	update := func(book *dto.OrderBookDTO) {
+		t.Helper()
+		if err := scanner.ProcessOrderBookUpdate(ctx, book); err != nil {
+			t.Fatalf("ProcessOrderBookUpdate(%s): %v", book.Symbol, err)
+		}
+	}
+	cycleIDs := func() []string {
+		var ids []string
+		for _, cycle := range scanner.ListCycles(ctx, "x") {
+			ids = append(ids, cycle.ID)
+		}
+		return ids
+	}
+
+	// BTC at 60000 and ETH at 3000 USDT put the fair ETH-BTC price at 0.05
+	update(venueBook("BTC-USDT", [2]float64{59990, 1}, [2]float64{60000, 0.5}))
+	update(venueBook("ETH-USDT", [2]float64{3000, 5}, [2]float64{3001, 5}))
+	update(venueBook("SOL-USDT", [2]float64{150, 5}, [2]float64{151, 5}))
+	if ids := cycleIDs(); len(ids) != 0 {
+		t.Fatalf("cycles without a third leg: %v", ids)
+	}
+
+	// ETH offered at 0.0495 BTC: the only profitable way round
+	update(venueBook("ETH-BTC", [2]float64{0.0494, 10}, [2]float64{0.0495, 4}))
+	cycles := scanner.ListCycles(ctx, "")
+	if len(cycles) != 1 {
+		t.Fatalf("got %d cycles, want 1", len(cycles))
+	}
+	cycle := cycles[0]
+	if cycle.ID != "x:BUY BTC-USDT|BUY ETH-BTC|SELL ETH-USDT" || cycle.StartCurrency != "USDT" {
+		t.Fatalf("cycle %s from %s", cycle.ID, cycle.StartCurrency)
+	}
+	if cycle.ProfitPct <= 0.05 || !cycle.DetectedAt.Equal(now) {
+		t.Fatalf("cycle returns %v%% detected at %v", cycle.ProfitPct, cycle.DetectedAt)
+	}
+
+	// A fair ETH-BTC book loses the fees both ways round
+	update(venueBook("ETH-BTC", [2]float64{0.04995, 10}, [2]float64{0.05005, 4}))
+	if ids := cycleIDs(); len(ids) != 0 {
+		t.Fatalf("cycles at fair prices: %v", ids)
+	}
+
+	// ETH bid at 0.0506 BTC runs the inverted cycle through ETH-BTC
+	update(venueBook("ETH-BTC", [2]float64{0.0506, 3}, [2]float64{0.0507, 4}))
+	if ids := cycleIDs(); len(ids) != 1 || ids[0] != "x:BUY ETH-USDT|SELL ETH-BTC|SELL BTC-USDT" {
+		t.Fatalf("inverted cycles = %v", ids)
+	}
+
+	// Only ETH-BTC is fresh once max_book_age passes
+	now = now.Add(1500 * time.Millisecond)
+	update(venueBook("ETH-BTC", [2]float64{0.0506, 3}, [2]float64{0.0507, 4}))
+	if ids := cycleIDs(); len(ids) != 0 {
+		t.Fatalf("cycles with stale legs: %v", ids)
+	}
+}
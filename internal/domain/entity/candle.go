@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"time"
+)
+
+type Candle struct {
+	exchangeID string
+	symbol     string
+	interval   time.Duration
+	openTime   time.Time
+	open       float64
+	high       float64
+	low        float64
+	close      float64
+	volume     float64
+	tradeCount int64
+}
+
+func NewCandle(
+	exchangeID string,
+	symbol string,
+	interval time.Duration,
+	openTime time.Time,
+	open, high, low, close, volume float64,
+	tradeCount int64,
+) *Candle {
+	return &Candle{
+		exchangeID: exchangeID,
+		symbol:     symbol,
+		interval:   interval,
+		openTime:   openTime,
+		open:       open,
+		high:       high,
+		low:        low,
+		close:      close,
+		volume:     volume,
+		tradeCount: tradeCount,
+	}
+}
+
+func (c *Candle) ExchangeID() string {
+	return c.exchangeID
+}
+
+func (c *Candle) Symbol() string {
+	return c.symbol
+}
+
+func (c *Candle) Interval() time.Duration {
+	return c.interval
+}
+
+func (c *Candle) OpenTime() time.Time {
+	return c.openTime
+}
+
+func (c *Candle) Open() float64 {
+	return c.open
+}
+
+func (c *Candle) High() float64 {
+	return c.high
+}
+
+func (c *Candle) Low() float64 {
+	return c.low
+}
+
+func (c *Candle) Close() float64 {
+	return c.close
+}
+
+func (c *Candle) Volume() float64 {
+	return c.volume
+}
+
+func (c *Candle) TradeCount() int64 {
+	return c.tradeCount
+}
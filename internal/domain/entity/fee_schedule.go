@@ -0,0 +1,145 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Liquidity is whether an order adds to the book or takes from it
+type Liquidity string
+
+const (
+	LiquidityMaker Liquidity = "maker"
+	LiquidityTaker Liquidity = "taker"
+)
+
+// FeeRates are fee rates as fractions of the notional, 0.001 for 0.1%. A
+// negative maker rate is a rebate.
+type FeeRates struct {
+	Maker float64
+	Taker float64
+}
+
+// Rate returns the rate paid for the given liquidity
+func (r FeeRates) Rate(liquidity Liquidity) float64 {
+	if liquidity == LiquidityMaker {
+		return r.Maker
+	}
+	return r.Taker
+}
+
+// Validate checks the rates are below 100%, the taker rate is not negative and
+// a maker rebate is below 100%
+func (r FeeRates) Validate() error {
+	if r.Taker < 0 || r.Taker >= 1 {
+		return fmt.Errorf("taker rate must be between 0 and 1, got %g", r.Taker)
+	}
+	if r.Maker <= -1 || r.Maker >= 1 {
+		return fmt.Errorf("maker rate must be between -1 and 1, got %g", r.Maker)
+	}
+	return nil
+}
+
+// FeeDiscount lowers the fees paid by Pct percent, such as the 25% Binance
+// takes off when fees are paid in BNB. It does not change rebates.
+type FeeDiscount struct {
+	Asset string
+	Pct   float64
+}
+
+// FeeSchedule is what an account pays on one exchange: the rates of its VIP
+// tier, overridden per symbol, less the fee asset discount
+type FeeSchedule struct {
+	exchangeID string
+	tier       string
+	rates      FeeRates
+	symbols    map[string]FeeRates
+	discount   FeeDiscount
+}
+
+// NewFeeSchedule validates the rates of a schedule. Symbols are matched
+// without their separator and case, so BTC-USDT and btcusdt share a rate.
+func NewFeeSchedule(
+	exchangeID string,
+	tier string,
+	rates FeeRates,
+	symbols map[string]FeeRates,
+	discount FeeDiscount,
+) (*FeeSchedule, error) {
+	if err := rates.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s fee rates: %w", exchangeID, err)
+	}
+	if discount.Pct < 0 || discount.Pct > 100 {
+		return nil, fmt.Errorf("invalid %s fee discount: must be between 0 and 100%%, got %g", exchangeID, discount.Pct)
+	}
+
+	normalized := make(map[string]FeeRates, len(symbols))
+	for symbol, symbolRates := range symbols {
+		if err := symbolRates.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s fee rates for %s: %w", exchangeID, symbol, err)
+		}
+		normalized[FeeSymbolKey(symbol)] = symbolRates
+	}
+
+	return &FeeSchedule{
+		exchangeID: exchangeID,
+		tier:       tier,
+		rates:      rates,
+		symbols:    normalized,
+		discount:   discount,
+	}, nil
+}
+
+func (s *FeeSchedule) ExchangeID() string {
+	return s.exchangeID
+}
+
+func (s *FeeSchedule) Tier() string {
+	return s.tier
+}
+
+func (s *FeeSchedule) Discount() FeeDiscount {
+	return s.discount
+}
+
+// SymbolOverrides is the number of symbols with their own rates
+func (s *FeeSchedule) SymbolOverrides() int {
+	return len(s.symbols)
+}
+
+// Rates returns the rates paid on a symbol after the discount; an empty
+// symbol gets the tier rates
+func (s *FeeSchedule) Rates(symbol string) FeeRates {
+	rates, ok := s.symbols[FeeSymbolKey(symbol)]
+	if !ok {
+		rates = s.rates
+	}
+	return FeeRates{
+		Maker: s.discounted(rates.Maker),
+		Taker: s.discounted(rates.Taker),
+	}
+}
+
+// Rate returns the rate paid on a symbol for the given liquidity
+func (s *FeeSchedule) Rate(symbol string, liquidity Liquidity) float64 {
+	return s.Rates(symbol).Rate(liquidity)
+}
+
+// Fee returns the fee paid on notional, in the currency of the notional; a
+// rebate is negative
+func (s *FeeSchedule) Fee(symbol string, liquidity Liquidity, notional float64) float64 {
+	return notional * s.Rate(symbol, liquidity)
+}
+
+func (s *FeeSchedule) discounted(rate float64) float64 {
+	if rate <= 0 {
+		return rate
+	}
+	return rate * (1 - s.discount.Pct/100)
+}
+
+// FeeSymbolKey is the form fee rates are looked up by: the symbol without
+// separators, in upper case
+func FeeSymbolKey(symbol string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", "/", "", "_", "").Replace(symbol))
+}
@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFeeScheduleRates(t *testing.T) {
+	schedule, err := NewFeeSchedule("binance", "vip1",
+		FeeRates{Maker: 0.0009, Taker: 0.001},
+		map[string]FeeRates{
+			"ETH-USDT": {Maker: -0.0001, Taker: 0.0005},
+			"btcusdt":  {Maker: 0.0002, Taker: 0.0004},
+		},
+		FeeDiscount{Asset: "BNB", Pct: 25},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		symbol    string
+		liquidity Liquidity
+		want      float64
+	}{
+		// Tier rates less the 25% discount
+		{"SOL-USDT", LiquidityMaker, 0.0009 * 0.75},
+		{"SOL-USDT", LiquidityTaker, 0.001 * 0.75},
+		{"", LiquidityTaker, 0.001 * 0.75},
+		// Overrides match without separator or case
+		{"BTC-USDT", LiquidityMaker, 0.0002 * 0.75},
+		{"BTC/USDT", LiquidityTaker, 0.0004 * 0.75},
+		{"ethusdt", LiquidityTaker, 0.0005 * 0.75},
+		// The discount does not shrink a rebate
+		{"ETH-USDT", LiquidityMaker, -0.0001},
+	}
+	for _, tt := range tests {
+		if got := schedule.Rate(tt.symbol, tt.liquidity); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("Rate(%q, %s) = %v, want %v", tt.symbol, tt.liquidity, got, tt.want)
+		}
+	}
+
+	if got, want := schedule.Fee("BTC-USDT", LiquidityTaker, 10000), 10000*0.0004*0.75; math.Abs(got-want) > 1e-9 {
+		t.Errorf("taker fee on 10000 = %v, want %v", got, want)
+	}
+	if got := schedule.Fee("ETH-USDT", LiquidityMaker, 10000); math.Abs(got+1) > 1e-9 {
+		t.Errorf("maker rebate on 10000 = %v, want -1", got)
+	}
+}
+
+func TestNewFeeScheduleValidates(t *testing.T) {
+	valid := FeeRates{Maker: 0.001, Taker: 0.001}
+	tests := map[string]struct {
+		rates    FeeRates
+		symbols  map[string]FeeRates
+		discount FeeDiscount
+	}{
+		"negative taker":        {rates: FeeRates{Taker: -0.001}},
+		"taker of 100%":         {rates: FeeRates{Taker: 1}},
+		"rebate of 100%":        {rates: FeeRates{Maker: -1, Taker: 0.001}},
+		"invalid symbol rates":  {rates: valid, symbols: map[string]FeeRates{"BTC-USDT": {Taker: 2}}},
+		"negative discount":     {rates: valid, discount: FeeDiscount{Pct: -5}},
+		"discount above 100pct": {rates: valid, discount: FeeDiscount{Pct: 101}},
+	}
+	for name, tt := range tests {
+		if _, err := NewFeeSchedule("binance", "", tt.rates, tt.symbols, tt.discount); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
@@ -29,6 +29,26 @@ func NewOrderBook(exchangeID, symbol string, timestamp time.Time) *OrderBook {
 	}
 }
 
+func (ob *OrderBook) ExchangeID() string {
+	return ob.exchangeID
+}
+
+func (ob *OrderBook) Symbol() string {
+	return ob.symbol
+}
+
+func (ob *OrderBook) Bids() []PriceLevel {
+	return ob.bids
+}
+
+func (ob *OrderBook) Asks() []PriceLevel {
+	return ob.asks
+}
+
+func (ob *OrderBook) Timestamp() time.Time {
+	return ob.timestamp
+}
+
 func (ob *OrderBook) UpdateBids(bids []PriceLevel) {
 	ob.bids = bids
 }
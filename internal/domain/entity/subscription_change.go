@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"time"
+)
+
+type SubscriptionAction string
+
+const (
+	SubscriptionAdded   SubscriptionAction = "subscribe"
+	SubscriptionUpdated SubscriptionAction = "update"
+	SubscriptionRemoved SubscriptionAction = "unsubscribe"
+)
+
+// SubscriptionSettings are how a symbol is streamed
+type SubscriptionSettings struct {
+	Depth           int
+	UpdateSpeed     time.Duration
+	PublishThrottle time.Duration
+	Record          bool
+}
+
+// SubscriptionChange records who started, changed or stopped an orderbook
+// subscription, with the settings before and after. A new subscription has no
+// previous settings and a removed one no current settings.
+type SubscriptionChange struct {
+	at         time.Time
+	actor      string
+	action     SubscriptionAction
+	exchangeID string
+	symbol     string
+	previous   *SubscriptionSettings
+	current    *SubscriptionSettings
+}
+
+func NewSubscriptionChange(
+	at time.Time,
+	actor string,
+	action SubscriptionAction,
+	exchangeID string,
+	symbol string,
+	previous *SubscriptionSettings,
+	current *SubscriptionSettings,
+) *SubscriptionChange {
+	return &SubscriptionChange{
+		at:         at,
+		actor:      actor,
+		action:     action,
+		exchangeID: exchangeID,
+		symbol:     symbol,
+		previous:   previous,
+		current:    current,
+	}
+}
+
+func (c *SubscriptionChange) At() time.Time {
+	return c.at
+}
+
+func (c *SubscriptionChange) Actor() string {
+	return c.actor
+}
+
+func (c *SubscriptionChange) Action() SubscriptionAction {
+	return c.action
+}
+
+func (c *SubscriptionChange) ExchangeID() string {
+	return c.exchangeID
+}
+
+func (c *SubscriptionChange) Symbol() string {
+	return c.symbol
+}
+
+// Previous returns the settings before the change, nil for a new subscription
+func (c *SubscriptionChange) Previous() *SubscriptionSettings {
+	return c.previous
+}
+
+// Current returns the settings after the change, nil for a removed
+// subscription
+func (c *SubscriptionChange) Current() *SubscriptionSettings {
+	return c.current
+}
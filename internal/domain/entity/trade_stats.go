@@ -0,0 +1,140 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// TradeStats summarizes the trades of one exchange/symbol within a time bucket
+type TradeStats struct {
+	exchangeID  string
+	symbol      string
+	interval    time.Duration
+	bucket      time.Time
+	open        float64
+	high        float64
+	low         float64
+	close       float64
+	volume      float64
+	quoteVolume float64
+	buyVolume   float64
+	sellVolume  float64
+	tradeCount  int64
+	buyCount    int64
+	sellCount   int64
+}
+
+func NewTradeStats(
+	exchangeID string,
+	symbol string,
+	interval time.Duration,
+	bucket time.Time,
+	open, high, low, close float64,
+	volume, quoteVolume, buyVolume, sellVolume float64,
+	tradeCount, buyCount, sellCount int64,
+) *TradeStats {
+	return &TradeStats{
+		exchangeID:  exchangeID,
+		symbol:      symbol,
+		interval:    interval,
+		bucket:      bucket,
+		open:        open,
+		high:        high,
+		low:         low,
+		close:       close,
+		volume:      volume,
+		quoteVolume: quoteVolume,
+		buyVolume:   buyVolume,
+		sellVolume:  sellVolume,
+		tradeCount:  tradeCount,
+		buyCount:    buyCount,
+		sellCount:   sellCount,
+	}
+}
+
+func (s *TradeStats) ExchangeID() string {
+	return s.exchangeID
+}
+
+func (s *TradeStats) Symbol() string {
+	return s.symbol
+}
+
+func (s *TradeStats) Interval() time.Duration {
+	return s.interval
+}
+
+func (s *TradeStats) Bucket() time.Time {
+	return s.bucket
+}
+
+func (s *TradeStats) Open() float64 {
+	return s.open
+}
+
+func (s *TradeStats) High() float64 {
+	return s.high
+}
+
+func (s *TradeStats) Low() float64 {
+	return s.low
+}
+
+func (s *TradeStats) Close() float64 {
+	return s.close
+}
+
+func (s *TradeStats) Volume() float64 {
+	return s.volume
+}
+
+func (s *TradeStats) QuoteVolume() float64 {
+	return s.quoteVolume
+}
+
+func (s *TradeStats) BuyVolume() float64 {
+	return s.buyVolume
+}
+
+func (s *TradeStats) SellVolume() float64 {
+	return s.sellVolume
+}
+
+func (s *TradeStats) TradeCount() int64 {
+	return s.tradeCount
+}
+
+func (s *TradeStats) BuyCount() int64 {
+	return s.buyCount
+}
+
+func (s *TradeStats) SellCount() int64 {
+	return s.sellCount
+}
+
+// VWAP is the volume-weighted average price, or 0 without volume
+func (s *TradeStats) VWAP() float64 {
+	if s.volume == 0 {
+		return 0
+	}
+	return s.quoteVolume / s.volume
+}
+
+// BuySellRatio is buy volume over sell volume; it is undefined without sells
+func (s *TradeStats) BuySellRatio() (float64, bool) {
+	if s.sellVolume == 0 {
+		return 0, false
+	}
+	return s.buyVolume / s.sellVolume, true
+}
+
+// RealizedVolatility estimates the bucket's volatility from its high/low range
+// using the Parkinson estimator, sqrt(ln(high/low)^2 / (4 ln 2)). It is not
+// annualized.
+func (s *TradeStats) RealizedVolatility() float64 {
+	if s.low <= 0 || s.high <= 0 {
+		return 0
+	}
+	r := math.Log(s.high / s.low)
+	return math.Sqrt(r * r / (4 * math.Ln2))
+}
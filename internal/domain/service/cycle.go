@@ -0,0 +1,157 @@
+package service
+
+import (
+	"math"
+	"strings"
+
+	"marketdata/internal/domain/entity"
+)
+
+// levelEpsilon treats a level as used up once less than this fraction of it
+// is left, so rounding does not leave dust to walk through
+const levelEpsilon = 1e-9
+
+// cycleLeg walks the levels of one leg. Amounts are in the currency the leg
+// spends: the quote when buying and the base when selling.
+type cycleLeg struct {
+	CycleLeg
+	levels []entity.PriceLevel
+	level  int
+	// left is what the current level still takes
+	left float64
+
+	spent    float64
+	received float64
+	gross    float64
+}
+
+func newCycleLeg(leg CycleLeg) *cycleLeg {
+	l := &cycleLeg{CycleLeg: leg, levels: leg.Book.Bids()}
+	if leg.Side == entity.TradeBuy {
+		l.levels = leg.Book.Asks()
+	}
+	l.left = l.capacity()
+	return l
+}
+
+func (l *cycleLeg) done() bool {
+	return l.level >= len(l.levels)
+}
+
+// capacity is what the current level takes in full
+func (l *cycleLeg) capacity() float64 {
+	if l.done() {
+		return 0
+	}
+	level := l.levels[l.level]
+	if l.Side == entity.TradeBuy {
+		return level.Price.Value() * level.Quantity.Value()
+	}
+	return level.Quantity.Value()
+}
+
+// grossRate is what the current level returns per unit spent before the fee
+func (l *cycleLeg) grossRate() float64 {
+	price := l.levels[l.level].Price.Value()
+	if l.Side == entity.TradeBuy {
+		if price <= 0 {
+			return 0
+		}
+		return 1 / price
+	}
+	return price
+}
+
+func (l *cycleLeg) rate() float64 {
+	return l.grossRate() * (1 - l.Fee)
+}
+
+// fill spends amount at the current level and returns what it receives
+func (l *cycleLeg) fill(amount float64) float64 {
+	gross := amount * l.grossRate()
+	l.spent += amount
+	l.gross += gross
+	l.received += gross * (1 - l.Fee)
+	l.left -= amount
+	return gross * (1 - l.Fee)
+}
+
+// advance moves past used up levels
+func (l *cycleLeg) advance() {
+	for !l.done() && l.left <= l.capacity()*levelEpsilon {
+		l.level++
+		l.left = l.capacity()
+	}
+}
+
+// EvaluateCycle walks the books of every leg together, sending amounts
+// around the cycle while a unit still comes back with more than minProfitPct
+// after fees. It reports false when not even the best levels do. The legs
+// must chain: each one spends the currency the previous one receives.
+func (s *orderBookService) EvaluateCycle(legs []CycleLeg, minProfitPct float64) (*ArbitrageCycle, bool) {
+	if len(legs) < 2 {
+		return nil, false
+	}
+	walks := make([]*cycleLeg, len(legs))
+	for i, leg := range legs {
+		walks[i] = newCycleLeg(leg)
+		walks[i].advance()
+	}
+
+	var start, end float64
+	for {
+		// The marginal return of the cycle, and the most the start currency
+		// can send before some leg runs out of its current level
+		rate, chunk := 1.0, math.Inf(1)
+		for _, walk := range walks {
+			if walk.done() {
+				rate = 0
+				break
+			}
+			chunk = math.Min(chunk, walk.left/rate)
+			rate *= walk.rate()
+		}
+		if (rate-1)*100 <= minProfitPct {
+			break
+		}
+
+		amount := chunk
+		start += chunk
+		for _, walk := range walks {
+			amount = walk.fill(amount)
+			walk.advance()
+		}
+		end += amount
+	}
+	if start <= 0 {
+		return nil, false
+	}
+
+	cycle := &ArbitrageCycle{
+		ExchangeID:  legs[0].Book.ExchangeID(),
+		StartAmount: start,
+		EndAmount:   end,
+		ProfitPct:   (end/start - 1) * 100,
+		Legs:        make([]CycleFill, len(walks)),
+	}
+	for i, walk := range walks {
+		base, quote, _ := strings.Cut(walk.Book.Symbol(), "-")
+		fill := CycleFill{
+			Symbol:       walk.Book.Symbol(),
+			Side:         walk.Side,
+			FromCurrency: base,
+			ToCurrency:   quote,
+			AmountIn:     walk.spent,
+			AmountOut:    walk.received,
+		}
+		if walk.Side == entity.TradeBuy {
+			fill.FromCurrency, fill.ToCurrency = quote, base
+			fill.Price = walk.spent / walk.gross
+		} else {
+			fill.Price = walk.gross / walk.spent
+		}
+		cycle.Legs[i] = fill
+	}
+	cycle.StartCurrency = cycle.Legs[0].FromCurrency
+	return cycle, true
+}
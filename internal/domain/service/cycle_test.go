@@ -0,0 +1,143 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"marketdata/internal/domain/entity"
+)
+
+func TestEvaluateCycle(t *testing.T) {
+	// BTC trades at 60000 USDT and ETH at 3000 USDT, so ETH-BTC is fair at 0.05
+	btc := newBook(t, "x", "BTC-USDT", [][2]float64{{59990, 1}}, [][2]float64{{60000, 0.5}})
+	eth := newBook(t, "x", "ETH-USDT", [][2]float64{{3000, 5}}, [][2]float64{{3001, 5}})
+
+	t.Run("profitable triangle", func(t *testing.T) {
+		// ETH-BTC offers 4 ETH at 0.0495: USDT buys BTC, BTC buys ETH below
+		// its fair price and ETH sells back for USDT
+		ethBTC := newBook(t, "x", "ETH-BTC", [][2]float64{{0.0494, 10}}, [][2]float64{{0.0495, 4}})
+		legs := []CycleLeg{
+			{Book: btc, Side: entity.TradeBuy},
+			{Book: ethBTC, Side: entity.TradeBuy},
+			{Book: eth, Side: entity.TradeSell},
+		}
+
+		cycle, ok := NewOrderBookService().EvaluateCycle(legs, 0.1)
+		if !ok {
+			t.Fatal("cycle not found")
+		}
+
+		// The 4 ETH on offer cost 0.198 BTC, which cost 11880 USDT, and
+		// sell for 12000 USDT
+		if cycle.ExchangeID != "x" || cycle.StartCurrency != "USDT" {
+			t.Fatalf("cycle on %s from %s", cycle.ExchangeID, cycle.StartCurrency)
+		}
+		if !near(cycle.StartAmount, 11880) || !near(cycle.EndAmount, 12000) {
+			t.Fatalf("start %v end %v, want 11880 and 12000", cycle.StartAmount, cycle.EndAmount)
+		}
+		if want := (12000.0/11880 - 1) * 100; !near(cycle.ProfitPct, want) {
+			t.Fatalf("profit %v%%, want %v%%", cycle.ProfitPct, want)
+		}
+
+		want := []CycleFill{
+			{Symbol: "BTC-USDT", Side: entity.TradeBuy, FromCurrency: "USDT", ToCurrency: "BTC", Price: 60000, AmountIn: 11880, AmountOut: 0.198},
+			{Symbol: "ETH-BTC", Side: entity.TradeBuy, FromCurrency: "BTC", ToCurrency: "ETH", Price: 0.0495, AmountIn: 0.198, AmountOut: 4},
+			{Symbol: "ETH-USDT", Side: entity.TradeSell, FromCurrency: "ETH", ToCurrency: "USDT", Price: 3000, AmountIn: 4, AmountOut: 12000},
+		}
+		assertFills(t, cycle.Legs, want)
+	})
+
+	t.Run("fees are taken on every leg", func(t *testing.T) {
+		ethBTC := newBook(t, "x", "ETH-BTC", [][2]float64{{0.0494, 10}}, [][2]float64{{0.0495, 4}})
+		legs := []CycleLeg{
+			{Book: btc, Side: entity.TradeBuy, Fee: 0.001},
+			{Book: ethBTC, Side: entity.TradeBuy, Fee: 0.001},
+			{Book: eth, Side: entity.TradeSell, Fee: 0.001},
+		}
+
+		cycle, ok := NewOrderBookService().EvaluateCycle(legs, 0)
+		if !ok {
+			t.Fatal("cycle not found")
+		}
+		if want := (3000/(60000*0.0495)*math.Pow(0.999, 3) - 1) * 100; !near(cycle.ProfitPct, want) {
+			t.Fatalf("profit %v%%, want %v%%", cycle.ProfitPct, want)
+		}
+		// The BTC received is net of the first fee, so less ETH is bought
+		if got := cycle.Legs[1].AmountIn; !near(got, cycle.Legs[0].AmountOut) {
+			t.Fatalf("second leg spends %v, first receives %v", got, cycle.Legs[0].AmountOut)
+		}
+	})
+
+	t.Run("inverted pair", func(t *testing.T) {
+		// ETH-BTC bids 0.0506, above the fair 0.05, so the cycle runs the other
+		// way: USDT buys ETH, ETH sells for BTC and BTC sells back for USDT
+		ethBTC := newBook(t, "x", "ETH-BTC", [][2]float64{{0.0506, 3}}, [][2]float64{{0.0507, 4}})
+		legs := []CycleLeg{
+			{Book: eth, Side: entity.TradeBuy},
+			{Book: ethBTC, Side: entity.TradeSell},
+			{Book: btc, Side: entity.TradeSell},
+		}
+
+		cycle, ok := NewOrderBookService().EvaluateCycle(legs, 0.1)
+		if !ok {
+			t.Fatal("cycle not found")
+		}
+
+		// 3 ETH cost 9003 USDT, sell for 0.1518 BTC and then 9106.482 USDT
+		want := []CycleFill{
+			{Symbol: "ETH-USDT", Side: entity.TradeBuy, FromCurrency: "USDT", ToCurrency: "ETH", Price: 3001, AmountIn: 9003, AmountOut: 3},
+			{Symbol: "ETH-BTC", Side: entity.TradeSell, FromCurrency: "ETH", ToCurrency: "BTC", Price: 0.0506, AmountIn: 3, AmountOut: 0.1518},
+			{Symbol: "BTC-USDT", Side: entity.TradeSell, FromCurrency: "BTC", ToCurrency: "USDT", Price: 59990, AmountIn: 0.1518, AmountOut: 0.1518 * 59990},
+		}
+		assertFills(t, cycle.Legs, want)
+		if !near(cycle.StartAmount, 9003) || !near(cycle.EndAmount, 0.1518*59990) {
+			t.Fatalf("start %v end %v", cycle.StartAmount, cycle.EndAmount)
+		}
+	})
+
+	t.Run("losing triangle", func(t *testing.T) {
+		// At 0.0505 ETH costs more in BTC than it sells for in USDT
+		ethBTC := newBook(t, "x", "ETH-BTC", [][2]float64{{0.0504, 10}}, [][2]float64{{0.0505, 4}})
+		legs := []CycleLeg{
+			{Book: btc, Side: entity.TradeBuy},
+			{Book: ethBTC, Side: entity.TradeBuy},
+			{Book: eth, Side: entity.TradeSell},
+		}
+
+		if cycle, ok := NewOrderBookService().EvaluateCycle(legs, 0); ok {
+			t.Fatalf("losing cycle reported: %+v", cycle)
+		}
+	})
+
+	t.Run("profit below the minimum", func(t *testing.T) {
+		ethBTC := newBook(t, "x", "ETH-BTC", [][2]float64{{0.0494, 10}}, [][2]float64{{0.0495, 4}})
+		legs := []CycleLeg{
+			{Book: btc, Side: entity.TradeBuy},
+			{Book: ethBTC, Side: entity.TradeBuy},
+			{Book: eth, Side: entity.TradeSell},
+		}
+
+		// The cycle returns about 1.01%
+		if cycle, ok := NewOrderBookService().EvaluateCycle(legs, 1.1); ok {
+			t.Fatalf("cycle under the minimum reported: %+v", cycle)
+		}
+	})
+}
+
+func assertFills(t *testing.T, got, want []CycleFill) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d legs, want %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Symbol != w.Symbol || g.Side != w.Side || g.FromCurrency != w.FromCurrency || g.ToCurrency != w.ToCurrency {
+			t.Fatalf("leg %d = %s %s %s->%s, want %s %s %s->%s", i,
+				g.Side, g.Symbol, g.FromCurrency, g.ToCurrency, w.Side, w.Symbol, w.FromCurrency, w.ToCurrency)
+		}
+		if !near(g.Price, w.Price) || !near(g.AmountIn, w.AmountIn) || !near(g.AmountOut, w.AmountOut) {
+			t.Fatalf("leg %d fills %v at %v for %v, want %v at %v for %v", i,
+				g.AmountIn, g.Price, g.AmountOut, w.AmountIn, w.Price, w.AmountOut)
+		}
+	}
+}
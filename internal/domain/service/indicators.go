@@ -0,0 +1,108 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"marketdata/internal/domain/entity"
+)
+
+// BookDepth is the liquidity of a book within a distance of its mid price.
+// Quantities are in the base currency and notionals in the quote.
+type BookDepth struct {
+	Bps         float64
+	BidQuantity float64
+	AskQuantity float64
+	BidNotional float64
+	AskNotional float64
+}
+
+// Imbalance is the share of the notional on the bid side less the share on
+// the ask side, from -1 when only asks are quoted to 1 when only bids are
+func (d BookDepth) Imbalance() float64 {
+	total := d.BidNotional + d.AskNotional
+	if total == 0 {
+		return 0
+	}
+	return (d.BidNotional - d.AskNotional) / total
+}
+
+// CalculateDepth sums the levels of a book priced within bps basis points of
+// its mid price
+func CalculateDepth(book *entity.OrderBook, bps float64) (BookDepth, error) {
+	bestBid, hasBid := book.BestBid()
+	bestAsk, hasAsk := book.BestAsk()
+	if !hasBid || !hasAsk {
+		return BookDepth{}, fmt.Errorf("orderbook %s:%s has no two-sided quote", book.ExchangeID(), book.Symbol())
+	}
+	mid := (bestBid.Price.Value() + bestAsk.Price.Value()) / 2
+	floor, ceiling := mid*(1-bps/10000), mid*(1+bps/10000)
+
+	depth := BookDepth{Bps: bps}
+	for _, level := range book.Bids() {
+		price := level.Price.Value()
+		if price < floor {
+			break
+		}
+		depth.BidQuantity += level.Quantity.Value()
+		depth.BidNotional += price * level.Quantity.Value()
+	}
+	for _, level := range book.Asks() {
+		price := level.Price.Value()
+		if price > ceiling {
+			break
+		}
+		depth.AskQuantity += level.Quantity.Value()
+		depth.AskNotional += price * level.Quantity.Value()
+	}
+	return depth, nil
+}
+
+// CalculateRealizedVolatility is the sample standard deviation of the log
+// returns between consecutive closes, as a fraction per interval. It is not
+// annualized.
+func CalculateRealizedVolatility(closes []float64) (float64, error) {
+	if len(closes) < 3 {
+		return 0, fmt.Errorf("at least 3 closes are required, got %d", len(closes))
+	}
+	returns := make([]float64, len(closes)-1)
+	for i := 1; i < len(closes); i++ {
+		if closes[i-1] <= 0 || closes[i] <= 0 {
+			return 0, fmt.Errorf("closes must be positive")
+		}
+		returns[i-1] = math.Log(closes[i] / closes[i-1])
+	}
+
+	m := mean(returns)
+	var sumSq float64
+	for _, r := range returns {
+		sumSq += (r - m) * (r - m)
+	}
+	return math.Sqrt(sumSq / float64(len(returns)-1)), nil
+}
+
+// CalculateATR is the average true range of candles, oldest first, with
+// Wilder's smoothing: the mean of the first period true ranges, then
+// (previous*(period-1) + true range) / period for each later candle
+func CalculateATR(candles []*entity.Candle, period int) (float64, error) {
+	if period < 1 {
+		return 0, fmt.Errorf("period must be positive, got %d", period)
+	}
+	if len(candles) < period+1 {
+		return 0, fmt.Errorf("at least %d candles are required, got %d", period+1, len(candles))
+	}
+
+	trueRange := func(i int) float64 {
+		prevClose := candles[i-1].Close()
+		return math.Max(candles[i].High(), prevClose) - math.Min(candles[i].Low(), prevClose)
+	}
+	var atr float64
+	for i := 1; i <= period; i++ {
+		atr += trueRange(i)
+	}
+	atr /= float64(period)
+	for i := period + 1; i < len(candles); i++ {
+		atr = (atr*float64(period-1) + trueRange(i)) / float64(period)
+	}
+	return atr, nil
+}
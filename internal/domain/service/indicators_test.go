@@ -0,0 +1,136 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"marketdata/internal/domain/entity"
+)
+
+func TestCalculateRealizedVolatility(t *testing.T) {
+	// Log returns of 0.01, -0.01 and 0.01: mean 0.01/3, squared deviations
+	// summing to 24/9 * 1e-4 over 2 degrees of freedom
+	closes := []float64{100, 100 * math.Exp(0.01), 100, 100 * math.Exp(0.01)}
+	vol, err := CalculateRealizedVolatility(closes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 0.01 * math.Sqrt(4.0/3); !near(vol, want) {
+		t.Errorf("volatility = %v, want %v", vol, want)
+	}
+
+	// Returns do not depend on the price level
+	scaled := make([]float64, len(closes))
+	for i, c := range closes {
+		scaled[i] = c * 600
+	}
+	if got, _ := CalculateRealizedVolatility(scaled); !near(got, vol) {
+		t.Errorf("volatility at 600x the price = %v, want %v", got, vol)
+	}
+
+	if got, err := CalculateRealizedVolatility([]float64{50, 50, 50}); err != nil || got != 0 {
+		t.Errorf("flat closes = %v, %v; want 0", got, err)
+	}
+
+	errorCases := map[string][]float64{
+		"two closes":     {100, 101},
+		"zero close":     {100, 0, 101},
+		"negative close": {100, 101, -1},
+	}
+	for name, closes := range errorCases {
+		if vol, err := CalculateRealizedVolatility(closes); err == nil {
+			t.Errorf("%s: got %v, want an error", name, vol)
+		}
+	}
+}
+
+func TestCalculateATR(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	var candles []*entity.Candle
+	// high, low, close
+	for i, c := range [][3]float64{
+		{101, 99, 100},
+		// True range 3, from the low to the high
+		{103, 100, 102},
+		// True range 4, from the low to the previous close
+		{102, 98, 99},
+		// Gapped down: true range 5, from the low to the previous close
+		{95, 94, 94.5},
+	} {
+		openTime := start.Add(time.Duration(i) * time.Minute)
+		candles = append(candles, entity.NewCandle("x", "BTC-USDT", time.Minute, openTime, c[2], c[0], c[1], c[2], 0, 0))
+	}
+
+	tests := []struct {
+		period int
+		want   float64
+	}{
+		// The mean of the only three true ranges
+		{3, 4},
+		// Seeded with (3+4)/2, then smoothed with 5: (3.5*1 + 5) / 2
+		{2, 4.25},
+		// With a period of 1 the ATR is the last true range
+		{1, 5},
+	}
+	for _, tt := range tests {
+		atr, err := CalculateATR(candles, tt.period)
+		if err != nil {
+			t.Fatalf("period %d: %v", tt.period, err)
+		}
+		if !near(atr, tt.want) {
+			t.Errorf("period %d: ATR = %v, want %v", tt.period, atr, tt.want)
+		}
+	}
+
+	if atr, err := CalculateATR(candles, 4); err == nil {
+		t.Errorf("period 4 of 4 candles: got %v, want an error", atr)
+	}
+	if atr, err := CalculateATR(candles, 0); err == nil {
+		t.Errorf("period 0: got %v, want an error", atr)
+	}
+}
+
+func TestCalculateDepth(t *testing.T) {
+	book := newBook(t, "x", "BTC-USDT",
+		[][2]float64{{100, 1}, {99.95, 2}, {99, 3}},
+		[][2]float64{{100.1, 1}, {100.2, 2}, {101, 4}},
+	)
+
+	// The mid is 100.05: 10bps reaches down to 99.95 and up to 100.1
+	depth, err := CalculateDepth(book, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDepth(t, depth, BookDepth{Bps: 10, BidQuantity: 3, AskQuantity: 1, BidNotional: 299.9, AskNotional: 100.1})
+	if got, want := depth.Imbalance(), (299.9-100.1)/400; !near(got, want) {
+		t.Errorf("imbalance = %v, want %v", got, want)
+	}
+
+	// 100bps takes every ask but not the bid at 99
+	depth, err = CalculateDepth(book, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDepth(t, depth, BookDepth{Bps: 100, BidQuantity: 3, AskQuantity: 7, BidNotional: 299.9, AskNotional: 704.5})
+
+	if got := (BookDepth{}).Imbalance(); got != 0 {
+		t.Errorf("imbalance of an empty depth = %v, want 0", got)
+	}
+	if got := (BookDepth{AskNotional: 10}).Imbalance(); got != -1 {
+		t.Errorf("imbalance of asks only = %v, want -1", got)
+	}
+
+	oneSided := newBook(t, "x", "BTC-USDT", [][2]float64{{100, 1}}, nil)
+	if depth, err := CalculateDepth(oneSided, 10); err == nil {
+		t.Errorf("one-sided book: got %+v, want an error", depth)
+	}
+}
+
+func assertDepth(t *testing.T, got, want BookDepth) {
+	t.Helper()
+	if got.Bps != want.Bps || !near(got.BidQuantity, want.BidQuantity) || !near(got.AskQuantity, want.AskQuantity) ||
+		!near(got.BidNotional, want.BidNotional) || !near(got.AskNotional, want.AskNotional) {
+		t.Errorf("depth = %+v, want %+v", got, want)
+	}
+}
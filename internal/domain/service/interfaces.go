@@ -8,6 +8,8 @@ type OrderBookDomainService interface {
 	ValidateOrderBook(orderbook *entity.OrderBook) error
 	CalculateSpread(orderbook *entity.OrderBook) (float64, error)
 	DetectArbitrageOpportunity(books []*entity.OrderBook) ([]*ArbitrageOpportunity, error)
+	EvaluateArbitrage(buy, sell *entity.OrderBook, costs ArbitrageCosts) (*ArbitrageOpportunity, bool)
+	EvaluateCycle(legs []CycleLeg, minProfitPct float64) (*ArbitrageCycle, bool)
 }
 
 type TradeDomainService interface {
@@ -21,4 +23,58 @@ type ArbitrageOpportunity struct {
 	Symbol       string
 	SpreadPct    float64
 	MaxVolume    float64
+
+	// Set by EvaluateArbitrage: the average fill prices of MaxVolume, what
+	// fees and walking the book cost, in percent, and the profit after fees
+	// in the quote currency
+	BuyPrice       float64
+	SellPrice      float64
+	FeesPct        float64
+	SlippagePct    float64
+	NetSpreadPct   float64
+	ExpectedProfit float64
+}
+
+// ArbitrageCosts are what an opportunity has to clear. Fees are taker rates
+// as fractions, 0.001 for 0.1%.
+type ArbitrageCosts struct {
+	BuyFee  float64
+	SellFee float64
+	// MinProfitPct is the spread, in percent, left after fees that every
+	// unit must earn
+	MinProfitPct float64
+}
+
+// CycleLeg is one conversion of a cycle on a single exchange: buying the base
+// of Book with its quote currency, or selling it for the quote
+type CycleLeg struct {
+	Book *entity.OrderBook
+	Side entity.TradeType
+	// Fee is the taker rate as a fraction
+	Fee float64
+}
+
+// ArbitrageCycle is a profitable sequence of conversions that ends in the
+// currency it starts with, such as USDT to BTC to ETH to USDT
+type ArbitrageCycle struct {
+	ExchangeID    string
+	StartCurrency string
+	Legs          []CycleFill
+	// StartAmount is the most that can go around the cycle with every unit
+	// still earning the minimum profit, and EndAmount what comes back
+	StartAmount float64
+	EndAmount   float64
+	ProfitPct   float64
+}
+
+// CycleFill is how one leg of a cycle fills: Price is the average price in
+// the symbol's quote currency and AmountOut is net of the fee
+type CycleFill struct {
+	Symbol       string
+	Side         entity.TradeType
+	FromCurrency string
+	ToCurrency   string
+	Price        float64
+	AmountIn     float64
+	AmountOut    float64
 }
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"marketdata/internal/domain/entity"
+)
+
+type orderBookService struct{}
+
+// NewOrderBookService creates the default OrderBookDomainService
+func NewOrderBookService() OrderBookDomainService {
+	return &orderBookService{}
+}
+
+// ValidateOrderBook checks that the book is identified, sorted and not crossed
+func (s *orderBookService) ValidateOrderBook(orderbook *entity.OrderBook) error {
+	if orderbook == nil {
+		return fmt.Errorf("orderbook is nil")
+	}
+	if orderbook.ExchangeID() == "" || orderbook.Symbol() == "" {
+		return fmt.Errorf("orderbook must have exchange and symbol")
+	}
+
+	bids := orderbook.Bids()
+	for i := 1; i < len(bids); i++ {
+		if bids[i].Price.Value() > bids[i-1].Price.Value() {
+			return fmt.Errorf("bids are not sorted in descending order")
+		}
+	}
+
+	asks := orderbook.Asks()
+	for i := 1; i < len(asks); i++ {
+		if asks[i].Price.Value() < asks[i-1].Price.Value() {
+			return fmt.Errorf("asks are not sorted in ascending order")
+		}
+	}
+
+	bestBid, hasBid := orderbook.BestBid()
+	bestAsk, hasAsk := orderbook.BestAsk()
+	if hasBid && hasAsk && bestBid.Price.Value() >= bestAsk.Price.Value() {
+		return fmt.Errorf("orderbook is crossed: bid %f >= ask %f", bestBid.Price.Value(), bestAsk.Price.Value())
+	}
+
+	return nil
+}
+
+// CalculateSpread returns the top-of-book spread as a percentage of the best bid
+func (s *orderBookService) CalculateSpread(orderbook *entity.OrderBook) (float64, error) {
+	bestBid, hasBid := orderbook.BestBid()
+	bestAsk, hasAsk := orderbook.BestAsk()
+	if !hasBid || !hasAsk {
+		return 0, fmt.Errorf("orderbook %s:%s has no two-sided quote", orderbook.ExchangeID(), orderbook.Symbol())
+	}
+	if bestBid.Price.Value() == 0 {
+		return 0, fmt.Errorf("best bid price is zero")
+	}
+
+	return (bestAsk.Price.Value() - bestBid.Price.Value()) / bestBid.Price.Value() * 100, nil
+}
+
+// DetectArbitrageOpportunity compares the top of book of every pair of exchanges
+// quoting the same symbol and reports where one venue's bid exceeds another's ask
+func (s *orderBookService) DetectArbitrageOpportunity(books []*entity.OrderBook) ([]*ArbitrageOpportunity, error) {
+	bySymbol := make(map[string][]*entity.OrderBook)
+	for _, book := range books {
+		bySymbol[book.Symbol()] = append(bySymbol[book.Symbol()], book)
+	}
+
+	var opportunities []*ArbitrageOpportunity
+	for symbol, group := range bySymbol {
+		for _, buy := range group {
+			ask, ok := buy.BestAsk()
+			if !ok || ask.Price.Value() == 0 {
+				continue
+			}
+			for _, sell := range group {
+				if sell.ExchangeID() == buy.ExchangeID() {
+					continue
+				}
+				bid, ok := sell.BestBid()
+				if !ok || bid.Price.Value() <= ask.Price.Value() {
+					continue
+				}
+
+				opportunities = append(opportunities, &ArbitrageOpportunity{
+					BuyExchange:  buy.ExchangeID(),
+					SellExchange: sell.ExchangeID(),
+					Symbol:       symbol,
+					SpreadPct:    (bid.Price.Value() - ask.Price.Value()) / ask.Price.Value() * 100,
+					MaxVolume:    math.Min(ask.Quantity.Value(), bid.Quantity.Value()),
+				})
+			}
+		}
+	}
+
+	sort.Slice(opportunities, func(i, j int) bool {
+		return opportunities[i].SpreadPct > opportunities[j].SpreadPct
+	})
+
+	return opportunities, nil
+}
+
+// EvaluateArbitrage walks the asks of buy and the bids of sell together,
+// taking every unit whose spread is more than the fees plus MinProfitPct, and
+// reports the average fill prices of that volume. It reports false when not
+// even the best levels clear the costs.
+func (s *orderBookService) EvaluateArbitrage(buy, sell *entity.OrderBook, costs ArbitrageCosts) (*ArbitrageOpportunity, bool) {
+	asks, bids := buy.Asks(), sell.Bids()
+	if len(asks) == 0 || len(bids) == 0 {
+		return nil, false
+	}
+	feesPct := (costs.BuyFee + costs.SellFee) * 100
+
+	var volume, cost, proceeds float64
+	i, j := 0, 0
+	askLeft, bidLeft := asks[0].Quantity.Value(), bids[0].Quantity.Value()
+	for i < len(asks) && j < len(bids) {
+		ask, bid := asks[i].Price.Value(), bids[j].Price.Value()
+		if ask <= 0 || (bid-ask)/ask*100-feesPct <= costs.MinProfitPct {
+			break
+		}
+
+		quantity := math.Min(askLeft, bidLeft)
+		volume += quantity
+		cost += quantity * ask
+		proceeds += quantity * bid
+		askLeft -= quantity
+		bidLeft -= quantity
+
+		if askLeft <= 0 {
+			if i++; i < len(asks) {
+				askLeft = asks[i].Quantity.Value()
+			}
+		}
+		if bidLeft <= 0 {
+			if j++; j < len(bids) {
+				bidLeft = bids[j].Quantity.Value()
+			}
+		}
+	}
+	if volume <= 0 {
+		return nil, false
+	}
+
+	buyPrice, sellPrice := cost/volume, proceeds/volume
+	bestAsk, bestBid := asks[0].Price.Value(), bids[0].Price.Value()
+	spreadPct := (sellPrice - buyPrice) / buyPrice * 100
+
+	return &ArbitrageOpportunity{
+		BuyExchange:    buy.ExchangeID(),
+		SellExchange:   sell.ExchangeID(),
+		Symbol:         buy.Symbol(),
+		SpreadPct:      spreadPct,
+		MaxVolume:      volume,
+		BuyPrice:       buyPrice,
+		SellPrice:      sellPrice,
+		FeesPct:        feesPct,
+		SlippagePct:    ((buyPrice-bestAsk)/bestAsk + (bestBid-sellPrice)/bestBid) * 100,
+		NetSpreadPct:   spreadPct - feesPct,
+		ExpectedProfit: proceeds*(1-costs.SellFee) - cost*(1+costs.BuyFee),
+	}, true
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"marketdata/internal/domain/entity"
+	"marketdata/internal/domain/valueobject"
+)
+
+const epsilon = 1e-9
+
+func newBook(t *testing.T, exchangeID, symbol string, bids, asks [][2]float64) *entity.OrderBook {
+	t.Helper()
+	book := entity.NewOrderBook(exchangeID, symbol, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	book.UpdateBids(newLevels(t, bids))
+	book.UpdateAsks(newLevels(t, asks))
+	return book
+}
+
+func newLevels(t *testing.T, levels [][2]float64) []entity.PriceLevel {
+	t.Helper()
+	out := make([]entity.PriceLevel, len(levels))
+	for i, level := range levels {
+		price, err := valueobject.NewPrice(level[0], "USDT")
+		if err != nil {
+			t.Fatal(err)
+		}
+		quantity, err := valueobject.NewVolume(level[1], "BTC")
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[i] = entity.PriceLevel{Price: *price, Quantity: *quantity}
+	}
+	return out
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < epsilon
+}
+
+func TestEvaluateArbitrage(t *testing.T) {
+	tests := []struct {
+		name  string
+		asks  [][2]float64
+		bids  [][2]float64
+		costs ArbitrageCosts
+		ok    bool
+		// expectations when ok
+		volume, buyPrice, sellPrice, feesPct, netSpreadPct, profit float64
+	}{
+		{
+			name: "best levels only",
+			asks: [][2]float64{{100, 1}, {102, 5}},
+			bids: [][2]float64{{101, 2}, {99, 5}},
+			ok:   true, volume: 1, buyPrice: 100, sellPrice: 101, netSpreadPct: 1, profit: 1,
+		},
+		{
+			// 50 @ 100 and 10 @ 100.1 fill the 60 bid at 100.5, the other
+			// 20 @ 100.1 go to the bid at 100.15, and the ask at 100.2 no
+			// longer clears it
+			name:   "walks the depth of both books",
+			asks:   [][2]float64{{100, 50}, {100.1, 30}, {100.2, 20}},
+			bids:   [][2]float64{{100.5, 60}, {100.15, 100}},
+			ok:     true,
+			volume: 80, buyPrice: (50*100 + 30*100.1) / 80.0, sellPrice: (60*100.5 + 20*100.15) / 80.0,
+			netSpreadPct: ((60*100.5 + 20*100.15) - (50*100 + 30*100.1)) / (50*100 + 30*100.1) * 100,
+			profit:       (60*100.5 + 20*100.15) - (50*100 + 30*100.1),
+		},
+		{
+			// 0.2% of fees leave 0.8% of the 1% spread
+			name:  "fees are deducted",
+			asks:  [][2]float64{{100, 1}},
+			bids:  [][2]float64{{101, 1}},
+			costs: ArbitrageCosts{BuyFee: 0.001, SellFee: 0.001},
+			ok:    true, volume: 1, buyPrice: 100, sellPrice: 101, feesPct: 0.2, netSpreadPct: 0.8,
+			profit: 101*0.999 - 100*1.001,
+		},
+		{
+			name:  "fees larger than the spread",
+			asks:  [][2]float64{{100, 1}},
+			bids:  [][2]float64{{100.1, 1}},
+			costs: ArbitrageCosts{BuyFee: 0.001, SellFee: 0.001},
+		},
+		{
+			name:  "just above the minimum profit",
+			asks:  [][2]float64{{100, 1}},
+			bids:  [][2]float64{{101, 1}},
+			costs: ArbitrageCosts{MinProfitPct: 0.99},
+			ok:    true, volume: 1, buyPrice: 100, sellPrice: 101, netSpreadPct: 1, profit: 1,
+		},
+		{
+			// Every unit must earn more than the minimum, not exactly it
+			name:  "exactly the minimum profit",
+			asks:  [][2]float64{{100, 1}},
+			bids:  [][2]float64{{101, 1}},
+			costs: ArbitrageCosts{MinProfitPct: 1},
+		},
+		{
+			// The second ask only clears 0.5%, under the 0.6% minimum
+			name:   "minimum profit stops the depth walk",
+			asks:   [][2]float64{{100, 1}, {100.5, 1}},
+			bids:   [][2]float64{{101, 2}},
+			costs:  ArbitrageCosts{MinProfitPct: 0.6},
+			ok:     true,
+			volume: 1, buyPrice: 100, sellPrice: 101, netSpreadPct: 1, profit: 1,
+		},
+		{
+			name: "crossed the wrong way",
+			asks: [][2]float64{{101, 1}},
+			bids: [][2]float64{{100, 1}},
+		},
+		{
+			name: "empty book",
+			bids: [][2]float64{{100, 1}},
+		},
+	}
+
+	svc := NewOrderBookService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buy := newBook(t, "a", "BTC-USDT", nil, tt.asks)
+			sell := newBook(t, "b", "BTC-USDT", tt.bids, nil)
+
+			got, ok := svc.EvaluateArbitrage(buy, sell, tt.costs)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v (%+v)", ok, tt.ok, got)
+			}
+			if !ok {
+				return
+			}
+
+			if got.BuyExchange != "a" || got.SellExchange != "b" || got.Symbol != "BTC-USDT" {
+				t.Fatalf("legs = %s -> %s %s", got.BuyExchange, got.SellExchange, got.Symbol)
+			}
+			checks := []struct {
+				field     string
+				got, want float64
+			}{
+				{"MaxVolume", got.MaxVolume, tt.volume},
+				{"BuyPrice", got.BuyPrice, tt.buyPrice},
+				{"SellPrice", got.SellPrice, tt.sellPrice},
+				{"FeesPct", got.FeesPct, tt.feesPct},
+				{"NetSpreadPct", got.NetSpreadPct, tt.netSpreadPct},
+				{"SpreadPct", got.SpreadPct, tt.netSpreadPct + tt.feesPct},
+				{"ExpectedProfit", got.ExpectedProfit, tt.profit},
+			}
+			for _, c := range checks {
+				if !near(c.got, c.want) {
+					t.Errorf("%s = %v, want %v", c.field, c.got, c.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"math"
+)
+
+// Risk limit names
+const (
+	RiskLimitPerTrade    = "per_trade"
+	RiskLimitDailyLoss   = "daily_loss"
+	RiskLimitMaxDrawdown = "max_drawdown"
+)
+
+// RiskLimits are the losses an account may take, in percent: 0.2 stops at a
+// 0.2% loss. A zero limit is not enforced.
+type RiskLimits struct {
+	// PerTradePct is the loss of one trade, relative to the equity before it
+	PerTradePct float64
+	// DailyLossPct is the loss since the start of the day, relative to the
+	// equity the day started with
+	DailyLossPct float64
+	// MaxDrawdownPct is the loss from the peak equity, relative to the peak
+	MaxDrawdownPct float64
+}
+
+// RiskBreach is a loss beyond a limit
+type RiskBreach struct {
+	Limit    string
+	LossPct  float64
+	LimitPct float64
+}
+
+func (b *RiskBreach) Error() string {
+	return fmt.Sprintf("%s limit breached: loss of %.4g%% exceeds %g%%", b.Limit, b.LossPct, b.LimitPct)
+}
+
+// AccountRisk is the equity of an account now, when the day started and at
+// its peak
+type AccountRisk struct {
+	Equity         float64
+	DayStartEquity float64
+	PeakEquity     float64
+}
+
+// DailyLossPct is the loss since the day started in percent, negative for a
+// gain
+func (a AccountRisk) DailyLossPct() float64 {
+	return lossPct(a.DayStartEquity, a.Equity)
+}
+
+// DrawdownPct is the loss from the peak equity in percent
+func (a AccountRisk) DrawdownPct() float64 {
+	return math.Max(lossPct(a.PeakEquity, a.Equity), 0)
+}
+
+// CheckRiskLimits returns the daily loss or drawdown limit the account is
+// beyond, or nil
+func CheckRiskLimits(limits RiskLimits, account AccountRisk) *RiskBreach {
+	if loss := account.DailyLossPct(); limits.DailyLossPct > 0 && loss > limits.DailyLossPct {
+		return &RiskBreach{Limit: RiskLimitDailyLoss, LossPct: loss, LimitPct: limits.DailyLossPct}
+	}
+	if loss := account.DrawdownPct(); limits.MaxDrawdownPct > 0 && loss > limits.MaxDrawdownPct {
+		return &RiskBreach{Limit: RiskLimitMaxDrawdown, LossPct: loss, LimitPct: limits.MaxDrawdownPct}
+	}
+	return nil
+}
+
+// CheckTradeRisk returns the limit a trade would breach if it lost maxLoss,
+// in the account currency: the per-trade limit, or the daily loss and
+// drawdown limits of the account after the loss
+func CheckTradeRisk(limits RiskLimits, account AccountRisk, maxLoss float64) *RiskBreach {
+	if loss := lossPct(account.Equity, account.Equity-maxLoss); limits.PerTradePct > 0 && loss > limits.PerTradePct {
+		return &RiskBreach{Limit: RiskLimitPerTrade, LossPct: loss, LimitPct: limits.PerTradePct}
+	}
+	account.Equity -= maxLoss
+	return CheckRiskLimits(limits, account)
+}
+
+func lossPct(from, to float64) float64 {
+	if from <= 0 {
+		return 0
+	}
+	return (from - to) / from * 100
+}
+
+// Position is a holding of one symbol at the average price it was opened
+// at. A positive Quantity is long and a negative one short.
+type Position struct {
+	Quantity float64
+	AvgPrice float64
+}
+
+// positionEpsilon is the quantity below which a position is flat, so float
+// residue does not leave dust positions behind
+const positionEpsilon = 1e-12
+
+// Fill applies a fill of quantity at price, negative to sell, and returns the
+// new position and the PnL the fill realized before fees. Fills that reduce
+// the position realize against its average price; a fill that flips it opens
+// the remainder at price.
+func (p Position) Fill(quantity, price float64) (Position, float64) {
+	if p.Quantity == 0 || (p.Quantity > 0) == (quantity > 0) {
+		total := p.Quantity + quantity
+		return Position{
+			Quantity: total,
+			AvgPrice: (p.Quantity*p.AvgPrice + quantity*price) / total,
+		}, 0
+	}
+
+	closed := math.Min(math.Abs(quantity), math.Abs(p.Quantity))
+	if p.Quantity < 0 {
+		closed = -closed
+	}
+	realized := closed * (price - p.AvgPrice)
+
+	remaining := p.Quantity + quantity
+	switch {
+	case math.Abs(remaining) < positionEpsilon:
+		return Position{}, realized
+	case (remaining > 0) == (p.Quantity > 0):
+		return Position{Quantity: remaining, AvgPrice: p.AvgPrice}, realized
+	default:
+		return Position{Quantity: remaining, AvgPrice: price}, realized
+	}
+}
+
+// UnrealizedPnL is what closing the position at mark would realize
+func (p Position) UnrealizedPnL(mark float64) float64 {
+	return p.Quantity * (mark - p.AvgPrice)
+}
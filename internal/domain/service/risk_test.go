@@ -0,0 +1,116 @@
+package service
+
+import "testing"
+
+func TestCheckRiskLimits(t *testing.T) {
+	limits := RiskLimits{DailyLossPct: 1, MaxDrawdownPct: 5}
+	tests := []struct {
+		name    string
+		limits  RiskLimits
+		account AccountRisk
+		limit   string
+		lossPct float64
+	}{
+		{
+			name:    "within the limits",
+			limits:  limits,
+			account: AccountRisk{Equity: 99100, DayStartEquity: 100000, PeakEquity: 100000},
+		},
+		{
+			name:    "daily loss",
+			limits:  limits,
+			account: AccountRisk{Equity: 98900, DayStartEquity: 100000, PeakEquity: 100000},
+			limit:   RiskLimitDailyLoss,
+			lossPct: 1.1,
+		},
+		{
+			// Up on the day but 6000 below a peak of 105000
+			name:    "drawdown on a winning day",
+			limits:  limits,
+			account: AccountRisk{Equity: 99000, DayStartEquity: 94000, PeakEquity: 105000},
+			limit:   RiskLimitMaxDrawdown,
+			lossPct: 6000.0 / 105000 * 100,
+		},
+		{
+			name:    "zero limits are not enforced",
+			account: AccountRisk{Equity: 50000, DayStartEquity: 100000, PeakEquity: 100000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertBreach(t, CheckRiskLimits(tt.limits, tt.account), tt.limit, tt.lossPct)
+		})
+	}
+}
+
+func TestCheckTradeRisk(t *testing.T) {
+	limits := RiskLimits{PerTradePct: 0.2, DailyLossPct: 0.5}
+	account := AccountRisk{Equity: 99500, DayStartEquity: 100000, PeakEquity: 100000}
+	tests := []struct {
+		name    string
+		maxLoss float64
+		limit   string
+		lossPct float64
+	}{
+		// 0.1% of the equity, leaving the day 0.6% down
+		{name: "daily loss after the trade", maxLoss: 99.5, limit: RiskLimitDailyLoss, lossPct: 0.5995},
+		// 0.25% of the equity
+		{name: "per trade", maxLoss: 248.75, limit: RiskLimitPerTrade, lossPct: 0.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertBreach(t, CheckTradeRisk(limits, account, tt.maxLoss), tt.limit, tt.lossPct)
+		})
+	}
+
+	t.Run("within the limits", func(t *testing.T) {
+		limits := RiskLimits{PerTradePct: 0.2, DailyLossPct: 1}
+		assertBreach(t, CheckTradeRisk(limits, account, 99.5), "", 0)
+	})
+}
+
+func TestPositionFill(t *testing.T) {
+	var p Position
+	steps := []struct {
+		quantity, price float64
+		want            Position
+		realized        float64
+	}{
+		{1, 100, Position{Quantity: 1, AvgPrice: 100}, 0},
+		// Adding to the position averages its price
+		{1, 110, Position{Quantity: 2, AvgPrice: 105}, 0},
+		// Reducing it realizes against the average price
+		{-0.5, 115, Position{Quantity: 1.5, AvgPrice: 105}, 5},
+		// Selling through it closes 1.5 and opens a short at the fill price
+		{-2, 100, Position{Quantity: -0.5, AvgPrice: 100}, -7.5},
+		{0.5, 90, Position{}, 5},
+	}
+	for i, step := range steps {
+		var realized float64
+		p, realized = p.Fill(step.quantity, step.price)
+		if !near(p.Quantity, step.want.Quantity) || !near(p.AvgPrice, step.want.AvgPrice) || !near(realized, step.realized) {
+			t.Fatalf("step %d: position %+v realizing %v, want %+v realizing %v", i, p, realized, step.want, step.realized)
+		}
+	}
+
+	short := Position{Quantity: -0.5, AvgPrice: 100}
+	if got := short.UnrealizedPnL(90); !near(got, 5) {
+		t.Errorf("short unrealized PnL = %v, want 5", got)
+	}
+}
+
+func assertBreach(t *testing.T, breach *RiskBreach, limit string, lossPct float64) {
+	t.Helper()
+	if limit == "" {
+		if breach != nil {
+			t.Fatalf("unexpected breach: %v", breach)
+		}
+		return
+	}
+	if breach == nil {
+		t.Fatalf("no breach, want %s", limit)
+	}
+	if breach.Limit != limit || !near(breach.LossPct, lossPct) {
+		t.Fatalf("breach of %s at %v%%, want %s at %v%%", breach.Limit, breach.LossPct, limit, lossPct)
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"fmt"
+	"math"
+)
+
+// Position size limit names
+const (
+	SizeLimitAccount      = "account"
+	SizeLimitLiquidity    = "liquidity"
+	SizeLimitVolatility   = "volatility"
+	SizeLimitQuoteBalance = "quote_balance"
+	SizeLimitBaseBalance  = "base_balance"
+)
+
+// SizeLimit is the most one constraint allows a position to be worth, in the
+// quote currency
+type SizeLimit struct {
+	Name     string
+	Notional float64
+}
+
+// PositionSize is how much to trade: Quantity is rounded down to the lot
+// size, Notional is its worth at the sizing price and Binding names the
+// smallest of Limits
+type PositionSize struct {
+	Quantity float64
+	Notional float64
+	Binding  string
+	Limits   []SizeLimit
+}
+
+// lotEpsilon absorbs float error when a quantity is a whole number of lots,
+// so 0.3/0.1 is not floored to 2 lots
+const lotEpsilon = 1e-9
+
+// lotDecimals is the most decimal places a lot size may have to be combined
+// with others
+const lotDecimals = 12
+
+// CommonLotSize is the smallest lot that is a whole number of each of
+// lotSizes, so a quantity rounded to it can be traded on every exchange:
+// 0.3 and 0.2 give 0.6. Zero lot sizes do not round and are skipped; it is
+// zero when all are.
+func CommonLotSize(lotSizes ...float64) (float64, error) {
+	var lots []float64
+	for _, lot := range lotSizes {
+		if lot < 0 {
+			return 0, fmt.Errorf("lot size must not be negative, got %g", lot)
+		}
+		if lot > 0 {
+			lots = append(lots, lot)
+		}
+	}
+	if len(lots) == 0 {
+		return 0, nil
+	}
+
+	// Scale the lots to whole numbers and take their least common multiple
+	scale := 1.0
+	for decimals := 0; ; decimals++ {
+		whole := true
+		for _, lot := range lots {
+			scaled := lot * scale
+			if math.Abs(scaled-math.Round(scaled)) > lotEpsilon*scaled {
+				whole = false
+				break
+			}
+		}
+		if whole {
+			break
+		}
+		if decimals == lotDecimals {
+			return 0, fmt.Errorf("lot sizes %v have more than %d decimals", lots, lotDecimals)
+		}
+		scale *= 10
+	}
+
+	lcm := int64(math.Round(lots[0] * scale))
+	for _, lot := range lots[1:] {
+		n := int64(math.Round(lot * scale))
+		a, b := lcm, n
+		for b != 0 {
+			a, b = b, a%b
+		}
+		lcm = lcm / a * n
+	}
+	return float64(lcm) / scale, nil
+}
+
+// CalculatePositionSize takes the smallest of limits at price and rounds the
+// quantity down to a multiple of lotSize; a zero lot size does not round.
+// Negative limits count as zero.
+func CalculatePositionSize(limits []SizeLimit, price, lotSize float64) (PositionSize, error) {
+	if len(limits) == 0 {
+		return PositionSize{}, fmt.Errorf("at least one size limit is required")
+	}
+	if price <= 0 {
+		return PositionSize{}, fmt.Errorf("price must be positive, got %g", price)
+	}
+	if lotSize < 0 {
+		return PositionSize{}, fmt.Errorf("lot size must not be negative, got %g", lotSize)
+	}
+
+	binding := limits[0]
+	for _, limit := range limits[1:] {
+		if limit.Notional < binding.Notional {
+			binding = limit
+		}
+	}
+
+	quantity := math.Max(binding.Notional, 0) / price
+	if lotSize > 0 {
+		quantity = math.Floor(quantity/lotSize+lotEpsilon) * lotSize
+	}
+	return PositionSize{
+		Quantity: quantity,
+		Notional: quantity * price,
+		Binding:  binding.Name,
+		Limits:   limits,
+	}, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import "testing"
+
+func TestCalculatePositionSize(t *testing.T) {
+	limits := []SizeLimit{
+		{Name: SizeLimitAccount, Notional: 10000},
+		{Name: SizeLimitVolatility, Notional: 5000},
+		{Name: SizeLimitLiquidity, Notional: 7500},
+	}
+	tests := []struct {
+		name     string
+		limits   []SizeLimit
+		price    float64
+		lotSize  float64
+		quantity float64
+		binding  string
+	}{
+		// 5000 / 50450 = 0.09910..., floored to 0.099
+		{name: "rounded down to the lot", limits: limits, price: 50450, lotSize: 0.001, quantity: 0.099, binding: SizeLimitVolatility},
+		{name: "no lot size", limits: limits, price: 50000, quantity: 0.1, binding: SizeLimitVolatility},
+		// 0.3/0.1 is 2.9999999999999996 in floating point
+		{name: "whole number of lots", limits: []SizeLimit{{Name: SizeLimitAccount, Notional: 0.3}}, price: 1, lotSize: 0.1, quantity: 0.3, binding: SizeLimitAccount},
+		{name: "below one lot", limits: limits, price: 50000, lotSize: 1, quantity: 0, binding: SizeLimitVolatility},
+		{name: "negative limit", limits: []SizeLimit{{Name: SizeLimitQuoteBalance, Notional: -5}}, price: 100, quantity: 0, binding: SizeLimitQuoteBalance},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, err := CalculatePositionSize(tt.limits, tt.price, tt.lotSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !near(size.Quantity, tt.quantity) || !near(size.Notional, tt.quantity*tt.price) || size.Binding != tt.binding {
+				t.Fatalf("got %v (%v) bound by %s, want %v bound by %s", size.Quantity, size.Notional, size.Binding, tt.quantity, tt.binding)
+			}
+		})
+	}
+
+	errorCases := map[string]struct {
+		limits         []SizeLimit
+		price, lotSize float64
+	}{
+		"no limits":         {price: 100},
+		"zero price":        {limits: limits},
+		"negative lot size": {limits: limits, price: 100, lotSize: -0.1},
+	}
+	for name, tc := range errorCases {
+		if size, err := CalculatePositionSize(tc.limits, tc.price, tc.lotSize); err == nil {
+			t.Errorf("%s: got %+v, want an error", name, size)
+		}
+	}
+}
+
+func TestCommonLotSize(t *testing.T) {
+	tests := []struct {
+		lots []float64
+		want float64
+	}{
+		{[]float64{0.3, 0.2}, 0.6},
+		{[]float64{0.001, 0.00001}, 0.001},
+		{[]float64{0.25, 0.1}, 0.5},
+		{[]float64{1, 5}, 5},
+		// A zero lot size does not round
+		{[]float64{0, 0.01}, 0.01},
+		{[]float64{0, 0}, 0},
+		{nil, 0},
+	}
+	for _, tt := range tests {
+		got, err := CommonLotSize(tt.lots...)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.lots, err)
+		}
+		if !near(got, tt.want) {
+			t.Errorf("common lot of %v = %v, want %v", tt.lots, got, tt.want)
+		}
+	}
+
+	for _, lots := range [][]float64{{0.1, -0.1}, {1e-15, 0.1}} {
+		if got, err := CommonLotSize(lots...); err == nil {
+			t.Errorf("common lot of %v = %v, want an error", lots, got)
+		}
+	}
+}
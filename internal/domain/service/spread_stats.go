@@ -0,0 +1,73 @@
+package service
+
+import (
+	"fmt"
+	"math"
+)
+
+// SpreadStatistics describes the spread between two price series over a
+// window. The spread is a - HedgeRatio*b - Intercept.
+type SpreadStatistics struct {
+	HedgeRatio float64
+	Intercept  float64
+	Mean       float64
+	StdDev     float64
+	// Spread and ZScore are of the latest sample
+	Spread float64
+	ZScore float64
+}
+
+// CalculateSpreadStatistics computes the spread statistics of two aligned
+// series of at least two samples. With ols the hedge ratio and intercept are
+// the least squares fit of a on b over the window; otherwise hedgeRatio is
+// used with no intercept. The standard deviation is the sample one.
+func CalculateSpreadStatistics(a, b []float64, hedgeRatio float64, ols bool) (SpreadStatistics, error) {
+	n := len(a)
+	if n != len(b) {
+		return SpreadStatistics{}, fmt.Errorf("series have different lengths: %d and %d", n, len(b))
+	}
+	if n < 2 {
+		return SpreadStatistics{}, fmt.Errorf("at least 2 samples are required, got %d", n)
+	}
+
+	stats := SpreadStatistics{HedgeRatio: hedgeRatio}
+	if ols {
+		meanA, meanB := mean(a), mean(b)
+		var cov, varB float64
+		for i := range a {
+			cov += (a[i] - meanA) * (b[i] - meanB)
+			varB += (b[i] - meanB) * (b[i] - meanB)
+		}
+		if varB == 0 {
+			return SpreadStatistics{}, fmt.Errorf("cannot fit a hedge ratio to a constant series")
+		}
+		stats.HedgeRatio = cov / varB
+		stats.Intercept = meanA - stats.HedgeRatio*meanB
+	}
+
+	spread := make([]float64, n)
+	for i := range a {
+		spread[i] = a[i] - stats.HedgeRatio*b[i] - stats.Intercept
+	}
+	stats.Mean = mean(spread)
+	var sumSq float64
+	for _, s := range spread {
+		sumSq += (s - stats.Mean) * (s - stats.Mean)
+	}
+	stats.StdDev = math.Sqrt(sumSq / float64(n-1))
+	if stats.StdDev == 0 {
+		return SpreadStatistics{}, fmt.Errorf("spread has no variance")
+	}
+
+	stats.Spread = spread[n-1]
+	stats.ZScore = (stats.Spread - stats.Mean) / stats.StdDev
+	return stats, nil
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
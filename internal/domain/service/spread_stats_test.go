@@ -0,0 +1,77 @@
+package service
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateSpreadStatistics(t *testing.T) {
+	t.Run("ols recovers the hedge ratio and intercept", func(t *testing.T) {
+		// a = 2b + 3 plus a residual with zero mean that is uncorrelated
+		// with b, so the fit is exact and the spread is the residual
+		b := []float64{1, 2, 3, 4, 5}
+		residual := []float64{1, -1, 0, -1, 1}
+		a := make([]float64, len(b))
+		for i := range b {
+			a[i] = 2*b[i] + 3 + residual[i]
+		}
+
+		stats, err := CalculateSpreadStatistics(a, b, 0, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Residual variance is 4/(5-1) = 1, and the latest residual is 1
+		want := SpreadStatistics{HedgeRatio: 2, Intercept: 3, Mean: 0, StdDev: 1, Spread: 1, ZScore: 1}
+		assertStats(t, stats, want)
+	})
+
+	t.Run("fixed hedge ratio", func(t *testing.T) {
+		a := []float64{10, 11, 12, 13, 14}
+		b := []float64{5, 5, 5, 5, 5}
+
+		stats, err := CalculateSpreadStatistics(a, b, 2, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The spread runs 0..4: mean 2, sample variance 10/4
+		want := SpreadStatistics{HedgeRatio: 2, Mean: 2, StdDev: math.Sqrt(2.5), Spread: 4, ZScore: 2 / math.Sqrt(2.5)}
+		assertStats(t, stats, want)
+	})
+
+	errorCases := map[string]struct {
+		a, b []float64
+		ols  bool
+	}{
+		"different lengths":    {a: []float64{1, 2, 3}, b: []float64{1, 2}},
+		"one sample":           {a: []float64{1}, b: []float64{1}},
+		"constant b with ols":  {a: []float64{1, 2, 3}, b: []float64{4, 4, 4}, ols: true},
+		"spread with no range": {a: []float64{3, 4, 5}, b: []float64{2, 3, 4}},
+	}
+	for name, tc := range errorCases {
+		t.Run(name, func(t *testing.T) {
+			if stats, err := CalculateSpreadStatistics(tc.a, tc.b, 1, tc.ols); err == nil {
+				t.Fatalf("got %+v, want an error", stats)
+			}
+		})
+	}
+}
+
+func assertStats(t *testing.T, got, want SpreadStatistics) {
+	t.Helper()
+	checks := []struct {
+		field     string
+		got, want float64
+	}{
+		{"HedgeRatio", got.HedgeRatio, want.HedgeRatio},
+		{"Intercept", got.Intercept, want.Intercept},
+		{"Mean", got.Mean, want.Mean},
+		{"StdDev", got.StdDev, want.StdDev},
+		{"Spread", got.Spread, want.Spread},
+		{"ZScore", got.ZScore, want.ZScore},
+	}
+	for _, c := range checks {
+		if !near(c.got, c.want) {
+			t.Errorf("%s = %v, want %v", c.field, c.got, c.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+)
+
+// defaultKeep bounds how many changes are kept in memory for Recent
+const defaultKeep = 1000
+
+var _ output.SubscriptionAuditPort = (*SubscriptionLog)(nil)
+
+// record is the JSON line written per change
+type record struct {
+	Time       time.Time `json:"time"`
+	Actor      string    `json:"actor"`
+	Action     string    `json:"action"`
+	ExchangeID string    `json:"exchange_id"`
+	Symbol     string    `json:"symbol"`
+	Old        *settings `json:"old,omitempty"`
+	New        *settings `json:"new,omitempty"`
+}
+
+// settings are the subscription settings before or after a change
+type settings struct {
+	Depth             int   `json:"depth"`
+	UpdateSpeedMs     int64 `json:"update_speed_ms"`
+	Record            bool  `json:"record"`
+	PublishThrottleMs int64 `json:"publish_throttle_ms"`
+}
+
+// SubscriptionLog appends subscription changes to a JSON lines file and keeps
+// the latest in memory. An empty path keeps them in memory only, so the
+// history is lost on restart.
+type SubscriptionLog struct {
+	keep int
+
+	mu      sync.Mutex
+	file    *os.File
+	changes []*entity.SubscriptionChange
+}
+
+// NewSubscriptionLog opens the log at path, loading its latest keep changes;
+// keep <= 0 uses the default
+func NewSubscriptionLog(path string, keep int) (*SubscriptionLog, error) {
+	if keep <= 0 {
+		keep = defaultKeep
+	}
+	l := &SubscriptionLog{keep: keep}
+	if path == "" {
+		return l, nil
+	}
+
+	if err := l.load(path); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	l.file = file
+	return l, nil
+}
+
+// Record appends a change to the file and the in-memory history
+func (l *SubscriptionLog) Record(ctx context.Context, change *entity.SubscriptionChange) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.remember(change)
+	if l.file == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(record{
+		Time:       change.At(),
+		Actor:      change.Actor(),
+		Action:     string(change.Action()),
+		ExchangeID: change.ExchangeID(),
+		Symbol:     change.Symbol(),
+		Old:        encodeSettings(change.Previous()),
+		New:        encodeSettings(change.Current()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// Recent returns up to limit changes, newest first
+func (l *SubscriptionLog) Recent(ctx context.Context, limit int) ([]*entity.SubscriptionChange, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := min(limit, len(l.changes))
+	recent := make([]*entity.SubscriptionChange, n)
+	for i := range recent {
+		recent[i] = l.changes[len(l.changes)-1-i]
+	}
+	return recent, nil
+}
+
+// Close closes the file
+func (l *SubscriptionLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// load reads the existing history; a missing file is an empty history. A
+// write cut short by a crash leaves an unterminated last line, which is
+// dropped and cut from the file so the next record starts on a line of its
+// own. A bad line anywhere else is an error.
+func (l *SubscriptionLog) load(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var size int64
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(data) == 0 {
+				return nil
+			}
+			if err := os.Truncate(path, size); err != nil {
+				return fmt.Errorf("failed to cut torn audit log line %d: %w", line, err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read audit log: %w", err)
+		}
+		size += int64(len(data))
+
+		var r record
+		if err := json.Unmarshal(data, &r); err != nil {
+			return fmt.Errorf("failed to decode audit log line %d: %w", line, err)
+		}
+		l.remember(entity.NewSubscriptionChange(r.Time, r.Actor, entity.SubscriptionAction(r.Action), r.ExchangeID, r.Symbol,
+			decodeSettings(r.Old), decodeSettings(r.New)))
+	}
+}
+
+func (l *SubscriptionLog) remember(change *entity.SubscriptionChange) {
+	if len(l.changes) == l.keep {
+		copy(l.changes, l.changes[1:])
+		l.changes = l.changes[:l.keep-1]
+	}
+	l.changes = append(l.changes, change)
+}
+
+func encodeSettings(s *entity.SubscriptionSettings) *settings {
+	if s == nil {
+		return nil
+	}
+	return &settings{
+		Depth:             s.Depth,
+		UpdateSpeedMs:     s.UpdateSpeed.Milliseconds(),
+		Record:            s.Record,
+		PublishThrottleMs: s.PublishThrottle.Milliseconds(),
+	}
+}
+
+func decodeSettings(s *settings) *entity.SubscriptionSettings {
+	if s == nil {
+		return nil
+	}
+	return &entity.SubscriptionSettings{
+		Depth:           s.Depth,
+		UpdateSpeed:     time.Duration(s.UpdateSpeedMs) * time.Millisecond,
+		PublishThrottle: time.Duration(s.PublishThrottleMs) * time.Millisecond,
+		Record:          s.Record,
+	}
+}
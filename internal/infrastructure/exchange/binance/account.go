@@ -0,0 +1,175 @@
+package binance
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+)
+
+const (
+	defaultBaseURL = "https://api.binance.com"
+	// recvWindow is how long Binance accepts a signed request after its
+	// timestamp, in milliseconds
+	recvWindow = 5000
+)
+
+var (
+	_ output.FeeRatesPort = (*Client)(nil)
+	_ output.AccountPort  = (*Client)(nil)
+)
+
+// GetTradeFees returns the account's maker and taker rates per symbol, keyed
+// by Binance's spelling such as BTCUSDT. The rates include the VIP tier but
+// not the BNB discount. It needs an API key with read access.
+func (c *Client) GetTradeFees(ctx context.Context) (map[string]entity.FeeRates, error) {
+	body, err := c.signedGet(ctx, "/sapi/v1/asset/tradeFee", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	var fees []TradeFee
+	if err := json.Unmarshal(body, &fees); err != nil {
+		return nil, fmt.Errorf("failed to decode trade fees: %w", err)
+	}
+
+	rates := make(map[string]entity.FeeRates, len(fees))
+	for _, fee := range fees {
+		maker, err := strconv.ParseFloat(fee.MakerCommission, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maker commission %q for %s: %w", fee.MakerCommission, fee.Symbol, err)
+		}
+		taker, err := strconv.ParseFloat(fee.TakerCommission, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid taker commission %q for %s: %w", fee.TakerCommission, fee.Symbol, err)
+		}
+		rates[fee.Symbol] = entity.FeeRates{Maker: maker, Taker: taker}
+	}
+	return rates, nil
+}
+
+// GetBalances returns the free balance of every asset the account holds. It
+// needs an API key with read access.
+func (c *Client) GetBalances(ctx context.Context) (map[string]float64, error) {
+	params := url.Values{}
+	params.Set("omitZeroBalances", "true")
+	body, err := c.signedGet(ctx, "/api/v3/account", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var account AccountInfo
+	if err := json.Unmarshal(body, &account); err != nil {
+		return nil, fmt.Errorf("failed to decode account: %w", err)
+	}
+
+	balances := make(map[string]float64, len(account.Balances))
+	for _, balance := range account.Balances {
+		free, err := strconv.ParseFloat(balance.Free, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid free balance %q for %s: %w", balance.Free, balance.Asset, err)
+		}
+		balances[balance.Asset] = free
+	}
+	return balances, nil
+}
+
+// GetLotSizes returns the LOT_SIZE step of every symbol, keyed by Binance's
+// spelling such as BTCUSDT
+func (c *Client) GetLotSizes(ctx context.Context) (map[string]float64, error) {
+	body, err := c.get(ctx, "/api/v3/exchangeInfo", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	var info ExchangeInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("failed to decode exchange info: %w", err)
+	}
+
+	lotSizes := make(map[string]float64, len(info.Symbols))
+	for _, symbol := range info.Symbols {
+		for _, filter := range symbol.Filters {
+			if filter.FilterType != "LOT_SIZE" {
+				continue
+			}
+			step, err := strconv.ParseFloat(filter.StepSize, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid step size %q for %s: %w", filter.StepSize, symbol.Symbol, err)
+			}
+			lotSizes[symbol.Symbol] = step
+		}
+	}
+	return lotSizes, nil
+}
+
+// signedGet calls a USER_DATA endpoint, signing the query with the API secret
+func (c *Client) signedGet(ctx context.Context, path string, params url.Values) ([]byte, error) {
+	apiKey, apiSecret := c.Credentials()
+	if apiKey == "" || apiSecret == "" {
+		return nil, fmt.Errorf("binance api key and secret are required for %s", path)
+	}
+	baseURL := c.BaseURL()
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	params.Set("recvWindow", strconv.Itoa(recvWindow))
+	params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	query := params.Encode()
+	mac := hmac.New(sha256.New, []byte(apiSecret))
+	mac.Write([]byte(query))
+	query += "&signature=" + hex.EncodeToString(mac.Sum(nil))
+
+	return c.do(ctx, path, baseURL+path+"?"+query, apiKey)
+}
+
+// get calls a public endpoint
+func (c *Client) get(ctx context.Context, path string, params url.Values) ([]byte, error) {
+	baseURL := c.BaseURL()
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	target := baseURL + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+	return c.do(ctx, path, target, "")
+}
+
+// do sends a GET, with the API key header when apiKey is set, and returns
+// the body of a 200 response
+func (c *Client) do(ctx context.Context, path, target, apiKey string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if apiKey != "" {
+		req.Header.Set("X-MBX-APIKEY", apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s: %s", path, resp.Status, body)
+	}
+	return body, nil
+}
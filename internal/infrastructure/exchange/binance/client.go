@@ -3,24 +3,52 @@ package binance
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/gorilla/websocket"
+
+	"marketdata/internal/application/port/output"
 	"marketdata/internal/domain/entity"
 	"marketdata/internal/infrastructure/exchange"
-	"marketdata/internal/infrastructure/websocket"
+	"marketdata/internal/infrastructure/exchange/recorder"
+)
+
+const (
+	defaultStreamURL = "wss://stream.binance.com:9443"
+	// snapshotLimit is the number of levels per side of a REST snapshot
+	snapshotLimit    = 1000
+	subscriberBuffer = 100
 )
 
 type Client struct {
 	*exchange.BaseExchange
-	wsConn      *websocket.Conn
-	subscribers map[string][]chan *entity.OrderBook
-	mu          sync.RWMutex
+	decoder          *Decoder
+	subscribers      map[string][]*subscription[*entity.OrderBook]
+	tradeSubscribers map[string][]*subscription[*entity.Trade]
+	mu               sync.RWMutex
+	httpClient       *http.Client
+}
+
+// subscription is one stream connection and the channel its books or trades
+// go to
+type subscription[T any] struct {
+	conn *websocket.Conn
+	ch   chan T
 }
 
 func NewClient(cfg exchange.Config) *Client {
 	return &Client{
-		BaseExchange: exchange.NewBaseExchange(cfg),
-		subscribers:  make(map[string][]chan *entity.OrderBook),
+		BaseExchange:     exchange.NewBaseExchange(cfg),
+		decoder:          NewDecoder(),
+		subscribers:      make(map[string][]*subscription[*entity.OrderBook]),
+		tradeSubscribers: make(map[string][]*subscription[*entity.Trade]),
+		httpClient:       &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
@@ -29,7 +57,7 @@ func (c *Client) Connect(ctx context.Context) error {
 		return err
 	}
 
-	// Implement Binance-specific connection logic
+	// Streams are connected per subscription
 	return nil
 }
 
@@ -41,35 +69,270 @@ func (c *Client) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Close all subscriber channels
-	for _, channels := range c.subscribers {
-		for _, ch := range channels {
-			close(ch)
+	// Close all stream connections and subscriber channels. The maps are
+	// cleared in place, as stream goroutines hold them to unsubscribe.
+	for _, subs := range c.subscribers {
+		for _, sub := range subs {
+			sub.conn.Close()
+			close(sub.ch)
 		}
 	}
-	c.subscribers = make(map[string][]chan *entity.OrderBook)
+	clear(c.subscribers)
+	for _, subs := range c.tradeSubscribers {
+		for _, sub := range subs {
+			sub.conn.Close()
+			close(sub.ch)
+		}
+	}
+	clear(c.tradeSubscribers)
 
 	return nil
 }
 
+// GetOrderBook fetches a REST depth snapshot
 func (c *Client) GetOrderBook(ctx context.Context, symbol string) (*entity.OrderBook, error) {
-	// Implement Binance-specific orderbook fetching
-	return nil, fmt.Errorf("not implemented")
+	snapshot, err := c.fetchSnapshot(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	book := exchange.NewLocalBook()
+	book.Replace(snapshot.Bids, snapshot.Asks, snapshot.Timestamp)
+	return book.OrderBook(c.GetName(), symbol), nil
 }
 
 func (c *Client) SubscribeOrderBook(ctx context.Context, symbol string) (<-chan *entity.OrderBook, error) {
+	return c.SubscribeOrderBookWithOptions(ctx, symbol, output.OrderBookStreamOptions{})
+}
+
+// SubscribeOrderBookWithOptions connects to the depth stream matching opts,
+// see streamName. The channel is closed when ctx is done, the client is
+// closed or the stream fails.
+func (c *Client) SubscribeOrderBookWithOptions(ctx context.Context, symbol string, opts output.OrderBookStreamOptions) (<-chan *entity.OrderBook, error) {
+	conn, err := c.dial(ctx, streamName(symbol, opts))
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &subscription[*entity.OrderBook]{conn: conn, ch: make(chan *entity.OrderBook, subscriberBuffer)}
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.subscribers[symbol] = append(c.subscribers[symbol], sub)
+	c.mu.Unlock()
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	go func() {
+		defer stop()
+		c.handleOrderBookUpdates(ctx, symbol, sub)
+	}()
+
+	return sub.ch, nil
+}
+
+// SubscribeTrades connects to the trade stream (<symbol>@trade). The channel
+// is closed when ctx is done, the client is closed or the stream fails.
+func (c *Client) SubscribeTrades(ctx context.Context, symbol string) (<-chan *entity.Trade, error) {
+	conn, err := c.dial(ctx, strings.ToLower(wireSymbol(symbol))+"@trade")
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &subscription[*entity.Trade]{conn: conn, ch: make(chan *entity.Trade, subscriberBuffer)}
+	c.mu.Lock()
+	c.tradeSubscribers[symbol] = append(c.tradeSubscribers[symbol], sub)
+	c.mu.Unlock()
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	go func() {
+		defer stop()
+		c.handleTrades(symbol, sub)
+	}()
+
+	return sub.ch, nil
+}
+
+// dial connects to a raw stream
+func (c *Client) dial(ctx context.Context, stream string) (*websocket.Conn, error) {
+	streamURL := c.WSURL()
+	if streamURL == "" {
+		streamURL = defaultStreamURL
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, streamURL+"/ws/"+stream, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", stream, err)
+	}
+	return conn, nil
+}
+
+// streamName picks the depth stream for a subscription: the partial book
+// stream (<symbol>@depth<levels>) for the depths Binance publishes, otherwise
+// the diff stream (<symbol>@depth), with the @100ms suffix for fast updates.
+// Binance pushes every second by default.
+func streamName(symbol string, opts output.OrderBookStreamOptions) string {
+	name := strings.ToLower(wireSymbol(symbol)) + "@depth"
+	switch opts.Depth {
+	case 5, 10, 20:
+		name += strconv.Itoa(opts.Depth)
+	}
+	if opts.UpdateSpeed > 0 && opts.UpdateSpeed < time.Second {
+		name += "@100ms"
+	}
+	return name
+}
+
+// wireSymbol spells a symbol the way the REST API does: BTC-USDT is BTCUSDT
+func wireSymbol(symbol string) string {
+	return strings.ToUpper(strings.ReplaceAll(symbol, "-", ""))
+}
+
+// handleOrderBookUpdates records every frame of a depth stream and folds it
+// into the subscriber's book. Partial book frames replace the book. Diff
+// frames start from a REST snapshot: those the snapshot already covers are
+// dropped, and a skipped update ID is reported and repaired with a new
+// snapshot.
+func (c *Client) handleOrderBookUpdates(ctx context.Context, symbol string, sub *subscription[*entity.OrderBook]) {
+	defer unsubscribe(c, c.subscribers, symbol, sub)
+
+	book := exchange.NewLocalBook()
+	sequence := exchange.NewSequenceTracker()
+	synced := false
 
-	ch := make(chan *entity.OrderBook, 100)
-	c.subscribers[symbol] = append(c.subscribers[symbol], ch)
+	for {
+		_, frame, err := sub.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.RecordFrame(symbol, recorder.FrameWebSocket, frame)
+
+		events, err := c.decode(symbol, recorder.FrameWebSocket, frame)
+		if err != nil {
+			continue
+		}
+
+		for _, event := range events {
+			switch event.Kind {
+			case exchange.EventBookSnapshot:
+				book.Replace(event.Bids, event.Asks, event.Timestamp)
+
+			case exchange.EventBookDelta:
+				if !synced {
+					if err := c.sync(ctx, symbol, book, sequence); err != nil {
+						return
+					}
+					synced = true
+				}
+				if sequence.Stale(symbol, event.FinalUpdateID) {
+					continue
+				}
+				if sequence.Next(symbol, event.FirstUpdateID, event.FinalUpdateID) {
+					// The book missed updates; start over from a new snapshot
+					c.ReportSequenceGap(symbol)
+					synced = false
+					continue
+				}
+				book.Apply(event.Bids, event.Asks, event.Timestamp)
 
-	// Implement Binance-specific subscription logic
-	go c.handleOrderBookUpdates(ctx, symbol, ch)
+			default:
+				continue
+			}
 
-	return ch, nil
+			deliver(c, c.subscribers, symbol, sub, book.OrderBook(c.GetName(), symbol))
+		}
+	}
 }
 
-func (c *Client) handleOrderBookUpdates(ctx context.Context, symbol string, ch chan<- *entity.OrderBook) {
-	// Implement websocket message handling
+// handleTrades records every frame of a trade stream and delivers its trades
+func (c *Client) handleTrades(symbol string, sub *subscription[*entity.Trade]) {
+	defer unsubscribe(c, c.tradeSubscribers, symbol, sub)
+
+	for {
+		_, frame, err := sub.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.RecordFrame(symbol, recorder.FrameWebSocket, frame)
+
+		events, err := c.decode(symbol, recorder.FrameWebSocket, frame)
+		if err != nil {
+			continue
+		}
+		for _, event := range events {
+			if event.Kind == exchange.EventTrade {
+				deliver(c, c.tradeSubscribers, symbol, sub, event.Trade)
+			}
+		}
+	}
+}
+
+// sync replaces the book with a REST snapshot and restarts the update IDs
+// from its last one
+func (c *Client) sync(ctx context.Context, symbol string, book *exchange.LocalBook, sequence *exchange.SequenceTracker) error {
+	snapshot, err := c.fetchSnapshot(ctx, symbol)
+	if err != nil {
+		return err
+	}
+	book.Replace(snapshot.Bids, snapshot.Asks, snapshot.Timestamp)
+	sequence.Reset(symbol, snapshot.FinalUpdateID)
+	return nil
+}
+
+// fetchSnapshot reads the REST depth snapshot, recording the raw body
+func (c *Client) fetchSnapshot(ctx context.Context, symbol string) (exchange.MarketEvent, error) {
+	body, err := c.get(ctx, "/api/v3/depth", url.Values{
+		"symbol": {wireSymbol(symbol)},
+		"limit":  {strconv.Itoa(snapshotLimit)},
+	})
+	if err != nil {
+		return exchange.MarketEvent{}, err
+	}
+	c.RecordFrame(symbol, recorder.FrameREST, body)
+
+	events, err := c.decode(symbol, recorder.FrameREST, body)
+	if err != nil {
+		return exchange.MarketEvent{}, err
+	}
+	if len(events) != 1 || events[0].Kind != exchange.EventBookSnapshot {
+		return exchange.MarketEvent{}, fmt.Errorf("unexpected %s depth response", symbol)
+	}
+	return events[0], nil
+}
+
+// decode decodes a frame just read with the decoder replays use, so a live
+// book and its replay are built alike
+func (c *Client) decode(symbol string, kind recorder.FrameKind, data []byte) ([]exchange.MarketEvent, error) {
+	return c.decoder.Decode(recorder.Frame{
+		ReceivedAt: time.Now().UTC(),
+		Exchange:   c.GetName(),
+		Symbol:     symbol,
+		Kind:       kind,
+		Data:       string(data),
+	})
+}
+
+// deliver hands a book or trade to the subscriber, dropping it when the
+// subscriber is behind or already unsubscribed
+func deliver[T any](c *Client, subscribers map[string][]*subscription[T], symbol string, sub *subscription[T], value T) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !slices.Contains(subscribers[symbol], sub) {
+		return
+	}
+	select {
+	case sub.ch <- value:
+	default:
+	}
+}
+
+// unsubscribe closes the subscription if the client has not closed it already
+func unsubscribe[T any](c *Client, subscribers map[string][]*subscription[T], symbol string, sub *subscription[T]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	subs := subscribers[symbol]
+	if i := slices.Index(subs, sub); i >= 0 {
+		subscribers[symbol] = slices.Delete(subs, i, i+1)
+		sub.conn.Close()
+		close(sub.ch)
+	}
 }
@@ -0,0 +1,246 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"marketdata/internal/domain/entity"
+	"marketdata/internal/domain/valueobject"
+	"marketdata/internal/infrastructure/exchange"
+	"marketdata/internal/infrastructure/exchange/recorder"
+)
+
+// DepthSnapshot is the REST /api/v3/depth response and the payload of the
+// partial book depth streams (<symbol>@depth<levels>)
+type DepthSnapshot struct {
+	LastUpdateID int64       `json:"lastUpdateId"`
+	Bids         [][2]string `json:"bids"`
+	Asks         [][2]string `json:"asks"`
+}
+
+// DepthUpdate is a diff depth stream event (<symbol>@depth)
+type DepthUpdate struct {
+	Event         string      `json:"e"`
+	EventTime     int64       `json:"E"`
+	Symbol        string      `json:"s"`
+	FirstUpdateID int64       `json:"U"`
+	FinalUpdateID int64       `json:"u"`
+	Bids          [][2]string `json:"b"`
+	Asks          [][2]string `json:"a"`
+}
+
+// TradeFee is an entry of the REST /sapi/v1/asset/tradeFee response
+type TradeFee struct {
+	Symbol          string `json:"symbol"`
+	MakerCommission string `json:"makerCommission"`
+	TakerCommission string `json:"takerCommission"`
+}
+
+// AccountInfo is the part of the REST /api/v3/account response with the
+// balances
+type AccountInfo struct {
+	Balances []AccountBalance `json:"balances"`
+}
+
+type AccountBalance struct {
+	Asset  string `json:"asset"`
+	Free   string `json:"free"`
+	Locked string `json:"locked"`
+}
+
+// ExchangeInfo is the part of the REST /api/v3/exchangeInfo response with
+// the symbol filters
+type ExchangeInfo struct {
+	Symbols []SymbolInfo `json:"symbols"`
+}
+
+type SymbolInfo struct {
+	Symbol  string         `json:"symbol"`
+	Filters []SymbolFilter `json:"filters"`
+}
+
+// SymbolFilter is a trading rule of a symbol; StepSize is set on LOT_SIZE
+type SymbolFilter struct {
+	FilterType string `json:"filterType"`
+	StepSize   string `json:"stepSize"`
+}
+
+// TradeEvent is a trade stream event (<symbol>@trade)
+type TradeEvent struct {
+	Event        string `json:"e"`
+	EventTime    int64  `json:"E"`
+	Symbol       string `json:"s"`
+	TradeID      int64  `json:"t"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	TradeTime    int64  `json:"T"`
+	BuyerIsMaker bool   `json:"m"`
+	// Ignore is Binance's deprecated best-match flag; it is declared so that
+	// case-insensitive decoding cannot write "M" into BuyerIsMaker
+	Ignore bool `json:"M"`
+}
+
+// StreamMessage wraps events received on a combined stream connection
+type StreamMessage struct {
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Decoder decodes recorded Binance frames
+type Decoder struct{}
+
+func NewDecoder() *Decoder {
+	return &Decoder{}
+}
+
+// Decode understands depth snapshots, diff depth updates and trades, on raw
+// or combined streams. Other messages decode to no events.
+func (d *Decoder) Decode(frame recorder.Frame) ([]exchange.MarketEvent, error) {
+	data := []byte(frame.Data)
+
+	var wrapped StreamMessage
+	if err := json.Unmarshal(data, &wrapped); err == nil && wrapped.Stream != "" && len(wrapped.Data) > 0 {
+		data = wrapped.Data
+	}
+
+	// EventTime keeps "E" from matching "e" case-insensitively
+	var probe struct {
+		Event        string           `json:"e"`
+		EventTime    json.RawMessage  `json:"E"`
+		LastUpdateID *json.RawMessage `json:"lastUpdateId"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("failed to decode binance frame: %w", err)
+	}
+
+	switch {
+	case probe.Event == "depthUpdate":
+		var update DepthUpdate
+		if err := json.Unmarshal(data, &update); err != nil {
+			return nil, fmt.Errorf("failed to decode depth update: %w", err)
+		}
+		return decodeDepth(exchange.EventBookDelta, symbolOf(frame, update.Symbol), update.Bids, update.Asks,
+			update.FirstUpdateID, update.FinalUpdateID, time.UnixMilli(update.EventTime).UTC())
+
+	case probe.Event == "trade":
+		var trade TradeEvent
+		if err := json.Unmarshal(data, &trade); err != nil {
+			return nil, fmt.Errorf("failed to decode trade: %w", err)
+		}
+		return decodeTrade(symbolOf(frame, trade.Symbol), trade)
+
+	case probe.LastUpdateID != nil:
+		var snapshot DepthSnapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, fmt.Errorf("failed to decode depth snapshot: %w", err)
+		}
+		return decodeDepth(exchange.EventBookSnapshot, frame.Symbol, snapshot.Bids, snapshot.Asks,
+			snapshot.LastUpdateID, snapshot.LastUpdateID, frame.ReceivedAt)
+	}
+
+	return nil, nil
+}
+
+func decodeDepth(kind exchange.EventKind, symbol string, bids, asks [][2]string, first, final int64, ts time.Time) ([]exchange.MarketEvent, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("depth message has no symbol")
+	}
+
+	bidLevels, err := parseLevels(symbol, bids)
+	if err != nil {
+		return nil, err
+	}
+	askLevels, err := parseLevels(symbol, asks)
+	if err != nil {
+		return nil, err
+	}
+
+	return []exchange.MarketEvent{{
+		Kind:          kind,
+		Symbol:        symbol,
+		Bids:          bidLevels,
+		Asks:          askLevels,
+		Timestamp:     ts,
+		FirstUpdateID: first,
+		FinalUpdateID: final,
+	}}, nil
+}
+
+func decodeTrade(symbol string, event TradeEvent) ([]exchange.MarketEvent, error) {
+	price, err := parsePrice(symbol, event.Price)
+	if err != nil {
+		return nil, err
+	}
+	quantity, err := parseVolume(symbol, event.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	// The maker is the resting order, so a buyer-maker trade was sell-initiated
+	side := entity.TradeBuy
+	if event.BuyerIsMaker {
+		side = entity.TradeSell
+	}
+
+	ts := time.UnixMilli(event.TradeTime).UTC()
+	trade := entity.NewTrade(strconv.FormatInt(event.TradeID, 10), "binance", symbol, *price, *quantity, side, ts)
+
+	return []exchange.MarketEvent{{
+		Kind:      exchange.EventTrade,
+		Symbol:    symbol,
+		Trade:     trade,
+		Timestamp: ts,
+	}}, nil
+}
+
+func parseLevels(symbol string, raw [][2]string) ([]entity.PriceLevel, error) {
+	levels := make([]entity.PriceLevel, len(raw))
+	for i, level := range raw {
+		price, err := parsePrice(symbol, level[0])
+		if err != nil {
+			return nil, err
+		}
+		quantity, err := parseVolume(symbol, level[1])
+		if err != nil {
+			return nil, err
+		}
+		levels[i] = entity.PriceLevel{Price: *price, Quantity: *quantity}
+	}
+	return levels, nil
+}
+
+func parsePrice(symbol, raw string) (*valueobject.Price, error) {
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price %q: %w", raw, err)
+	}
+	return valueobject.NewPrice(value, quoteAsset(symbol))
+}
+
+func parseVolume(symbol, raw string) (*valueobject.Volume, error) {
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity %q: %w", raw, err)
+	}
+	return valueobject.NewVolume(value, symbol)
+}
+
+// symbolOf prefers the symbol the frame was recorded under ("BTC-USDT") over
+// Binance's own spelling ("BTCUSDT")
+func symbolOf(frame recorder.Frame, wire string) string {
+	if frame.Symbol != "" {
+		return frame.Symbol
+	}
+	return wire
+}
+
+// quoteAsset returns the quote currency of "BTC-USDT", defaulting to USDT
+func quoteAsset(symbol string) string {
+	if _, quote, ok := strings.Cut(symbol, "-"); ok && quote != "" {
+		return quote
+	}
+	return "USDT"
+}
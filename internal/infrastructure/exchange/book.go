@@ -0,0 +1,70 @@
+package exchange
+
+import (
+	"sort"
+	"time"
+
+	"marketdata/internal/domain/entity"
+)
+
+// LocalBook rebuilds a full book from snapshots and level deltas. It is not
+// safe for concurrent use.
+type LocalBook struct {
+	bids map[float64]entity.PriceLevel
+	asks map[float64]entity.PriceLevel
+	ts   time.Time
+}
+
+func NewLocalBook() *LocalBook {
+	return &LocalBook{
+		bids: make(map[float64]entity.PriceLevel),
+		asks: make(map[float64]entity.PriceLevel),
+	}
+}
+
+// Replace drops every level and sets those of a snapshot
+func (b *LocalBook) Replace(bids, asks []entity.PriceLevel, ts time.Time) {
+	clear(b.bids)
+	clear(b.asks)
+	b.Apply(bids, asks, ts)
+}
+
+// Apply sets each level; a zero quantity removes it
+func (b *LocalBook) Apply(bids, asks []entity.PriceLevel, ts time.Time) {
+	applySide(b.bids, bids)
+	applySide(b.asks, asks)
+	b.ts = ts
+}
+
+func applySide(side map[float64]entity.PriceLevel, levels []entity.PriceLevel) {
+	for _, level := range levels {
+		price := level.Price.Value()
+		if level.Quantity.Value() == 0 {
+			delete(side, price)
+			continue
+		}
+		side[price] = level
+	}
+}
+
+// OrderBook returns a sorted copy: bids descending, asks ascending
+func (b *LocalBook) OrderBook(exchangeID, symbol string) *entity.OrderBook {
+	orderbook := entity.NewOrderBook(exchangeID, symbol, b.ts)
+	orderbook.UpdateBids(sortedLevels(b.bids, true))
+	orderbook.UpdateAsks(sortedLevels(b.asks, false))
+	return orderbook
+}
+
+func sortedLevels(side map[float64]entity.PriceLevel, descending bool) []entity.PriceLevel {
+	levels := make([]entity.PriceLevel, 0, len(side))
+	for _, level := range side {
+		levels = append(levels, level)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if descending {
+			return levels[i].Price.Value() > levels[j].Price.Value()
+		}
+		return levels[i].Price.Value() < levels[j].Price.Value()
+	})
+	return levels
+}
@@ -0,0 +1,40 @@
+package exchange
+
+import (
+	"time"
+
+	"marketdata/internal/domain/entity"
+	"marketdata/internal/infrastructure/exchange/recorder"
+)
+
+type EventKind int
+
+const (
+	// EventBookSnapshot replaces the whole book
+	EventBookSnapshot EventKind = iota
+	// EventBookDelta updates individual levels; a zero quantity removes the level
+	EventBookDelta
+	// EventTrade reports one executed trade
+	EventTrade
+)
+
+// MarketEvent is an exchange message decoded into exchange-neutral form
+type MarketEvent struct {
+	Kind      EventKind
+	Symbol    string
+	Bids      []entity.PriceLevel
+	Asks      []entity.PriceLevel
+	Trade     *entity.Trade
+	Timestamp time.Time
+	// FirstUpdateID and FinalUpdateID are the update IDs a book event covers,
+	// for exchanges that number them; a snapshot sets both to its last ID.
+	// They are zero when the exchange does not number updates.
+	FirstUpdateID int64
+	FinalUpdateID int64
+}
+
+// FrameDecoder turns a raw recorded frame into market events. Frames that
+// carry no market data, such as subscription acknowledgements, decode to none.
+type FrameDecoder interface {
+	Decode(frame recorder.Frame) ([]MarketEvent, error)
+}
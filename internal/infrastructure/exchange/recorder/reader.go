@@ -0,0 +1,196 @@
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"container/heap"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxLineSize bounds a single recorded frame; full-depth snapshots can be large
+const maxLineSize = 64 << 20
+
+// Reader merges recorded files into one stream ordered by receive time. Each
+// file is already in time order, so frames are merged rather than sorted.
+type Reader struct {
+	sources []*source
+	queue   sourceQueue
+}
+
+type source struct {
+	path    string
+	file    *os.File
+	gz      *gzip.Reader
+	scanner *bufio.Scanner
+	next    Frame
+}
+
+// OpenFiles opens recordings for reading. Directories are searched recursively
+// for *.jsonl.gz files.
+func OpenFiles(paths []string) (*Reader, error) {
+	files, err := expand(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reader{}
+	for _, path := range files {
+		src, err := openSource(path)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		r.sources = append(r.sources, src)
+
+		ok, err := src.advance()
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		if ok {
+			r.queue = append(r.queue, src)
+		}
+	}
+	heap.Init(&r.queue)
+
+	return r, nil
+}
+
+// Next returns the earliest remaining frame, or io.EOF when all files are read
+func (r *Reader) Next() (Frame, error) {
+	if len(r.queue) == 0 {
+		return Frame{}, io.EOF
+	}
+
+	src := r.queue[0]
+	frame := src.next
+
+	ok, err := src.advance()
+	if err != nil {
+		return Frame{}, err
+	}
+	if ok {
+		heap.Fix(&r.queue, 0)
+	} else {
+		heap.Pop(&r.queue)
+	}
+
+	return frame, nil
+}
+
+func (r *Reader) Close() error {
+	var errs []error
+	for _, src := range r.sources {
+		if err := src.gz.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		if err := src.file.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	r.sources = nil
+	r.queue = nil
+	return errors.Join(errs...)
+}
+
+func openSource(path string) (*source, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read recording %s: %w", path, err)
+	}
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+
+	return &source{
+		path:    path,
+		file:    file,
+		gz:      gz,
+		scanner: scanner,
+	}, nil
+}
+
+// advance reads the next frame into src.next and reports whether there was one
+func (src *source) advance() (bool, error) {
+	for src.scanner.Scan() {
+		line := src.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		src.next = Frame{}
+		if err := json.Unmarshal(line, &src.next); err != nil {
+			return false, fmt.Errorf("failed to decode frame in %s: %w", src.path, err)
+		}
+		return true, nil
+	}
+
+	// A recording cut short by a crash ends in a truncated gzip stream; keep
+	// the frames that were read
+	if err := src.scanner.Err(); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return false, fmt.Errorf("failed to read %s: %w", src.path, err)
+	}
+	return false, nil
+}
+
+func expand(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open recording: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(p, fileExtension) {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list recordings in %s: %w", path, err)
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// sourceQueue is a min-heap of sources by their next frame's receive time
+type sourceQueue []*source
+
+func (q sourceQueue) Len() int { return len(q) }
+
+func (q sourceQueue) Less(i, j int) bool {
+	return q[i].next.ReceivedAt.Before(q[j].next.ReceivedAt)
+}
+
+func (q sourceQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *sourceQueue) Push(x any) { *q = append(*q, x.(*source)) }
+
+func (q *sourceQueue) Pop() any {
+	old := *q
+	src := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return src
+}
@@ -0,0 +1,309 @@
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"marketdata/internal/application/port/output"
+	"marketdata/pkg/logger"
+	"marketdata/pkg/metrics"
+)
+
+const (
+	defaultQueueSize   = 10000
+	defaultMaxFileSize = 256 << 20
+	flushInterval      = time.Second
+	fileExtension      = ".jsonl.gz"
+)
+
+type FrameKind string
+
+const (
+	FrameWebSocket FrameKind = "ws"
+	FrameREST      FrameKind = "rest"
+)
+
+// Frame is one raw message received from an exchange, stored as one JSON line
+type Frame struct {
+	ReceivedAt time.Time `json:"ts"`
+	Exchange   string    `json:"exchange"`
+	Symbol     string    `json:"symbol,omitempty"`
+	Kind       FrameKind `json:"kind"`
+	Data       string    `json:"data"`
+}
+
+type Config struct {
+	// Dir is the root directory; files go to Dir/<exchange>/<YYYY-MM-DD>/
+	Dir string
+	// QueueSize bounds the frames waiting to be written; further frames are dropped
+	QueueSize int
+	// MaxFileSize rotates a file once this many uncompressed bytes were written
+	MaxFileSize int64
+	// Symbols lists the recorded symbols per exchange. An exchange with an
+	// empty list records every symbol; exchanges not listed are not recorded
+	// until SetRecording enables one of their symbols.
+	Symbols map[string][]string
+}
+
+// Recorder tees raw exchange frames into gzip-compressed JSON Lines files, one
+// open file per exchange, rotated at midnight UTC and at MaxFileSize. Record
+// never blocks: when the write queue is full the frame is dropped and counted,
+// so a slow disk cannot stall ingestion.
+type Recorder struct {
+	cfg     Config
+	queue   chan Frame
+	done    chan struct{}
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+
+	filterMu sync.RWMutex
+	enabled  map[string]map[string]bool
+
+	mu        sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+}
+
+var _ output.FrameRecordingPort = (*Recorder)(nil)
+
+func New(cfg Config, log *logger.Logger, m *metrics.Metrics) (*Recorder, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("recorder directory is required")
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	if cfg.MaxFileSize <= 0 {
+		cfg.MaxFileSize = defaultMaxFileSize
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recorder directory: %w", err)
+	}
+
+	enabled := make(map[string]map[string]bool, len(cfg.Symbols))
+	for exchange, symbols := range cfg.Symbols {
+		if len(symbols) == 0 {
+			enabled[exchange] = nil
+			continue
+		}
+		enabled[exchange] = make(map[string]bool, len(symbols))
+		for _, symbol := range symbols {
+			enabled[exchange][symbol] = true
+		}
+	}
+
+	r := &Recorder{
+		cfg:     cfg,
+		enabled: enabled,
+		queue:   make(chan Frame, cfg.QueueSize),
+		done:    make(chan struct{}),
+		logger:  log,
+		metrics: m,
+	}
+
+	go r.run()
+
+	return r, nil
+}
+
+// Enabled reports whether frames of the exchange and symbol are recorded.
+// Frames without a symbol, such as connection acknowledgements, are recorded
+// for every enabled exchange.
+func (r *Recorder) Enabled(exchange, symbol string) bool {
+	r.filterMu.RLock()
+	defer r.filterMu.RUnlock()
+
+	symbols, ok := r.enabled[exchange]
+	if !ok {
+		return false
+	}
+	return symbols == nil || symbol == "" || symbols[symbol]
+}
+
+// SetRecording starts or stops recording a symbol of an exchange. An exchange
+// configured to record every symbol keeps doing so.
+func (r *Recorder) SetRecording(exchange, symbol string, enabled bool) {
+	r.filterMu.Lock()
+	defer r.filterMu.Unlock()
+
+	symbols, ok := r.enabled[exchange]
+	switch {
+	case ok && symbols == nil:
+	case enabled && !ok:
+		r.enabled[exchange] = map[string]bool{symbol: true}
+	case enabled:
+		symbols[symbol] = true
+	default:
+		delete(symbols, symbol)
+	}
+}
+
+// Record queues a copy of data for writing, dropping it when the queue is full
+func (r *Recorder) Record(exchange, symbol string, kind FrameKind, data []byte) {
+	if !r.Enabled(exchange, symbol) {
+		return
+	}
+
+	frame := Frame{
+		ReceivedAt: time.Now().UTC(),
+		Exchange:   exchange,
+		Symbol:     symbol,
+		Kind:       kind,
+		Data:       string(data),
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+
+	select {
+	case r.queue <- frame:
+		r.metrics.RecordFrameRecorded(exchange)
+	default:
+		r.metrics.RecordFrameDropped(exchange)
+	}
+}
+
+// Close stops accepting frames and waits until the queued ones are written and
+// every file is closed
+func (r *Recorder) Close() error {
+	r.closeOnce.Do(func() {
+		r.mu.Lock()
+		r.closed = true
+		close(r.queue)
+		r.mu.Unlock()
+	})
+
+	<-r.done
+	return nil
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+
+	files := make(map[string]*segment)
+	defer func() {
+		for exchange, file := range files {
+			if err := file.close(); err != nil {
+				r.logger.Error("failed to close recording", "exchange", exchange, "error", err)
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case frame, ok := <-r.queue:
+			if !ok {
+				return
+			}
+			if err := r.write(files, frame); err != nil {
+				r.metrics.RecordFrameDropped(frame.Exchange)
+				r.logger.Error("failed to record frame", "exchange", frame.Exchange, "error", err)
+			}
+		case <-ticker.C:
+			// Flush regularly so a crash loses at most about a second of frames
+			for exchange, file := range files {
+				if err := file.flush(); err != nil {
+					r.logger.Error("failed to flush recording", "exchange", exchange, "error", err)
+				}
+			}
+		}
+	}
+}
+
+func (r *Recorder) write(files map[string]*segment, frame Frame) error {
+	line, err := json.Marshal(frame)
+	if err != nil {
+		return fmt.Errorf("failed to marshal frame: %w", err)
+	}
+	line = append(line, '\n')
+
+	day := frame.ReceivedAt.Format(time.DateOnly)
+	file := files[frame.Exchange]
+	if file != nil && (file.day != day || file.written > 0 && file.written+int64(len(line)) > r.cfg.MaxFileSize) {
+		delete(files, frame.Exchange)
+		if err := file.close(); err != nil {
+			r.logger.Error("failed to close recording", "exchange", frame.Exchange, "error", err)
+		}
+		file = nil
+	}
+	if file == nil {
+		if file, err = r.open(frame.Exchange, frame.ReceivedAt); err != nil {
+			return err
+		}
+		files[frame.Exchange] = file
+	}
+
+	return file.write(line)
+}
+
+// open creates Dir/<exchange>/<day>/<exchange>-<timestamp>.jsonl.gz
+func (r *Recorder) open(exchange string, at time.Time) (*segment, error) {
+	day := at.Format(time.DateOnly)
+	dir := filepath.Join(r.cfg.Dir, exchange, day)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s%s", exchange, at.Format("20060102T150405.000000000Z"), fileExtension)
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording file: %w", err)
+	}
+
+	gz := gzip.NewWriter(f)
+	return &segment{
+		day:  day,
+		file: f,
+		gz:   gz,
+		buf:  bufio.NewWriter(gz),
+	}, nil
+}
+
+// segment is one open recording file
+type segment struct {
+	day     string
+	file    *os.File
+	gz      *gzip.Writer
+	buf     *bufio.Writer
+	written int64
+}
+
+func (s *segment) write(line []byte) error {
+	n, err := s.buf.Write(line)
+	s.written += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write frame: %w", err)
+	}
+	return nil
+}
+
+func (s *segment) flush() error {
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+	return s.gz.Flush()
+}
+
+func (s *segment) close() error {
+	if err := s.buf.Flush(); err != nil {
+		s.file.Close()
+		return err
+	}
+	if err := s.gz.Close(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
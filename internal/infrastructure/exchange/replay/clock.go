@@ -0,0 +1,26 @@
+package replay
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the replay's virtual time: the receive time of the last frame
+// played. Components under test read it instead of time.Now so that time-based
+// logic behaves as it did when the data was recorded.
+type Clock struct {
+	nanos atomic.Int64
+}
+
+// Now returns the virtual time, or the zero time before the first frame
+func (c *Clock) Now() time.Time {
+	nanos := c.nanos.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos).UTC()
+}
+
+func (c *Clock) set(t time.Time) {
+	c.nanos.Store(t.UnixNano())
+}
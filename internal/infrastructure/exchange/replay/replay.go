@@ -0,0 +1,397 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+	"marketdata/internal/infrastructure/exchange"
+	"marketdata/internal/infrastructure/exchange/recorder"
+)
+
+var ErrPlayed = errors.New("replay already played")
+
+type Config struct {
+	// Name selects the recorded exchange to play; it is also reported by GetName
+	Name string
+	// Files are recordings or directories of recordings
+	Files   []string
+	Decoder exchange.FrameDecoder
+	// Speed scales playback: 1 keeps the recorded pace, 10 plays ten times
+	// faster and 0 plays as fast as subscribers consume
+	Speed float64
+	// Start and End bound the replayed frames to [Start, End); zero is unbounded
+	Start time.Time
+	End   time.Time
+	// Gaps, when set, is told about every skipped update ID in the recordings
+	Gaps output.SequenceGapPort
+	// Hold keeps subscriber channels open after playback until Close, for
+	// consumers that take a closed channel for a dropped connection
+	Hold bool
+}
+
+// Update is one replayed book or trade; exactly one is set
+type Update struct {
+	Book  *entity.OrderBook
+	Trade *entity.Trade
+}
+
+// Exchange is an ExchangePort that plays recorded frames instead of connecting
+// to a venue. Subscribe first, then call Play: updates are delivered to every
+// subscriber in recorded order without drops, so a replay is deterministic.
+// Subscriber channels are unbuffered, so playback keeps in step with the
+// slowest subscriber and has handed over every update when Play returns.
+// Book deltas the last snapshot already covers are dropped, and skipped update
+// IDs are reported, as a live adapter does.
+type Exchange struct {
+	*exchange.BaseExchange
+	cfg   Config
+	clock *Clock
+
+	mu        sync.RWMutex
+	bookSubs  map[string][]*subscriber[*entity.OrderBook]
+	tradeSubs map[string][]*subscriber[*entity.Trade]
+	allSubs   []*updateSubscriber
+	books     map[string]*exchange.LocalBook
+	sequence  *exchange.SequenceTracker
+	latest    map[string]*entity.OrderBook
+	played    bool
+
+	decodeErrors atomic.Int64
+	stop         chan struct{}
+	stopOnce     sync.Once
+	finished     chan struct{}
+}
+
+type subscriber[T any] struct {
+	ctx context.Context
+	ch  chan T
+}
+
+// updateSubscriber receives the books and trades of several symbols
+type updateSubscriber struct {
+	*subscriber[Update]
+	symbols map[string]bool
+}
+
+func NewExchange(cfg Config) *Exchange {
+	return &Exchange{
+		BaseExchange: exchange.NewBaseExchange(exchange.Config{Name: cfg.Name, Gaps: cfg.Gaps}),
+		cfg:          cfg,
+		clock:        &Clock{},
+		bookSubs:     make(map[string][]*subscriber[*entity.OrderBook]),
+		tradeSubs:    make(map[string][]*subscriber[*entity.Trade]),
+		books:        make(map[string]*exchange.LocalBook),
+		sequence:     exchange.NewSequenceTracker(),
+		latest:       make(map[string]*entity.OrderBook),
+		stop:         make(chan struct{}),
+		finished:     make(chan struct{}),
+	}
+}
+
+// Clock returns the virtual clock driven by the replayed frames
+func (e *Exchange) Clock() *Clock {
+	return e.clock
+}
+
+// DecodeErrors counts frames that were skipped because they failed to decode
+func (e *Exchange) DecodeErrors() int64 {
+	return e.decodeErrors.Load()
+}
+
+// Close stops a running playback and closes held subscriber channels.
+// Closing an exchange that is not playing does nothing more.
+func (e *Exchange) Close() error {
+	e.stopOnce.Do(func() {
+		close(e.stop)
+		if e.cfg.Hold {
+			e.release()
+		}
+	})
+
+	if !e.IsConnected() {
+		return nil
+	}
+	return e.BaseExchange.Close()
+}
+
+// release closes held subscriber channels once a running playback stopped
+// sending; an exchange not played yet cannot be played anymore
+func (e *Exchange) release() {
+	e.mu.Lock()
+	playing := e.played
+	e.played = true
+	e.mu.Unlock()
+
+	if playing {
+		<-e.finished
+	}
+	e.closeSubscribers()
+}
+
+// GetOrderBook returns the latest replayed book of the symbol
+func (e *Exchange) GetOrderBook(ctx context.Context, symbol string) (*entity.OrderBook, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	orderbook, ok := e.latest[symbol]
+	if !ok {
+		return nil, fmt.Errorf("no %s orderbook replayed yet", symbol)
+	}
+	return orderbook, nil
+}
+
+// SubscribeOrderBook delivers the full book after every replayed snapshot or delta
+func (e *Exchange) SubscribeOrderBook(ctx context.Context, symbol string) (<-chan *entity.OrderBook, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.played {
+		return nil, ErrPlayed
+	}
+	sub := &subscriber[*entity.OrderBook]{ctx: ctx, ch: make(chan *entity.OrderBook)}
+	e.bookSubs[symbol] = append(e.bookSubs[symbol], sub)
+	return sub.ch, nil
+}
+
+// SubscribeTrades delivers every replayed trade of the symbol
+func (e *Exchange) SubscribeTrades(ctx context.Context, symbol string) (<-chan *entity.Trade, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.played {
+		return nil, ErrPlayed
+	}
+	sub := &subscriber[*entity.Trade]{ctx: ctx, ch: make(chan *entity.Trade)}
+	e.tradeSubs[symbol] = append(e.tradeSubs[symbol], sub)
+	return sub.ch, nil
+}
+
+// SubscribeAll delivers the books and trades of the symbols on one channel,
+// interleaved in recorded order
+func (e *Exchange) SubscribeAll(ctx context.Context, symbols []string) (<-chan Update, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.played {
+		return nil, ErrPlayed
+	}
+	sub := &updateSubscriber{
+		subscriber: &subscriber[Update]{ctx: ctx, ch: make(chan Update)},
+		symbols:    make(map[string]bool, len(symbols)),
+	}
+	for _, symbol := range symbols {
+		sub.symbols[symbol] = true
+	}
+	e.allSubs = append(e.allSubs, sub)
+	return sub.ch, nil
+}
+
+// Play replays the recordings and returns when they are exhausted, End is
+// reached, ctx is done or the exchange is closed. Subscriber channels are
+// closed when it returns unless Config.Hold is set. The exchange reads as
+// connected while it plays. An exchange can be played once.
+func (e *Exchange) Play(ctx context.Context) error {
+	e.mu.Lock()
+	if e.played {
+		e.mu.Unlock()
+		return ErrPlayed
+	}
+	e.played = true
+	e.mu.Unlock()
+	defer close(e.finished)
+	if !e.cfg.Hold {
+		defer e.closeSubscribers()
+	}
+
+	if err := e.BaseExchange.Connect(ctx); err != nil {
+		return err
+	}
+	defer e.BaseExchange.Close()
+
+	reader, err := recorder.OpenFiles(e.cfg.Files)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	var origin, wallStart time.Time
+	for {
+		frame, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if frame.Exchange != e.cfg.Name || frame.ReceivedAt.Before(e.cfg.Start) {
+			continue
+		}
+		if !e.cfg.End.IsZero() && !frame.ReceivedAt.Before(e.cfg.End) {
+			return nil
+		}
+
+		if e.cfg.Speed > 0 {
+			if origin.IsZero() {
+				origin, wallStart = frame.ReceivedAt, time.Now()
+			}
+			due := wallStart.Add(time.Duration(float64(frame.ReceivedAt.Sub(origin)) / e.cfg.Speed))
+			if !e.sleepUntil(ctx, due) {
+				return ctx.Err()
+			}
+		}
+
+		e.clock.set(frame.ReceivedAt)
+
+		events, err := e.cfg.Decoder.Decode(frame)
+		if err != nil {
+			e.decodeErrors.Add(1)
+			continue
+		}
+		for _, event := range events {
+			if !e.deliver(ctx, event) {
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// sleepUntil waits for the wall-clock time, returning false if playback stopped
+func (e *Exchange) sleepUntil(ctx context.Context, due time.Time) bool {
+	wait := time.Until(due)
+	if wait <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-e.stop:
+		return false
+	}
+}
+
+func (e *Exchange) deliver(ctx context.Context, event exchange.MarketEvent) bool {
+	switch event.Kind {
+	case exchange.EventBookSnapshot, exchange.EventBookDelta:
+		e.mu.Lock()
+		if !e.sequenced(event) {
+			e.mu.Unlock()
+			return true
+		}
+		book, ok := e.books[event.Symbol]
+		if !ok {
+			book = exchange.NewLocalBook()
+			e.books[event.Symbol] = book
+		}
+		if event.Kind == exchange.EventBookSnapshot {
+			book.Replace(event.Bids, event.Asks, event.Timestamp)
+		} else {
+			book.Apply(event.Bids, event.Asks, event.Timestamp)
+		}
+		orderbook := book.OrderBook(e.GetName(), event.Symbol)
+		e.latest[event.Symbol] = orderbook
+		subs := e.bookSubs[event.Symbol]
+		all := e.updateSubs(event.Symbol)
+		e.mu.Unlock()
+
+		return send(ctx, e.stop, subs, orderbook) && send(ctx, e.stop, all, Update{Book: orderbook})
+
+	case exchange.EventTrade:
+		e.mu.RLock()
+		subs := e.tradeSubs[event.Symbol]
+		all := e.updateSubs(event.Symbol)
+		e.mu.RUnlock()
+
+		return send(ctx, e.stop, subs, event.Trade) && send(ctx, e.stop, all, Update{Trade: event.Trade})
+	}
+
+	return true
+}
+
+// sequenced checks the update IDs of a book event: a snapshot restarts them,
+// a delta the book already covers is dropped, and a skipped ID is reported.
+// Events without IDs always apply. The caller holds e.mu.
+func (e *Exchange) sequenced(event exchange.MarketEvent) bool {
+	if event.FinalUpdateID == 0 {
+		return true
+	}
+	if event.Kind == exchange.EventBookSnapshot {
+		e.sequence.Reset(event.Symbol, event.FinalUpdateID)
+		return true
+	}
+	if e.sequence.Stale(event.Symbol, event.FinalUpdateID) {
+		return false
+	}
+	if e.sequence.Next(event.Symbol, event.FirstUpdateID, event.FinalUpdateID) {
+		e.ReportSequenceGap(event.Symbol)
+	}
+	return true
+}
+
+// updateSubs returns the SubscribeAll subscribers of the symbol. The caller
+// holds e.mu.
+func (e *Exchange) updateSubs(symbol string) []*subscriber[Update] {
+	var subs []*subscriber[Update]
+	for _, sub := range e.allSubs {
+		if sub.symbols[symbol] {
+			subs = append(subs, sub.subscriber)
+		}
+	}
+	return subs
+}
+
+// send blocks until every live subscriber took the value, so slow consumers
+// slow the replay down instead of losing updates
+func send[T any](ctx context.Context, stop <-chan struct{}, subs []*subscriber[T], value T) bool {
+	for _, sub := range subs {
+		select {
+		case sub.ch <- value:
+		case <-sub.ctx.Done():
+		case <-ctx.Done():
+			return false
+		case <-stop:
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Exchange) closeSubscribers() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, subs := range e.bookSubs {
+		for _, sub := range subs {
+			close(sub.ch)
+		}
+	}
+	for _, subs := range e.tradeSubs {
+		for _, sub := range subs {
+			close(sub.ch)
+		}
+	}
+	for _, sub := range e.allSubs {
+		close(sub.ch)
+	}
+	e.bookSubs = nil
+	e.tradeSubs = nil
+	e.allSubs = nil
+}
+
+var (
+	_ output.ExchangePort    = (*Exchange)(nil)
+	_ output.TradeStreamPort = (*Exchange)(nil)
+)
@@ -0,0 +1,32 @@
+package exchange
+
+// SequenceTracker follows the update IDs of each symbol's book stream. An
+// update covers the IDs first..final; it is stale when the book already
+// covers final, and follows a gap when first is past the ID after the last
+// one applied. It is not safe for concurrent use.
+type SequenceTracker struct {
+	last map[string]int64
+}
+
+func NewSequenceTracker() *SequenceTracker {
+	return &SequenceTracker{last: make(map[string]int64)}
+}
+
+// Reset starts the symbol over from a snapshot's last update ID
+func (t *SequenceTracker) Reset(symbol string, lastUpdateID int64) {
+	t.last[symbol] = lastUpdateID
+}
+
+// Stale reports whether an update ending at final is already in the book
+func (t *SequenceTracker) Stale(symbol string, final int64) bool {
+	last, ok := t.last[symbol]
+	return ok && final <= last
+}
+
+// Next records an update and reports whether updates were skipped before it.
+// The first update of a symbol never counts as a gap.
+func (t *SequenceTracker) Next(symbol string, first, final int64) bool {
+	last, ok := t.last[symbol]
+	t.last[symbol] = final
+	return ok && first > last+1
+}
@@ -0,0 +1,111 @@
+package simulated
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultName           = "simulated"
+	defaultUpdateInterval = 100 * time.Millisecond
+	defaultDepth          = 20
+	defaultSpreadBps      = 2
+	defaultVolatility     = 0.0005
+	defaultLevelQuantity  = 1
+	defaultSpikeDelay     = 2 * time.Second
+)
+
+type Config struct {
+	// Name is reported by GetName and used as the exchange ID of books and trades
+	Name           string
+	Symbols        []SymbolConfig
+	UpdateInterval time.Duration
+	Depth          int
+	// Seed makes a run reproducible; zero seeds from the clock
+	Seed   int64
+	Faults Faults
+}
+
+// SymbolConfig shapes the random walk of one instrument
+type SymbolConfig struct {
+	Symbol     string
+	StartPrice float64
+	// SpreadBps is the quoted spread around the mid price in basis points
+	SpreadBps float64
+	// Volatility is the standard deviation of mid price log returns per second
+	Volatility float64
+	// TickSize is the price step between levels; zero derives it from StartPrice
+	TickSize float64
+	// LevelQuantity is the mean resting quantity of a level
+	LevelQuantity float64
+	// TradesPerUpdate is the mean number of trades generated per update; zero
+	// generates none
+	TradesPerUpdate float64
+}
+
+// Faults are per-update probabilities of misbehaving like a real venue
+type Faults struct {
+	// SequenceGap drops an update, so consumers see the update IDs jump and
+	// the in-process exchange reports a gap
+	SequenceGap float64
+	// Disconnect drops every subscriber and connection
+	Disconnect float64
+	// CrossedBook quotes a bid above the best ask for one update
+	CrossedBook float64
+	// LatencySpike stalls the feed for SpikeDelay before the update
+	LatencySpike float64
+	SpikeDelay   time.Duration
+}
+
+func (c Config) withDefaults() (Config, error) {
+	if len(c.Symbols) == 0 {
+		return c, fmt.Errorf("simulated exchange needs at least one symbol")
+	}
+	if c.Name == "" {
+		c.Name = defaultName
+	}
+	if c.UpdateInterval <= 0 {
+		c.UpdateInterval = defaultUpdateInterval
+	}
+	if c.Depth <= 0 {
+		c.Depth = defaultDepth
+	}
+	if c.Seed == 0 {
+		c.Seed = time.Now().UnixNano()
+	}
+	if c.Faults.SpikeDelay <= 0 {
+		c.Faults.SpikeDelay = defaultSpikeDelay
+	}
+
+	symbols := make([]SymbolConfig, len(c.Symbols))
+	seen := make(map[string]bool, len(c.Symbols))
+	for i, symbol := range c.Symbols {
+		if symbol.Symbol == "" {
+			return c, fmt.Errorf("simulated symbol %d has no name", i)
+		}
+		if seen[symbol.Symbol] {
+			return c, fmt.Errorf("simulated symbol %s is configured twice", symbol.Symbol)
+		}
+		seen[symbol.Symbol] = true
+
+		if symbol.StartPrice <= 0 {
+			return c, fmt.Errorf("simulated symbol %s needs a positive start price", symbol.Symbol)
+		}
+		if symbol.SpreadBps <= 0 {
+			symbol.SpreadBps = defaultSpreadBps
+		}
+		if symbol.Volatility <= 0 {
+			symbol.Volatility = defaultVolatility
+		}
+		if symbol.TickSize <= 0 {
+			symbol.TickSize = symbol.StartPrice * 1e-5
+		}
+		if symbol.LevelQuantity <= 0 {
+			symbol.LevelQuantity = defaultLevelQuantity
+		}
+		symbols[i] = symbol
+	}
+	c.Symbols = symbols
+
+	return c, nil
+}
@@ -0,0 +1,249 @@
+package simulated
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+	"marketdata/internal/domain/valueobject"
+	"marketdata/internal/infrastructure/exchange"
+)
+
+const subscriberBuffer = 100
+
+// Exchange is an ExchangePort backed by random-walk markets, for running the
+// service without live venues. Slow subscribers miss updates, as they would
+// on a real feed, a disconnect fault closes every subscription until the
+// exchange is connected again, and updates lost to sequence gap faults are
+// reported like a live adapter reports them.
+type Exchange struct {
+	*exchange.BaseExchange
+	feed *Feed
+
+	mu        sync.Mutex
+	bookSubs  map[string][]chan *entity.OrderBook
+	tradeSubs map[string][]chan *entity.Trade
+	sequence  *exchange.SequenceTracker
+}
+
+// NewExchange serves the markets of feed, which the caller runs; gaps may be
+// nil
+func NewExchange(feed *Feed, gaps output.SequenceGapPort) *Exchange {
+	e := &Exchange{
+		BaseExchange: exchange.NewBaseExchange(exchange.Config{Name: feed.Name(), Gaps: gaps}),
+		feed:         feed,
+		bookSubs:     make(map[string][]chan *entity.OrderBook),
+		tradeSubs:    make(map[string][]chan *entity.Trade),
+		sequence:     exchange.NewSequenceTracker(),
+	}
+	feed.attach(e)
+	return e
+}
+
+// Close closes every subscription; the feed keeps running
+func (e *Exchange) Close() error {
+	e.closeSubscribers()
+
+	if !e.IsConnected() {
+		return nil
+	}
+	return e.BaseExchange.Close()
+}
+
+func (e *Exchange) GetOrderBook(ctx context.Context, symbol string) (*entity.OrderBook, error) {
+	_, bids, asks, err := e.feed.snapshot(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return newOrderBook(e.GetName(), symbol, bids, asks, time.Now())
+}
+
+func (e *Exchange) SubscribeOrderBook(ctx context.Context, symbol string) (<-chan *entity.OrderBook, error) {
+	if err := e.checkSubscribe(symbol); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ch := make(chan *entity.OrderBook, subscriberBuffer)
+	e.bookSubs[symbol] = append(e.bookSubs[symbol], ch)
+	go e.unsubscribeOnDone(ctx, func() { e.bookSubs[symbol] = removeChan(e.bookSubs[symbol], ch) })
+
+	return ch, nil
+}
+
+func (e *Exchange) SubscribeTrades(ctx context.Context, symbol string) (<-chan *entity.Trade, error) {
+	if err := e.checkSubscribe(symbol); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ch := make(chan *entity.Trade, subscriberBuffer)
+	e.tradeSubs[symbol] = append(e.tradeSubs[symbol], ch)
+	go e.unsubscribeOnDone(ctx, func() { e.tradeSubs[symbol] = removeChan(e.tradeSubs[symbol], ch) })
+
+	return ch, nil
+}
+
+func (e *Exchange) checkSubscribe(symbol string) error {
+	if !e.IsConnected() {
+		return fmt.Errorf("exchange %s is not connected", e.GetName())
+	}
+	if !e.feed.has(symbol) {
+		return fmt.Errorf("symbol %s is not simulated", symbol)
+	}
+	return nil
+}
+
+// unsubscribeOnDone runs remove once ctx is done; remove closes the channel
+// if it is still subscribed
+func (e *Exchange) unsubscribeOnDone(ctx context.Context, remove func()) {
+	<-ctx.Done()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	remove()
+}
+
+func (e *Exchange) publish(u update) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.sequence.Next(u.symbol, u.firstUpdateID, u.lastUpdateID) {
+		e.ReportSequenceGap(u.symbol)
+	}
+
+	if subs := e.bookSubs[u.symbol]; len(subs) > 0 {
+		orderbook, err := newOrderBook(e.GetName(), u.symbol, u.bookBids, u.bookAsks, u.at)
+		if err == nil {
+			for _, ch := range subs {
+				select {
+				case ch <- orderbook:
+				default:
+				}
+			}
+		}
+	}
+
+	if subs := e.tradeSubs[u.symbol]; len(subs) > 0 {
+		for _, t := range u.trades {
+			trade, err := newTrade(e.GetName(), u.symbol, t)
+			if err != nil {
+				continue
+			}
+			for _, ch := range subs {
+				select {
+				case ch <- trade:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// disconnect simulates a dropped connection
+func (e *Exchange) disconnect() {
+	if e.IsConnected() {
+		e.BaseExchange.Close()
+	}
+	e.closeSubscribers()
+}
+
+func (e *Exchange) closeSubscribers() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, subs := range e.bookSubs {
+		for _, ch := range subs {
+			close(ch)
+		}
+	}
+	for _, subs := range e.tradeSubs {
+		for _, ch := range subs {
+			close(ch)
+		}
+	}
+	e.bookSubs = make(map[string][]chan *entity.OrderBook)
+	e.tradeSubs = make(map[string][]chan *entity.Trade)
+}
+
+// removeChan closes ch and removes it from subs if it is still there
+func removeChan[T any](subs []chan T, ch chan T) []chan T {
+	for i, sub := range subs {
+		if sub == ch {
+			close(ch)
+			return append(subs[:i], subs[i+1:]...)
+		}
+	}
+	return subs
+}
+
+func newOrderBook(exchangeID, symbol string, bids, asks []level, at time.Time) (*entity.OrderBook, error) {
+	bidLevels, err := toPriceLevels(symbol, bids)
+	if err != nil {
+		return nil, err
+	}
+	askLevels, err := toPriceLevels(symbol, asks)
+	if err != nil {
+		return nil, err
+	}
+
+	orderbook := entity.NewOrderBook(exchangeID, symbol, at)
+	orderbook.UpdateBids(bidLevels)
+	orderbook.UpdateAsks(askLevels)
+	return orderbook, nil
+}
+
+func toPriceLevels(symbol string, levels []level) ([]entity.PriceLevel, error) {
+	priceLevels := make([]entity.PriceLevel, len(levels))
+	for i, l := range levels {
+		price, err := valueobject.NewPrice(l.price, quoteAsset(symbol))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create price: %w", err)
+		}
+		quantity, err := valueobject.NewVolume(l.quantity, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create volume: %w", err)
+		}
+		priceLevels[i] = entity.PriceLevel{Price: *price, Quantity: *quantity}
+	}
+	return priceLevels, nil
+}
+
+func newTrade(exchangeID, symbol string, t trade) (*entity.Trade, error) {
+	price, err := valueobject.NewPrice(t.price, quoteAsset(symbol))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create price: %w", err)
+	}
+	quantity, err := valueobject.NewVolume(t.quantity, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create volume: %w", err)
+	}
+
+	side := entity.TradeSell
+	if t.buy {
+		side = entity.TradeBuy
+	}
+	return entity.NewTrade(strconv.FormatInt(t.id, 10), exchangeID, symbol, *price, *quantity, side, t.at), nil
+}
+
+var (
+	_ output.ExchangePort    = (*Exchange)(nil)
+	_ output.TradeStreamPort = (*Exchange)(nil)
+)
+
+// quoteAsset returns the quote currency of "BTC-USDT", defaulting to USDT
+func quoteAsset(symbol string) string {
+	if _, quote, ok := strings.Cut(symbol, "-"); ok && quote != "" {
+		return quote
+	}
+	return "USDT"
+}
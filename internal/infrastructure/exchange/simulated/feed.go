@@ -0,0 +1,140 @@
+package simulated
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// sink receives what the feed generates
+type sink interface {
+	publish(u update)
+	disconnect()
+}
+
+// Feed steps every market once per update interval and injects faults. One
+// feed drives the in-process Exchange and the Server alike, so both simulate
+// the same market.
+type Feed struct {
+	cfg     Config
+	mu      sync.Mutex
+	rng     *rand.Rand
+	markets map[string]*market
+	order   []string
+	sinks   []sink
+}
+
+func NewFeed(cfg Config) (*Feed, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	return newFeed(cfg), nil
+}
+
+func newFeed(cfg Config) *Feed {
+	rng := rand.New(rand.NewPCG(uint64(cfg.Seed), uint64(cfg.Seed)>>1|1))
+	f := &Feed{
+		cfg:     cfg,
+		rng:     rng,
+		markets: make(map[string]*market, len(cfg.Symbols)),
+	}
+	for _, symbol := range cfg.Symbols {
+		f.markets[symbol.Symbol] = newMarket(symbol, cfg.Depth, rng)
+		f.order = append(f.order, symbol.Symbol)
+	}
+	return f
+}
+
+// Name is the configured exchange name
+func (f *Feed) Name() string {
+	return f.cfg.Name
+}
+
+// attach adds a sink that receives every update and fault from now on
+func (f *Feed) attach(out sink) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sinks = append(f.sinks, out)
+}
+
+// Run steps the markets until ctx is done
+func (f *Feed) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.cfg.UpdateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if f.chance(f.cfg.Faults.LatencySpike) {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(f.cfg.Faults.SpikeDelay):
+			}
+		}
+		disconnect := f.chance(f.cfg.Faults.Disconnect)
+		updates, sinks := f.step(time.Now())
+
+		for _, out := range sinks {
+			if disconnect {
+				out.disconnect()
+			}
+			for _, u := range updates {
+				out.publish(u)
+			}
+		}
+	}
+}
+
+// step advances every market, leaving out updates lost to sequence gaps, and
+// returns the sinks to publish to
+func (f *Feed) step(at time.Time) ([]update, []sink) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	updates := make([]update, 0, len(f.order))
+	for _, symbol := range f.order {
+		crossed := f.chanceLocked(f.cfg.Faults.CrossedBook)
+		u := f.markets[symbol].step(f.cfg.UpdateInterval, at, crossed)
+		if f.chanceLocked(f.cfg.Faults.SequenceGap) {
+			continue
+		}
+		updates = append(updates, u)
+	}
+	return updates, f.sinks
+}
+
+// snapshot returns the current book of a symbol and the ID of its last update
+func (f *Feed) snapshot(symbol string) (int64, []level, []level, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	m, ok := f.markets[symbol]
+	if !ok {
+		return 0, nil, nil, fmt.Errorf("symbol %s is not simulated", symbol)
+	}
+	bids, asks := m.book()
+	return m.updateID, bids, asks, nil
+}
+
+func (f *Feed) has(symbol string) bool {
+	_, ok := f.markets[symbol]
+	return ok
+}
+
+func (f *Feed) chance(p float64) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.chanceLocked(p)
+}
+
+func (f *Feed) chanceLocked(p float64) bool {
+	return p > 0 && f.rng.Float64() < p
+}
@@ -0,0 +1,214 @@
+package simulated
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+)
+
+// level is a price level; a zero quantity in an update removes it
+type level struct {
+	price    float64
+	quantity float64
+}
+
+type trade struct {
+	id       int64
+	price    float64
+	quantity float64
+	buy      bool
+	at       time.Time
+}
+
+// update is one step of a market: the changed levels, the full book after the
+// change and the trades executed since the previous step
+type update struct {
+	symbol        string
+	firstUpdateID int64
+	lastUpdateID  int64
+	bids, asks    []level
+	bookBids      []level
+	bookAsks      []level
+	trades        []trade
+	at            time.Time
+}
+
+// market random-walks the mid price of one symbol and quotes a book around it.
+// Levels are keyed by their price in ticks to avoid float drift.
+type market struct {
+	cfg      SymbolConfig
+	depth    int
+	rng      *rand.Rand
+	mid      float64
+	bids     map[int64]float64
+	asks     map[int64]float64
+	updateID int64
+	tradeID  int64
+}
+
+func newMarket(cfg SymbolConfig, depth int, rng *rand.Rand) *market {
+	m := &market{
+		cfg:   cfg,
+		depth: depth,
+		rng:   rng,
+		mid:   cfg.StartPrice,
+		bids:  make(map[int64]float64),
+		asks:  make(map[int64]float64),
+	}
+	m.requoteFrom(nil, nil, false)
+	return m
+}
+
+// step advances the walk by dt and returns what changed. A crossed step
+// quotes a bid one tick above the best ask; the next step removes it.
+func (m *market) step(dt time.Duration, at time.Time, crossed bool) update {
+	trades := m.trade(at)
+
+	m.mid *= math.Exp(m.cfg.Volatility * math.Sqrt(dt.Seconds()) * m.rng.NormFloat64())
+
+	prevBids, prevAsks := m.bids, m.asks
+	m.bids, m.asks = make(map[int64]float64, m.depth), make(map[int64]float64, m.depth)
+	m.requoteFrom(prevBids, prevAsks, crossed)
+
+	bids := diff(prevBids, m.bids, m.cfg.TickSize)
+	asks := diff(prevAsks, m.asks, m.cfg.TickSize)
+
+	changes := int64(max(len(bids)+len(asks), 1))
+	u := update{
+		symbol:        m.cfg.Symbol,
+		firstUpdateID: m.updateID + 1,
+		lastUpdateID:  m.updateID + changes,
+		bids:          sortLevels(bids, true),
+		asks:          sortLevels(asks, false),
+		trades:        trades,
+		at:            at,
+	}
+	m.updateID = u.lastUpdateID
+	u.bookBids, u.bookAsks = m.book()
+
+	return u
+}
+
+// requoteFrom quotes depth levels per side around the mid price, keeping most
+// quantities of levels that were already quoted so updates stay small
+func (m *market) requoteFrom(prevBids, prevAsks map[int64]float64, crossed bool) {
+	half := m.mid * m.cfg.SpreadBps / 2e4
+	bestBid := int64(math.Floor((m.mid - half) / m.cfg.TickSize))
+	bestAsk := int64(math.Ceil((m.mid + half) / m.cfg.TickSize))
+	if bestAsk <= bestBid {
+		bestAsk = bestBid + 1
+	}
+
+	for i := 0; i < m.depth; i++ {
+		m.bids[bestBid-int64(i)] = m.quantity(prevBids, bestBid-int64(i), i)
+		m.asks[bestAsk+int64(i)] = m.quantity(prevAsks, bestAsk+int64(i), i)
+	}
+
+	if crossed {
+		m.bids[bestAsk+1] = m.quantity(nil, bestAsk+1, 0)
+	}
+}
+
+// quantity grows with the distance from the top of the book
+func (m *market) quantity(prev map[int64]float64, ticks int64, distance int) float64 {
+	if qty, ok := prev[ticks]; ok && m.rng.Float64() < 0.7 {
+		return qty
+	}
+	qty := m.cfg.LevelQuantity * (0.5 + m.rng.Float64()) * (1 + float64(distance)*0.1)
+	return roundQuantity(qty)
+}
+
+// trade executes a Poisson number of market orders against the current book
+func (m *market) trade(at time.Time) []trade {
+	count := poisson(m.rng, m.cfg.TradesPerUpdate)
+	if count == 0 {
+		return nil
+	}
+
+	bids, asks := m.book()
+	trades := make([]trade, 0, count)
+	for i := 0; i < count; i++ {
+		buy := m.rng.IntN(2) == 0
+		side := bids
+		if buy {
+			side = asks
+		}
+		if len(side) == 0 {
+			continue
+		}
+
+		m.tradeID++
+		trades = append(trades, trade{
+			id:       m.tradeID,
+			price:    side[0].price,
+			quantity: roundQuantity(m.rng.ExpFloat64() * m.cfg.LevelQuantity * 0.2),
+			buy:      buy,
+			at:       at,
+		})
+	}
+	return trades
+}
+
+// book returns the sorted bids and asks
+func (m *market) book() ([]level, []level) {
+	return sortLevels(toLevels(m.bids, m.cfg.TickSize), true), sortLevels(toLevels(m.asks, m.cfg.TickSize), false)
+}
+
+func toLevels(side map[int64]float64, tick float64) []level {
+	levels := make([]level, 0, len(side))
+	for ticks, qty := range side {
+		levels = append(levels, level{price: price(ticks, tick), quantity: qty})
+	}
+	return levels
+}
+
+// diff lists the levels that were added or changed, and removed ones with zero quantity
+func diff(prev, next map[int64]float64, tick float64) []level {
+	var changed []level
+	for ticks, qty := range next {
+		if old, ok := prev[ticks]; !ok || old != qty {
+			changed = append(changed, level{price: price(ticks, tick), quantity: qty})
+		}
+	}
+	for ticks := range prev {
+		if _, ok := next[ticks]; !ok {
+			changed = append(changed, level{price: price(ticks, tick)})
+		}
+	}
+	return changed
+}
+
+func sortLevels(levels []level, descending bool) []level {
+	sort.Slice(levels, func(i, j int) bool {
+		if descending {
+			return levels[i].price > levels[j].price
+		}
+		return levels[i].price < levels[j].price
+	})
+	return levels
+}
+
+// price converts ticks back to a price, rounded to hide float artifacts
+func price(ticks int64, tick float64) float64 {
+	return math.Round(float64(ticks)*tick*1e8) / 1e8
+}
+
+func roundQuantity(qty float64) float64 {
+	return math.Max(math.Round(qty*1e6)/1e6, 1e-6)
+}
+
+// poisson draws from a Poisson distribution with the given mean (Knuth)
+func poisson(rng *rand.Rand, mean float64) int {
+	if mean <= 0 {
+		return 0
+	}
+
+	limit := math.Exp(-mean)
+	count, product := 0, rng.Float64()
+	for product > limit {
+		count++
+		product *= rng.Float64()
+	}
+	return count
+}
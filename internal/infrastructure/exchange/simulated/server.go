@@ -0,0 +1,328 @@
+package simulated
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"marketdata/internal/infrastructure/exchange/binance"
+	"marketdata/pkg/logger"
+)
+
+const (
+	connectionBuffer = 256
+	writeTimeout     = 5 * time.Second
+	maxDepthLimit    = 5000
+)
+
+// partialDepths are the levels of the partial book depth streams
+var partialDepths = []int{5, 10, 20}
+
+// Server serves simulated markets over the Binance spot API, so adapters can
+// be tested against a local venue. It implements raw streams (/ws/<stream>),
+// combined streams (/stream?streams=<a>/<b>) and the REST depth snapshot
+// (/api/v3/depth). Streams are the diff stream <symbol>@depth, the partial
+// book streams <symbol>@depth5, @depth10 and @depth20, and <symbol>@trade,
+// with the symbol spelled the Binance way (btcusdt for BTC-USDT).
+type Server struct {
+	feed     *Feed
+	log      *logger.Logger
+	upgrader websocket.Upgrader
+	symbols  map[string]string
+
+	mu    sync.Mutex
+	conns map[*streamConn]struct{}
+}
+
+type streamConn struct {
+	ws       *websocket.Conn
+	streams  map[string]bool
+	combined bool
+	send     chan []byte
+	once     sync.Once
+}
+
+// NewServer serves the markets of feed, which the caller runs
+func NewServer(feed *Feed, log *logger.Logger) *Server {
+	symbols := make(map[string]string, len(feed.cfg.Symbols))
+	for _, symbol := range feed.cfg.Symbols {
+		symbols[wireSymbol(symbol.Symbol)] = symbol.Symbol
+	}
+
+	s := &Server{
+		feed:     feed,
+		log:      log,
+		upgrader: websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
+		symbols:  symbols,
+		conns:    make(map[*streamConn]struct{}),
+	}
+	feed.attach(s)
+	return s
+}
+
+// Close closes every connection
+func (s *Server) Close() {
+	s.disconnect()
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ws/{streams...}", s.handleRaw)
+	mux.HandleFunc("GET /stream", s.handleCombined)
+	mux.HandleFunc("GET /api/v3/depth", s.handleDepth)
+	return mux
+}
+
+func (s *Server) handleRaw(w http.ResponseWriter, r *http.Request) {
+	s.serveStreams(w, r, strings.Split(r.PathValue("streams"), "/"), false)
+}
+
+func (s *Server) handleCombined(w http.ResponseWriter, r *http.Request) {
+	s.serveStreams(w, r, strings.Split(r.URL.Query().Get("streams"), "/"), true)
+}
+
+func (s *Server) serveStreams(w http.ResponseWriter, r *http.Request, names []string, combined bool) {
+	streams := make(map[string]bool, len(names))
+	for _, name := range names {
+		symbol, kind, ok := strings.Cut(strings.ToLower(name), "@")
+		// update speed suffixes such as depth@100ms are accepted; updates
+		// follow the simulated update interval
+		kind, _, _ = strings.Cut(kind, "@")
+		if !ok || s.symbols[symbol] == "" || !validStream(kind) {
+			writeError(w, http.StatusBadRequest, -1121, "Invalid symbol or stream: "+name)
+			return
+		}
+		streams[symbol+"@"+kind] = true
+	}
+
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	conn := &streamConn{
+		ws:       ws,
+		streams:  streams,
+		combined: combined,
+		send:     make(chan []byte, connectionBuffer),
+	}
+	s.mu.Lock()
+	s.conns[conn] = struct{}{}
+	s.mu.Unlock()
+
+	go s.writeLoop(conn)
+	s.readLoop(conn)
+}
+
+// readLoop discards client messages and notices when the client goes away
+func (s *Server) readLoop(conn *streamConn) {
+	defer s.drop(conn)
+	for {
+		if _, _, err := conn.ws.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) writeLoop(conn *streamConn) {
+	defer s.drop(conn)
+	for msg := range conn.send {
+		conn.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err := conn.ws.WriteMessage(websocket.TextMessage, msg); err != nil {
+			return
+		}
+	}
+}
+
+// validStream reports whether kind is a stream the server publishes
+func validStream(kind string) bool {
+	if kind == "depth" || kind == "trade" {
+		return true
+	}
+	for _, levels := range partialDepths {
+		if kind == "depth"+strconv.Itoa(levels) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) handleDepth(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	symbol, ok := s.symbols[strings.ToLower(query.Get("symbol"))]
+	if !ok {
+		writeError(w, http.StatusBadRequest, -1121, "Invalid symbol.")
+		return
+	}
+
+	limit := 100
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxDepthLimit {
+			writeError(w, http.StatusBadRequest, -1100, "Illegal characters found in parameter 'limit'.")
+			return
+		}
+		limit = parsed
+	}
+
+	lastUpdateID, bids, asks, err := s.feed.snapshot(symbol)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, -1121, "Invalid symbol.")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(binance.DepthSnapshot{
+		LastUpdateID: lastUpdateID,
+		Bids:         wireLevels(bids[:min(limit, len(bids))]),
+		Asks:         wireLevels(asks[:min(limit, len(asks))]),
+	})
+}
+
+func (s *Server) publish(u update) {
+	symbol := wireSymbol(u.symbol)
+
+	depth, err := json.Marshal(binance.DepthUpdate{
+		Event:         "depthUpdate",
+		EventTime:     u.at.UnixMilli(),
+		Symbol:        strings.ToUpper(symbol),
+		FirstUpdateID: u.firstUpdateID,
+		FinalUpdateID: u.lastUpdateID,
+		Bids:          wireLevels(u.bids),
+		Asks:          wireLevels(u.asks),
+	})
+	if err != nil {
+		s.log.Error("failed to encode depth update", "symbol", u.symbol, "error", err)
+		return
+	}
+	s.broadcast(symbol+"@depth", depth)
+
+	for _, levels := range partialDepths {
+		stream := symbol + "@depth" + strconv.Itoa(levels)
+		if !s.subscribed(stream) {
+			continue
+		}
+		partial, err := json.Marshal(binance.DepthSnapshot{
+			LastUpdateID: u.lastUpdateID,
+			Bids:         wireLevels(u.bookBids[:min(levels, len(u.bookBids))]),
+			Asks:         wireLevels(u.bookAsks[:min(levels, len(u.bookAsks))]),
+		})
+		if err != nil {
+			s.log.Error("failed to encode partial depth", "symbol", u.symbol, "error", err)
+			continue
+		}
+		s.broadcast(stream, partial)
+	}
+
+	for _, t := range u.trades {
+		msg, err := json.Marshal(binance.TradeEvent{
+			Event:        "trade",
+			EventTime:    u.at.UnixMilli(),
+			Symbol:       strings.ToUpper(symbol),
+			TradeID:      t.id,
+			Price:        formatFloat(t.price),
+			Quantity:     formatFloat(t.quantity),
+			TradeTime:    t.at.UnixMilli(),
+			BuyerIsMaker: !t.buy,
+		})
+		if err != nil {
+			s.log.Error("failed to encode trade", "symbol", u.symbol, "error", err)
+			continue
+		}
+		s.broadcast(symbol+"@trade", msg)
+	}
+}
+
+// subscribed reports whether any connection listens to the stream
+func (s *Server) subscribed(stream string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.conns {
+		if conn.streams[stream] {
+			return true
+		}
+	}
+	return false
+}
+
+// broadcast sends a payload to the connections subscribed to the stream,
+// dropping connections that cannot keep up like Binance does
+func (s *Server) broadcast(stream string, payload []byte) {
+	var combined []byte
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.conns {
+		if !conn.streams[stream] {
+			continue
+		}
+
+		msg := payload
+		if conn.combined {
+			if combined == nil {
+				combined, _ = json.Marshal(binance.StreamMessage{Stream: stream, Data: payload})
+			}
+			msg = combined
+		}
+
+		select {
+		case conn.send <- msg:
+		default:
+			s.dropLocked(conn)
+		}
+	}
+}
+
+// disconnect closes every connection
+func (s *Server) disconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.conns {
+		s.dropLocked(conn)
+	}
+}
+
+func (s *Server) drop(conn *streamConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropLocked(conn)
+}
+
+func (s *Server) dropLocked(conn *streamConn) {
+	conn.once.Do(func() {
+		delete(s.conns, conn)
+		close(conn.send)
+		conn.ws.Close()
+	})
+}
+
+func writeError(w http.ResponseWriter, status, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": code, "msg": msg})
+}
+
+func wireLevels(levels []level) [][2]string {
+	wire := make([][2]string, len(levels))
+	for i, l := range levels {
+		wire[i] = [2]string{formatFloat(l.price), formatFloat(l.quantity)}
+	}
+	return wire
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 8, 64)
+}
+
+// wireSymbol spells a symbol the way Binance streams do: BTC-USDT is btcusdt
+func wireSymbol(symbol string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "/", "", "_", "").Replace(symbol))
+}
@@ -6,8 +6,15 @@ import (
 	"sync"
 
 	"marketdata/internal/application/port/output"
+	"marketdata/internal/infrastructure/exchange/recorder"
 )
 
+// FrameRecorder receives a copy of every raw message an adapter reads, see
+// recorder.Recorder. Implementations must not block.
+type FrameRecorder interface {
+	Record(exchange, symbol string, kind recorder.FrameKind, data []byte)
+}
+
 // BaseExchange provides common functionality for exchange implementations
 type BaseExchange struct {
 	name      string
@@ -15,6 +22,8 @@ type BaseExchange struct {
 	apiSecret string
 	baseURL   string
 	wsURL     string
+	recorder  FrameRecorder
+	gaps      output.SequenceGapPort
 	mu        sync.RWMutex
 	connected bool
 }
@@ -26,6 +35,10 @@ type Config struct {
 	APISecret string
 	BaseURL   string
 	WSURL     string
+	// Recorder, when set, receives every raw websocket frame and REST snapshot
+	Recorder FrameRecorder
+	// Gaps, when set, is told about every sequence gap the adapter detects
+	Gaps output.SequenceGapPort
 }
 
 // NewBaseExchange creates a new BaseExchange
@@ -36,6 +49,8 @@ func NewBaseExchange(cfg Config) *BaseExchange {
 		apiSecret: cfg.APISecret,
 		baseURL:   cfg.BaseURL,
 		wsURL:     cfg.WSURL,
+		recorder:  cfg.Recorder,
+		gaps:      cfg.Gaps,
 	}
 }
 
@@ -44,6 +59,37 @@ func (e *BaseExchange) GetName() string {
 	return e.name
 }
 
+// Credentials returns the API key and secret, empty for public access only
+func (e *BaseExchange) Credentials() (apiKey, apiSecret string) {
+	return e.apiKey, e.apiSecret
+}
+
+// BaseURL returns the REST endpoint, empty when the adapter uses its default
+func (e *BaseExchange) BaseURL() string {
+	return e.baseURL
+}
+
+// WSURL returns the websocket endpoint, empty when the adapter uses its default
+func (e *BaseExchange) WSURL() string {
+	return e.wsURL
+}
+
+// RecordFrame passes a raw message to the recorder, if one is configured.
+// Adapters call it for every websocket frame and REST response before decoding.
+func (e *BaseExchange) RecordFrame(symbol string, kind recorder.FrameKind, data []byte) {
+	if e.recorder != nil {
+		e.recorder.Record(e.name, symbol, kind, data)
+	}
+}
+
+// ReportSequenceGap passes a gap in the symbol's update IDs to the gap
+// port, if one is configured. Adapters detect gaps with a SequenceTracker.
+func (e *BaseExchange) ReportSequenceGap(symbol string) {
+	if e.gaps != nil {
+		e.gaps.RecordSequenceGap(e.name, symbol)
+	}
+}
+
 // IsConnected returns the connection status
 func (e *BaseExchange) IsConnected() bool {
 	e.mu.RLock()
@@ -76,5 +122,5 @@ func (e *BaseExchange) Close() error {
 	return nil
 }
 
-// Ensure BaseExchange implements ExchangePort
-var _ output.ExchangePort = (*BaseExchange)(nil)
+// Ensure BaseExchange reports its connection status
+var _ output.ExchangeStatusPort = (*BaseExchange)(nil)
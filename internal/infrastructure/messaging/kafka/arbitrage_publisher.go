@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/output"
+)
+
+var _ output.ArbitragePublisherPort = (*ArbitragePublisher)(nil)
+
+// ArbitragePublisher writes opportunity events to their own topic, keyed by
+// opportunity so the events of one opportunity stay in order
+type ArbitragePublisher struct {
+	writer *kafka.Writer
+}
+
+func NewArbitragePublisher(brokers []string, topic string) *ArbitragePublisher {
+	return &ArbitragePublisher{
+		writer: newEventWriter(brokers, topic),
+	}
+}
+
+func (p *ArbitragePublisher) PublishArbitrageEvent(ctx context.Context, event *dto.ArbitrageEventDTO) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal arbitrage event: %w", err)
+	}
+
+	message := kafka.Message{
+		Key:   []byte(event.Opportunity.ID),
+		Value: data,
+	}
+	if err := p.writer.WriteMessages(ctx, message); err != nil {
+		return fmt.Errorf("failed to publish arbitrage event: %w", err)
+	}
+
+	return nil
+}
+
+func (p *ArbitragePublisher) Close() error {
+	return p.writer.Close()
+}
@@ -8,34 +8,52 @@ import (
 	"github.com/segmentio/kafka-go"
 
 	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
 )
 
+var (
+	_ output.EventPublisherPort = (*Publisher)(nil)
+	_ output.HealthCheckPort    = (*Publisher)(nil)
+)
+
+// Publisher writes orderbook updates and trades as JSON, keyed by exchange
+// and symbol so the events of one book stay in order
 type Publisher struct {
-	writer *kafka.Writer
-	topic  string
+	writer  *kafka.Writer
+	brokers []string
+	topic   string
 }
 
 func NewPublisher(brokers []string, topic string) *Publisher {
-	writer := kafka.NewWriter(kafka.WriterConfig{
-		Brokers: brokers,
-		Topic:   topic,
-	})
-
 	return &Publisher{
-		writer: writer,
-		topic:  topic,
+		writer:  newEventWriter(brokers, topic),
+		brokers: brokers,
+		topic:   topic,
 	}
 }
 
-func (p *Publisher) PublishOrderBookUpdate(ctx context.Context, orderbook *dto.OrderBookDTO) error {
-	data, err := json.Marshal(orderbook)
+func (p *Publisher) PublishOrderBookUpdate(ctx context.Context, orderbook *entity.OrderBook) error {
+	data, err := json.Marshal(orderBookMessage(orderbook))
 	if err != nil {
 		return fmt.Errorf("failed to marshal orderbook: %w", err)
 	}
 
-	key := fmt.Sprintf("%s-%s", orderbook.ExchangeID, orderbook.Symbol)
+	return p.write(ctx, orderbook.ExchangeID(), orderbook.Symbol(), data)
+}
+
+func (p *Publisher) PublishTrade(ctx context.Context, trade *entity.Trade) error {
+	data, err := json.Marshal(tradeMessage(trade))
+	if err != nil {
+		return fmt.Errorf("failed to marshal trade: %w", err)
+	}
+
+	return p.write(ctx, trade.ExchangeID(), trade.Symbol(), data)
+}
+
+func (p *Publisher) write(ctx context.Context, exchangeID, symbol string, data []byte) error {
 	message := kafka.Message{
-		Key:   []byte(key),
+		Key:   []byte(fmt.Sprintf("%s-%s", exchangeID, symbol)),
 		Value: data,
 	}
 
@@ -46,6 +64,70 @@ func (p *Publisher) PublishOrderBookUpdate(ctx context.Context, orderbook *dto.O
 	return nil
 }
 
+// orderBookMessage is the wire form of a book, the same as the API's
+func orderBookMessage(orderbook *entity.OrderBook) *dto.OrderBookDTO {
+	return &dto.OrderBookDTO{
+		ExchangeID: orderbook.ExchangeID(),
+		Symbol:     orderbook.Symbol(),
+		Bids:       priceLevelMessages(orderbook.Bids()),
+		Asks:       priceLevelMessages(orderbook.Asks()),
+		Timestamp:  orderbook.Timestamp(),
+	}
+}
+
+func priceLevelMessages(levels []entity.PriceLevel) []dto.PriceLevelDTO {
+	messages := make([]dto.PriceLevelDTO, len(levels))
+	for i, level := range levels {
+		messages[i] = dto.PriceLevelDTO{
+			Price:    level.Price.Value(),
+			Quantity: level.Quantity.Value(),
+		}
+	}
+	return messages
+}
+
+// tradeMessage is the wire form of a trade, the same as the API's
+func tradeMessage(trade *entity.Trade) *dto.TradeDTO {
+	return &dto.TradeDTO{
+		ID:         trade.ID(),
+		ExchangeID: trade.ExchangeID(),
+		Symbol:     trade.Symbol(),
+		Price:      trade.Price().Value(),
+		Volume:     trade.Volume().Value(),
+		TradeType:  string(trade.Type()),
+		Timestamp:  trade.Timestamp(),
+	}
+}
+
+// Name identifies Kafka in readiness reports
+func (p *Publisher) Name() string {
+	return "kafka"
+}
+
+// Check succeeds when at least one broker accepts a connection and reports the topic's partitions
+func (p *Publisher) Check(ctx context.Context) error {
+	var lastErr error
+	for _, broker := range p.brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		_, err = conn.ReadPartitions(p.topic)
+		conn.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+
+	if lastErr == nil {
+		return fmt.Errorf("no kafka brokers configured")
+	}
+	return fmt.Errorf("failed to reach kafka: %w", lastErr)
+}
+
 func (p *Publisher) Close() error {
 	return p.writer.Close()
 }
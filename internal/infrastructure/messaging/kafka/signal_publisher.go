@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/output"
+)
+
+var _ output.StatArbSignalPublisherPort = (*SignalPublisher)(nil)
+
+// SignalPublisher writes statistical arbitrage signals to their own topic,
+// keyed by pair so the signals of one pair stay in order
+type SignalPublisher struct {
+	writer *kafka.Writer
+}
+
+func NewSignalPublisher(brokers []string, topic string) *SignalPublisher {
+	return &SignalPublisher{
+		writer: newEventWriter(brokers, topic),
+	}
+}
+
+func (p *SignalPublisher) PublishStatArbSignal(ctx context.Context, signal *dto.StatArbSignalDTO) error {
+	data, err := json.Marshal(signal)
+	if err != nil {
+		return fmt.Errorf("failed to marshal statistical arbitrage signal: %w", err)
+	}
+
+	message := kafka.Message{
+		Key:   []byte(signal.Pair),
+		Value: data,
+	}
+	if err := p.writer.WriteMessages(ctx, message); err != nil {
+		return fmt.Errorf("failed to publish statistical arbitrage signal: %w", err)
+	}
+
+	return nil
+}
+
+func (p *SignalPublisher) Close() error {
+	return p.writer.Close()
+}
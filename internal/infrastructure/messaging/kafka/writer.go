@@ -0,0 +1,25 @@
+package kafka
+
+import (
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// eventBatchTimeout bounds how long a write waits for a batch to fill
+const eventBatchTimeout = 10 * time.Millisecond
+
+// newEventWriter returns a writer for events published one at a time from a
+// single goroutine. Messages are hashed by key, so the events of one key land
+// on one partition and stay in order. Each write is sent as soon as it is
+// queued rather than waiting out the default one second batch timeout, which
+// would cap a synchronous publisher at one event per second.
+func newEventWriter(brokers []string, topic string) *kafka.Writer {
+	return kafka.NewWriter(kafka.WriterConfig{
+		Brokers:      brokers,
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		BatchSize:    1,
+		BatchTimeout: eventBatchTimeout,
+	})
+}
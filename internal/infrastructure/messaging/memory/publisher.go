@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"marketdata/internal/domain/entity"
+)
+
+type EventType string
+
+const (
+	EventOrderBook EventType = "orderbook"
+	EventTrade     EventType = "trade"
+)
+
+// Event is one published message; exactly one of OrderBook and Trade is set
+type Event struct {
+	Type        EventType
+	OrderBook   *entity.OrderBook
+	Trade       *entity.Trade
+	PublishedAt time.Time
+}
+
+// Publisher is an in-process EventPublisherPort. It records the latest
+// maxEvents events and fans each one out to subscribers. Publishing never
+// blocks: a subscriber whose buffer is full misses the event.
+type Publisher struct {
+	mu          sync.RWMutex
+	events      []Event
+	maxEvents   int
+	subscribers map[int]chan Event
+	nextID      int
+}
+
+func NewPublisher(maxEvents int) *Publisher {
+	return &Publisher{
+		maxEvents:   maxEvents,
+		subscribers: make(map[int]chan Event),
+	}
+}
+
+func (p *Publisher) PublishOrderBookUpdate(ctx context.Context, orderbook *entity.OrderBook) error {
+	p.publish(Event{
+		Type:      EventOrderBook,
+		OrderBook: orderbook,
+	})
+	return nil
+}
+
+func (p *Publisher) PublishTrade(ctx context.Context, trade *entity.Trade) error {
+	p.publish(Event{
+		Type:  EventTrade,
+		Trade: trade,
+	})
+	return nil
+}
+
+// Events returns the recorded events, oldest first
+func (p *Publisher) Events() []Event {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	events := p.events
+	if p.maxEvents > 0 && len(events) > p.maxEvents {
+		events = events[len(events)-p.maxEvents:]
+	}
+	return append([]Event(nil), events...)
+}
+
+// Subscribe delivers every event published from now on until cancel is called
+func (p *Publisher) Subscribe(buffer int) (<-chan Event, func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id := p.nextID
+	p.nextID++
+	events := make(chan Event, buffer)
+	p.subscribers[id] = events
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+
+			delete(p.subscribers, id)
+			close(events)
+		})
+	}
+
+	return events, cancel
+}
+
+func (p *Publisher) publish(event Event) {
+	event.PublishedAt = time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Compact only once the history doubles so trimming stays amortized O(1)
+	p.events = append(p.events, event)
+	if p.maxEvents > 0 && len(p.events) >= 2*p.maxEvents {
+		p.events = append(p.events[:0:0], p.events[len(p.events)-p.maxEvents:]...)
+	}
+
+	for _, subscriber := range p.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package conformance holds behavioural test suites shared by every repository
+// implementation. Adapter tests call them with a factory that returns an empty
+// repository, e.g.
+//
+//	conformance.TestTradeRepository(t, func(t *testing.T) output.TradeRepositoryPort {
+//		return memory.NewTradeRepository(0)
+//	})
+//
+// Factories for database-backed repositories must clear their tables, since
+// each subtest expects to start from an empty store.
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	"marketdata/internal/domain/entity"
+	"marketdata/internal/domain/valueobject"
+)
+
+// base is a fixed, microsecond-aligned time so timestamps survive a round
+// trip through PostgreSQL unchanged
+var base = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTrade(t *testing.T, id, exchangeID, symbol string, price, volume float64, side entity.TradeType, ts time.Time) *entity.Trade {
+	t.Helper()
+
+	priceVO, err := valueobject.NewPrice(price, "USDT")
+	if err != nil {
+		t.Fatalf("failed to create price: %v", err)
+	}
+	volumeVO, err := valueobject.NewVolume(volume, symbol)
+	if err != nil {
+		t.Fatalf("failed to create volume: %v", err)
+	}
+
+	return entity.NewTrade(id, exchangeID, symbol, *priceVO, *volumeVO, side, ts)
+}
+
+func newOrderBook(t *testing.T, exchangeID, symbol string, ts time.Time, bids, asks [][2]float64) *entity.OrderBook {
+	t.Helper()
+
+	orderbook := entity.NewOrderBook(exchangeID, symbol, ts)
+	orderbook.UpdateBids(newLevels(t, symbol, bids))
+	orderbook.UpdateAsks(newLevels(t, symbol, asks))
+	return orderbook
+}
+
+func newLevels(t *testing.T, symbol string, levels [][2]float64) []entity.PriceLevel {
+	t.Helper()
+
+	result := make([]entity.PriceLevel, len(levels))
+	for i, level := range levels {
+		price, err := valueobject.NewPrice(level[0], "USDT")
+		if err != nil {
+			t.Fatalf("failed to create price: %v", err)
+		}
+		quantity, err := valueobject.NewVolume(level[1], symbol)
+		if err != nil {
+			t.Fatalf("failed to create volume: %v", err)
+		}
+		result[i] = entity.PriceLevel{Price: *price, Quantity: *quantity}
+	}
+	return result
+}
+
+func tradeIDs(trades []*entity.Trade) []string {
+	ids := make([]string, len(trades))
+	for i, trade := range trades {
+		ids[i] = trade.ID()
+	}
+	return ids
+}
+
+func equalIDs(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func assertLevels(t *testing.T, side string, got []entity.PriceLevel, want [][2]float64) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %d levels, want %d", side, len(got), len(want))
+	}
+	for i, level := range got {
+		if level.Price.Value() != want[i][0] || level.Quantity.Value() != want[i][1] {
+			t.Fatalf("%s[%d]: got %v@%v, want %v@%v",
+				side, i, level.Quantity.Value(), level.Price.Value(), want[i][1], want[i][0])
+		}
+	}
+}
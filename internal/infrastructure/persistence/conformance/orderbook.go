@@ -0,0 +1,156 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"marketdata/internal/application/port/output"
+)
+
+// TestOrderBookRepository checks the behaviour every OrderBookRepositoryPort
+// implementation must share
+func TestOrderBookRepository(t *testing.T, newRepo func(t *testing.T) output.OrderBookRepositoryPort) {
+	ctx := context.Background()
+	bids := [][2]float64{{100, 1}, {99.5, 2}}
+	asks := [][2]float64{{100.5, 1.5}, {101, 3}}
+
+	t.Run("get returns the stored book", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.Store(ctx, newOrderBook(t, "binance", "BTC-USDT", base, bids, asks)); err != nil {
+			t.Fatalf("Store: %v", err)
+		}
+
+		got, err := repo.Get(ctx, "binance", "BTC-USDT")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if got == nil {
+			t.Fatal("Get returned nil for a stored book")
+		}
+		if got.ExchangeID() != "binance" || got.Symbol() != "BTC-USDT" {
+			t.Fatalf("got %s/%s, want binance/BTC-USDT", got.ExchangeID(), got.Symbol())
+		}
+		if !got.Timestamp().Equal(base) {
+			t.Fatalf("timestamp: got %s, want %s", got.Timestamp(), base)
+		}
+		assertLevels(t, "bids", got.Bids(), bids)
+		assertLevels(t, "asks", got.Asks(), asks)
+	})
+
+	t.Run("get returns nil for an unknown book", func(t *testing.T) {
+		repo := newRepo(t)
+		got, err := repo.Get(ctx, "binance", "ETH-USDT")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if got != nil {
+			t.Fatalf("Get returned %v for an unknown book", got)
+		}
+	})
+
+	t.Run("store replaces the previous book", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.Store(ctx, newOrderBook(t, "binance", "BTC-USDT", base, bids, asks)); err != nil {
+			t.Fatalf("Store: %v", err)
+		}
+		newer := [][2]float64{{102, 4}}
+		if err := repo.Store(ctx, newOrderBook(t, "binance", "BTC-USDT", base.Add(time.Second), newer, asks)); err != nil {
+			t.Fatalf("Store: %v", err)
+		}
+
+		got, err := repo.Get(ctx, "binance", "BTC-USDT")
+		if err != nil || got == nil {
+			t.Fatalf("Get: %v, %v", got, err)
+		}
+		assertLevels(t, "bids", got.Bids(), newer)
+	})
+
+	t.Run("get all returns every book", func(t *testing.T) {
+		repo := newRepo(t)
+		for _, exchangeID := range []string{"binance", "okx"} {
+			if err := repo.Store(ctx, newOrderBook(t, exchangeID, "BTC-USDT", base, bids, asks)); err != nil {
+				t.Fatalf("Store: %v", err)
+			}
+		}
+
+		all, err := repo.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		found := make(map[string]bool)
+		for _, orderbook := range all {
+			found[orderbook.ExchangeID()+"/"+orderbook.Symbol()] = true
+		}
+		if len(all) != 2 || !found["binance/BTC-USDT"] || !found["okx/BTC-USDT"] {
+			t.Fatalf("GetAll returned %v, want binance and okx BTC-USDT books", found)
+		}
+	})
+}
+
+// TestOrderBookSnapshotRepository checks the behaviour every
+// OrderBookSnapshotRepositoryPort implementation must share
+func TestOrderBookSnapshotRepository(t *testing.T, newRepo func(t *testing.T) output.OrderBookSnapshotRepositoryPort) {
+	ctx := context.Background()
+	bids := [][2]float64{{100, 1}, {99, 2}, {98, 3}}
+	asks := [][2]float64{{101, 1}, {102, 2}, {103, 3}}
+
+	t.Run("snapshot is truncated to depth", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.StoreSnapshot(ctx, newOrderBook(t, "binance", "BTC-USDT", base, bids, asks), 2); err != nil {
+			t.Fatalf("StoreSnapshot: %v", err)
+		}
+
+		got, err := repo.GetSnapshotAt(ctx, "binance", "BTC-USDT", base)
+		if err != nil || got == nil {
+			t.Fatalf("GetSnapshotAt: %v, %v", got, err)
+		}
+		assertLevels(t, "bids", got.Bids(), bids[:2])
+		assertLevels(t, "asks", got.Asks(), asks[:2])
+	})
+
+	t.Run("as of returns the latest snapshot at or before the time", func(t *testing.T) {
+		repo := newRepo(t)
+		for i, bid := range []float64{100, 200, 300} {
+			ts := base.Add(time.Duration(i) * time.Minute)
+			book := newOrderBook(t, "binance", "BTC-USDT", ts, [][2]float64{{bid, 1}}, asks)
+			if err := repo.StoreSnapshot(ctx, book, 10); err != nil {
+				t.Fatalf("StoreSnapshot: %v", err)
+			}
+		}
+
+		cases := []struct {
+			at   time.Time
+			want float64
+		}{
+			{base, 100},
+			{base.Add(90 * time.Second), 200},
+			{base.Add(2 * time.Minute), 300},
+			{base.Add(time.Hour), 300},
+		}
+		for _, c := range cases {
+			got, err := repo.GetSnapshotAt(ctx, "binance", "BTC-USDT", c.at)
+			if err != nil || got == nil {
+				t.Fatalf("GetSnapshotAt(%s): %v, %v", c.at, got, err)
+			}
+			if bid := got.Bids()[0].Price.Value(); bid != c.want {
+				t.Fatalf("GetSnapshotAt(%s): best bid %v, want %v", c.at, bid, c.want)
+			}
+		}
+	})
+
+	t.Run("as of before the first snapshot returns nil", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.StoreSnapshot(ctx, newOrderBook(t, "binance", "BTC-USDT", base, bids, asks), 10); err != nil {
+			t.Fatalf("StoreSnapshot: %v", err)
+		}
+
+		got, err := repo.GetSnapshotAt(ctx, "binance", "BTC-USDT", base.Add(-time.Second))
+		if err != nil {
+			t.Fatalf("GetSnapshotAt: %v", err)
+		}
+		if got != nil {
+			t.Fatalf("GetSnapshotAt returned a snapshot taken after the requested time")
+		}
+	})
+}
@@ -0,0 +1,219 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+)
+
+// TestTradeRepository checks the behaviour every TradeRepositoryPort
+// implementation must share. The repository must be synchronous: a stored
+// trade has to be visible to the next query.
+func TestTradeRepository(t *testing.T, newRepo func(t *testing.T) output.TradeRepositoryPort) {
+	ctx := context.Background()
+
+	// seed stores six BTC-USDT trades on binance one second apart, except t3
+	// and t4 which share a timestamp, plus one okx trade and one ETH trade
+	seed := func(t *testing.T, repo output.TradeRepositoryPort) {
+		t.Helper()
+		trades := []*entity.Trade{
+			newTrade(t, "t1", "binance", "BTC-USDT", 100, 1, entity.TradeBuy, base),
+			newTrade(t, "t2", "binance", "BTC-USDT", 101, 2, entity.TradeSell, base.Add(time.Second)),
+			newTrade(t, "t3", "binance", "BTC-USDT", 102, 1, entity.TradeBuy, base.Add(2*time.Second)),
+			newTrade(t, "t4", "binance", "BTC-USDT", 99, 3, entity.TradeSell, base.Add(2*time.Second)),
+			newTrade(t, "t5", "binance", "BTC-USDT", 103, 1, entity.TradeBuy, base.Add(3*time.Second)),
+			newTrade(t, "t6", "binance", "BTC-USDT", 104, 1, entity.TradeBuy, base.Add(4*time.Second)),
+			newTrade(t, "o1", "okx", "BTC-USDT", 100, 1, entity.TradeBuy, base.Add(time.Second)),
+			newTrade(t, "e1", "binance", "ETH-USDT", 10, 1, entity.TradeBuy, base),
+		}
+		for _, trade := range trades {
+			if err := repo.StoreTrade(ctx, trade); err != nil {
+				t.Fatalf("StoreTrade(%s): %v", trade.ID(), err)
+			}
+		}
+	}
+
+	query := func(t *testing.T, repo output.TradeRepositoryPort, filter output.TradeFilter) []string {
+		t.Helper()
+		trades, err := repo.QueryTrades(ctx, filter)
+		if err != nil {
+			t.Fatalf("QueryTrades: %v", err)
+		}
+		return tradeIDs(trades)
+	}
+
+	t.Run("duplicate trades are stored once", func(t *testing.T) {
+		repo := newRepo(t)
+		trade := newTrade(t, "t1", "binance", "BTC-USDT", 100, 1, entity.TradeBuy, base)
+		for i := 0; i < 2; i++ {
+			if err := repo.StoreTrade(ctx, trade); err != nil {
+				t.Fatalf("StoreTrade: %v", err)
+			}
+		}
+
+		got := query(t, repo, output.TradeFilter{Symbol: "BTC-USDT", Limit: 10})
+		if !equalIDs(got, []string{"t1"}) {
+			t.Fatalf("got %v, want [t1]", got)
+		}
+	})
+
+	t.Run("newest first with ties broken by id", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		got := query(t, repo, output.TradeFilter{ExchangeID: "binance", Symbol: "BTC-USDT", Limit: 10})
+		want := []string{"t6", "t5", "t4", "t3", "t2", "t1"}
+		if !equalIDs(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("ascending order", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		got := query(t, repo, output.TradeFilter{ExchangeID: "binance", Symbol: "BTC-USDT", Limit: 3, Ascending: true})
+		want := []string{"t1", "t2", "t3"}
+		if !equalIDs(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("filters by exchange, side and half-open time range", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		if got := query(t, repo, output.TradeFilter{ExchangeID: "okx", Symbol: "BTC-USDT", Limit: 10}); !equalIDs(got, []string{"o1"}) {
+			t.Fatalf("exchange filter: got %v, want [o1]", got)
+		}
+		if got := query(t, repo, output.TradeFilter{Symbol: "BTC-USDT", Limit: 10, Side: entity.TradeSell}); !equalIDs(got, []string{"t4", "t2"}) {
+			t.Fatalf("side filter: got %v, want [t4 t2]", got)
+		}
+
+		got := query(t, repo, output.TradeFilter{
+			ExchangeID: "binance",
+			Symbol:     "BTC-USDT",
+			From:       base.Add(time.Second),
+			To:         base.Add(3 * time.Second),
+			Limit:      10,
+		})
+		if want := []string{"t4", "t3", "t2"}; !equalIDs(got, want) {
+			t.Fatalf("time range: got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("keyset pages neither skip nor repeat", func(t *testing.T) {
+		for _, ascending := range []bool{false, true} {
+			repo := newRepo(t)
+			seed(t, repo)
+
+			filter := output.TradeFilter{ExchangeID: "binance", Symbol: "BTC-USDT", Limit: 2, Ascending: ascending}
+			var all []string
+			for page := 0; page < 10; page++ {
+				trades, err := repo.QueryTrades(ctx, filter)
+				if err != nil {
+					t.Fatalf("QueryTrades: %v", err)
+				}
+				all = append(all, tradeIDs(trades)...)
+				if len(trades) < filter.Limit {
+					break
+				}
+				last := trades[len(trades)-1]
+				filter.After = &output.TradeKey{Timestamp: last.Timestamp(), ExchangeID: last.ExchangeID(), ID: last.ID()}
+			}
+
+			want := []string{"t6", "t5", "t4", "t3", "t2", "t1"}
+			if ascending {
+				want = []string{"t1", "t2", "t3", "t4", "t5", "t6"}
+			}
+			if !equalIDs(all, want) {
+				t.Fatalf("ascending=%v: paged %v, want %v", ascending, all, want)
+			}
+		}
+	})
+
+	t.Run("keyset pages break ties across exchanges", func(t *testing.T) {
+		for _, ascending := range []bool{false, true} {
+			repo := newRepo(t)
+			// The same trade id at the same instant on three exchanges
+			for _, exchangeID := range []string{"okx", "binance", "kraken"} {
+				trade := newTrade(t, "42", exchangeID, "BTC-USDT", 100, 1, entity.TradeBuy, base)
+				if err := repo.StoreTrade(ctx, trade); err != nil {
+					t.Fatalf("StoreTrade(%s): %v", exchangeID, err)
+				}
+			}
+
+			filter := output.TradeFilter{Symbol: "BTC-USDT", Limit: 1, Ascending: ascending}
+			var all []string
+			for page := 0; page < 10; page++ {
+				trades, err := repo.QueryTrades(ctx, filter)
+				if err != nil {
+					t.Fatalf("QueryTrades: %v", err)
+				}
+				if len(trades) == 0 {
+					break
+				}
+				last := trades[len(trades)-1]
+				all = append(all, last.ExchangeID())
+				filter.After = &output.TradeKey{Timestamp: last.Timestamp(), ExchangeID: last.ExchangeID(), ID: last.ID()}
+			}
+
+			want := []string{"okx", "kraken", "binance"}
+			if ascending {
+				want = []string{"binance", "kraken", "okx"}
+			}
+			if !equalIDs(all, want) {
+				t.Fatalf("ascending=%v: paged exchanges %v, want %v", ascending, all, want)
+			}
+		}
+	})
+
+	t.Run("latest trades by symbol", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		trades, err := repo.GetTradesBySymbol(ctx, "ETH-USDT", 10)
+		if err != nil {
+			t.Fatalf("GetTradesBySymbol: %v", err)
+		}
+		if got := tradeIDs(trades); !equalIDs(got, []string{"e1"}) {
+			t.Fatalf("got %v, want [e1]", got)
+		}
+	})
+
+	t.Run("candles aggregate OHLCV per bucket", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		candles, err := repo.GetCandles(ctx, output.CandleFilter{
+			ExchangeID: "binance",
+			Symbol:     "BTC-USDT",
+			Interval:   2 * time.Second,
+			Limit:      10,
+		})
+		if err != nil {
+			t.Fatalf("GetCandles: %v", err)
+		}
+
+		// Buckets: [t1 t2], [t3 t4 t5], [t6]. t3 and t4 share a timestamp, so
+		// only the high, low and volume of the middle bucket are asserted.
+		if len(candles) != 3 {
+			t.Fatalf("got %d candles, want 3", len(candles))
+		}
+		first := candles[0]
+		if !first.OpenTime().Equal(base) || first.Open() != 100 || first.Close() != 101 ||
+			first.High() != 101 || first.Low() != 100 || first.Volume() != 3 || first.TradeCount() != 2 {
+			t.Fatalf("first candle: got %+v", first)
+		}
+		middle := candles[1]
+		if middle.High() != 103 || middle.Low() != 99 || middle.Volume() != 5 || middle.TradeCount() != 3 || middle.Close() != 103 {
+			t.Fatalf("middle candle: got %+v", middle)
+		}
+		if last := candles[2]; last.Open() != 104 || last.TradeCount() != 1 {
+			t.Fatalf("last candle: got %+v", last)
+		}
+	})
+}
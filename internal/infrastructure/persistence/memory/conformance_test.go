@@ -0,0 +1,27 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/infrastructure/persistence/conformance"
+)
+
+func TestOrderBookRepositoryConformance(t *testing.T) {
+	conformance.TestOrderBookRepository(t, func(t *testing.T) output.OrderBookRepositoryPort {
+		return NewOrderBookRepository(time.Hour)
+	})
+}
+
+func TestOrderBookSnapshotRepositoryConformance(t *testing.T) {
+	conformance.TestOrderBookSnapshotRepository(t, func(t *testing.T) output.OrderBookSnapshotRepositoryPort {
+		return NewOrderBookSnapshotRepository(0)
+	})
+}
+
+func TestTradeRepositoryConformance(t *testing.T) {
+	conformance.TestTradeRepository(t, func(t *testing.T) output.TradeRepositoryPort {
+		return NewTradeRepository(0)
+	})
+}
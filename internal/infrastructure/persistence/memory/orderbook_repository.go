@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"marketdata/internal/domain/entity"
+)
+
+type bookKey struct {
+	exchangeID string
+	symbol     string
+}
+
+type storedBook struct {
+	orderbook *entity.OrderBook
+	expiresAt time.Time
+}
+
+// OrderBookRepository keeps the latest orderbook per exchange/symbol in memory.
+// Like the Redis repository, a book expires ttl after it was stored; a zero ttl
+// keeps books forever.
+type OrderBookRepository struct {
+	mu    sync.RWMutex
+	books map[bookKey]storedBook
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+func NewOrderBookRepository(ttl time.Duration) *OrderBookRepository {
+	return &OrderBookRepository{
+		books: make(map[bookKey]storedBook),
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+func (r *OrderBookRepository) Store(ctx context.Context, orderbook *entity.OrderBook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := storedBook{orderbook: copyOrderBook(orderbook, 0)}
+	if r.ttl > 0 {
+		stored.expiresAt = r.now().Add(r.ttl)
+	}
+	r.books[bookKey{orderbook.ExchangeID(), orderbook.Symbol()}] = stored
+
+	return nil
+}
+
+// Get returns the stored book, or nil when it is missing or expired
+func (r *OrderBookRepository) Get(ctx context.Context, exchangeID, symbol string) (*entity.OrderBook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.books[bookKey{exchangeID, symbol}]
+	if !ok || r.expired(stored) {
+		return nil, nil
+	}
+	return copyOrderBook(stored.orderbook, 0), nil
+}
+
+// GetAll returns every book that has not expired, ordered by exchange and symbol
+func (r *OrderBookRepository) GetAll(ctx context.Context) ([]*entity.OrderBook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	orderbooks := make([]*entity.OrderBook, 0, len(r.books))
+	for key, stored := range r.books {
+		if r.expired(stored) {
+			delete(r.books, key)
+			continue
+		}
+		orderbooks = append(orderbooks, copyOrderBook(stored.orderbook, 0))
+	}
+
+	sort.Slice(orderbooks, func(i, j int) bool {
+		if orderbooks[i].ExchangeID() != orderbooks[j].ExchangeID() {
+			return orderbooks[i].ExchangeID() < orderbooks[j].ExchangeID()
+		}
+		return orderbooks[i].Symbol() < orderbooks[j].Symbol()
+	})
+
+	return orderbooks, nil
+}
+
+func (r *OrderBookRepository) expired(stored storedBook) bool {
+	return !stored.expiresAt.IsZero() && !r.now().Before(stored.expiresAt)
+}
+
+// copyOrderBook copies the book so callers cannot mutate stored levels. A
+// positive depth keeps only the best depth levels of each side.
+func copyOrderBook(orderbook *entity.OrderBook, depth int) *entity.OrderBook {
+	copied := entity.NewOrderBook(orderbook.ExchangeID(), orderbook.Symbol(), orderbook.Timestamp())
+	copied.UpdateBids(copyLevels(orderbook.Bids(), depth))
+	copied.UpdateAsks(copyLevels(orderbook.Asks(), depth))
+	return copied
+}
+
+func copyLevels(levels []entity.PriceLevel, depth int) []entity.PriceLevel {
+	if depth > 0 && len(levels) > depth {
+		levels = levels[:depth]
+	}
+	return append([]entity.PriceLevel(nil), levels...)
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"marketdata/internal/domain/entity"
+)
+
+// OrderBookSnapshotRepository keeps archived orderbook snapshots in memory,
+// at most maxPerBook per exchange/symbol, dropping the oldest first
+type OrderBookSnapshotRepository struct {
+	mu         sync.RWMutex
+	snapshots  map[bookKey][]*entity.OrderBook
+	maxPerBook int
+}
+
+func NewOrderBookSnapshotRepository(maxPerBook int) *OrderBookSnapshotRepository {
+	return &OrderBookSnapshotRepository{
+		snapshots:  make(map[bookKey][]*entity.OrderBook),
+		maxPerBook: maxPerBook,
+	}
+}
+
+// StoreSnapshot stores the top depth levels of each side. A second snapshot of
+// the same book at the same timestamp is ignored.
+func (r *OrderBookSnapshotRepository) StoreSnapshot(ctx context.Context, orderbook *entity.OrderBook, depth int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := bookKey{orderbook.ExchangeID(), orderbook.Symbol()}
+	snapshots := r.snapshots[key]
+
+	i := sort.Search(len(snapshots), func(i int) bool {
+		return !snapshots[i].Timestamp().Before(orderbook.Timestamp())
+	})
+	if i < len(snapshots) && snapshots[i].Timestamp().Equal(orderbook.Timestamp()) {
+		return nil
+	}
+
+	snapshots = append(snapshots, nil)
+	copy(snapshots[i+1:], snapshots[i:])
+	snapshots[i] = copyOrderBook(orderbook, depth)
+
+	if r.maxPerBook > 0 && len(snapshots) > r.maxPerBook {
+		snapshots = snapshots[len(snapshots)-r.maxPerBook:]
+	}
+	r.snapshots[key] = snapshots
+
+	return nil
+}
+
+// GetSnapshotAt returns the latest snapshot taken at or before at, or nil if none
+func (r *OrderBookSnapshotRepository) GetSnapshotAt(ctx context.Context, exchangeID, symbol string, at time.Time) (*entity.OrderBook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshots := r.snapshots[bookKey{exchangeID, symbol}]
+	i := sort.Search(len(snapshots), func(i int) bool {
+		return snapshots[i].Timestamp().After(at)
+	})
+	if i == 0 {
+		return nil, nil
+	}
+
+	return copyOrderBook(snapshots[i-1], 0), nil
+}
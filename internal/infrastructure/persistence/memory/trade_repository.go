@@ -0,0 +1,292 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+)
+
+// TradeRepository keeps trades in memory ordered by (timestamp, exchange_id, id), keeping at
+// most maxTrades and dropping the oldest first. It answers the same queries as
+// the TimescaleDB repository, including candles and trade statistics.
+type TradeRepository struct {
+	mu        sync.RWMutex
+	trades    []*entity.Trade
+	seen      map[tradeKey]struct{}
+	maxTrades int
+}
+
+// tradeKey mirrors the unique (exchange_id, id, timestamp) index in TimescaleDB
+type tradeKey struct {
+	exchangeID string
+	id         string
+	timestamp  int64
+}
+
+func NewTradeRepository(maxTrades int) *TradeRepository {
+	return &TradeRepository{
+		seen:      make(map[tradeKey]struct{}),
+		maxTrades: maxTrades,
+	}
+}
+
+// StoreTrade stores the trade unless the same exchange already reported a trade
+// with that id and timestamp
+func (r *TradeRepository) StoreTrade(ctx context.Context, trade *entity.Trade) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := keyOf(trade)
+	if _, ok := r.seen[key]; ok {
+		return nil
+	}
+	r.seen[key] = struct{}{}
+
+	i := sort.Search(len(r.trades), func(i int) bool {
+		return !tradeBefore(r.trades[i], trade)
+	})
+	r.trades = append(r.trades, nil)
+	copy(r.trades[i+1:], r.trades[i:])
+	r.trades[i] = trade
+
+	if r.maxTrades > 0 && len(r.trades) > r.maxTrades {
+		evicted := len(r.trades) - r.maxTrades
+		for i, old := range r.trades[:evicted] {
+			delete(r.seen, keyOf(old))
+			r.trades[i] = nil
+		}
+		// Reslicing is O(1); append reallocates and drops the evicted prefix
+		// once capacity runs out
+		r.trades = r.trades[evicted:]
+	}
+
+	return nil
+}
+
+func (r *TradeRepository) GetTradesBySymbol(ctx context.Context, symbol string, limit int) ([]*entity.Trade, error) {
+	return r.QueryTrades(ctx, output.TradeFilter{
+		Symbol: symbol,
+		Limit:  limit,
+	})
+}
+
+// QueryTrades returns trades in (timestamp, exchange_id, id) order, starting strictly after
+// filter.After
+func (r *TradeRepository) QueryTrades(ctx context.Context, filter output.TradeFilter) ([]*entity.Trade, error) {
+	if filter.Limit <= 0 {
+		return nil, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var trades []*entity.Trade
+	visit := func(trade *entity.Trade) bool {
+		if !matches(trade, filter.ExchangeID, filter.Symbol, filter.From, filter.To) {
+			return true
+		}
+		if filter.Side != "" && trade.Type() != filter.Side {
+			return true
+		}
+		if filter.After != nil && !pastKey(trade, filter.After, filter.Ascending) {
+			return true
+		}
+		trades = append(trades, trade)
+		return len(trades) < filter.Limit
+	}
+
+	if filter.Ascending {
+		for _, trade := range r.trades {
+			if !visit(trade) {
+				break
+			}
+		}
+	} else {
+		for i := len(r.trades) - 1; i >= 0; i-- {
+			if !visit(r.trades[i]) {
+				break
+			}
+		}
+	}
+
+	return trades, nil
+}
+
+// GetCandles aggregates trades into the latest filter.Limit buckets, oldest first
+func (r *TradeRepository) GetCandles(ctx context.Context, filter output.CandleFilter) ([]*entity.Candle, error) {
+	if filter.Interval <= 0 {
+		return nil, fmt.Errorf("candle interval must be positive")
+	}
+
+	buckets := r.aggregate(filter.ExchangeID, filter.Symbol, filter.Interval, filter.From, filter.To, filter.Limit)
+
+	candles := make([]*entity.Candle, len(buckets))
+	for i, b := range buckets {
+		candles[i] = entity.NewCandle(
+			b.exchangeID, b.symbol, filter.Interval, b.start,
+			b.open, b.high, b.low, b.close, b.volume, b.tradeCount,
+		)
+	}
+
+	return candles, nil
+}
+
+// GetTradeStats aggregates trades into statistics buckets, so the in-memory
+// repository also serves the analytics port
+func (r *TradeRepository) GetTradeStats(ctx context.Context, filter output.TradeStatsFilter) ([]*entity.TradeStats, error) {
+	if filter.Interval <= 0 {
+		return nil, fmt.Errorf("stats interval must be positive")
+	}
+
+	buckets := r.aggregate(filter.ExchangeID, filter.Symbol, filter.Interval, filter.From, filter.To, filter.Limit)
+
+	stats := make([]*entity.TradeStats, len(buckets))
+	for i, b := range buckets {
+		stats[i] = entity.NewTradeStats(
+			b.exchangeID, b.symbol, filter.Interval, b.start,
+			b.open, b.high, b.low, b.close,
+			b.volume, b.quoteVolume, b.buyVolume, b.sellVolume,
+			b.tradeCount, b.buyCount, b.sellCount,
+		)
+	}
+
+	return stats, nil
+}
+
+type bucket struct {
+	exchangeID  string
+	symbol      string
+	start       time.Time
+	open        float64
+	high        float64
+	low         float64
+	close       float64
+	volume      float64
+	quoteVolume float64
+	buyVolume   float64
+	sellVolume  float64
+	tradeCount  int64
+	buyCount    int64
+	sellCount   int64
+}
+
+type bucketKey struct {
+	exchangeID string
+	symbol     string
+	start      int64
+}
+
+// aggregate groups matching trades into interval-wide buckets and returns the
+// latest limit buckets oldest first. Buckets of intervals that divide a day
+// line up with time_bucket's.
+func (r *TradeRepository) aggregate(exchangeID, symbol string, interval time.Duration, from, to time.Time, limit int) []*bucket {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	index := make(map[bucketKey]*bucket)
+	var buckets []*bucket
+	for _, trade := range r.trades {
+		if !matches(trade, exchangeID, symbol, from, to) {
+			continue
+		}
+
+		start := trade.Timestamp().Truncate(interval).UTC()
+		key := bucketKey{trade.ExchangeID(), trade.Symbol(), start.UnixNano()}
+		price := trade.Price().Value()
+		volume := trade.Volume().Value()
+
+		// Trades are visited in time order, so the first trade opens the bucket
+		// and the last one closes it
+		b, ok := index[key]
+		if !ok {
+			b = &bucket{
+				exchangeID: trade.ExchangeID(),
+				symbol:     trade.Symbol(),
+				start:      start,
+				open:       price,
+				high:       price,
+				low:        price,
+			}
+			index[key] = b
+			buckets = append(buckets, b)
+		}
+
+		b.high = max(b.high, price)
+		b.low = min(b.low, price)
+		b.close = price
+		b.volume += volume
+		b.quoteVolume += price * volume
+		b.tradeCount++
+		switch trade.Type() {
+		case entity.TradeBuy:
+			b.buyVolume += volume
+			b.buyCount++
+		case entity.TradeSell:
+			b.sellVolume += volume
+			b.sellCount++
+		}
+	}
+
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return buckets[i].start.Before(buckets[j].start)
+	})
+	if limit > 0 && len(buckets) > limit {
+		buckets = buckets[len(buckets)-limit:]
+	}
+
+	return buckets
+}
+
+func matches(trade *entity.Trade, exchangeID, symbol string, from, to time.Time) bool {
+	if exchangeID != "" && trade.ExchangeID() != exchangeID {
+		return false
+	}
+	if symbol != "" && trade.Symbol() != symbol {
+		return false
+	}
+	if !from.IsZero() && trade.Timestamp().Before(from) {
+		return false
+	}
+	if !to.IsZero() && !trade.Timestamp().Before(to) {
+		return false
+	}
+	return true
+}
+
+// tradeBefore orders trades by (timestamp, exchange_id, id)
+func tradeBefore(a, b *entity.Trade) bool {
+	if !a.Timestamp().Equal(b.Timestamp()) {
+		return a.Timestamp().Before(b.Timestamp())
+	}
+	if a.ExchangeID() != b.ExchangeID() {
+		return a.ExchangeID() < b.ExchangeID()
+	}
+	return a.ID() < b.ID()
+}
+
+// pastKey reports whether trade comes strictly after key in the query order
+func pastKey(trade *entity.Trade, key *output.TradeKey, ascending bool) bool {
+	if !trade.Timestamp().Equal(key.Timestamp) {
+		return trade.Timestamp().After(key.Timestamp) == ascending
+	}
+	if trade.ExchangeID() != key.ExchangeID {
+		return (trade.ExchangeID() > key.ExchangeID) == ascending
+	}
+	if trade.ID() == key.ID {
+		return false
+	}
+	return (trade.ID() > key.ID) == ascending
+}
+
+func keyOf(trade *entity.Trade) tradeKey {
+	return tradeKey{
+		exchangeID: trade.ExchangeID(),
+		id:         trade.ID(),
+		timestamp:  trade.Timestamp().UnixNano(),
+	}
+}
@@ -9,8 +9,26 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	"marketdata/internal/domain/entity"
+	"marketdata/internal/domain/valueobject"
 )
 
+// storedOrderBook is the JSON form of an orderbook in Redis. The entity keeps
+// its fields unexported, so it cannot be marshalled directly.
+type storedOrderBook struct {
+	ExchangeID string        `json:"exchange_id"`
+	Symbol     string        `json:"symbol"`
+	Bids       []storedLevel `json:"bids"`
+	Asks       []storedLevel `json:"asks"`
+	Timestamp  time.Time     `json:"timestamp"`
+}
+
+type storedLevel struct {
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency"`
+	Quantity float64 `json:"quantity"`
+	Asset    string  `json:"asset"`
+}
+
 type OrderBookRepository struct {
 	client *redis.Client
 	ttl    time.Duration
@@ -26,7 +44,7 @@ func NewOrderBookRepository(client *redis.Client, ttl time.Duration) *OrderBookR
 func (r *OrderBookRepository) Store(ctx context.Context, orderbook *entity.OrderBook) error {
 	key := r.makeKey(orderbook.ExchangeID(), orderbook.Symbol())
 
-	data, err := json.Marshal(orderbook)
+	data, err := encodeOrderBook(orderbook)
 	if err != nil {
 		return fmt.Errorf("failed to marshal orderbook: %w", err)
 	}
@@ -49,14 +67,110 @@ func (r *OrderBookRepository) Get(ctx context.Context, exchangeID, symbol string
 		return nil, fmt.Errorf("failed to get orderbook from redis: %w", err)
 	}
 
-	var orderbook entity.OrderBook
-	if err := json.Unmarshal(data, &orderbook); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal orderbook: %w", err)
+	return decodeOrderBook(data)
+}
+
+// GetAll returns every orderbook that has not expired
+func (r *OrderBookRepository) GetAll(ctx context.Context) ([]*entity.OrderBook, error) {
+	var orderbooks []*entity.OrderBook
+
+	iter := r.client.Scan(ctx, 0, "orderbook:*", 100).Iterator()
+	for iter.Next(ctx) {
+		data, err := r.client.Get(ctx, iter.Val()).Bytes()
+		if err != nil {
+			if err == redis.Nil {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get orderbook from redis: %w", err)
+		}
+
+		orderbook, err := decodeOrderBook(data)
+		if err != nil {
+			return nil, err
+		}
+		orderbooks = append(orderbooks, orderbook)
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan orderbooks in redis: %w", err)
 	}
 
-	return &orderbook, nil
+	return orderbooks, nil
+}
+
+// Name identifies Redis in readiness reports
+func (r *OrderBookRepository) Name() string {
+	return "redis"
+}
+
+// Check pings Redis
+func (r *OrderBookRepository) Check(ctx context.Context) error {
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to ping redis: %w", err)
+	}
+	return nil
 }
 
 func (r *OrderBookRepository) makeKey(exchangeID, symbol string) string {
 	return fmt.Sprintf("orderbook:%s:%s", exchangeID, symbol)
 }
+
+func encodeOrderBook(orderbook *entity.OrderBook) ([]byte, error) {
+	return json.Marshal(storedOrderBook{
+		ExchangeID: orderbook.ExchangeID(),
+		Symbol:     orderbook.Symbol(),
+		Bids:       encodeLevels(orderbook.Bids()),
+		Asks:       encodeLevels(orderbook.Asks()),
+		Timestamp:  orderbook.Timestamp(),
+	})
+}
+
+func encodeLevels(levels []entity.PriceLevel) []storedLevel {
+	encoded := make([]storedLevel, len(levels))
+	for i, level := range levels {
+		encoded[i] = storedLevel{
+			Price:    level.Price.Value(),
+			Currency: level.Price.Currency(),
+			Quantity: level.Quantity.Value(),
+			Asset:    level.Quantity.Asset(),
+		}
+	}
+	return encoded
+}
+
+func decodeOrderBook(data []byte) (*entity.OrderBook, error) {
+	var stored storedOrderBook
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal orderbook: %w", err)
+	}
+
+	bids, err := decodeLevels(stored.Bids)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := decodeLevels(stored.Asks)
+	if err != nil {
+		return nil, err
+	}
+
+	orderbook := entity.NewOrderBook(stored.ExchangeID, stored.Symbol, stored.Timestamp)
+	orderbook.UpdateBids(bids)
+	orderbook.UpdateAsks(asks)
+	return orderbook, nil
+}
+
+func decodeLevels(encoded []storedLevel) ([]entity.PriceLevel, error) {
+	levels := make([]entity.PriceLevel, len(encoded))
+	for i, level := range encoded {
+		price, err := valueobject.NewPrice(level.Price, level.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create price value object: %w", err)
+		}
+		quantity, err := valueobject.NewVolume(level.Quantity, level.Asset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create volume value object: %w", err)
+		}
+		levels[i] = entity.PriceLevel{Price: *price, Quantity: *quantity}
+	}
+	return levels, nil
+}
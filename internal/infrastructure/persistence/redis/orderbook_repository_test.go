@@ -0,0 +1,40 @@
+package redis
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/infrastructure/persistence/conformance"
+)
+
+// redisAddrEnv names the Redis server the conformance suite runs against.
+// Its orderbook keys are deleted before every subtest.
+const redisAddrEnv = "MARKETDATA_TEST_REDIS_ADDR"
+
+func TestOrderBookRepositoryConformance(t *testing.T) {
+	addr := os.Getenv(redisAddrEnv)
+	if addr == "" {
+		t.Skipf("%s is not set", redisAddrEnv)
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { client.Close() })
+
+	conformance.TestOrderBookRepository(t, func(t *testing.T) output.OrderBookRepositoryPort {
+		ctx := context.Background()
+		iter := client.Scan(ctx, 0, "orderbook:*", 100).Iterator()
+		for iter.Next(ctx) {
+			if err := client.Del(ctx, iter.Val()).Err(); err != nil {
+				t.Fatalf("failed to clear orderbooks: %v", err)
+			}
+		}
+		if err := iter.Err(); err != nil {
+			t.Fatalf("failed to clear orderbooks: %v", err)
+		}
+		return NewOrderBookRepository(client, time.Hour)
+	})
+}
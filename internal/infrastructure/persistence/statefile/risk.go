@@ -0,0 +1,49 @@
+package statefile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/output"
+)
+
+var _ output.RiskStatePort = (*RiskStore)(nil)
+
+// RiskStore keeps the risk manager state in a JSON file
+type RiskStore struct {
+	path string
+}
+
+func NewRiskStore(path string) *RiskStore {
+	return &RiskStore{path: path}
+}
+
+// LoadRiskState returns the saved state; a missing file has none
+func (s *RiskStore) LoadRiskState(ctx context.Context) (*dto.RiskStateDTO, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var state dto.RiskStateDTO
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", s.path, err)
+	}
+	return &state, nil
+}
+
+func (s *RiskStore) SaveRiskState(ctx context.Context, state *dto.RiskStateDTO) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	return writeFile(s.path, data)
+}
@@ -0,0 +1,50 @@
+package statefile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/output"
+)
+
+var _ output.StatArbStatePort = (*StatArbStore)(nil)
+
+// StatArbStore keeps the statistical arbitrage state in a JSON file, which is
+// replaced as a whole on every save so a crash never leaves it half written
+type StatArbStore struct {
+	path string
+}
+
+func NewStatArbStore(path string) *StatArbStore {
+	return &StatArbStore{path: path}
+}
+
+// LoadStatArbState returns the saved pairs; a missing file has none
+func (s *StatArbStore) LoadStatArbState(ctx context.Context) ([]*dto.StatArbStateDTO, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var pairs []*dto.StatArbStateDTO
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", s.path, err)
+	}
+	return pairs, nil
+}
+
+func (s *StatArbStore) SaveStatArbState(ctx context.Context, pairs []*dto.StatArbStateDTO) error {
+	data, err := json.Marshal(pairs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	return writeFile(s.path, data)
+}
@@ -0,0 +1,91 @@
+package timescale
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/domain/entity"
+)
+
+// tradeStatsViews maps each supported bucket width to its continuous aggregate
+var tradeStatsViews = map[time.Duration]string{
+	time.Minute:    "trade_stats_1m",
+	time.Hour:      "trade_stats_1h",
+	24 * time.Hour: "trade_stats_1d",
+}
+
+// AnalyticsRepository reads the trade_stats_* continuous aggregates
+type AnalyticsRepository struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepository(db *sql.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{
+		db: db,
+	}
+}
+
+// GetTradeStats returns the latest filter.Limit buckets in the range, oldest first
+func (r *AnalyticsRepository) GetTradeStats(ctx context.Context, filter output.TradeStatsFilter) ([]*entity.TradeStats, error) {
+	view, ok := tradeStatsViews[filter.Interval]
+	if !ok {
+		return nil, fmt.Errorf("no trade statistics aggregated at %s", filter.Interval)
+	}
+
+	conditions, args := rangeConditions("bucket", filter.ExchangeID, filter.Symbol, filter.From, filter.To)
+
+	query := `
+		SELECT exchange_id, symbol, bucket, open, high, low, close,
+			volume, quote_volume, buy_volume, sell_volume,
+			trade_count, buy_count, sell_count
+		FROM (
+			SELECT *
+			FROM ` + view + where(conditions) + `
+			ORDER BY bucket DESC
+			LIMIT ` + placeholder(&args, filter.Limit) + `
+		) stats
+		ORDER BY bucket ASC, exchange_id, symbol
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trade stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*entity.TradeStats
+	for rows.Next() {
+		var (
+			exchangeID                                 string
+			symbol                                     string
+			bucket                                     time.Time
+			open, high, low, close                     float64
+			volume, quoteVolume, buyVolume, sellVolume float64
+			tradeCount, buyCount, sellCount            int64
+		)
+
+		if err := rows.Scan(
+			&exchangeID, &symbol, &bucket, &open, &high, &low, &close,
+			&volume, &quoteVolume, &buyVolume, &sellVolume,
+			&tradeCount, &buyCount, &sellCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan trade stats row: %w", err)
+		}
+
+		stats = append(stats, entity.NewTradeStats(
+			exchangeID, symbol, filter.Interval, bucket,
+			open, high, low, close,
+			volume, quoteVolume, buyVolume, sellVolume,
+			tradeCount, buyCount, sellCount,
+		))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trade stats rows: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,199 @@
+package timescale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"marketdata/internal/domain/entity"
+	"marketdata/pkg/logger"
+	"marketdata/pkg/metrics"
+)
+
+var ErrWriterClosed = errors.New("trade writer is closed")
+
+const (
+	defaultBatchSize     = 1000
+	defaultFlushInterval = 250 * time.Millisecond
+	defaultMaxPending    = 50000
+	flushRetries         = 3
+	flushRetryBackoff    = 200 * time.Millisecond
+	flushTimeout         = 10 * time.Second
+)
+
+// BatchConfig controls when buffered trades are flushed
+type BatchConfig struct {
+	// BatchSize flushes as soon as this many trades are buffered
+	BatchSize int
+	// FlushInterval flushes whatever is buffered at least this often
+	FlushInterval time.Duration
+	// MaxPending bounds the buffer; StoreTrade blocks while it is full
+	MaxPending int
+	// DeadLetter, when set, keeps batches that fail every retry instead of
+	// dropping them
+	DeadLetter *DeadLetterQueue
+}
+
+// BatchTradeWriter buffers trades and writes them to TimescaleDB in batches.
+// It embeds TradeRepository so reads go straight to the database, while
+// StoreTrade only enqueues: a nil error means the trade was accepted, not that
+// it is durable. Call Close to flush the buffer on shutdown.
+type BatchTradeWriter struct {
+	*TradeRepository
+
+	cfg     BatchConfig
+	input   chan *entity.Trade
+	closing chan struct{}
+	done    chan struct{}
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+
+	mu        sync.RWMutex
+	closeOnce sync.Once
+}
+
+func NewBatchTradeWriter(repo *TradeRepository, cfg BatchConfig, log *logger.Logger, m *metrics.Metrics) *BatchTradeWriter {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	if cfg.MaxPending < cfg.BatchSize {
+		cfg.MaxPending = max(defaultMaxPending, cfg.BatchSize)
+	}
+
+	w := &BatchTradeWriter{
+		TradeRepository: repo,
+		cfg:             cfg,
+		input:           make(chan *entity.Trade, cfg.MaxPending),
+		closing:         make(chan struct{}),
+		done:            make(chan struct{}),
+		logger:          log,
+		metrics:         m,
+	}
+
+	go w.run()
+
+	return w
+}
+
+// StoreTrade enqueues a trade, blocking while the buffer is full until space
+// frees up, ctx is done or the writer closes
+func (w *BatchTradeWriter) StoreTrade(ctx context.Context, trade *entity.Trade) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	select {
+	case <-w.closing:
+		return ErrWriterClosed
+	default:
+	}
+
+	select {
+	case w.input <- trade:
+		w.metrics.SetTradeWriterPending(float64(len(w.input)))
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to enqueue trade: %w", ctx.Err())
+	case <-w.closing:
+		return ErrWriterClosed
+	}
+}
+
+// Close stops accepting trades and flushes everything buffered, waiting until
+// the flush completes or ctx is done
+func (w *BatchTradeWriter) Close(ctx context.Context) error {
+	w.closeOnce.Do(func() {
+		close(w.closing)
+
+		// Wait for in-flight StoreTrade calls before closing the input
+		w.mu.Lock()
+		close(w.input)
+		w.mu.Unlock()
+	})
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("trade writer did not flush before shutdown: %w", ctx.Err())
+	}
+}
+
+func (w *BatchTradeWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*entity.Trade, 0, w.cfg.BatchSize)
+	for {
+		select {
+		case trade, ok := <-w.input:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, trade)
+			if len(batch) >= w.cfg.BatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// flush writes one batch, retrying transient failures. Retrying is safe
+// because StoreTrades ignores trades that were already written.
+func (w *BatchTradeWriter) flush(batch []*entity.Trade) {
+	if len(batch) == 0 {
+		return
+	}
+
+	var err error
+	for attempt := 0; attempt < flushRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(flushRetryBackoff * time.Duration(attempt))
+		}
+
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+		err = w.StoreTrades(ctx, batch)
+		cancel()
+
+		w.metrics.RecordTradeFlush(len(batch), time.Since(start).Seconds(), err)
+		if err == nil {
+			break
+		}
+	}
+	w.metrics.SetTradeWriterPending(float64(len(w.input)))
+
+	if err == nil {
+		return
+	}
+
+	if w.cfg.DeadLetter != nil {
+		dlqErr := w.cfg.DeadLetter.Write(batch)
+		if dlqErr == nil {
+			w.logger.Error("moved trade batch to dead letter queue after retries",
+				"error", err,
+				"trades", len(batch),
+			)
+			return
+		}
+		w.logger.Error("failed to write dead letter batch", "error", dlqErr)
+	}
+
+	w.logger.Error("dropping trade batch after retries",
+		"error", err,
+		"trades", len(batch),
+	)
+}
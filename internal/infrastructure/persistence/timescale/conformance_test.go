@@ -0,0 +1,64 @@
+package timescale
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"marketdata/internal/application/port/output"
+	"marketdata/internal/infrastructure/persistence/conformance"
+)
+
+// timescaleDSNEnv names the database the conformance suite runs against. It
+// is migrated, and its trades and snapshots are truncated before every
+// subtest.
+const timescaleDSNEnv = "MARKETDATA_TEST_TIMESCALE_DSN"
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(timescaleDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", timescaleDSNEnv)
+	}
+	ctx := context.Background()
+	db, err := Open(ctx, dsn, 0)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func truncate(t *testing.T, db *sql.DB, table string) {
+	t.Helper()
+
+	if _, err := db.ExecContext(context.Background(), "TRUNCATE "+table); err != nil {
+		t.Fatalf("failed to truncate %s: %v", table, err)
+	}
+}
+
+func TestTradeRepositoryConformance(t *testing.T) {
+	db := openTestDB(t)
+	conformance.TestTradeRepository(t, func(t *testing.T) output.TradeRepositoryPort {
+		truncate(t, db, "trades")
+		return NewTradeRepository(db)
+	})
+}
+
+func TestOrderBookSnapshotRepositoryConformance(t *testing.T) {
+	db := openTestDB(t)
+	conformance.TestOrderBookSnapshotRepository(t, func(t *testing.T) output.OrderBookSnapshotRepositoryPort {
+		truncate(t, db, "orderbook_snapshots")
+		return NewOrderBookSnapshotRepository(db)
+	})
+}
@@ -0,0 +1,164 @@
+package timescale
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"marketdata/internal/domain/entity"
+	"marketdata/internal/domain/valueobject"
+)
+
+const deadLetterExtension = ".jsonl"
+
+// DeadLetterQueue keeps trade batches that could not be written, one JSON
+// lines file per batch, so they can be replayed once the database recovers
+type DeadLetterQueue struct {
+	dir string
+}
+
+type deadLetterTrade struct {
+	ID         string    `json:"id"`
+	ExchangeID string    `json:"exchange_id"`
+	Symbol     string    `json:"symbol"`
+	Price      float64   `json:"price"`
+	Currency   string    `json:"currency"`
+	Volume     float64   `json:"volume"`
+	Type       string    `json:"type"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+func NewDeadLetterQueue(dir string) (*DeadLetterQueue, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create dead letter directory: %w", err)
+	}
+	return &DeadLetterQueue{dir: dir}, nil
+}
+
+// Write stores a batch. The file is renamed into place once complete, so a
+// crash never leaves a partial batch behind.
+func (q *DeadLetterQueue) Write(trades []*entity.Trade) error {
+	name := fmt.Sprintf("trades-%s%s", time.Now().UTC().Format("20060102T150405.000000000Z"), deadLetterExtension)
+	tmp, err := os.CreateTemp(q.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create dead letter file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, trade := range trades {
+		record := deadLetterTrade{
+			ID:         trade.ID(),
+			ExchangeID: trade.ExchangeID(),
+			Symbol:     trade.Symbol(),
+			Price:      trade.Price().Value(),
+			Currency:   trade.Price().Currency(),
+			Volume:     trade.Volume().Value(),
+			Type:       string(trade.Type()),
+			Timestamp:  trade.Timestamp(),
+		}
+		if err := encoder.Encode(record); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to encode dead letter trade: %w", err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write dead letter file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close dead letter file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(q.dir, name)); err != nil {
+		return fmt.Errorf("failed to commit dead letter file: %w", err)
+	}
+	return nil
+}
+
+// Files lists the queued batches, oldest first
+func (q *DeadLetterQueue) Files() ([]string, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letter directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), deadLetterExtension) {
+			files = append(files, filepath.Join(q.dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Replay passes every queued batch to store, oldest first, removing each
+// file once it was stored. It stops at the first failure, leaving that
+// batch and later ones queued, and returns the number of trades replayed.
+func (q *DeadLetterQueue) Replay(ctx context.Context, store func(ctx context.Context, trades []*entity.Trade) error) (int, error) {
+	files, err := q.Files()
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, path := range files {
+		trades, err := readDeadLetterFile(path)
+		if err != nil {
+			return replayed, err
+		}
+		if err := store(ctx, trades); err != nil {
+			return replayed, fmt.Errorf("failed to replay %s: %w", filepath.Base(path), err)
+		}
+		if err := os.Remove(path); err != nil {
+			return replayed, fmt.Errorf("failed to remove replayed dead letter file: %w", err)
+		}
+		replayed += len(trades)
+	}
+	return replayed, nil
+}
+
+func readDeadLetterFile(path string) ([]*entity.Trade, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead letter file: %w", err)
+	}
+	defer file.Close()
+
+	var trades []*entity.Trade
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		var record deadLetterTrade
+		if err := decoder.Decode(&record); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", filepath.Base(path), err)
+		}
+
+		price, err := valueobject.NewPrice(record.Price, record.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create price value object: %w", err)
+		}
+		volume, err := valueobject.NewVolume(record.Volume, record.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create volume value object: %w", err)
+		}
+
+		trades = append(trades, entity.NewTrade(
+			record.ID,
+			record.ExchangeID,
+			record.Symbol,
+			*price,
+			*volume,
+			entity.TradeType(record.Type),
+			record.Timestamp,
+		))
+	}
+	return trades, nil
+}
@@ -0,0 +1,23 @@
+CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+CREATE TABLE IF NOT EXISTS trades (
+    id          TEXT             NOT NULL,
+    exchange_id TEXT             NOT NULL,
+    symbol      TEXT             NOT NULL,
+    price       DOUBLE PRECISION NOT NULL,
+    volume      DOUBLE PRECISION NOT NULL,
+    trade_type  TEXT             NOT NULL,
+    timestamp   TIMESTAMPTZ      NOT NULL
+);
+
+SELECT create_hypertable('trades', 'timestamp', chunk_time_interval => INTERVAL '1 day', if_not_exists => TRUE);
+
+-- Unique indexes on a hypertable must include the partitioning column
+CREATE UNIQUE INDEX IF NOT EXISTS trades_exchange_id_id_timestamp_idx
+    ON trades (exchange_id, id, timestamp);
+
+CREATE INDEX IF NOT EXISTS trades_exchange_id_symbol_timestamp_idx
+    ON trades (exchange_id, symbol, timestamp DESC);
+
+CREATE INDEX IF NOT EXISTS trades_symbol_timestamp_idx
+    ON trades (symbol, timestamp DESC);
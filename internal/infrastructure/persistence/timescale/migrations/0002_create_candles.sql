@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS candles (
+    exchange_id TEXT             NOT NULL,
+    symbol      TEXT             NOT NULL,
+    interval    INTERVAL         NOT NULL,
+    open_time   TIMESTAMPTZ      NOT NULL,
+    open        DOUBLE PRECISION NOT NULL,
+    high        DOUBLE PRECISION NOT NULL,
+    low         DOUBLE PRECISION NOT NULL,
+    close       DOUBLE PRECISION NOT NULL,
+    volume      DOUBLE PRECISION NOT NULL,
+    trade_count BIGINT           NOT NULL DEFAULT 0,
+    PRIMARY KEY (exchange_id, symbol, interval, open_time)
+);
+
+SELECT create_hypertable('candles', 'open_time', chunk_time_interval => INTERVAL '7 days', if_not_exists => TRUE);
+
+CREATE INDEX IF NOT EXISTS candles_exchange_id_symbol_open_time_idx
+    ON candles (exchange_id, symbol, open_time DESC);
@@ -0,0 +1,14 @@
+-- Levels are stored as JSONB arrays of {"price": ..., "quantity": ...}, best first
+CREATE TABLE IF NOT EXISTS orderbook_snapshots (
+    exchange_id TEXT        NOT NULL,
+    symbol      TEXT        NOT NULL,
+    timestamp   TIMESTAMPTZ NOT NULL,
+    depth       INTEGER     NOT NULL,
+    bids        JSONB       NOT NULL,
+    asks        JSONB       NOT NULL
+);
+
+SELECT create_hypertable('orderbook_snapshots', 'timestamp', chunk_time_interval => INTERVAL '1 day', if_not_exists => TRUE);
+
+CREATE UNIQUE INDEX IF NOT EXISTS orderbook_snapshots_exchange_id_symbol_timestamp_idx
+    ON orderbook_snapshots (exchange_id, symbol, timestamp DESC);
@@ -0,0 +1,25 @@
+-- Trades: compress after a week, keep six months of raw ticks
+ALTER TABLE trades SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'exchange_id, symbol',
+    timescaledb.compress_orderby = 'timestamp DESC, id'
+);
+SELECT add_compression_policy('trades', INTERVAL '7 days', if_not_exists => TRUE);
+SELECT add_retention_policy('trades', INTERVAL '180 days', if_not_exists => TRUE);
+
+-- Candles are small; compress but keep indefinitely
+ALTER TABLE candles SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'exchange_id, symbol, interval',
+    timescaledb.compress_orderby = 'open_time DESC'
+);
+SELECT add_compression_policy('candles', INTERVAL '30 days', if_not_exists => TRUE);
+
+-- Book snapshots are large; compress after a day, keep a month
+ALTER TABLE orderbook_snapshots SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'exchange_id, symbol',
+    timescaledb.compress_orderby = 'timestamp DESC'
+);
+SELECT add_compression_policy('orderbook_snapshots', INTERVAL '1 day', if_not_exists => TRUE);
+SELECT add_retention_policy('orderbook_snapshots', INTERVAL '30 days', if_not_exists => TRUE);
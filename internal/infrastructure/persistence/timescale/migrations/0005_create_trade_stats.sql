@@ -0,0 +1,85 @@
+-- Per exchange/symbol trade statistics at 1m, 1h and 1d, maintained by
+-- continuous aggregates so analytics queries never scan raw trades. VWAP, the
+-- buy/sell ratio and realized volatility are derived from these columns when
+-- queried.
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS trade_stats_1m
+WITH (timescaledb.continuous) AS
+SELECT
+    time_bucket(INTERVAL '1 minute', timestamp)                 AS bucket,
+    exchange_id,
+    symbol,
+    first(price, timestamp)                                     AS open,
+    max(price)                                                  AS high,
+    min(price)                                                  AS low,
+    last(price, timestamp)                                      AS close,
+    sum(volume)                                                 AS volume,
+    sum(price * volume)                                         AS quote_volume,
+    count(*)                                                    AS trade_count,
+    coalesce(sum(volume) FILTER (WHERE trade_type = 'BUY'), 0)  AS buy_volume,
+    coalesce(sum(volume) FILTER (WHERE trade_type = 'SELL'), 0) AS sell_volume,
+    count(*) FILTER (WHERE trade_type = 'BUY')                  AS buy_count,
+    count(*) FILTER (WHERE trade_type = 'SELL')                 AS sell_count
+FROM trades
+GROUP BY bucket, exchange_id, symbol
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('trade_stats_1m',
+    start_offset      => INTERVAL '3 hours',
+    end_offset        => INTERVAL '1 minute',
+    schedule_interval => INTERVAL '1 minute',
+    if_not_exists     => TRUE);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS trade_stats_1h
+WITH (timescaledb.continuous) AS
+SELECT
+    time_bucket(INTERVAL '1 hour', timestamp)                   AS bucket,
+    exchange_id,
+    symbol,
+    first(price, timestamp)                                     AS open,
+    max(price)                                                  AS high,
+    min(price)                                                  AS low,
+    last(price, timestamp)                                      AS close,
+    sum(volume)                                                 AS volume,
+    sum(price * volume)                                         AS quote_volume,
+    count(*)                                                    AS trade_count,
+    coalesce(sum(volume) FILTER (WHERE trade_type = 'BUY'), 0)  AS buy_volume,
+    coalesce(sum(volume) FILTER (WHERE trade_type = 'SELL'), 0) AS sell_volume,
+    count(*) FILTER (WHERE trade_type = 'BUY')                  AS buy_count,
+    count(*) FILTER (WHERE trade_type = 'SELL')                 AS sell_count
+FROM trades
+GROUP BY bucket, exchange_id, symbol
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('trade_stats_1h',
+    start_offset      => INTERVAL '3 days',
+    end_offset        => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '30 minutes',
+    if_not_exists     => TRUE);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS trade_stats_1d
+WITH (timescaledb.continuous) AS
+SELECT
+    time_bucket(INTERVAL '1 day', timestamp)                    AS bucket,
+    exchange_id,
+    symbol,
+    first(price, timestamp)                                     AS open,
+    max(price)                                                  AS high,
+    min(price)                                                  AS low,
+    last(price, timestamp)                                      AS close,
+    sum(volume)                                                 AS volume,
+    sum(price * volume)                                         AS quote_volume,
+    count(*)                                                    AS trade_count,
+    coalesce(sum(volume) FILTER (WHERE trade_type = 'BUY'), 0)  AS buy_volume,
+    coalesce(sum(volume) FILTER (WHERE trade_type = 'SELL'), 0) AS sell_volume,
+    count(*) FILTER (WHERE trade_type = 'BUY')                  AS buy_count,
+    count(*) FILTER (WHERE trade_type = 'SELL')                 AS sell_count
+FROM trades
+GROUP BY bucket, exchange_id, symbol
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('trade_stats_1d',
+    start_offset      => INTERVAL '30 days',
+    end_offset        => INTERVAL '1 day',
+    schedule_interval => INTERVAL '1 hour',
+    if_not_exists     => TRUE);
@@ -0,0 +1,151 @@
+package timescale
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"marketdata/internal/domain/entity"
+	"marketdata/internal/domain/valueobject"
+)
+
+// snapshotLevel is the JSONB representation of one price level
+type snapshotLevel struct {
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+}
+
+// OrderBookSnapshotRepository stores sampled orderbooks in the
+// orderbook_snapshots hypertable, levels as JSONB arrays best first
+type OrderBookSnapshotRepository struct {
+	db *sql.DB
+}
+
+func NewOrderBookSnapshotRepository(db *sql.DB) *OrderBookSnapshotRepository {
+	return &OrderBookSnapshotRepository{
+		db: db,
+	}
+}
+
+// StoreSnapshot stores the top depth levels of each side. A second snapshot of
+// the same book at the same timestamp is ignored.
+func (r *OrderBookSnapshotRepository) StoreSnapshot(ctx context.Context, orderbook *entity.OrderBook, depth int) error {
+	bids, err := encodeLevels(orderbook.Bids(), depth)
+	if err != nil {
+		return err
+	}
+	asks, err := encodeLevels(orderbook.Asks(), depth)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO orderbook_snapshots (
+			exchange_id, symbol, timestamp, depth, bids, asks
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (exchange_id, symbol, timestamp) DO NOTHING
+	`
+
+	_, err = r.db.ExecContext(ctx,
+		query,
+		orderbook.ExchangeID(),
+		orderbook.Symbol(),
+		orderbook.Timestamp(),
+		depth,
+		bids,
+		asks,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store orderbook snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// GetSnapshotAt returns the book as it was at time at: the latest snapshot
+// taken at or before it. It returns nil when no such snapshot exists.
+func (r *OrderBookSnapshotRepository) GetSnapshotAt(ctx context.Context, exchangeID, symbol string, at time.Time) (*entity.OrderBook, error) {
+	query := `
+		SELECT timestamp, bids, asks
+		FROM orderbook_snapshots
+		WHERE exchange_id = $1 AND symbol = $2 AND timestamp <= $3
+		ORDER BY timestamp DESC
+		LIMIT 1
+	`
+
+	var (
+		timestamp  time.Time
+		bids, asks []byte
+	)
+	err := r.db.QueryRowContext(ctx, query, exchangeID, symbol, at).Scan(&timestamp, &bids, &asks)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orderbook snapshot: %w", err)
+	}
+
+	bidLevels, err := decodeLevels(bids, symbol)
+	if err != nil {
+		return nil, err
+	}
+	askLevels, err := decodeLevels(asks, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	orderbook := entity.NewOrderBook(exchangeID, symbol, timestamp)
+	orderbook.UpdateBids(bidLevels)
+	orderbook.UpdateAsks(askLevels)
+
+	return orderbook, nil
+}
+
+func encodeLevels(levels []entity.PriceLevel, depth int) ([]byte, error) {
+	if depth > 0 && len(levels) > depth {
+		levels = levels[:depth]
+	}
+
+	encoded := make([]snapshotLevel, len(levels))
+	for i, level := range levels {
+		encoded[i] = snapshotLevel{
+			Price:    level.Price.Value(),
+			Quantity: level.Quantity.Value(),
+		}
+	}
+
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal orderbook levels: %w", err)
+	}
+	return data, nil
+}
+
+func decodeLevels(data []byte, symbol string) ([]entity.PriceLevel, error) {
+	var encoded []snapshotLevel
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal orderbook levels: %w", err)
+	}
+
+	levels := make([]entity.PriceLevel, len(encoded))
+	for i, level := range encoded {
+		price, err := valueobject.NewPrice(level.Price, "USDT")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create price value object: %w", err)
+		}
+
+		quantity, err := valueobject.NewVolume(level.Quantity, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create volume value object: %w", err)
+		}
+
+		levels[i] = entity.PriceLevel{
+			Price:    *price,
+			Quantity: *quantity,
+		}
+	}
+
+	return levels, nil
+}
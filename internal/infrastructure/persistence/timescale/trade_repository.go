@@ -4,12 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
+	"marketdata/internal/application/port/output"
 	"marketdata/internal/domain/entity"
 	"marketdata/internal/domain/valueobject"
 )
 
+const (
+	tradeColumns = 7
+
+	// PostgreSQL accepts at most 65535 bind parameters per statement
+	maxRowsPerInsert = 65535 / tradeColumns
+)
+
 type TradeRepository struct {
 	db *sql.DB
 }
@@ -25,6 +34,7 @@ func (r *TradeRepository) StoreTrade(ctx context.Context, trade *entity.Trade) e
 		INSERT INTO trades (
 			id, exchange_id, symbol, price, volume, trade_type, timestamp
 		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (exchange_id, id, timestamp) DO NOTHING
 	`
 
 	_, err := r.db.ExecContext(ctx,
@@ -45,6 +55,58 @@ func (r *TradeRepository) StoreTrade(ctx context.Context, trade *entity.Trade) e
 	return nil
 }
 
+// StoreTrades writes trades with multi-row INSERTs. Trades already stored are
+// skipped, so a batch can be retried safely. The conflict target includes the
+// timestamp because unique indexes on a hypertable must contain its time column.
+func (r *TradeRepository) StoreTrades(ctx context.Context, trades []*entity.Trade) error {
+	for start := 0; start < len(trades); start += maxRowsPerInsert {
+		end := min(start+maxRowsPerInsert, len(trades))
+		if err := r.insertTrades(ctx, trades[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TradeRepository) insertTrades(ctx context.Context, trades []*entity.Trade) error {
+	var (
+		query strings.Builder
+		args  = make([]interface{}, 0, len(trades)*tradeColumns)
+	)
+
+	query.WriteString(`INSERT INTO trades (id, exchange_id, symbol, price, volume, trade_type, timestamp) VALUES `)
+	for i, trade := range trades {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(")
+		for col := 0; col < tradeColumns; col++ {
+			if col > 0 {
+				query.WriteString(", ")
+			}
+			fmt.Fprintf(&query, "$%d", i*tradeColumns+col+1)
+		}
+		query.WriteString(")")
+
+		args = append(args,
+			trade.ID(),
+			trade.ExchangeID(),
+			trade.Symbol(),
+			trade.Price().Value(),
+			trade.Volume().Value(),
+			trade.Type(),
+			trade.Timestamp(),
+		)
+	}
+	query.WriteString(` ON CONFLICT (exchange_id, id, timestamp) DO NOTHING`)
+
+	if _, err := r.db.ExecContext(ctx, query.String(), args...); err != nil {
+		return fmt.Errorf("failed to store %d trades: %w", len(trades), err)
+	}
+
+	return nil
+}
+
 func (r *TradeRepository) GetTradesBySymbol(ctx context.Context, symbol string, limit int) ([]*entity.Trade, error) {
 	query := `
 		SELECT id, exchange_id, symbol, price, volume, trade_type, timestamp
@@ -60,6 +122,161 @@ func (r *TradeRepository) GetTradesBySymbol(ctx context.Context, symbol string,
 	}
 	defer rows.Close()
 
+	return scanTrades(rows)
+}
+
+// QueryTrades returns trades in (timestamp, exchange_id, id) order using keyset pagination:
+// filter.After resumes strictly past the last trade of the previous page, so
+// pages stay stable while new trades are inserted
+func (r *TradeRepository) QueryTrades(ctx context.Context, filter output.TradeFilter) ([]*entity.Trade, error) {
+	conditions, args := tradeConditions(filter.ExchangeID, filter.Symbol, filter.From, filter.To)
+	if filter.Side != "" {
+		conditions = append(conditions, "trade_type = "+placeholder(&args, string(filter.Side)))
+	}
+
+	order, cmp := "DESC", "<"
+	if filter.Ascending {
+		order, cmp = "ASC", ">"
+	}
+	if filter.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(timestamp, exchange_id, id) %s (%s, %s, %s)",
+			cmp,
+			placeholder(&args, filter.After.Timestamp),
+			placeholder(&args, filter.After.ExchangeID),
+			placeholder(&args, filter.After.ID),
+		))
+	}
+
+	query := `
+		SELECT id, exchange_id, symbol, price, volume, trade_type, timestamp
+		FROM trades` + where(conditions) + `
+		ORDER BY timestamp ` + order + `, exchange_id ` + order + `, id ` + order + `
+		LIMIT ` + placeholder(&args, filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trades: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTrades(rows)
+}
+
+func (r *TradeRepository) GetCandles(ctx context.Context, filter output.CandleFilter) ([]*entity.Candle, error) {
+	conditions, args := tradeConditions(filter.ExchangeID, filter.Symbol, filter.From, filter.To)
+	bucket := placeholder(&args, fmt.Sprintf("%d seconds", int64(filter.Interval.Seconds())))
+
+	query := `
+		SELECT exchange_id, symbol, bucket, open, high, low, close, volume, trade_count
+		FROM (
+			SELECT
+				exchange_id,
+				symbol,
+				time_bucket(` + bucket + `::interval, timestamp) AS bucket,
+				first(price, timestamp) AS open,
+				max(price) AS high,
+				min(price) AS low,
+				last(price, timestamp) AS close,
+				sum(volume) AS volume,
+				count(*) AS trade_count
+			FROM trades` + where(conditions) + `
+			GROUP BY exchange_id, symbol, bucket
+			ORDER BY bucket DESC
+			LIMIT ` + placeholder(&args, filter.Limit) + `
+		) candles
+		ORDER BY bucket ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query candles: %w", err)
+	}
+	defer rows.Close()
+
+	var candles []*entity.Candle
+	for rows.Next() {
+		var (
+			exchangeID             string
+			symbol                 string
+			openTime               time.Time
+			open, high, low, close float64
+			volume                 float64
+			tradeCount             int64
+		)
+
+		if err := rows.Scan(&exchangeID, &symbol, &openTime, &open, &high, &low, &close, &volume, &tradeCount); err != nil {
+			return nil, fmt.Errorf("failed to scan candle row: %w", err)
+		}
+
+		candles = append(candles, entity.NewCandle(
+			exchangeID, symbol, filter.Interval, openTime,
+			open, high, low, close, volume, tradeCount,
+		))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating candle rows: %w", err)
+	}
+
+	return candles, nil
+}
+
+// Name identifies TimescaleDB in readiness reports
+func (r *TradeRepository) Name() string {
+	return "timescale"
+}
+
+// Check pings the database
+func (r *TradeRepository) Check(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping timescale: %w", err)
+	}
+	return nil
+}
+
+// tradeConditions builds the WHERE clauses shared by trade and candle queries
+func tradeConditions(exchangeID, symbol string, from, to time.Time) ([]string, []interface{}) {
+	return rangeConditions("timestamp", exchangeID, symbol, from, to)
+}
+
+// rangeConditions filters on exchange and symbol and a half-open [from, to)
+// range of timeColumn
+func rangeConditions(timeColumn, exchangeID, symbol string, from, to time.Time) ([]string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	if exchangeID != "" {
+		conditions = append(conditions, "exchange_id = "+placeholder(&args, exchangeID))
+	}
+	if symbol != "" {
+		conditions = append(conditions, "symbol = "+placeholder(&args, symbol))
+	}
+	if !from.IsZero() {
+		conditions = append(conditions, timeColumn+" >= "+placeholder(&args, from))
+	}
+	if !to.IsZero() {
+		conditions = append(conditions, timeColumn+" < "+placeholder(&args, to))
+	}
+
+	return conditions, args
+}
+
+// placeholder appends the value to args and returns its positional parameter
+func placeholder(args *[]interface{}, value interface{}) string {
+	*args = append(*args, value)
+	return fmt.Sprintf("$%d", len(*args))
+}
+
+func where(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "\n\t\tWHERE " + strings.Join(conditions, " AND ")
+}
+
+func scanTrades(rows *sql.Rows) ([]*entity.Trade, error) {
 	var trades []*entity.Trade
 	for rows.Next() {
 		var (
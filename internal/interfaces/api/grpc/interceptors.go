@@ -0,0 +1,242 @@
+package grpc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"marketdata/pkg/logger"
+	"marketdata/pkg/metrics"
+)
+
+const (
+	apiKeyHeader    = "x-api-key"
+	authHeader      = "authorization"
+	requestIDHeader = "x-request-id"
+)
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID assigned by the logging interceptor
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Interceptors bundles the unary and stream interceptors applied to every RPC
+type Interceptors struct {
+	apiKeys [][]byte
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+func NewInterceptors(apiKeys []string, log *logger.Logger, m *metrics.Metrics) *Interceptors {
+	keys := make([][]byte, 0, len(apiKeys))
+	for _, key := range apiKeys {
+		if key != "" {
+			keys = append(keys, []byte(key))
+		}
+	}
+
+	return &Interceptors{
+		apiKeys: keys,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+// ServerOptions returns the chained interceptors in execution order: request
+// ID and logging, metrics, recovery, then authentication. Recovery runs inside
+// the others so a panic is logged with its request ID and counted as an
+// internal error.
+func (i *Interceptors) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			i.loggingUnary,
+			i.metricsUnary,
+			i.recoveryUnary,
+			i.authUnary,
+		),
+		grpc.ChainStreamInterceptor(
+			i.loggingStream,
+			i.metricsStream,
+			i.recoveryStream,
+			i.authStream,
+		),
+	}
+}
+
+func (i *Interceptors) recoveryUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = i.handlePanic(ctx, info.FullMethod, r)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func (i *Interceptors) recoveryStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = i.handlePanic(ss.Context(), info.FullMethod, r)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func (i *Interceptors) handlePanic(ctx context.Context, method string, r interface{}) error {
+	i.logger.Error("panic in grpc handler",
+		"method", method,
+		"request_id", RequestIDFromContext(ctx),
+		"panic", r,
+		"stack", string(debug.Stack()),
+	)
+	return status.Error(codes.Internal, "internal server error")
+}
+
+func (i *Interceptors) loggingUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx = withRequestID(ctx)
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	i.logCall(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func (i *Interceptors) loggingStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := withRequestID(ss.Context())
+	start := time.Now()
+
+	err := handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+
+	i.logCall(ctx, info.FullMethod, start, err)
+	return err
+}
+
+func (i *Interceptors) logCall(ctx context.Context, method string, start time.Time, err error) {
+	fields := []interface{}{
+		"method", method,
+		"request_id", RequestIDFromContext(ctx),
+		"code", status.Code(err).String(),
+		"duration", time.Since(start),
+	}
+
+	if err != nil {
+		i.logger.Error("grpc request failed", append(fields, "error", err)...)
+		return
+	}
+	i.logger.Info("grpc request", fields...)
+}
+
+func (i *Interceptors) metricsUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer i.recordCall(info.FullMethod, time.Now(), &err)
+	return handler(ctx, req)
+}
+
+func (i *Interceptors) metricsStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer i.recordCall(info.FullMethod, time.Now(), &err)
+	return handler(srv, ss)
+}
+
+// recordCall is deferred so a call is counted however its handler returns
+func (i *Interceptors) recordCall(method string, start time.Time, err *error) {
+	i.metrics.RecordGRPCRequest(method, status.Code(*err).String(), time.Since(start).Seconds())
+}
+
+func (i *Interceptors) authUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := i.authenticate(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (i *Interceptors) authStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := i.authenticate(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// authenticate checks the API key carried in the request metadata. Health and
+// reflection services stay open so orchestrators and tooling can reach them.
+func (i *Interceptors) authenticate(ctx context.Context, method string) error {
+	if len(i.apiKeys) == 0 || isPublicMethod(method) {
+		return nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	key := firstValue(md, apiKeyHeader)
+	if key == "" {
+		key = strings.TrimPrefix(firstValue(md, authHeader), "Bearer ")
+	}
+	if key == "" {
+		return status.Error(codes.Unauthenticated, "missing api key")
+	}
+
+	for _, valid := range i.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(key), valid) == 1 {
+			return nil
+		}
+	}
+
+	return status.Error(codes.Unauthenticated, "invalid api key")
+}
+
+func isPublicMethod(method string) bool {
+	return strings.HasPrefix(method, "/grpc.health.v1.Health/") ||
+		strings.HasPrefix(method, "/grpc.reflection.")
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// withRequestID reuses the caller's request ID when present, otherwise generates one,
+// and echoes it back in the response header
+func withRequestID(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		id = firstValue(md, requestIDHeader)
+	}
+	if id == "" {
+		id = newRequestID()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, id))
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// wrappedStream overrides the stream context so handlers see the request ID
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
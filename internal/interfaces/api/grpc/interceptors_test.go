@@ -0,0 +1,198 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "marketdata/api/proto"
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+	"marketdata/pkg/logger"
+	"marketdata/pkg/metrics"
+)
+
+const (
+	testAPIKey           = "secret"
+	testMetricsNamespace = "grpc_interceptors_test"
+)
+
+// testMetrics is shared because metrics register with the default registry
+var testMetrics = metrics.NewMetrics(testMetricsNamespace)
+
+// fakeMarketData answers GetOrderBook with getOrderBook; the other methods
+// are not called
+type fakeMarketData struct {
+	input.MarketDataUseCase
+	getOrderBook func(ctx context.Context, exchangeID, symbol string) (*dto.OrderBookDTO, error)
+}
+
+func (f *fakeMarketData) GetOrderBook(ctx context.Context, exchangeID, symbol string) (*dto.OrderBookDTO, error) {
+	return f.getOrderBook(ctx, exchangeID, symbol)
+}
+
+// startServer serves the market data service with the full interceptor chain
+// over an in-memory listener
+func startServer(t *testing.T, useCase input.MarketDataUseCase) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	server, _ := NewServer(NewMarketDataServer(useCase), NewInterceptors([]string{testAPIKey}, logger.NewLogger(), testMetrics))
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func withAPIKey(ctx context.Context, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, apiKeyHeader, key)
+}
+
+// requestCount reads the gRPC request counter of a method and code
+func requestCount(t *testing.T, method string, code codes.Code) float64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != testMetricsNamespace+"_grpc_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["method"] == method && labels["code"] == code.String() {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestInterceptorsRejectMissingAndInvalidKeys(t *testing.T) {
+	called := false
+	conn := startServer(t, &fakeMarketData{
+		getOrderBook: func(context.Context, string, string) (*dto.OrderBookDTO, error) {
+			called = true
+			return &dto.OrderBookDTO{}, nil
+		},
+	})
+	client := pb.NewMarketDataServiceClient(conn)
+	req := &pb.GetOrderBookRequest{ExchangeId: "binance", Symbol: "BTC-USDT"}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{name: "missing key", ctx: context.Background(), want: codes.Unauthenticated},
+		{name: "invalid key", ctx: withAPIKey(context.Background(), "wrong"), want: codes.Unauthenticated},
+		{name: "bearer key", ctx: metadata.AppendToOutgoingContext(context.Background(), authHeader, "Bearer "+testAPIKey), want: codes.OK},
+		{name: "valid key", ctx: withAPIKey(context.Background(), testAPIKey), want: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+			_, err := client.GetOrderBook(tt.ctx, req)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("code = %s, want %s (%v)", got, tt.want, err)
+			}
+			if called != (tt.want == codes.OK) {
+				t.Fatalf("handler called = %v", called)
+			}
+		})
+	}
+
+	// Health checks stay open without a key
+	_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("health check failed without a key: %v", err)
+	}
+}
+
+func TestInterceptorsRecoverPanicsWithRequestID(t *testing.T) {
+	var requestID string
+	conn := startServer(t, &fakeMarketData{
+		getOrderBook: func(ctx context.Context, _, _ string) (*dto.OrderBookDTO, error) {
+			requestID = RequestIDFromContext(ctx)
+			panic("boom")
+		},
+	})
+	client := pb.NewMarketDataServiceClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(withAPIKey(context.Background(), testAPIKey), requestIDHeader, "req-1")
+	var header metadata.MD
+	_, err := client.GetOrderBook(ctx, &pb.GetOrderBookRequest{ExchangeId: "binance", Symbol: "BTC-USDT"}, grpc.Header(&header))
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("code = %s, want Internal (%v)", status.Code(err), err)
+	}
+	if requestID != "req-1" {
+		t.Fatalf("handler saw request ID %q, want req-1", requestID)
+	}
+	if got := header.Get(requestIDHeader); len(got) != 1 || got[0] != "req-1" {
+		t.Fatalf("response request ID = %v, want req-1", got)
+	}
+
+	// The server keeps serving after the panic
+	_, err = client.GetOrderBook(ctx, &pb.GetOrderBookRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("code after panic = %s, want InvalidArgument", status.Code(err))
+	}
+}
+
+func TestInterceptorsRecordMetrics(t *testing.T) {
+	conn := startServer(t, &fakeMarketData{
+		getOrderBook: func(_ context.Context, _, symbol string) (*dto.OrderBookDTO, error) {
+			if symbol == "PANIC" {
+				panic("boom")
+			}
+			return nil, nil
+		},
+	})
+	client := pb.NewMarketDataServiceClient(conn)
+	method := pb.MarketDataService_GetOrderBook_FullMethodName
+	ctx := withAPIKey(context.Background(), testAPIKey)
+
+	calls := []struct {
+		ctx    context.Context
+		symbol string
+		code   codes.Code
+	}{
+		{ctx: ctx, symbol: "BTC-USDT", code: codes.NotFound},
+		{ctx: ctx, symbol: "PANIC", code: codes.Internal},
+		{ctx: context.Background(), symbol: "BTC-USDT", code: codes.Unauthenticated},
+	}
+	for _, call := range calls {
+		before := requestCount(t, method, call.code)
+		_, err := client.GetOrderBook(call.ctx, &pb.GetOrderBookRequest{ExchangeId: "binance", Symbol: call.symbol})
+		if status.Code(err) != call.code {
+			t.Fatalf("%s: code = %s, want %s", call.symbol, status.Code(err), call.code)
+		}
+		if got := requestCount(t, method, call.code); got != before+1 {
+			t.Fatalf("%s: %s requests = %v, want %v", call.symbol, call.code, got, before+1)
+		}
+	}
+}
@@ -2,11 +2,18 @@ package grpc
 
 import (
 	"context"
+	"errors"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "marketdata/api/proto"
+	"marketdata/internal/application/dto"
 	"marketdata/internal/application/port/input"
 )
 
@@ -21,6 +28,23 @@ func NewMarketDataServer(useCase input.MarketDataUseCase) *MarketDataServer {
 	}
 }
 
+// NewServer creates a gRPC server with the interceptor chain installed and the
+// market data, health and reflection services registered
+func NewServer(svc *MarketDataServer, interceptors *Interceptors) (*grpc.Server, *health.Server) {
+	server := grpc.NewServer(interceptors.ServerOptions()...)
+
+	pb.RegisterMarketDataServiceServer(server, svc)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(pb.MarketDataService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+
+	return server, healthServer
+}
+
 func (s *MarketDataServer) GetOrderBook(ctx context.Context, req *pb.GetOrderBookRequest) (*pb.OrderBook, error) {
 	if req.ExchangeId == "" || req.Symbol == "" {
 		return nil, status.Error(codes.InvalidArgument, "exchange_id and symbol are required")
@@ -53,10 +77,99 @@ func (s *MarketDataServer) SubscribeOrderBook(req *pb.SubscribeOrderBookRequest,
 		select {
 		case <-stream.Context().Done():
 			return nil
-		case update := <-updates:
+		case update, ok := <-updates:
+			if !ok {
+				return status.Error(codes.Unavailable, "orderbook stream ended")
+			}
 			if err := stream.Send(convertToProto(update)); err != nil {
 				return status.Error(codes.Internal, err.Error())
 			}
 		}
 	}
 }
+
+// GetTrades returns one page of trades; pass next_cursor back to get the next one
+func (s *MarketDataServer) GetTrades(ctx context.Context, req *pb.GetTradesRequest) (*pb.GetTradesResponse, error) {
+	if req.Symbol == "" {
+		return nil, status.Error(codes.InvalidArgument, "symbol is required")
+	}
+
+	query := dto.TradeQueryDTO{
+		ExchangeID: req.ExchangeId,
+		Symbol:     req.Symbol,
+		Cursor:     req.Cursor,
+		Limit:      int(req.Limit),
+		Ascending:  req.Order == pb.SortOrder_SORT_ORDER_ASC,
+	}
+	if req.From != nil {
+		query.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		query.To = req.To.AsTime()
+	}
+	switch req.Side {
+	case pb.TradeSide_TRADE_SIDE_BUY:
+		query.Side = "BUY"
+	case pb.TradeSide_TRADE_SIDE_SELL:
+		query.Side = "SELL"
+	}
+
+	page, err := s.marketDataUseCase.GetTrades(ctx, query)
+	if errors.Is(err, input.ErrInvalidQuery) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &pb.GetTradesResponse{
+		Trades:     make([]*pb.Trade, len(page.Trades)),
+		NextCursor: page.NextCursor,
+	}
+	for i, trade := range page.Trades {
+		resp.Trades[i] = convertTradeToProto(trade)
+	}
+
+	return resp, nil
+}
+
+func convertToProto(orderbook *dto.OrderBookDTO) *pb.OrderBook {
+	return &pb.OrderBook{
+		ExchangeId: orderbook.ExchangeID,
+		Symbol:     orderbook.Symbol,
+		Bids:       convertLevelsToProto(orderbook.Bids),
+		Asks:       convertLevelsToProto(orderbook.Asks),
+		Timestamp:  timestamppb.New(orderbook.Timestamp),
+	}
+}
+
+func convertLevelsToProto(levels []dto.PriceLevelDTO) []*pb.PriceLevel {
+	result := make([]*pb.PriceLevel, len(levels))
+	for i, level := range levels {
+		result[i] = &pb.PriceLevel{
+			Price:    level.Price,
+			Quantity: level.Quantity,
+		}
+	}
+	return result
+}
+
+func convertTradeToProto(trade *dto.TradeDTO) *pb.Trade {
+	side := pb.TradeSide_TRADE_SIDE_UNSPECIFIED
+	switch trade.TradeType {
+	case "BUY":
+		side = pb.TradeSide_TRADE_SIDE_BUY
+	case "SELL":
+		side = pb.TradeSide_TRADE_SIDE_SELL
+	}
+
+	return &pb.Trade{
+		Id:         trade.ID,
+		ExchangeId: trade.ExchangeID,
+		Symbol:     trade.Symbol,
+		Price:      trade.Price,
+		Volume:     trade.Volume,
+		Side:       side,
+		Timestamp:  timestamppb.New(trade.Timestamp),
+	}
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+	"marketdata/pkg/logger"
+)
+
+type AdminHandler struct {
+	subscriptionUseCase input.SubscriptionUseCase
+	defaults            dto.SubscriptionSpecDTO
+}
+
+// NewAdminHandler creates the admin handler; defaults holds the settings of
+// new subscriptions that do not set them
+func NewAdminHandler(useCase input.SubscriptionUseCase, defaults dto.SubscriptionSpecDTO) *AdminHandler {
+	return &AdminHandler{
+		subscriptionUseCase: useCase,
+		defaults:            defaults,
+	}
+}
+
+// NewAdminRouter mounts the admin API behind API key authentication. Each key
+// appears in the audit log as a short fingerprint rather than the key itself.
+// The risk routes are mounted when risk is given.
+func NewAdminRouter(h *AdminHandler, risk *RiskHandler, apiKeys []string, log *logger.Logger) http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("GET /admin/v1/subscriptions", h.ListSubscriptions)
+	api.HandleFunc("POST /admin/v1/subscriptions", h.AddSubscription)
+	api.HandleFunc("DELETE /admin/v1/subscriptions/{exchange}/{symbol}", h.RemoveSubscription)
+	api.HandleFunc("GET /admin/v1/audit", h.GetAuditLog)
+	if risk != nil {
+		api.HandleFunc("POST /admin/v1/risk/fills", risk.RecordFill)
+		api.HandleFunc("POST /admin/v1/risk/halt", risk.Halt)
+		api.HandleFunc("POST /admin/v1/risk/resume", risk.Resume)
+	}
+
+	return recoverer(jsonErrors(requireAPIKey(api, apiKeys)), log)
+}
+
+// subscriptionRequest leaves settings nil that fall back to the defaults
+type subscriptionRequest struct {
+	ExchangeID        string `json:"exchange_id"`
+	Symbol            string `json:"symbol"`
+	Depth             *int   `json:"depth"`
+	UpdateSpeedMs     *int64 `json:"update_speed_ms"`
+	Record            *bool  `json:"record"`
+	PublishThrottleMs *int64 `json:"publish_throttle_ms"`
+}
+
+func (r subscriptionRequest) spec(defaults dto.SubscriptionSpecDTO) dto.SubscriptionSpecDTO {
+	spec := defaults
+	spec.ExchangeID, spec.Symbol = r.ExchangeID, r.Symbol
+	if r.Depth != nil {
+		spec.Depth = *r.Depth
+	}
+	if r.UpdateSpeedMs != nil {
+		spec.UpdateSpeedMs = *r.UpdateSpeedMs
+	}
+	if r.Record != nil {
+		spec.Record = *r.Record
+	}
+	if r.PublishThrottleMs != nil {
+		spec.PublishThrottleMs = *r.PublishThrottleMs
+	}
+	return spec
+}
+
+// ListSubscriptions handles GET /admin/v1/subscriptions
+func (h *AdminHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.subscriptionUseCase.ListSubscriptions(r.Context()))
+}
+
+// AddSubscription handles POST /admin/v1/subscriptions with a JSON body of
+// exchange_id, symbol and optionally depth, update_speed_ms, record and
+// publish_throttle_ms. Posting an active symbol changes its settings.
+func (h *AdminHandler) AddSubscription(w http.ResponseWriter, r *http.Request) {
+	var req subscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, "body must be a JSON object with exchange_id and symbol")
+		return
+	}
+	if req.ExchangeID == "" || req.Symbol == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, "exchange_id and symbol are required")
+		return
+	}
+
+	subscription, err := h.subscriptionUseCase.Subscribe(r.Context(), req.spec(h.defaults), actorFromContext(r.Context()))
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, subscription)
+}
+
+// RemoveSubscription handles DELETE /admin/v1/subscriptions/{exchange}/{symbol}
+func (h *AdminHandler) RemoveSubscription(w http.ResponseWriter, r *http.Request) {
+	err := h.subscriptionUseCase.Unsubscribe(r.Context(), r.PathValue("exchange"), r.PathValue("symbol"), actorFromContext(r.Context()))
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAuditLog handles GET /admin/v1/audit?limit=
+func (h *AdminHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	limit, err := intParam(r, "limit", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+
+	changes, err := h.subscriptionUseCase.GetAuditLog(r.Context(), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, changes)
+}
+
+func writeSubscriptionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, input.ErrNotSubscribed):
+		writeError(w, http.StatusNotFound, CodeNotFound, err.Error())
+	case errors.Is(err, input.ErrUnknownExchange), errors.Is(err, input.ErrInvalidQuery):
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
+	}
+}
+
+type actorKey struct{}
+
+func actorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// requireAPIKey accepts a key in the X-API-Key header or as a bearer token
+func requireAPIKey(next http.Handler, apiKeys []string) http.Handler {
+	keys := make([][]byte, 0, len(apiKeys))
+	for _, key := range apiKeys {
+		if key != "" {
+			keys = append(keys, []byte(key))
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if key == "" {
+			key, _ = strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+
+		for _, valid := range keys {
+			if subtle.ConstantTimeCompare([]byte(key), valid) == 1 {
+				sum := sha256.Sum256(valid)
+				ctx := context.WithValue(r.Context(), actorKey{}, "admin:"+hex.EncodeToString(sum[:4]))
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+		}
+		writeError(w, http.StatusUnauthorized, CodeUnauthenticated, "missing or invalid API key")
+	})
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+)
+
+type AnalyticsHandler struct {
+	analyticsUseCase input.AnalyticsUseCase
+}
+
+func NewAnalyticsHandler(useCase input.AnalyticsUseCase) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsUseCase: useCase,
+	}
+}
+
+// GetTradeStats handles GET /api/v1/analytics/trades?exchange=&symbol=&interval=&from=&to=&limit=
+func (h *AnalyticsHandler) GetTradeStats(w http.ResponseWriter, r *http.Request) {
+	query := dto.TradeStatsQueryDTO{
+		ExchangeID: r.URL.Query().Get("exchange"),
+		Symbol:     r.URL.Query().Get("symbol"),
+	}
+
+	if query.Symbol == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, "symbol is required")
+		return
+	}
+
+	var err error
+	if query.Interval, err = parseInterval(r.URL.Query().Get("interval")); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+	if query.From, query.To, err = timeRange(r); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+	if query.Limit, err = intParam(r, "limit", 500); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+	if query.Limit > maxPageLimit {
+		query.Limit = maxPageLimit
+	}
+
+	stats, err := h.analyticsUseCase.GetTradeStats(r.Context(), query)
+	if errors.Is(err, input.ErrInvalidQuery) {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"marketdata/internal/application/port/input"
+)
+
+// ArbitrageHandler serves the opportunities tracked by the arbitrage scanner
+type ArbitrageHandler struct {
+	scannerUseCase    input.ArbitrageScannerUseCase
+	heartbeatInterval time.Duration
+}
+
+func NewArbitrageHandler(useCase input.ArbitrageScannerUseCase, heartbeatInterval time.Duration) *ArbitrageHandler {
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = defaultHeartbeatInterval
+	}
+	return &ArbitrageHandler{
+		scannerUseCase:    useCase,
+		heartbeatInterval: heartbeatInterval,
+	}
+}
+
+// ListOpportunities handles GET /api/v1/arbitrage/opportunities?symbol=
+func (h *ArbitrageHandler) ListOpportunities(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.scannerUseCase.ListOpportunities(r.Context(), r.URL.Query().Get("symbol")))
+}
+
+// StreamOpportunities handles GET /api/v1/sse/arbitrage?symbol=. It sends the
+// open opportunities as a snapshot event, then opened, updated and closed
+// events. Events racing the snapshot may repeat it, so clients should upsert
+// by id. The stream ends when the client falls behind; reconnecting starts
+// from a new snapshot.
+func (h *ArbitrageHandler) StreamOpportunities(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "streaming unsupported")
+		return
+	}
+
+	ctx := r.Context()
+	symbol := r.URL.Query().Get("symbol")
+	events := h.scannerUseCase.WatchOpportunities(ctx, symbol)
+	snapshot := h.scannerUseCase.ListOpportunities(ctx, symbol)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	if err := writeSSE(w, eventSnapshot, snapshot); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeSSE(w, event.Type, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", name, err)
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+	return err
+}
+
+// TriangularHandler serves the cycles found within single exchanges
+type TriangularHandler struct {
+	triangularUseCase input.TriangularArbitrageUseCase
+}
+
+func NewTriangularHandler(useCase input.TriangularArbitrageUseCase) *TriangularHandler {
+	return &TriangularHandler{triangularUseCase: useCase}
+}
+
+// ListCycles handles GET /api/v1/arbitrage/triangular?exchange=
+func (h *TriangularHandler) ListCycles(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.triangularUseCase.ListCycles(r.Context(), r.URL.Query().Get("exchange")))
+}
+
+// StatArbHandler serves the pairs of the statistical arbitrage engine
+type StatArbHandler struct {
+	statArbUseCase input.StatisticalArbitrageUseCase
+}
+
+func NewStatArbHandler(useCase input.StatisticalArbitrageUseCase) *StatArbHandler {
+	return &StatArbHandler{statArbUseCase: useCase}
+}
+
+// ListPairs handles GET /api/v1/arbitrage/statistical
+func (h *StatArbHandler) ListPairs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.statArbUseCase.ListPairs(r.Context()))
+}
@@ -0,0 +1,21 @@
+package http
+
+import (
+	"net/http"
+
+	"marketdata/internal/application/port/input"
+)
+
+// FeeHandler serves the fee schedules of the exchanges
+type FeeHandler struct {
+	feeUseCase input.FeeScheduleUseCase
+}
+
+func NewFeeHandler(useCase input.FeeScheduleUseCase) *FeeHandler {
+	return &FeeHandler{feeUseCase: useCase}
+}
+
+// ListFeeSchedules handles GET /api/v1/fees
+func (h *FeeHandler) ListFeeSchedules(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.feeUseCase.ListFeeSchedules(r.Context()))
+}
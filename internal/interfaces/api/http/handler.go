@@ -1,12 +1,18 @@
 package http
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
+	"marketdata/internal/application/dto"
 	"marketdata/internal/application/port/input"
 )
 
+const maxPageLimit = 1000
+
 type MarketDataHandler struct {
 	marketDataUseCase input.MarketDataUseCase
 }
@@ -17,26 +23,284 @@ func NewMarketDataHandler(useCase input.MarketDataUseCase) *MarketDataHandler {
 	}
 }
 
+// GetOrderBook handles GET /api/v1/orderbook?exchange=&symbol=&depth=
 func (h *MarketDataHandler) GetOrderBook(w http.ResponseWriter, r *http.Request) {
 	exchangeID := r.URL.Query().Get("exchange")
 	symbol := r.URL.Query().Get("symbol")
 
 	if exchangeID == "" || symbol == "" {
-		http.Error(w, "missing required parameters", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, "exchange and symbol are required")
+		return
+	}
+
+	depth, err := intParam(r, "depth", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
 		return
 	}
 
 	orderbook, err := h.marketDataUseCase.GetOrderBook(r.Context(), exchangeID, symbol)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
 		return
 	}
 
 	if orderbook == nil {
-		http.Error(w, "orderbook not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, CodeNotFound, "orderbook not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, limitDepth(orderbook, depth))
+}
+
+// GetOrderBookHistory handles GET /api/v1/orderbook/history?exchange=&symbol=&at=&depth=,
+// returning the latest archived snapshot taken at or before at
+func (h *MarketDataHandler) GetOrderBookHistory(w http.ResponseWriter, r *http.Request) {
+	exchangeID := r.URL.Query().Get("exchange")
+	symbol := r.URL.Query().Get("symbol")
+
+	if exchangeID == "" || symbol == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, "exchange and symbol are required")
+		return
+	}
+
+	at, err := timeParam(r, "at")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+	if at.IsZero() {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, "at is required")
+		return
+	}
+
+	depth, err := intParam(r, "depth", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+
+	orderbook, err := h.marketDataUseCase.GetOrderBookAt(r.Context(), exchangeID, symbol, at)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
+		return
+	}
+
+	if orderbook == nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "no orderbook snapshot at or before the given time")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, limitDepth(orderbook, depth))
+}
+
+// GetOrderBooks handles GET /api/v1/orderbooks?depth=
+func (h *MarketDataHandler) GetOrderBooks(w http.ResponseWriter, r *http.Request) {
+	depth, err := intParam(r, "depth", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+
+	orderbooks, err := h.marketDataUseCase.GetAllOrderBooks(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
+		return
+	}
+
+	for i, orderbook := range orderbooks {
+		orderbooks[i] = limitDepth(orderbook, depth)
+	}
+
+	writeJSON(w, http.StatusOK, orderbooks)
+}
+
+// GetTrades handles GET /api/v1/trades?exchange=&symbol=&from=&to=&side=&order=&cursor=&limit=
+func (h *MarketDataHandler) GetTrades(w http.ResponseWriter, r *http.Request) {
+	query := dto.TradeQueryDTO{
+		ExchangeID: r.URL.Query().Get("exchange"),
+		Symbol:     r.URL.Query().Get("symbol"),
+		Side:       r.URL.Query().Get("side"),
+		Cursor:     r.URL.Query().Get("cursor"),
+	}
+
+	if query.Symbol == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, "symbol is required")
+		return
+	}
+
+	var err error
+	if query.From, query.To, err = timeRange(r); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+	if query.Limit, err = intParam(r, "limit", 100); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+	if query.Limit > maxPageLimit {
+		query.Limit = maxPageLimit
+	}
+	switch r.URL.Query().Get("order") {
+	case "", "desc":
+	case "asc":
+		query.Ascending = true
+	default:
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, "order must be asc or desc")
+		return
+	}
+
+	page, err := h.marketDataUseCase.GetTrades(r.Context(), query)
+	if errors.Is(err, input.ErrInvalidQuery) {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(orderbook)
+	writeJSON(w, http.StatusOK, page)
+}
+
+// GetCandles handles GET /api/v1/candles?exchange=&symbol=&interval=&from=&to=&limit=
+func (h *MarketDataHandler) GetCandles(w http.ResponseWriter, r *http.Request) {
+	query := dto.CandleQueryDTO{
+		ExchangeID: r.URL.Query().Get("exchange"),
+		Symbol:     r.URL.Query().Get("symbol"),
+	}
+
+	if query.ExchangeID == "" || query.Symbol == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, "exchange and symbol are required")
+		return
+	}
+
+	var err error
+	if query.Interval, err = parseInterval(r.URL.Query().Get("interval")); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+	if query.From, query.To, err = timeRange(r); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+	if query.Limit, err = intParam(r, "limit", 500); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+	if query.Limit > maxPageLimit {
+		query.Limit = maxPageLimit
+	}
+
+	candles, err := h.marketDataUseCase.GetCandles(r.Context(), query)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, candles)
+}
+
+// GetInstruments handles GET /api/v1/instruments
+func (h *MarketDataHandler) GetInstruments(w http.ResponseWriter, r *http.Request) {
+	instruments, err := h.marketDataUseCase.GetInstruments(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, instruments)
+}
+
+// GetArbitrageOpportunities handles GET /api/v1/arbitrage?symbol=
+func (h *MarketDataHandler) GetArbitrageOpportunities(w http.ResponseWriter, r *http.Request) {
+	opportunities, err := h.marketDataUseCase.GetArbitrageOpportunities(r.Context(), r.URL.Query().Get("symbol"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, opportunities)
+}
+
+// limitDepth returns a copy of the orderbook truncated to depth levels per side
+func limitDepth(orderbook *dto.OrderBookDTO, depth int) *dto.OrderBookDTO {
+	if depth <= 0 {
+		return orderbook
+	}
+
+	limited := *orderbook
+	if len(limited.Bids) > depth {
+		limited.Bids = limited.Bids[:depth]
+	}
+	if len(limited.Asks) > depth {
+		limited.Asks = limited.Asks[:depth]
+	}
+	return &limited
+}
+
+func intParam(r *http.Request, name string, defaultValue int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return value, nil
+}
+
+func timeRange(r *http.Request) (time.Time, time.Time, error) {
+	from, err := timeParam(r, "from")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := timeParam(r, "to")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must be before to")
+	}
+	return from, to, nil
+}
+
+// timeParam accepts RFC3339 timestamps or Unix milliseconds
+func timeParam(r *http.Request, name string) (time.Time, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	if millis, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.UnixMilli(millis).UTC(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be RFC3339 or unix milliseconds", name)
+	}
+	return t, nil
+}
+
+// parseInterval accepts exchange-style intervals such as 1m, 15m, 4h and 1d
+func parseInterval(raw string) (time.Duration, error) {
+	if raw == "" {
+		return time.Minute, nil
+	}
+
+	if len(raw) > 1 && raw[len(raw)-1] == 'd' {
+		days, err := strconv.Atoi(raw[:len(raw)-1])
+		if err == nil && days > 0 {
+			return time.Duration(days) * 24 * time.Hour, nil
+		}
+	}
+
+	interval, err := time.ParseDuration(raw)
+	if err != nil || interval < time.Minute {
+		return 0, fmt.Errorf("interval must be a duration of at least 1m, e.g. 1m, 1h, 1d")
+	}
+	return interval, nil
 }
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+
+	"marketdata/internal/application/port/input"
+)
+
+type HealthHandler struct {
+	statusUseCase input.StatusUseCase
+}
+
+func NewHealthHandler(useCase input.StatusUseCase) *HealthHandler {
+	return &HealthHandler{
+		statusUseCase: useCase,
+	}
+}
+
+// Healthz handles GET /healthz; it only reports that the process is serving
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readyz handles GET /readyz and answers 503 until every dependency is
+// reachable and at least one exchange is connected
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	readiness := h.statusUseCase.Readiness(r.Context())
+
+	status := http.StatusOK
+	if !readiness.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, readiness)
+}
+
+// Status handles GET /status
+func (h *HealthHandler) Status(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.statusUseCase.Status(r.Context()))
+}
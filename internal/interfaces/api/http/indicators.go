@@ -0,0 +1,22 @@
+package http
+
+import (
+	"net/http"
+
+	"marketdata/internal/application/port/input"
+)
+
+// IndicatorHandler serves the volatility and market quality indicators
+type IndicatorHandler struct {
+	indicatorUseCase input.IndicatorUseCase
+}
+
+func NewIndicatorHandler(useCase input.IndicatorUseCase) *IndicatorHandler {
+	return &IndicatorHandler{indicatorUseCase: useCase}
+}
+
+// ListIndicators handles GET /api/v1/indicators?exchange=&symbol=
+func (h *IndicatorHandler) ListIndicators(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	writeJSON(w, http.StatusOK, h.indicatorUseCase.ListIndicators(r.Context(), query.Get("exchange"), query.Get("symbol")))
+}
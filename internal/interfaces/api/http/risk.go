@@ -0,0 +1,88 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+)
+
+// RiskHandler serves the risk manager: its status and trade checks on the
+// API, and fills and the kill switch on the admin API
+type RiskHandler struct {
+	riskUseCase input.RiskUseCase
+}
+
+func NewRiskHandler(useCase input.RiskUseCase) *RiskHandler {
+	return &RiskHandler{riskUseCase: useCase}
+}
+
+// GetStatus handles GET /api/v1/risk
+func (h *RiskHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.riskUseCase.GetRiskStatus(r.Context()))
+}
+
+// CheckTrade handles POST /api/v1/risk/check with a JSON body of
+// exchange_id, symbol, side, quantity, price and optionally max_loss. A
+// rejected trade is still a 200 with approved false.
+func (h *RiskHandler) CheckTrade(w http.ResponseWriter, r *http.Request) {
+	var check dto.TradeCheckDTO
+	if err := json.NewDecoder(r.Body).Decode(&check); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, "body must be a JSON object with exchange_id, symbol, side, quantity and price")
+		return
+	}
+	result, err := h.riskUseCase.CheckTrade(r.Context(), &check)
+	if err != nil {
+		writeRiskError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// RecordFill handles POST /admin/v1/risk/fills with a JSON body of
+// exchange_id, symbol, side, quantity, price and optionally liquidity
+func (h *RiskHandler) RecordFill(w http.ResponseWriter, r *http.Request) {
+	var fill dto.FillDTO
+	if err := json.NewDecoder(r.Body).Decode(&fill); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, "body must be a JSON object with exchange_id, symbol, side, quantity and price")
+		return
+	}
+	result, err := h.riskUseCase.RecordFill(r.Context(), &fill)
+	if err != nil {
+		writeRiskError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// haltRequest gives the reason of a manual halt
+type haltRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Halt handles POST /admin/v1/risk/halt with an optional JSON body of reason
+func (h *RiskHandler) Halt(w http.ResponseWriter, r *http.Request) {
+	var req haltRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidArgument, "body must be a JSON object with an optional reason")
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, h.riskUseCase.Halt(r.Context(), req.Reason))
+}
+
+// Resume handles POST /admin/v1/risk/resume
+func (h *RiskHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.riskUseCase.Resume(r.Context()))
+}
+
+func writeRiskError(w http.ResponseWriter, err error) {
+	if errors.Is(err, input.ErrInvalidQuery) {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
+}
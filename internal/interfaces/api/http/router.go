@@ -0,0 +1,142 @@
+package http
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"marketdata/pkg/logger"
+)
+
+// Router serves the HTTP API. Every route answers panics and unknown paths
+// with JSON error bodies; routes other than streams also run under the
+// request timeout.
+type Router struct {
+	mux            *http.ServeMux
+	handler        http.Handler
+	requestTimeout time.Duration
+}
+
+// NewRouter mounts the REST API
+func NewRouter(h *MarketDataHandler, analytics *AnalyticsHandler, requestTimeout time.Duration, log *logger.Logger) *Router {
+	mux := http.NewServeMux()
+	router := &Router{
+		mux:            mux,
+		handler:        recoverer(jsonErrors(mux), log),
+		requestTimeout: requestTimeout,
+	}
+	router.HandleFunc("GET /api/v1/orderbook", h.GetOrderBook)
+	router.HandleFunc("GET /api/v1/orderbook/history", h.GetOrderBookHistory)
+	router.HandleFunc("GET /api/v1/orderbooks", h.GetOrderBooks)
+	router.HandleFunc("GET /api/v1/trades", h.GetTrades)
+	router.HandleFunc("GET /api/v1/candles", h.GetCandles)
+	router.HandleFunc("GET /api/v1/instruments", h.GetInstruments)
+	router.HandleFunc("GET /api/v1/arbitrage", h.GetArbitrageOpportunities)
+	router.HandleFunc("GET /api/v1/analytics/trades", analytics.GetTradeStats)
+	return router
+}
+
+// Handle mounts a request-response route under the request timeout
+func (r *Router) Handle(pattern string, handler http.Handler) {
+	if r.requestTimeout > 0 {
+		handler = http.TimeoutHandler(handler, r.requestTimeout, timeoutBody())
+	}
+	r.mux.Handle(pattern, handler)
+}
+
+func (r *Router) HandleFunc(pattern string, handler http.HandlerFunc) {
+	r.Handle(pattern, handler)
+}
+
+// HandleStream mounts a long-lived stream, which the request timeout would
+// cut off and whose flushes and hijacking TimeoutHandler does not support
+func (r *Router) HandleStream(pattern string, handler http.Handler) {
+	r.mux.Handle(pattern, handler)
+}
+
+func (r *Router) HandleStreamFunc(pattern string, handler http.HandlerFunc) {
+	r.HandleStream(pattern, handler)
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.handler.ServeHTTP(w, req)
+}
+
+// jsonErrors rewrites the plain-text 404 and 405 responses produced by ServeMux
+// routing, and presets the JSON content type so TimeoutHandler's body matches it
+func jsonErrors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		next.ServeHTTP(&plainErrorWriter{ResponseWriter: w}, r)
+	})
+}
+
+type plainErrorWriter struct {
+	http.ResponseWriter
+	suppressed bool
+}
+
+func (w *plainErrorWriter) WriteHeader(status int) {
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		switch status {
+		case http.StatusNotFound:
+			w.suppressed = true
+			writeError(w.ResponseWriter, status, CodeNotFound, "route not found")
+			return
+		case http.StatusMethodNotAllowed:
+			w.suppressed = true
+			writeError(w.ResponseWriter, status, CodeMethodNotAllowed, "method not allowed")
+			return
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *plainErrorWriter) Write(b []byte) (int, error) {
+	if w.suppressed {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush and Hijack pass through to the underlying writer so streams work
+// behind jsonErrors
+func (w *plainErrorWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *plainErrorWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+func (w *plainErrorWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func recoverer(next http.Handler, log *logger.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				log.Error("panic in http handler",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"panic", rec,
+				)
+				writeError(w, http.StatusInternalServerError, CodeInternal, "internal server error")
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"marketdata/pkg/logger"
+)
+
+func TestRouterWrapsEveryRoute(t *testing.T) {
+	router := NewRouter(nil, nil, 50*time.Millisecond, logger.NewLogger())
+	router.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	router.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	router.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	router.HandleStreamFunc("GET /stream", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Hijacker); !ok {
+			t.Error("stream cannot hijack its connection")
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			t.Error("stream cannot flush")
+			return
+		}
+		// Streams outlive the request timeout
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {}\n\n"))
+		flusher.Flush()
+	})
+	router.HandleStreamFunc("GET /stream/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	tests := []struct {
+		path   string
+		status int
+		code   string
+	}{
+		{"/healthz", http.StatusOK, ""},
+		{"/missing", http.StatusNotFound, CodeNotFound},
+		{"/slow", http.StatusServiceUnavailable, CodeTimeout},
+		{"/panic", http.StatusInternalServerError, CodeInternal},
+		{"/stream", http.StatusOK, ""},
+		{"/stream/panic", http.StatusInternalServerError, CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.code == "" {
+				return
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+				t.Fatalf("content type %q", ct)
+			}
+			var body ErrorBody
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error.Code != tt.code {
+				t.Fatalf("body %+v (%v), want code %s", body, err, tt.code)
+			}
+		})
+	}
+}
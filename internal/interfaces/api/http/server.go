@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"marketdata/pkg/logger"
+)
+
+type Server struct {
+	httpServer *http.Server
+	logger     *logger.Logger
+	baseCtx    context.Context
+	cancel     context.CancelFunc
+}
+
+func NewServer(port int, handler http.Handler, log *logger.Logger) *Server {
+	// Request contexts derive from baseCtx so long-lived streams, which
+	// http.Server.Shutdown does not track, end when the server shuts down
+	baseCtx, cancel := context.WithCancel(context.Background())
+
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              fmt.Sprintf(":%d", port),
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			BaseContext: func(net.Listener) context.Context {
+				return baseCtx
+			},
+		},
+		logger:  log,
+		baseCtx: baseCtx,
+		cancel:  cancel,
+	}
+}
+
+// Start serves in the background; errors other than a clean shutdown are logged
+func (s *Server) Start() {
+	go func() {
+		s.logger.Info("http server listening", "addr", s.httpServer.Addr)
+		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("http server stopped", "error", err)
+		}
+	}()
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests until ctx expires
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.cancel()
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shut down http server: %w", err)
+	}
+	return nil
+}
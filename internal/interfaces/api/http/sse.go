@@ -0,0 +1,182 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"marketdata/internal/application/port/input"
+	"marketdata/pkg/logger"
+)
+
+const (
+	defaultSSEBufferSize = 1024
+	defaultSSELinger     = time.Minute
+	maxSSEThrottle       = time.Minute
+	sseRetry             = 3 * time.Second
+)
+
+// SSEOptions tunes the Server-Sent Events endpoints
+type SSEOptions struct {
+	BufferSize        int
+	HeartbeatInterval time.Duration
+	Linger            time.Duration
+}
+
+func (o SSEOptions) withDefaults() SSEOptions {
+	if o.BufferSize <= 0 {
+		o.BufferSize = defaultSSEBufferSize
+	}
+	if o.HeartbeatInterval <= 0 {
+		o.HeartbeatInterval = defaultHeartbeatInterval
+	}
+	if o.Linger <= 0 {
+		o.Linger = defaultSSELinger
+	}
+	return o
+}
+
+// SSEHandler streams orderbook and trade updates as Server-Sent Events
+type SSEHandler struct {
+	hub    *sseHub
+	opts   SSEOptions
+	logger *logger.Logger
+}
+
+func NewSSEHandler(useCase input.MarketDataUseCase, opts SSEOptions, log *logger.Logger) *SSEHandler {
+	opts = opts.withDefaults()
+	return &SSEHandler{
+		hub:    newSSEHub(useCase, opts, log),
+		opts:   opts,
+		logger: log,
+	}
+}
+
+// StreamOrderBook handles GET /api/v1/sse/orderbook?exchange=&symbol=&throttle=.
+// With a throttle only the latest book of each interval is sent.
+func (h *SSEHandler) StreamOrderBook(w http.ResponseWriter, r *http.Request) {
+	h.stream(w, r, sseKindOrderBook)
+}
+
+// StreamTrades handles GET /api/v1/sse/trades?exchange=&symbol=&throttle=.
+// With a throttle trades are delivered in batches once per interval.
+func (h *SSEHandler) StreamTrades(w http.ResponseWriter, r *http.Request) {
+	h.stream(w, r, sseKindTrade)
+}
+
+// Close stops the upstream feeds of every topic
+func (h *SSEHandler) Close() {
+	h.hub.close()
+}
+
+func (h *SSEHandler) stream(w http.ResponseWriter, r *http.Request, kind string) {
+	exchangeID := r.URL.Query().Get("exchange")
+	symbol := r.URL.Query().Get("symbol")
+	if exchangeID == "" || symbol == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, "exchange and symbol are required")
+		return
+	}
+
+	throttle, err := throttleParam(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "streaming unsupported")
+		return
+	}
+
+	topic := h.hub.acquire(kind, exchangeID, symbol)
+	defer h.hub.release(topic)
+
+	notify := topic.watch()
+	defer topic.unwatch(notify)
+
+	// Resume after Last-Event-ID when it is still buffered. Otherwise books
+	// start from the latest update and trades from the most recent few.
+	var last uint64
+	if seq, ok := topic.resumePoint(r.Header.Get("Last-Event-ID")); ok {
+		last = seq
+	} else {
+		backlog := uint64(tradeSnapshotSize)
+		if kind == sseKindOrderBook {
+			backlog = 1
+		}
+		last = topic.current()
+		last -= min(last, backlog)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.opts.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	var lastSent time.Time
+	pending := true
+	for {
+		if pending {
+			if wait := throttle - time.Since(lastSent); throttle > 0 && wait > 0 {
+				select {
+				case <-r.Context().Done():
+					return
+				case <-topic.done:
+					return
+				case <-time.After(wait):
+				}
+			}
+
+			events, seq, gap := topic.since(last)
+			last = seq
+			if kind == sseKindOrderBook && len(events) > 1 {
+				events = events[len(events)-1:]
+			}
+			if gap && kind == sseKindTrade {
+				fmt.Fprint(w, "event: gap\ndata: {}\n\n")
+			}
+			for _, event := range events {
+				if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.id, event.name, event.data); err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+			lastSent = time.Now()
+			pending = false
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-topic.done:
+			return
+		case <-notify:
+			pending = true
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func throttleParam(r *http.Request) (time.Duration, error) {
+	raw := r.URL.Query().Get("throttle")
+	if raw == "" {
+		return 0, nil
+	}
+
+	throttle, err := time.ParseDuration(raw)
+	if err != nil || throttle < 0 || throttle > maxSSEThrottle {
+		return 0, fmt.Errorf("throttle must be a duration between 0 and %s, e.g. 250ms", maxSSEThrottle)
+	}
+	return throttle, nil
+}
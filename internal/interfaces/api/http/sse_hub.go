@@ -0,0 +1,320 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+	"marketdata/pkg/logger"
+)
+
+const (
+	sseKindOrderBook = "orderbook"
+	sseKindTrade     = "trade"
+)
+
+type sseEvent struct {
+	id   string
+	name string
+	data []byte
+}
+
+// sseTopic fans one upstream feed out to every SSE client of an exchange/symbol
+// and keeps the most recent events in a ring buffer so clients can resume
+type sseTopic struct {
+	key   string
+	kind  string
+	epoch string
+	done  chan struct{}
+
+	mu     sync.Mutex
+	seq    uint64
+	ring   []sseEvent
+	next   int
+	full   bool
+	notify map[chan struct{}]struct{}
+	refs   int
+	linger *time.Timer
+	cancel context.CancelFunc
+}
+
+func newSSETopic(key, kind string, bufferSize int) *sseTopic {
+	return &sseTopic{
+		key:    key,
+		kind:   kind,
+		epoch:  strconv.FormatInt(time.Now().UnixNano(), 36),
+		done:   make(chan struct{}),
+		ring:   make([]sseEvent, bufferSize),
+		notify: make(map[chan struct{}]struct{}),
+	}
+}
+
+func (t *sseTopic) publish(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", t.kind, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.seq++
+	t.ring[t.next] = sseEvent{
+		id:   fmt.Sprintf("%s-%d", t.epoch, t.seq),
+		name: t.kind,
+		data: data,
+	}
+	t.next = (t.next + 1) % len(t.ring)
+	if t.next == 0 {
+		t.full = true
+	}
+
+	for ch := range t.notify {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// since returns buffered events after the given sequence number. gap reports
+// that events were evicted before the client could read them.
+func (t *sseTopic) since(seq uint64) (events []sseEvent, last uint64, gap bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	count := uint64(t.next)
+	if t.full {
+		count = uint64(len(t.ring))
+	}
+	oldest := t.seq - count + 1
+
+	if seq >= t.seq {
+		return nil, t.seq, false
+	}
+	if seq+1 < oldest {
+		gap = true
+		seq = oldest - 1
+	}
+
+	start := (t.next - int(t.seq-seq) + len(t.ring)) % len(t.ring)
+	for i := uint64(0); i < t.seq-seq; i++ {
+		events = append(events, t.ring[(start+int(i))%len(t.ring)])
+	}
+
+	return events, t.seq, gap
+}
+
+// resumePoint maps a Last-Event-ID header to a sequence number. IDs from a
+// previous incarnation of the topic are treated as too old to resume.
+func (t *sseTopic) resumePoint(lastEventID string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(lastEventID, "-")
+	if !ok || epoch != t.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// current returns the latest sequence number
+func (t *sseTopic) current() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.seq
+}
+
+func (t *sseTopic) watch() chan struct{} {
+	ch := make(chan struct{}, 1)
+	t.mu.Lock()
+	t.notify[ch] = struct{}{}
+	t.mu.Unlock()
+	return ch
+}
+
+func (t *sseTopic) unwatch(ch chan struct{}) {
+	t.mu.Lock()
+	delete(t.notify, ch)
+	t.mu.Unlock()
+}
+
+// sseHub owns the topics and their upstream feeds. A topic lingers after its
+// last client leaves so that a reconnecting client can still resume.
+type sseHub struct {
+	marketDataUseCase input.MarketDataUseCase
+	opts              SSEOptions
+	logger            *logger.Logger
+
+	mu     sync.Mutex
+	topics map[string]*sseTopic
+}
+
+func newSSEHub(useCase input.MarketDataUseCase, opts SSEOptions, log *logger.Logger) *sseHub {
+	return &sseHub{
+		marketDataUseCase: useCase,
+		opts:              opts,
+		logger:            log,
+		topics:            make(map[string]*sseTopic),
+	}
+}
+
+func (h *sseHub) acquire(kind, exchangeID, symbol string) *sseTopic {
+	key := strings.Join([]string{kind, exchangeID, symbol}, "|")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if topic, ok := h.topics[key]; ok {
+		topic.mu.Lock()
+		topic.refs++
+		if topic.linger != nil {
+			topic.linger.Stop()
+			topic.linger = nil
+		}
+		topic.mu.Unlock()
+		return topic
+	}
+
+	topic := newSSETopic(key, kind, h.opts.BufferSize)
+	topic.refs = 1
+	upstreamCtx, cancel := context.WithCancel(context.Background())
+	topic.cancel = cancel
+	h.topics[key] = topic
+
+	go func() {
+		defer close(topic.done)
+		defer h.drop(topic)
+
+		var err error
+		switch kind {
+		case sseKindOrderBook:
+			err = h.runOrderBook(upstreamCtx, topic, exchangeID, symbol)
+		case sseKindTrade:
+			err = h.runTrades(upstreamCtx, topic, exchangeID, symbol)
+		}
+		if err != nil && upstreamCtx.Err() == nil {
+			h.logger.Error("sse upstream stopped",
+				"error", err,
+				"kind", kind,
+				"exchange", exchangeID,
+				"symbol", symbol,
+			)
+		}
+	}()
+
+	return topic
+}
+
+func (h *sseHub) release(topic *sseTopic) {
+	topic.mu.Lock()
+	defer topic.mu.Unlock()
+
+	topic.refs--
+	if topic.refs > 0 {
+		return
+	}
+	topic.linger = time.AfterFunc(h.opts.Linger, func() {
+		topic.mu.Lock()
+		idle := topic.refs == 0
+		topic.mu.Unlock()
+		if idle {
+			topic.cancel()
+		}
+	})
+}
+
+func (h *sseHub) drop(topic *sseTopic) {
+	topic.cancel()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.topics[topic.key] == topic {
+		delete(h.topics, topic.key)
+	}
+}
+
+// close stops every upstream feed
+func (h *sseHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, topic := range h.topics {
+		topic.cancel()
+	}
+}
+
+func (h *sseHub) runOrderBook(ctx context.Context, topic *sseTopic, exchangeID, symbol string) error {
+	updates, err := h.marketDataUseCase.SubscribeOrderBook(ctx, exchangeID, symbol)
+	if err != nil {
+		return err
+	}
+
+	orderbook, err := h.marketDataUseCase.GetOrderBook(ctx, exchangeID, symbol)
+	if err != nil {
+		return err
+	}
+	if orderbook != nil {
+		if err := topic.publish(orderbook); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case update, ok := <-updates:
+			if !ok || update == nil {
+				return nil
+			}
+			if err := topic.publish(update); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (h *sseHub) runTrades(ctx context.Context, topic *sseTopic, exchangeID, symbol string) error {
+	live, err := h.marketDataUseCase.SubscribeTrades(ctx, exchangeID, symbol)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.marketDataUseCase.GetTrades(ctx, dto.TradeQueryDTO{
+		ExchangeID: exchangeID,
+		Symbol:     symbol,
+		Limit:      tradeSnapshotSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	var publishErr error
+	publish := func(trades []*dto.TradeDTO) bool {
+		for _, trade := range trades {
+			if publishErr = topic.publish(trade); publishErr != nil {
+				return false
+			}
+		}
+		return true
+	}
+
+	trades := page.Trades
+	sortTradesAscending(trades)
+	if !publish(trades) {
+		return publishErr
+	}
+
+	if err := followTrades(ctx, live, trades, publish); err != nil {
+		return err
+	}
+	return publishErr
+}
@@ -0,0 +1,353 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+)
+
+const (
+	eventSnapshot = "snapshot"
+	eventDelta    = "delta"
+
+	tradeSnapshotSize  = 50
+	candleSnapshotSize = 100
+)
+
+// emitFunc hands an update to the consumer and reports whether it is still listening
+type emitFunc func(kind string, data interface{}) bool
+
+// feed produces a snapshot followed by deltas until ctx is done or emit returns false
+type feed func(ctx context.Context, emit emitFunc) error
+
+// BookDelta carries the levels that changed since the previous update.
+// A level with zero quantity has been removed from the book.
+type BookDelta struct {
+	ExchangeID string              `json:"exchange_id"`
+	Symbol     string              `json:"symbol"`
+	Bids       []dto.PriceLevelDTO `json:"bids"`
+	Asks       []dto.PriceLevelDTO `json:"asks"`
+	Timestamp  time.Time           `json:"timestamp"`
+}
+
+// ArbitrageDelta lists opportunities that appeared or changed and the ones that closed
+type ArbitrageDelta struct {
+	Upserted []*dto.ArbitrageOpportunityDTO `json:"upserted"`
+	Closed   []*dto.ArbitrageOpportunityDTO `json:"closed"`
+}
+
+// bookFeed sends the stored book as a snapshot and then the changed levels of every update
+func bookFeed(useCase input.MarketDataUseCase, exchangeID, symbol string, depth int) feed {
+	return func(ctx context.Context, emit emitFunc) error {
+		updates, err := useCase.SubscribeOrderBook(ctx, exchangeID, symbol)
+		if err != nil {
+			return err
+		}
+
+		prev, err := useCase.GetOrderBook(ctx, exchangeID, symbol)
+		if err != nil {
+			return err
+		}
+		if prev != nil {
+			prev = limitDepth(prev, depth)
+			if !emit(eventSnapshot, prev) {
+				return nil
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case update, ok := <-updates:
+				if !ok || update == nil {
+					return nil
+				}
+				update = limitDepth(update, depth)
+
+				if prev == nil {
+					prev = update
+					if !emit(eventSnapshot, update) {
+						return nil
+					}
+					continue
+				}
+
+				delta := diffBook(prev, update)
+				prev = update
+				if len(delta.Bids) == 0 && len(delta.Asks) == 0 {
+					continue
+				}
+				if !emit(eventDelta, delta) {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// tradeFeed sends the latest trades as a snapshot and then every trade the
+// service processes. The subscription is taken before the snapshot is read,
+// so a trade stored late, after newer ones, is still delivered.
+func tradeFeed(useCase input.MarketDataUseCase, exchangeID, symbol string) feed {
+	return func(ctx context.Context, emit emitFunc) error {
+		live, err := useCase.SubscribeTrades(ctx, exchangeID, symbol)
+		if err != nil {
+			return err
+		}
+
+		page, err := useCase.GetTrades(ctx, dto.TradeQueryDTO{
+			ExchangeID: exchangeID,
+			Symbol:     symbol,
+			Limit:      tradeSnapshotSize,
+		})
+		if err != nil {
+			return err
+		}
+		trades := page.Trades
+
+		sortTradesAscending(trades)
+		if !emit(eventSnapshot, trades) {
+			return nil
+		}
+
+		return followTrades(ctx, live, trades, func(fresh []*dto.TradeDTO) bool {
+			return emit(eventDelta, fresh)
+		})
+	}
+}
+
+// candleFeed sends recent candles as a snapshot and then every candle that changed
+func candleFeed(useCase input.MarketDataUseCase, exchangeID, symbol string, interval, pollInterval time.Duration) feed {
+	return func(ctx context.Context, emit emitFunc) error {
+		query := dto.CandleQueryDTO{
+			ExchangeID: exchangeID,
+			Symbol:     symbol,
+			Interval:   interval,
+			Limit:      candleSnapshotSize,
+		}
+
+		candles, err := useCase.GetCandles(ctx, query)
+		if err != nil {
+			return err
+		}
+		if !emit(eventSnapshot, candles) {
+			return nil
+		}
+
+		last := make(map[time.Time]dto.CandleDTO, len(candles))
+		var since time.Time
+		for _, candle := range candles {
+			last[candle.OpenTime] = *candle
+			if candle.OpenTime.After(since) {
+				since = candle.OpenTime
+			}
+		}
+
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				query.From = since
+				candles, err := useCase.GetCandles(ctx, query)
+				if err != nil {
+					return err
+				}
+
+				var changed []*dto.CandleDTO
+				for _, candle := range candles {
+					if prev, ok := last[candle.OpenTime]; ok && prev == *candle {
+						continue
+					}
+					last[candle.OpenTime] = *candle
+					if candle.OpenTime.After(since) {
+						since = candle.OpenTime
+					}
+					changed = append(changed, candle)
+				}
+
+				// Only the open candle can still change; forget the closed ones
+				for openTime := range last {
+					if openTime.Before(since) {
+						delete(last, openTime)
+					}
+				}
+
+				if len(changed) == 0 {
+					continue
+				}
+				if !emit(eventDelta, changed) {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// arbitrageFeed sends open opportunities as a snapshot and then what opened, changed or closed
+func arbitrageFeed(useCase input.MarketDataUseCase, symbol string, pollInterval time.Duration) feed {
+	return func(ctx context.Context, emit emitFunc) error {
+		opportunities, err := useCase.GetArbitrageOpportunities(ctx, symbol)
+		if err != nil {
+			return err
+		}
+		if !emit(eventSnapshot, opportunities) {
+			return nil
+		}
+
+		open := indexOpportunities(opportunities)
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				opportunities, err := useCase.GetArbitrageOpportunities(ctx, symbol)
+				if err != nil {
+					return err
+				}
+
+				current := indexOpportunities(opportunities)
+				var delta ArbitrageDelta
+				for key, opportunity := range current {
+					if prev, ok := open[key]; !ok || *prev != *opportunity {
+						delta.Upserted = append(delta.Upserted, opportunity)
+					}
+				}
+				for key, opportunity := range open {
+					if _, ok := current[key]; !ok {
+						delta.Closed = append(delta.Closed, opportunity)
+					}
+				}
+				open = current
+
+				if len(delta.Upserted) == 0 && len(delta.Closed) == 0 {
+					continue
+				}
+				if !emit(eventDelta, delta) {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+func diffBook(prev, next *dto.OrderBookDTO) *BookDelta {
+	return &BookDelta{
+		ExchangeID: next.ExchangeID,
+		Symbol:     next.Symbol,
+		Bids:       diffLevels(prev.Bids, next.Bids),
+		Asks:       diffLevels(prev.Asks, next.Asks),
+		Timestamp:  next.Timestamp,
+	}
+}
+
+func diffLevels(prev, next []dto.PriceLevelDTO) []dto.PriceLevelDTO {
+	before := make(map[float64]float64, len(prev))
+	for _, level := range prev {
+		before[level.Price] = level.Quantity
+	}
+
+	var changed []dto.PriceLevelDTO
+	after := make(map[float64]struct{}, len(next))
+	for _, level := range next {
+		after[level.Price] = struct{}{}
+		if quantity, ok := before[level.Price]; !ok || quantity != level.Quantity {
+			changed = append(changed, level)
+		}
+	}
+	for _, level := range prev {
+		if _, ok := after[level.Price]; !ok {
+			changed = append(changed, dto.PriceLevelDTO{Price: level.Price})
+		}
+	}
+
+	return changed
+}
+
+// errTradesLagged ends a trade stream whose subscriber fell too far behind
+var errTradesLagged = errors.New("trade stream fell behind, resubscribe")
+
+// followTrades forwards live trades in batches of those already waiting,
+// skipping the ones the snapshot taken after subscribing already contained.
+// It returns nil once ctx is done or send reports the consumer gone.
+func followTrades(ctx context.Context, live <-chan *dto.TradeDTO, snapshot []*dto.TradeDTO, send func([]*dto.TradeDTO) bool) error {
+	sent := make(map[string]struct{}, len(snapshot))
+	for _, trade := range snapshot {
+		sent[tradeKey(trade)] = struct{}{}
+	}
+
+	for {
+		var batch []*dto.TradeDTO
+		select {
+		case <-ctx.Done():
+			return nil
+		case trade, ok := <-live:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return errTradesLagged
+			}
+			batch = append(batch, trade)
+		}
+		for n := len(live); n > 0; n-- {
+			batch = append(batch, <-live)
+		}
+
+		fresh := batch[:0]
+		for _, trade := range batch {
+			if trade == nil {
+				continue
+			}
+			key := tradeKey(trade)
+			if _, ok := sent[key]; ok {
+				delete(sent, key)
+				continue
+			}
+			fresh = append(fresh, trade)
+		}
+		if len(fresh) == 0 {
+			continue
+		}
+		if !send(fresh) {
+			return nil
+		}
+	}
+}
+
+func tradeKey(trade *dto.TradeDTO) string {
+	return trade.ExchangeID + ":" + trade.ID
+}
+
+func sortTradesAscending(trades []*dto.TradeDTO) {
+	sort.SliceStable(trades, func(i, j int) bool {
+		if !trades[i].Timestamp.Equal(trades[j].Timestamp) {
+			return trades[i].Timestamp.Before(trades[j].Timestamp)
+		}
+		if trades[i].ExchangeID != trades[j].ExchangeID {
+			return trades[i].ExchangeID < trades[j].ExchangeID
+		}
+		return trades[i].ID < trades[j].ID
+	})
+}
+
+func indexOpportunities(opportunities []*dto.ArbitrageOpportunityDTO) map[string]*dto.ArbitrageOpportunityDTO {
+	index := make(map[string]*dto.ArbitrageOpportunityDTO, len(opportunities))
+	for _, opportunity := range opportunities {
+		key := fmt.Sprintf("%s:%s:%s", opportunity.Symbol, opportunity.BuyExchange, opportunity.SellExchange)
+		index[key] = opportunity
+	}
+	return index
+}
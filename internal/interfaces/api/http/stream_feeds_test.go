@@ -0,0 +1,70 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"marketdata/internal/application/dto"
+	"marketdata/internal/application/port/input"
+)
+
+// liveTrades serves a fixed trade snapshot and streams the trades sent on live
+type liveTrades struct {
+	input.MarketDataUseCase
+	snapshot []*dto.TradeDTO
+	live     chan *dto.TradeDTO
+}
+
+func (u *liveTrades) SubscribeTrades(ctx context.Context, exchangeID, symbol string) (<-chan *dto.TradeDTO, error) {
+	return u.live, nil
+}
+
+func (u *liveTrades) GetTrades(ctx context.Context, query dto.TradeQueryDTO) (*dto.TradePageDTO, error) {
+	return &dto.TradePageDTO{Trades: u.snapshot}, nil
+}
+
+func TestTradeFeedDeliversLateTradesOnce(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	trade := func(exchangeID, id string, ts time.Time) *dto.TradeDTO {
+		return &dto.TradeDTO{ID: id, ExchangeID: exchangeID, Symbol: "BTC-USDT", Timestamp: ts}
+	}
+
+	useCase := &liveTrades{
+		snapshot: []*dto.TradeDTO{trade("binance", "2", at), trade("binance", "1", at.Add(-time.Second))},
+		live:     make(chan *dto.TradeDTO, 8),
+	}
+	// Trade 2 was processed before the snapshot was read, and trade 0 is
+	// older than everything in the snapshot but was stored after it
+	useCase.live <- trade("binance", "2", at)
+	useCase.live <- trade("binance", "0", at.Add(-time.Minute))
+	useCase.live <- trade("okx", "2", at)
+	close(useCase.live)
+
+	var kinds []string
+	var delivered []string
+	err := tradeFeed(useCase, "", "BTC-USDT")(context.Background(), func(kind string, data interface{}) bool {
+		kinds = append(kinds, kind)
+		for _, trade := range data.([]*dto.TradeDTO) {
+			delivered = append(delivered, trade.ExchangeID+":"+trade.ID)
+		}
+		return true
+	})
+	if !errors.Is(err, errTradesLagged) {
+		t.Fatalf("feed ended with %v, want errTradesLagged once the subscription closes", err)
+	}
+
+	want := []string{"binance:1", "binance:2", "binance:0", "okx:2"}
+	if len(delivered) != len(want) {
+		t.Fatalf("delivered %v, want %v", delivered, want)
+	}
+	for i := range want {
+		if delivered[i] != want[i] {
+			t.Fatalf("delivered %v, want %v", delivered, want)
+		}
+	}
+	if len(kinds) != 2 || kinds[0] != eventSnapshot || kinds[1] != eventDelta {
+		t.Fatalf("event kinds %v, want a snapshot then one delta batch", kinds)
+	}
+}
@@ -0,0 +1,350 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"marketdata/internal/application/port/input"
+	"marketdata/pkg/logger"
+)
+
+// Channels a WebSocket client can subscribe to
+const (
+	ChannelBook      = "book"
+	ChannelTrades    = "trades"
+	ChannelCandles   = "candles"
+	ChannelArbitrage = "arbitrage"
+)
+
+const (
+	defaultMaxSubscriptions  = 50
+	defaultHeartbeatInterval = 15 * time.Second
+	defaultPollInterval      = time.Second
+
+	maxClientMessageSize = 4096
+	sendBufferSize       = 256
+	writeTimeout         = 10 * time.Second
+)
+
+// StreamOptions tunes the streaming endpoints
+type StreamOptions struct {
+	MaxSubscriptions  int
+	HeartbeatInterval time.Duration
+	PollInterval      time.Duration
+}
+
+func (o StreamOptions) withDefaults() StreamOptions {
+	if o.MaxSubscriptions <= 0 {
+		o.MaxSubscriptions = defaultMaxSubscriptions
+	}
+	if o.HeartbeatInterval <= 0 {
+		o.HeartbeatInterval = defaultHeartbeatInterval
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultPollInterval
+	}
+	return o
+}
+
+// ClientMessage is sent by WebSocket clients to manage subscriptions
+type ClientMessage struct {
+	Op       string `json:"op"`
+	Channel  string `json:"channel"`
+	Exchange string `json:"exchange"`
+	Symbol   string `json:"symbol"`
+	Depth    int    `json:"depth,omitempty"`
+	Interval string `json:"interval,omitempty"`
+}
+
+// ServerMessage is sent to WebSocket clients. Type is one of subscribed,
+// unsubscribed, snapshot, delta, heartbeat or error.
+type ServerMessage struct {
+	Type     string       `json:"type"`
+	Channel  string       `json:"channel,omitempty"`
+	Exchange string       `json:"exchange,omitempty"`
+	Symbol   string       `json:"symbol,omitempty"`
+	Interval string       `json:"interval,omitempty"`
+	Data     interface{}  `json:"data,omitempty"`
+	Error    *ErrorDetail `json:"error,omitempty"`
+	Time     time.Time    `json:"time"`
+}
+
+// StreamHandler serves the WebSocket streaming endpoint
+type StreamHandler struct {
+	marketDataUseCase input.MarketDataUseCase
+	opts              StreamOptions
+	upgrader          websocket.Upgrader
+	logger            *logger.Logger
+}
+
+func NewStreamHandler(useCase input.MarketDataUseCase, opts StreamOptions, log *logger.Logger) *StreamHandler {
+	return &StreamHandler{
+		marketDataUseCase: useCase,
+		opts:              opts.withDefaults(),
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 4096,
+			CheckOrigin: func(r *http.Request) bool {
+				return true
+			},
+		},
+		logger: log,
+	}
+}
+
+func (h *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied with an HTTP error
+		return
+	}
+
+	conn := &streamConn{
+		handler: h,
+		ws:      ws,
+		send:    make(chan *ServerMessage, sendBufferSize),
+		subs:    make(map[string]*subscription),
+	}
+	conn.run(r.Context())
+}
+
+type streamConn struct {
+	handler *StreamHandler
+	ws      *websocket.Conn
+	send    chan *ServerMessage
+	cancel  context.CancelFunc
+
+	mu   sync.Mutex
+	subs map[string]*subscription
+}
+
+type subscription struct {
+	cancel context.CancelFunc
+}
+
+func (c *streamConn) run(parent context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	c.cancel = cancel
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.writeLoop(ctx)
+	}()
+
+	c.readLoop(ctx)
+	cancel()
+	<-done
+}
+
+func (c *streamConn) readLoop(ctx context.Context) {
+	heartbeat := c.handler.opts.HeartbeatInterval
+	c.ws.SetReadLimit(maxClientMessageSize)
+	c.ws.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	})
+
+	for {
+		var msg ClientMessage
+		if err := c.ws.ReadJSON(&msg); err != nil {
+			if ctx.Err() == nil && !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				c.handler.logger.Info("websocket client disconnected", "error", err)
+			}
+			return
+		}
+		c.ws.SetReadDeadline(time.Now().Add(2 * heartbeat))
+
+		switch strings.ToLower(msg.Op) {
+		case "subscribe":
+			c.subscribe(ctx, msg)
+		case "unsubscribe":
+			c.unsubscribe(msg)
+		case "ping":
+			c.enqueue(&ServerMessage{Type: "pong"})
+		default:
+			c.sendError(msg, CodeInvalidArgument, fmt.Sprintf("unknown op %q", msg.Op))
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (c *streamConn) writeLoop(ctx context.Context) {
+	ticker := time.NewTicker(c.handler.opts.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.ws.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, ""),
+				time.Now().Add(writeTimeout))
+			// Unblock the reader, which may be waiting on a silent client
+			c.ws.Close()
+			return
+		case msg := <-c.send:
+			c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := c.ws.WriteJSON(msg); err != nil {
+				c.cancel()
+				return
+			}
+		case now := <-ticker.C:
+			c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := c.ws.WriteJSON(&ServerMessage{Type: "heartbeat", Time: now.UTC()}); err != nil {
+				c.cancel()
+				return
+			}
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				c.cancel()
+				return
+			}
+		}
+	}
+}
+
+// enqueue queues a message for the writer. A client too slow to drain its
+// buffer is disconnected rather than allowed to stall the feeds.
+func (c *streamConn) enqueue(msg *ServerMessage) bool {
+	if msg.Time.IsZero() {
+		msg.Time = time.Now().UTC()
+	}
+
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		c.handler.logger.Info("disconnecting slow websocket client")
+		c.cancel()
+		return false
+	}
+}
+
+func (c *streamConn) subscribe(ctx context.Context, msg ClientMessage) {
+	f, err := c.feedFor(msg)
+	if err != nil {
+		c.sendError(msg, CodeInvalidArgument, err.Error())
+		return
+	}
+
+	key := subscriptionKey(msg)
+
+	c.mu.Lock()
+	if _, ok := c.subs[key]; ok {
+		c.mu.Unlock()
+		c.sendError(msg, CodeInvalidArgument, "already subscribed")
+		return
+	}
+	if len(c.subs) >= c.handler.opts.MaxSubscriptions {
+		c.mu.Unlock()
+		c.sendError(msg, CodeInvalidArgument, fmt.Sprintf("subscription limit of %d reached", c.handler.opts.MaxSubscriptions))
+		return
+	}
+	subCtx, cancel := context.WithCancel(ctx)
+	sub := &subscription{cancel: cancel}
+	c.subs[key] = sub
+	c.mu.Unlock()
+
+	c.enqueue(reply("subscribed", msg, nil))
+
+	go func() {
+		defer c.remove(key, sub)
+
+		err := f(subCtx, func(kind string, data interface{}) bool {
+			return subCtx.Err() == nil && c.enqueue(reply(kind, msg, data))
+		})
+		if err != nil && subCtx.Err() == nil {
+			c.sendError(msg, CodeInternal, err.Error())
+		}
+	}()
+}
+
+func (c *streamConn) unsubscribe(msg ClientMessage) {
+	key := subscriptionKey(msg)
+
+	c.mu.Lock()
+	sub, ok := c.subs[key]
+	delete(c.subs, key)
+	c.mu.Unlock()
+
+	if !ok {
+		c.sendError(msg, CodeNotFound, "not subscribed")
+		return
+	}
+
+	sub.cancel()
+	c.enqueue(reply("unsubscribed", msg, nil))
+}
+
+// remove drops a finished subscription unless it was already replaced by a resubscribe
+func (c *streamConn) remove(key string, sub *subscription) {
+	sub.cancel()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.subs[key] == sub {
+		delete(c.subs, key)
+	}
+}
+
+func (c *streamConn) feedFor(msg ClientMessage) (feed, error) {
+	useCase := c.handler.marketDataUseCase
+	poll := c.handler.opts.PollInterval
+
+	switch msg.Channel {
+	case ChannelBook:
+		if msg.Exchange == "" || msg.Symbol == "" {
+			return nil, fmt.Errorf("exchange and symbol are required")
+		}
+		return bookFeed(useCase, msg.Exchange, msg.Symbol, msg.Depth), nil
+	case ChannelTrades:
+		if msg.Symbol == "" {
+			return nil, fmt.Errorf("symbol is required")
+		}
+		return tradeFeed(useCase, msg.Exchange, msg.Symbol), nil
+	case ChannelCandles:
+		if msg.Exchange == "" || msg.Symbol == "" {
+			return nil, fmt.Errorf("exchange and symbol are required")
+		}
+		interval, err := parseInterval(msg.Interval)
+		if err != nil {
+			return nil, err
+		}
+		return candleFeed(useCase, msg.Exchange, msg.Symbol, interval, poll), nil
+	case ChannelArbitrage:
+		return arbitrageFeed(useCase, msg.Symbol, poll), nil
+	default:
+		return nil, fmt.Errorf("unknown channel %q", msg.Channel)
+	}
+}
+
+func (c *streamConn) sendError(msg ClientMessage, code, message string) {
+	resp := reply("error", msg, nil)
+	resp.Error = &ErrorDetail{Code: code, Message: message}
+	c.enqueue(resp)
+}
+
+func reply(kind string, msg ClientMessage, data interface{}) *ServerMessage {
+	return &ServerMessage{
+		Type:     kind,
+		Channel:  msg.Channel,
+		Exchange: msg.Exchange,
+		Symbol:   msg.Symbol,
+		Interval: msg.Interval,
+		Data:     data,
+	}
+}
+
+func subscriptionKey(msg ClientMessage) string {
+	return strings.Join([]string{msg.Channel, msg.Exchange, msg.Symbol, msg.Interval}, "|")
+}
@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -11,6 +13,19 @@ type Metrics struct {
 	tradeUpdates        *prometheus.CounterVec
 	exchangeErrors      *prometheus.CounterVec
 	activeSubscriptions *prometheus.GaugeVec
+	grpcRequests        *prometheus.CounterVec
+	grpcLatency         *prometheus.HistogramVec
+	tradeBatchSize      prometheus.Histogram
+	tradeFlushLatency   prometheus.Histogram
+	tradeFlushErrors    prometheus.Counter
+	tradeWriterPending  prometheus.Gauge
+	framesRecorded      *prometheus.CounterVec
+	framesDropped       *prometheus.CounterVec
+	volatility          *prometheus.GaugeVec
+	atr                 *prometheus.GaugeVec
+	range24h            *prometheus.GaugeVec
+	bookDepth           *prometheus.GaugeVec
+	bookImbalance       *prometheus.GaugeVec
 }
 
 func NewMetrics(namespace string) *Metrics {
@@ -56,6 +71,109 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"exchange", "symbol"},
 		),
+		grpcRequests: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "grpc_requests_total",
+				Help:      "Total number of gRPC requests handled",
+			},
+			[]string{"method", "code"},
+		),
+		grpcLatency: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "grpc_request_duration_seconds",
+				Help:      "Latency of gRPC requests",
+				Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+			},
+			[]string{"method"},
+		),
+		tradeBatchSize: promauto.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "trade_batch_size",
+				Help:      "Number of trades written per batch",
+				Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+			},
+		),
+		tradeFlushLatency: promauto.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "trade_flush_duration_seconds",
+				Help:      "Latency of trade batch flushes",
+				Buckets:   prometheus.ExponentialBuckets(0.001, 2, 12),
+			},
+		),
+		tradeFlushErrors: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "trade_flush_errors_total",
+				Help:      "Total number of failed trade batch flushes",
+			},
+		),
+		tradeWriterPending: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "trade_writer_pending",
+				Help:      "Number of trades buffered and not yet written",
+			},
+		),
+		framesRecorded: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "recorder_frames_total",
+				Help:      "Total number of raw exchange frames queued for recording",
+			},
+			[]string{"exchange"},
+		),
+		framesDropped: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "recorder_frames_dropped_total",
+				Help:      "Total number of raw exchange frames dropped by the recorder",
+			},
+			[]string{"exchange"},
+		),
+		volatility: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "volatility_pct",
+				Help:      "Realized volatility of mid price candle returns in percent",
+			},
+			[]string{"exchange", "symbol"},
+		),
+		atr: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "atr_pct",
+				Help:      "Average true range of mid price candles in percent of the last close",
+			},
+			[]string{"exchange", "symbol"},
+		),
+		range24h: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "range_24h_pct",
+				Help:      "Range between the 24h high and low mid price in percent of the low",
+			},
+			[]string{"exchange", "symbol"},
+		),
+		bookDepth: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "book_depth_notional",
+				Help:      "Quote notional of the orderbook within a distance of the mid price",
+			},
+			[]string{"exchange", "symbol", "side", "bps"},
+		),
+		bookImbalance: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "book_imbalance",
+				Help:      "Bid less ask share of the orderbook notional within a distance of the mid price",
+			},
+			[]string{"exchange", "symbol", "bps"},
+		),
 	}
 }
 
@@ -78,3 +196,57 @@ func (m *Metrics) RecordExchangeError(exchange, errorType string) {
 func (m *Metrics) SetActiveSubscriptions(exchange, symbol string, count float64) {
 	m.activeSubscriptions.WithLabelValues(exchange, symbol).Set(count)
 }
+
+func (m *Metrics) RecordGRPCRequest(method, code string, latency float64) {
+	m.grpcRequests.WithLabelValues(method, code).Inc()
+	m.grpcLatency.WithLabelValues(method).Observe(latency)
+}
+
+func (m *Metrics) RecordTradeFlush(batchSize int, latency float64, err error) {
+	m.tradeBatchSize.Observe(float64(batchSize))
+	m.tradeFlushLatency.Observe(latency)
+	if err != nil {
+		m.tradeFlushErrors.Inc()
+	}
+}
+
+func (m *Metrics) SetTradeWriterPending(count float64) {
+	m.tradeWriterPending.Set(count)
+}
+
+func (m *Metrics) RecordFrameRecorded(exchange string) {
+	m.framesRecorded.WithLabelValues(exchange).Inc()
+}
+
+func (m *Metrics) RecordFrameDropped(exchange string) {
+	m.framesDropped.WithLabelValues(exchange).Inc()
+}
+
+func (m *Metrics) SetVolatility(exchange, symbol string, pct float64) {
+	m.volatility.WithLabelValues(exchange, symbol).Set(pct)
+}
+
+func (m *Metrics) SetATR(exchange, symbol string, pct float64) {
+	m.atr.WithLabelValues(exchange, symbol).Set(pct)
+}
+
+func (m *Metrics) SetRange24h(exchange, symbol string, pct float64) {
+	m.range24h.WithLabelValues(exchange, symbol).Set(pct)
+}
+
+func (m *Metrics) SetBookDepth(exchange, symbol, side string, bps, notional float64) {
+	m.bookDepth.WithLabelValues(exchange, symbol, side, strconv.FormatFloat(bps, 'f', -1, 64)).Set(notional)
+}
+
+func (m *Metrics) SetBookImbalance(exchange, symbol string, bps, imbalance float64) {
+	m.bookImbalance.WithLabelValues(exchange, symbol, strconv.FormatFloat(bps, 'f', -1, 64)).Set(imbalance)
+}
+
+// DeleteIndicators removes the indicator gauges of a symbol, on every side and
+// distance
+func (m *Metrics) DeleteIndicators(exchange, symbol string) {
+	labels := prometheus.Labels{"exchange": exchange, "symbol": symbol}
+	for _, gauge := range []*prometheus.GaugeVec{m.volatility, m.atr, m.range24h, m.bookDepth, m.bookImbalance} {
+		gauge.DeletePartialMatch(labels)
+	}
+}